@@ -202,6 +202,18 @@ func CombinedAuthContext(next http.Handler) http.Handler {
 	})
 }
 
+// OptionalPubKeyContext sets the pubkey of callers that send a token and lets
+// anonymous callers through, handlers decide what they may see
+func OptionalPubKeyContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") == "" && r.Header.Get("x-jwt") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		PubKeyContext(next).ServeHTTP(w, r)
+	})
+}
+
 // ConnectionContext parses token for connection code
 // ConnectionCodeContext godoc
 //
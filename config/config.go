@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -48,6 +49,42 @@ var V2BotToken string
 var IsV2Payment bool = false
 var FfWebsocket bool = false
 var SWAuth string
+var RateLimitEnabled bool = true
+var RateLimitPolicies string
+
+// TrustedProxies are the IPs or CIDRs whose X-Forwarded-For and X-Real-IP
+// headers are believed when keying rate limits by client IP
+var TrustedProxies []string
+var TrashRetentionDays int = 30
+
+// blob stores for uploads and generated exports, one of local, s3 or meme
+var BlobStore string
+var ExportBlobStore string
+var BlobLocalDir string
+var BlobPublicUrl string
+var S3Endpoint string
+
+// unreferenced chat files are archived after FileArchiveDays and removed from
+// the blob store FileRetentionDays later, WorkspaceStorageQuota is in bytes
+// and 0 turns it off
+var FileArchiveDays int = 30
+var FileRetentionDays int = 30
+var WorkspaceStorageQuota int64 = 1 << 30
+
+// context tags resolved into a chat message are cut to ContextTagMaxChars
+// each and ContextTagsMaxChars together
+var ContextTagMaxChars int = 8000
+var ContextTagsMaxChars int = 32000
+
+// MaxSSEClients caps the remote SSE streams followed at the same time, a
+// stream sending nothing for SSEClientIdleMinutes is considered finished
+var MaxSSEClients int = 100
+var SSEClientIdleMinutes int = 30
+
+// outbound webhooks are signed with WebhookSecret and given up on after
+// WebhookMaxAttempts, a dead delivery can still be replayed
+var WebhookSecret string
+var WebhookMaxAttempts int = 8
 
 func InitConfig() {
 	Host = os.Getenv("LN_SERVER_BASE_URL")
@@ -71,6 +108,47 @@ func InitConfig() {
 	FfWebsocket = os.Getenv("FF_WEBSOCKET") == "true"
 	LogLevel = strings.ToUpper(os.Getenv("LOG_LEVEL"))
 	SWAuth = os.Getenv("SWAUTH")
+	RateLimitEnabled = os.Getenv("RATE_LIMIT_ENABLED") != "false"
+	RateLimitPolicies = os.Getenv("RATE_LIMIT_POLICIES")
+	TrustedProxies = nil
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TrustedProxies = append(TrustedProxies, proxy)
+		}
+	}
+	BlobStore = strings.ToLower(os.Getenv("BLOB_STORE"))
+	ExportBlobStore = strings.ToLower(os.Getenv("EXPORT_BLOB_STORE"))
+	BlobLocalDir = os.Getenv("BLOB_LOCAL_DIR")
+	BlobPublicUrl = os.Getenv("BLOB_PUBLIC_URL")
+	S3Endpoint = os.Getenv("S3_ENDPOINT")
+	if days, err := strconv.Atoi(os.Getenv("FILE_ARCHIVE_DAYS")); err == nil && days > 0 {
+		FileArchiveDays = days
+	}
+	if days, err := strconv.Atoi(os.Getenv("FILE_RETENTION_DAYS")); err == nil && days > 0 {
+		FileRetentionDays = days
+	}
+	if mb, err := strconv.ParseInt(os.Getenv("WORKSPACE_STORAGE_QUOTA_MB"), 10, 64); err == nil && mb >= 0 {
+		WorkspaceStorageQuota = mb << 20
+	}
+	if chars, err := strconv.Atoi(os.Getenv("CONTEXT_TAG_MAX_CHARS")); err == nil && chars > 0 {
+		ContextTagMaxChars = chars
+	}
+	if chars, err := strconv.Atoi(os.Getenv("CONTEXT_TAGS_MAX_CHARS")); err == nil && chars > 0 {
+		ContextTagsMaxChars = chars
+	}
+	if clients, err := strconv.Atoi(os.Getenv("MAX_SSE_CLIENTS")); err == nil && clients > 0 {
+		MaxSSEClients = clients
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("SSE_CLIENT_IDLE_MINUTES")); err == nil && minutes > 0 {
+		SSEClientIdleMinutes = minutes
+	}
+	WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	if attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		WebhookMaxAttempts = attempts
+	}
+	if days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && days > 0 {
+		TrashRetentionDays = days
+	}
 
 	// Add to super admins
 	SuperAdmins = StripSuperAdmins(AdminStrings)
@@ -94,7 +172,13 @@ func InitConfig() {
 	}
 
 	// create a s3 client session
-	S3Client = s3.NewFromConfig(awsConfig)
+	S3Client = s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+		// S3 compatible servers such as minio serve buckets by path
+		if S3Endpoint != "" {
+			o.BaseEndpoint = aws.String(S3Endpoint)
+			o.UsePathStyle = true
+		}
+	})
 	PresignClient = s3.NewPresignClient(S3Client)
 
 	// only make this call if there is a Relay auth key
@@ -136,6 +220,22 @@ func InitConfig() {
 		S3Url = "https://sphinx-tribes.s3.amazonaws.com"
 	}
 
+	if BlobStore == "" {
+		BlobStore = "meme"
+	}
+
+	if ExportBlobStore == "" {
+		ExportBlobStore = "s3"
+	}
+
+	if BlobLocalDir == "" {
+		BlobLocalDir = "./uploads/blobs"
+	}
+
+	if BlobPublicUrl == "" {
+		BlobPublicUrl = Host + "/blobs"
+	}
+
 	if JarvisUrl == "" {
 		JarvisUrl = ""
 	}
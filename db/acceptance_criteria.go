@@ -0,0 +1,386 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAcceptanceCriterionUnknown = errors.New("acceptance criterion does not belong to the feature")
+	ErrTraceItemNotFound          = errors.New("ticket or bounty not found in the feature")
+)
+
+type StoryCoverage string
+
+const (
+	StoryCovered   StoryCoverage = "covered"
+	StoryPartial   StoryCoverage = "partial"
+	StoryUntouched StoryCoverage = "untouched"
+)
+
+// CriterionTrace is an acceptance criterion with the tickets and bounties
+// linked to it. A criterion is satisfied once one of them reached PAY or
+// COMPLETED.
+type CriterionTrace struct {
+	AcceptanceCriterion
+	Items     []BoardCard `json:"items"`
+	Covered   bool        `json:"covered"`
+	Satisfied bool        `json:"satisfied"`
+}
+
+type StoryTrace struct {
+	Story          FeatureStory     `json:"story"`
+	Coverage       StoryCoverage    `json:"coverage"`
+	Criteria       []CriterionTrace `json:"criteria"`
+	CoveredCount   int              `json:"covered_count"`
+	SatisfiedCount int              `json:"satisfied_count"`
+}
+
+// FeatureTraceability shows how far the tickets and bounties of a feature
+// cover the acceptance criteria of its stories
+type FeatureTraceability struct {
+	FeatureUuid string       `json:"feature_uuid"`
+	Stories     []StoryTrace `json:"stories"`
+	Covered     int          `json:"covered"`
+	Partial     int          `json:"partial"`
+	Untouched   int          `json:"untouched"`
+	// Unlinked are the tickets and bounties that satisfy no criterion
+	Unlinked []BoardCard `json:"unlinked"`
+}
+
+func criterionSatisfied(status TicketStatus) bool {
+	return status == PayTicket || status == CompletedTicket
+}
+
+// BuildFeatureTraceability matches the links against the latest ticket
+// versions and the bounties of a feature. Links to deleted items are ignored.
+func BuildFeatureTraceability(featureUuid string, stories []FeatureStory, criteria []AcceptanceCriterion, links []AcceptanceCriterionLink, tickets []Tickets, bounties []NewBounty) FeatureTraceability {
+	cards := map[string]BoardCard{}
+	var order []string
+	for _, ticket := range LatestTicketsByGroup(tickets) {
+		card := ticketBoardCard(ticket)
+		key := string(card.ItemType) + ":" + card.ItemKey
+		cards[key] = card
+		order = append(order, key)
+	}
+	for _, bounty := range bounties {
+		card := bountyBoardCard(bounty)
+		key := string(card.ItemType) + ":" + card.ItemKey
+		cards[key] = card
+		order = append(order, key)
+	}
+
+	linked := map[string][]BoardCard{}
+	used := map[string]bool{}
+	for _, link := range links {
+		key := string(link.ItemType) + ":" + link.ItemKey
+		card, ok := cards[key]
+		if !ok {
+			continue
+		}
+		linked[link.CriterionUuid] = append(linked[link.CriterionUuid], card)
+		used[key] = true
+	}
+
+	criteriaOf := map[string][]AcceptanceCriterion{}
+	for _, criterion := range criteria {
+		criteriaOf[criterion.StoryUuid] = append(criteriaOf[criterion.StoryUuid], criterion)
+	}
+
+	report := FeatureTraceability{
+		FeatureUuid: featureUuid,
+		Stories:     []StoryTrace{},
+		Unlinked:    []BoardCard{},
+	}
+	for _, story := range stories {
+		storyCriteria := criteriaOf[story.Uuid]
+		sort.SliceStable(storyCriteria, func(i, j int) bool {
+			return storyCriteria[i].Position < storyCriteria[j].Position
+		})
+		story.AcceptanceCriteria = storyCriteria
+
+		trace := StoryTrace{Story: story, Criteria: []CriterionTrace{}}
+		for _, criterion := range storyCriteria {
+			entry := CriterionTrace{AcceptanceCriterion: criterion, Items: []BoardCard{}}
+			for _, card := range linked[criterion.Uuid] {
+				entry.Items = append(entry.Items, card)
+				entry.Covered = true
+				if criterionSatisfied(card.Status) {
+					entry.Satisfied = true
+				}
+			}
+			if entry.Covered {
+				trace.CoveredCount++
+			}
+			if entry.Satisfied {
+				trace.SatisfiedCount++
+			}
+			trace.Criteria = append(trace.Criteria, entry)
+		}
+
+		switch {
+		case trace.CoveredCount == 0:
+			trace.Coverage = StoryUntouched
+			report.Untouched++
+		case trace.CoveredCount == len(trace.Criteria):
+			trace.Coverage = StoryCovered
+			report.Covered++
+		default:
+			trace.Coverage = StoryPartial
+			report.Partial++
+		}
+		report.Stories = append(report.Stories, trace)
+	}
+
+	for _, key := range order {
+		if !used[key] {
+			report.Unlinked = append(report.Unlinked, cards[key])
+		}
+	}
+
+	return report
+}
+
+// saveStoryCriteria replaces the criteria of a story with the given list.
+// Criteria keep their uuid when it is sent back, the links of removed
+// criteria are dropped with them.
+func saveStoryCriteria(tx *gorm.DB, story FeatureStory) ([]AcceptanceCriterion, error) {
+	var existing []AcceptanceCriterion
+	if err := tx.Where("story_uuid = ?", story.Uuid).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	current := map[string]AcceptanceCriterion{}
+	for _, criterion := range existing {
+		current[criterion.Uuid] = criterion
+	}
+
+	now := time.Now()
+	kept := map[string]bool{}
+	saved := make([]AcceptanceCriterion, 0, len(story.AcceptanceCriteria))
+	for _, criterion := range story.AcceptanceCriteria {
+		criterion.Description = strings.TrimSpace(criterion.Description)
+		if criterion.Description == "" {
+			continue
+		}
+		criterion.StoryUuid = story.Uuid
+		criterion.FeatureUuid = story.FeatureUuid
+		criterion.Position = len(saved)
+		criterion.Updated = &now
+
+		if previous, ok := current[criterion.Uuid]; ok && !kept[criterion.Uuid] {
+			criterion.ID = previous.ID
+			criterion.Created = previous.Created
+			if err := tx.Save(&criterion).Error; err != nil {
+				return nil, err
+			}
+		} else {
+			criterion.ID = 0
+			criterion.Uuid = xid.New().String()
+			criterion.Created = &now
+			if err := tx.Create(&criterion).Error; err != nil {
+				return nil, err
+			}
+		}
+		kept[criterion.Uuid] = true
+		saved = append(saved, criterion)
+	}
+
+	var removed []string
+	for _, criterion := range existing {
+		if !kept[criterion.Uuid] {
+			removed = append(removed, criterion.Uuid)
+		}
+	}
+	if len(removed) > 0 {
+		if err := tx.Where("criterion_uuid IN ?", removed).Delete(&AcceptanceCriterionLink{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("uuid IN ?", removed).Delete(&AcceptanceCriterion{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return saved, nil
+}
+
+// attachStoryCriteria loads the acceptance criteria of the stories in order
+func (db database) attachStoryCriteria(stories []FeatureStory) error {
+	if len(stories) == 0 {
+		return nil
+	}
+	uuids := make([]string, 0, len(stories))
+	for _, story := range stories {
+		uuids = append(uuids, story.Uuid)
+	}
+
+	var criteria []AcceptanceCriterion
+	if err := db.db.Where("story_uuid IN ?", uuids).Order("position ASC").Find(&criteria).Error; err != nil {
+		return fmt.Errorf("failed to fetch acceptance criteria: %w", err)
+	}
+	byStory := map[string][]AcceptanceCriterion{}
+	for _, criterion := range criteria {
+		byStory[criterion.StoryUuid] = append(byStory[criterion.StoryUuid], criterion)
+	}
+	for i := range stories {
+		stories[i].AcceptanceCriteria = byStory[stories[i].Uuid]
+		if stories[i].AcceptanceCriteria == nil {
+			stories[i].AcceptanceCriteria = []AcceptanceCriterion{}
+		}
+	}
+	return nil
+}
+
+// resolveTraceItem checks that a ticket or a bounty is part of the feature and
+// returns the key its links are stored under. Tickets can be addressed by the
+// uuid of any version and resolve to their group.
+func (db database) resolveTraceItem(featureUuid string, itemType BoardItemType, itemKey string) (string, error) {
+	switch itemType {
+	case BoardTicket:
+		var ticket Tickets
+		result := db.db.Where("feature_uuid = ? AND (ticket_group::text = ? OR uuid::text = ?)", featureUuid, itemKey, itemKey).
+			Limit(1).Find(&ticket)
+		if result.Error != nil {
+			return "", fmt.Errorf("failed to fetch ticket %s: %w", itemKey, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return ticketGroupOf(ticket).String(), nil
+		}
+	case BoardBounty:
+		id, err := strconv.ParseUint(itemKey, 10, 64)
+		if err != nil {
+			break
+		}
+		var count int64
+		phases := db.db.Model(&FeaturePhase{}).Select("uuid").Where("feature_uuid = ?", featureUuid)
+		if err := db.db.Model(&NewBounty{}).
+			Where("id = ? AND (feature_uuid = ? OR phase_uuid IN (?))", id, featureUuid, phases).
+			Count(&count).Error; err != nil {
+			return "", fmt.Errorf("failed to fetch bounty %s: %w", itemKey, err)
+		}
+		if count > 0 {
+			return strconv.FormatUint(id, 10), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s %s", ErrTraceItemNotFound, itemType, itemKey)
+}
+
+func (db database) GetAcceptanceCriterionLinks(featureUuid string, itemType BoardItemType, itemKey string) ([]AcceptanceCriterionLink, error) {
+	links := []AcceptanceCriterionLink{}
+	if err := db.db.Where("feature_uuid = ? AND item_type = ? AND item_key = ?", featureUuid, itemType, itemKey).
+		Order("id ASC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch acceptance criterion links: %w", err)
+	}
+	return links, nil
+}
+
+// SetAcceptanceCriterionLinks replaces the criteria a ticket group or a bounty
+// of the feature satisfies
+func (db database) SetAcceptanceCriterionLinks(featureUuid string, itemType BoardItemType, itemKey string, criteria []string, pubkey string) ([]AcceptanceCriterionLink, error) {
+	itemKey, err := db.resolveTraceItem(featureUuid, itemType, itemKey)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, criterion := range criteria {
+		if !seen[criterion] {
+			seen[criterion] = true
+			unique = append(unique, criterion)
+		}
+	}
+	if len(unique) > 0 {
+		var count int64
+		if err := db.db.Model(&AcceptanceCriterion{}).Where("feature_uuid = ? AND uuid IN ?", featureUuid, unique).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch acceptance criteria: %w", err)
+		}
+		if int(count) != len(unique) {
+			return nil, ErrAcceptanceCriterionUnknown
+		}
+	}
+
+	now := time.Now()
+	links := make([]AcceptanceCriterionLink, 0, len(unique))
+	for _, criterion := range unique {
+		links = append(links, AcceptanceCriterionLink{
+			CriterionUuid: criterion,
+			FeatureUuid:   featureUuid,
+			ItemType:      itemType,
+			ItemKey:       itemKey,
+			CreatedBy:     pubkey,
+			Created:       now,
+		})
+	}
+
+	err = db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("item_type = ? AND item_key = ?", itemType, itemKey).Delete(&AcceptanceCriterionLink{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save acceptance criterion links: %w", err)
+	}
+
+	return links, nil
+}
+
+// copyTicketCriterionLinks lets a bounty created from a ticket satisfy the
+// same criteria as the ticket
+func (db database) copyTicketCriterionLinks(ticket Tickets, bounty NewBounty) error {
+	var links []AcceptanceCriterionLink
+	if err := db.db.Where("item_type = ? AND item_key = ?", BoardTicket, ticketGroupOf(ticket).String()).Find(&links).Error; err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range links {
+		links[i].ID = 0
+		links[i].ItemType = BoardBounty
+		links[i].ItemKey = strconv.FormatUint(uint64(bounty.ID), 10)
+		links[i].Created = now
+	}
+	return db.db.Create(&links).Error
+}
+
+func (db database) GetFeatureTraceability(featureUuid string) (FeatureTraceability, error) {
+	var stories []FeatureStory
+	if err := db.db.Where("feature_uuid = ?", featureUuid).Order("priority ASC").Find(&stories).Error; err != nil {
+		return FeatureTraceability{}, fmt.Errorf("failed to fetch stories: %w", err)
+	}
+
+	var criteria []AcceptanceCriterion
+	if err := db.db.Where("feature_uuid = ?", featureUuid).Find(&criteria).Error; err != nil {
+		return FeatureTraceability{}, fmt.Errorf("failed to fetch acceptance criteria: %w", err)
+	}
+
+	var links []AcceptanceCriterionLink
+	if err := db.db.Where("feature_uuid = ?", featureUuid).Find(&links).Error; err != nil {
+		return FeatureTraceability{}, fmt.Errorf("failed to fetch acceptance criterion links: %w", err)
+	}
+
+	tickets, err := db.GetTicketsByFeatureUUID(featureUuid)
+	if err != nil {
+		return FeatureTraceability{}, err
+	}
+
+	bounties, err := db.GetBountiesByFeatureUuid(featureUuid)
+	if err != nil {
+		return FeatureTraceability{}, err
+	}
+
+	return BuildFeatureTraceability(featureUuid, stories, criteria, links, tickets, bounties), nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFeatureTraceability(t *testing.T) {
+	stories := []FeatureStory{
+		{Uuid: "login", FeatureUuid: "feature", Priority: 1},
+		{Uuid: "logout", FeatureUuid: "feature", Priority: 2},
+		{Uuid: "profile", FeatureUuid: "feature", Priority: 3},
+	}
+	criteria := []AcceptanceCriterion{
+		{Uuid: "login-2", StoryUuid: "login", Position: 1},
+		{Uuid: "login-1", StoryUuid: "login", Position: 0},
+		{Uuid: "logout-1", StoryUuid: "logout", Position: 0},
+		{Uuid: "logout-2", StoryUuid: "logout", Position: 1},
+		{Uuid: "profile-1", StoryUuid: "profile", Position: 0},
+	}
+
+	done := newGraphTicket("done", 0, CompletedTicket)
+	working := newGraphTicket("working", 1, InProgressTicket)
+	spare := newGraphTicket("spare", 2, DraftTicket)
+	paid := NewBounty{ID: 7, Title: "paid", Paid: true}
+
+	links := []AcceptanceCriterionLink{
+		{CriterionUuid: "login-1", ItemType: BoardTicket, ItemKey: ticketGroupOf(done).String()},
+		{CriterionUuid: "login-2", ItemType: BoardBounty, ItemKey: "7"},
+		{CriterionUuid: "logout-1", ItemType: BoardTicket, ItemKey: ticketGroupOf(working).String()},
+		{CriterionUuid: "profile-1", ItemType: BoardBounty, ItemKey: "99"},
+	}
+
+	report := BuildFeatureTraceability("feature", stories, criteria, links, []Tickets{done, working, spare}, []NewBounty{paid})
+
+	t.Run("Should classify story coverage", func(t *testing.T) {
+		assert.Equal(t, StoryCovered, report.Stories[0].Coverage)
+		assert.Equal(t, StoryPartial, report.Stories[1].Coverage)
+		assert.Equal(t, StoryUntouched, report.Stories[2].Coverage)
+		assert.Equal(t, 1, report.Covered)
+		assert.Equal(t, 1, report.Partial)
+		assert.Equal(t, 1, report.Untouched)
+	})
+
+	t.Run("Should order criteria and mark satisfied ones", func(t *testing.T) {
+		login := report.Stories[0]
+		assert.Equal(t, "login-1", login.Criteria[0].Uuid)
+		assert.Equal(t, 2, login.SatisfiedCount)
+		assert.Len(t, login.Story.AcceptanceCriteria, 2)
+
+		logout := report.Stories[1]
+		assert.True(t, logout.Criteria[0].Covered)
+		assert.False(t, logout.Criteria[0].Satisfied)
+		assert.Equal(t, 0, logout.SatisfiedCount)
+	})
+
+	t.Run("Should list tickets that satisfy no criterion", func(t *testing.T) {
+		assert.Len(t, report.Unlinked, 1)
+		assert.Equal(t, "spare", report.Unlinked[0].Title)
+	})
+}
@@ -0,0 +1,369 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBoardCardNotFound = errors.New("board card not found")
+	ErrBoardWipLimit     = errors.New("board column is at its work in progress limit")
+	ErrBoardBountyStatus = errors.New("bounty status follows its assignment and payment and can not be moved to another column")
+)
+
+// BoardStatuses are the board columns in order
+var BoardStatuses = []TicketStatus{DraftTicket, ReadyTicket, InProgressTicket, TestTicket, DeployTicket, PayTicket, CompletedTicket}
+
+// BountyCardStatus derives the status of a bounty card from its assignment,
+// proof of work and payment
+func BountyCardStatus(bounty NewBounty) BountyStatus {
+	if bounty.Paid {
+		return StatusPaid
+	}
+	if bounty.Completed || bounty.PaymentPending {
+		return StatusComplete
+	}
+	if bounty.Assignee == "" {
+		return StatusTodo
+	}
+	if bounty.Assignee != "" && bounty.ProofOfWorkCount == 0 {
+		return StatusInProgress
+	}
+	if bounty.Assignee != "" && bounty.ProofOfWorkCount > 0 {
+		return StatusInReview
+	}
+
+	return StatusTodo
+}
+
+// bountyBoardStatus places a bounty in the ticket column matching its status
+func bountyBoardStatus(bounty NewBounty) TicketStatus {
+	switch BountyCardStatus(bounty) {
+	case StatusInProgress:
+		return InProgressTicket
+	case StatusInReview:
+		return TestTicket
+	case StatusComplete:
+		return PayTicket
+	case StatusPaid:
+		return CompletedTicket
+	default:
+		return ReadyTicket
+	}
+}
+
+func ticketBoardCard(ticket Tickets) BoardCard {
+	group := ticketGroupOf(ticket)
+	id := ticket.UUID
+	card := BoardCard{
+		ItemType:    BoardTicket,
+		ItemKey:     group.String(),
+		TicketUUID:  &id,
+		TicketGroup: &group,
+		Title:       ticket.Name,
+		Status:      ticket.Status,
+		Position:    -1,
+		FeatureUUID: ticket.FeatureUUID,
+		PhaseUUID:   ticket.PhaseUUID,
+	}
+	if card.Status == "" {
+		card.Status = DraftTicket
+	}
+	if ticket.Category != nil {
+		card.Category = string(*ticket.Category)
+	}
+	if ticket.Amount != nil {
+		card.Amount = *ticket.Amount
+	}
+	return card
+}
+
+func bountyBoardCard(bounty NewBounty) BoardCard {
+	return BoardCard{
+		ItemType:    BoardBounty,
+		ItemKey:     strconv.FormatUint(uint64(bounty.ID), 10),
+		BountyID:    bounty.ID,
+		Title:       bounty.Title,
+		Status:      bountyBoardStatus(bounty),
+		Position:    -1,
+		FeatureUUID: bounty.FeatureUuid,
+		PhaseUUID:   bounty.PhaseUuid,
+		Assignee:    bounty.Assignee,
+		Category:    bounty.WantedType,
+		Amount:      int64(bounty.Price),
+	}
+}
+
+func (f BoardFilter) matches(card BoardCard) bool {
+	if f.FeatureUUID != "" && card.FeatureUUID != f.FeatureUUID {
+		return false
+	}
+	if f.PhaseUUID != "" && card.PhaseUUID != f.PhaseUUID {
+		return false
+	}
+	if f.Assignee != "" && card.Assignee != f.Assignee {
+		return false
+	}
+	if f.Category != "" && card.Category != f.Category {
+		return false
+	}
+	return true
+}
+
+// BuildBoard groups the latest ticket versions and the bounties of a workspace
+// into status columns. Column counts ignore the filter so WIP limits always
+// reflect the whole workspace.
+func BuildBoard(workspaceUuid string, tickets []Tickets, bounties []NewBounty, positions []BoardPosition, limits []BoardColumnConfig, filter BoardFilter) Board {
+	stored := map[string]int{}
+	for _, p := range positions {
+		stored[string(p.ItemType)+":"+p.ItemKey] = p.Position
+	}
+
+	columns := map[TicketStatus][]BoardCard{}
+	add := func(card BoardCard, fallback int) {
+		if position, ok := stored[string(card.ItemType)+":"+card.ItemKey]; ok {
+			card.Position = position
+		} else {
+			card.Position = len(stored) + fallback
+		}
+		columns[card.Status] = append(columns[card.Status], card)
+	}
+	for i, ticket := range LatestTicketsByGroup(tickets) {
+		add(ticketBoardCard(ticket), i)
+	}
+	for i, bounty := range bounties {
+		add(bountyBoardCard(bounty), len(tickets)+i)
+	}
+
+	wip := map[TicketStatus]int{}
+	for _, limit := range limits {
+		wip[limit.Status] = limit.WipLimit
+	}
+
+	board := Board{WorkspaceUuid: workspaceUuid, Columns: []BoardColumn{}}
+	for _, status := range BoardStatuses {
+		cards := columns[status]
+		sort.SliceStable(cards, func(i, j int) bool {
+			return cards[i].Position < cards[j].Position
+		})
+
+		column := BoardColumn{
+			Status:   status,
+			WipLimit: wip[status],
+			Count:    len(cards),
+			Cards:    []BoardCard{},
+		}
+		column.OverLimit = column.WipLimit > 0 && column.Count > column.WipLimit
+		for i, card := range cards {
+			card.Position = i
+			if filter.matches(card) {
+				column.Cards = append(column.Cards, card)
+			}
+		}
+		board.Columns = append(board.Columns, column)
+	}
+
+	return board
+}
+
+func loadBoard(tx *gorm.DB, workspaceUuid string, filter BoardFilter) (Board, error) {
+	var tickets []Tickets
+	features := tx.Model(&WorkspaceFeatures{}).Select("uuid").Where("workspace_uuid = ?", workspaceUuid)
+	if err := tx.Where("workspace_uuid = ? OR feature_uuid IN (?)", workspaceUuid, features).Find(&tickets).Error; err != nil {
+		return Board{}, fmt.Errorf("failed to fetch board tickets: %w", err)
+	}
+
+	var bounties []NewBounty
+	if err := tx.Where("workspace_uuid = ?", workspaceUuid).Order("created ASC").Find(&bounties).Error; err != nil {
+		return Board{}, fmt.Errorf("failed to fetch board bounties: %w", err)
+	}
+
+	var positions []BoardPosition
+	if err := tx.Where("workspace_uuid = ?", workspaceUuid).Find(&positions).Error; err != nil {
+		return Board{}, fmt.Errorf("failed to fetch board positions: %w", err)
+	}
+
+	var limits []BoardColumnConfig
+	if err := tx.Where("workspace_uuid = ?", workspaceUuid).Find(&limits).Error; err != nil {
+		return Board{}, fmt.Errorf("failed to fetch board limits: %w", err)
+	}
+
+	return BuildBoard(workspaceUuid, tickets, bounties, positions, limits, filter), nil
+}
+
+func (db database) GetWorkspaceBoard(workspaceUuid string, filter BoardFilter) (Board, error) {
+	return loadBoard(db.db, workspaceUuid, filter)
+}
+
+// SetBoardColumnLimits replaces the WIP limits of the workspace board
+func (db database) SetBoardColumnLimits(workspaceUuid string, limits []BoardColumnConfig) ([]BoardColumnConfig, error) {
+	for _, limit := range limits {
+		if !IsValidTicketStatus(limit.Status) {
+			return nil, fmt.Errorf("invalid board column %q", limit.Status)
+		}
+		if limit.WipLimit < 0 {
+			return nil, fmt.Errorf("wip limit of %s can not be negative", limit.Status)
+		}
+	}
+
+	now := time.Now()
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_uuid = ?", workspaceUuid).Delete(&BoardColumnConfig{}).Error; err != nil {
+			return err
+		}
+		for i := range limits {
+			limits[i].ID = 0
+			limits[i].WorkspaceUuid = workspaceUuid
+			limits[i].UpdatedAt = now
+		}
+		if len(limits) == 0 {
+			return nil
+		}
+		return tx.Create(&limits).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save board limits: %w", err)
+	}
+
+	return limits, nil
+}
+
+// MoveBoardCard changes the status and position of a card in one transaction.
+// Ticket moves go through the workspace workflow and dependency checks, bounty
+// cards can only be reordered inside their column.
+func (db database) MoveBoardCard(workspaceUuid string, pubkey string, move BoardMove) (BoardMoveResult, error) {
+	var result BoardMoveResult
+	if !IsValidTicketStatus(move.ToStatus) {
+		return result, fmt.Errorf("invalid board column %q", move.ToStatus)
+	}
+
+	var moved Tickets
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		// moves of one workspace run one at a time so WIP counts stay accurate
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "board:"+workspaceUuid).Error; err != nil {
+			return fmt.Errorf("failed to lock board: %w", err)
+		}
+
+		board, err := loadBoard(tx, workspaceUuid, BoardFilter{})
+		if err != nil {
+			return err
+		}
+
+		var card *BoardCard
+		var target *BoardColumn
+		for c := range board.Columns {
+			column := &board.Columns[c]
+			if column.Status == move.ToStatus {
+				target = column
+			}
+			for i := range column.Cards {
+				if column.Cards[i].ItemType == move.ItemType && column.Cards[i].ItemKey == move.ItemKey {
+					found := column.Cards[i]
+					card = &found
+				}
+			}
+		}
+		if card == nil {
+			return fmt.Errorf("%w: %s %s", ErrBoardCardNotFound, move.ItemType, move.ItemKey)
+		}
+		result.FromStatus = card.Status
+
+		if card.Status != move.ToStatus {
+			if card.ItemType == BoardBounty {
+				return ErrBoardBountyStatus
+			}
+			if target.WipLimit > 0 && target.Count >= target.WipLimit {
+				return fmt.Errorf("%w: %s allows %d cards", ErrBoardWipLimit, target.Status, target.WipLimit)
+			}
+
+			if err := tx.Where("uuid = ?", card.TicketUUID).First(&moved).Error; err != nil {
+				return fmt.Errorf("failed to fetch ticket: %w", err)
+			}
+			if err := keepTicketVersion(tx, moved); err != nil {
+				return fmt.Errorf("failed to record ticket version: %w", err)
+			}
+			moved.Status = move.ToStatus
+			rule, err := db.CheckTicketTransition(pubkey, result.FromStatus, moved)
+			if err != nil {
+				return err
+			}
+			result.Rule = rule
+			if err := checkTicketDependencies(tx, moved); err != nil {
+				return err
+			}
+
+			// the move is a new version of the ticket, so the snapshot of the
+			// version it leaves stays in the history
+			version, err := nextTicketVersion(tx, moved)
+			if err != nil {
+				return err
+			}
+			moved.Version = version
+			moved.UpdatedAt = time.Now()
+			if err := tx.Model(&Tickets{}).Where("uuid = ?", moved.UUID).Updates(map[string]interface{}{
+				"status":     moved.Status,
+				"version":    moved.Version,
+				"updated_at": moved.UpdatedAt,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to move ticket: %w", err)
+			}
+			if err := saveTicketVersion(tx, moved); err != nil {
+				return fmt.Errorf("failed to record ticket version: %w", err)
+			}
+			card.Status = move.ToStatus
+		}
+
+		cards := []BoardCard{}
+		for _, c := range target.Cards {
+			if c.ItemType != card.ItemType || c.ItemKey != card.ItemKey {
+				cards = append(cards, c)
+			}
+		}
+		position := move.Position
+		if position < 0 {
+			position = 0
+		}
+		if position > len(cards) {
+			position = len(cards)
+		}
+		cards = append(cards[:position], append([]BoardCard{*card}, cards[position:]...)...)
+
+		now := time.Now()
+		positions := make([]BoardPosition, 0, len(cards))
+		for i, c := range cards {
+			positions = append(positions, BoardPosition{
+				WorkspaceUuid: workspaceUuid,
+				ItemType:      c.ItemType,
+				ItemKey:       c.ItemKey,
+				Position:      i,
+				UpdatedAt:     now,
+			})
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "workspace_uuid"}, {Name: "item_type"}, {Name: "item_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"position", "updated_at"}),
+		}).Create(&positions).Error; err != nil {
+			return fmt.Errorf("failed to save board positions: %w", err)
+		}
+
+		card.Position = position
+		result.Card = *card
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if moved.UUID != uuid.Nil {
+		result.Ticket = &moved
+	}
+
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBoard(t *testing.T) {
+	group := uuid.New()
+	older := Tickets{UUID: uuid.New(), TicketGroup: &group, Name: "old", Status: DraftTicket, Version: 1, FeatureUUID: "feature"}
+	latest := Tickets{UUID: uuid.New(), TicketGroup: &group, Name: "latest", Status: InProgressTicket, Version: 2, FeatureUUID: "feature"}
+	other := newGraphTicket("other", 0, InProgressTicket)
+	other.FeatureUUID = "elsewhere"
+	open := NewBounty{ID: 1, Title: "open", FeatureUuid: "feature"}
+	assigned := NewBounty{ID: 2, Title: "assigned", Assignee: "hunter"}
+	paid := NewBounty{ID: 3, Title: "paid", Assignee: "hunter", Paid: true}
+
+	positions := []BoardPosition{
+		{ItemType: BoardBounty, ItemKey: "2", Position: 0},
+		{ItemType: BoardTicket, ItemKey: group.String(), Position: 1},
+	}
+	limits := []BoardColumnConfig{{Status: InProgressTicket, WipLimit: 2}}
+
+	column := func(board Board, status TicketStatus) BoardColumn {
+		for _, c := range board.Columns {
+			if c.Status == status {
+				return c
+			}
+		}
+		return BoardColumn{}
+	}
+
+	t.Run("Should group cards into ordered status columns", func(t *testing.T) {
+		board := BuildBoard("workspace", []Tickets{older, latest, other}, []NewBounty{open, assigned, paid}, positions, limits, BoardFilter{})
+		assert.Len(t, board.Columns, len(BoardStatuses))
+
+		inProgress := column(board, InProgressTicket)
+		assert.Equal(t, 3, inProgress.Count)
+		assert.True(t, inProgress.OverLimit)
+		assert.Equal(t, []string{"assigned", "latest", "other"}, []string{inProgress.Cards[0].Title, inProgress.Cards[1].Title, inProgress.Cards[2].Title})
+		assert.Equal(t, 2, inProgress.Cards[2].Position)
+
+		assert.Equal(t, "open", column(board, ReadyTicket).Cards[0].Title)
+		assert.Equal(t, "paid", column(board, CompletedTicket).Cards[0].Title)
+		assert.Len(t, column(board, DraftTicket).Cards, 0)
+	})
+
+	t.Run("Should filter cards but keep column counts", func(t *testing.T) {
+		board := BuildBoard("workspace", []Tickets{latest, other}, []NewBounty{open, assigned}, positions, limits, BoardFilter{Assignee: "hunter"})
+		inProgress := column(board, InProgressTicket)
+		assert.Equal(t, 3, inProgress.Count)
+		assert.Len(t, inProgress.Cards, 1)
+		assert.Equal(t, "assigned", inProgress.Cards[0].Title)
+
+		board = BuildBoard("workspace", []Tickets{latest, other}, []NewBounty{open, assigned}, positions, limits, BoardFilter{FeatureUUID: "feature"})
+		assert.Len(t, column(board, InProgressTicket).Cards, 1)
+		assert.Len(t, column(board, ReadyTicket).Cards, 1)
+	})
+}
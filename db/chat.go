@@ -351,6 +351,8 @@ func (db database) CreateOrEditChatWorkflow(workflow *ChatWorkflow) (*ChatWorkfl
 
 	existing.URL = workflow.URL
 	existing.StackworkID = workflow.StackworkID
+	existing.HistorySize = workflow.HistorySize
+	existing.TokenBudget = workflow.TokenBudget
 	existing.UpdatedAt = now
 
 	if err := db.db.Save(&existing).Error; err != nil {
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/xid"
+	"gorm.io/gorm"
+)
+
+// MainChatBranch is the branch of the messages sent before any edit
+const MainChatBranch = ""
+
+var (
+	ErrChatBranchNotFound     = errors.New("chat branch not found")
+	ErrChatMessageNotEditable = errors.New("only user messages of this chat can be edited")
+	ErrChatBranchCycle        = errors.New("chat branches form a cycle")
+)
+
+// ChatBranchHistory returns the messages of a branch in order, the history it
+// shares with its parents followed by its own messages
+func ChatBranchHistory(messages []ChatMessage, branches []ChatBranch, branchID string) ([]ChatMessage, error) {
+	byID := map[string]ChatBranch{}
+	for _, branch := range branches {
+		byID[branch.ID] = branch
+	}
+
+	// walk up to the main branch
+	chain := []ChatBranch{}
+	seen := map[string]bool{}
+	for id := branchID; id != MainChatBranch; {
+		branch, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrChatBranchNotFound, id)
+		}
+		if seen[id] {
+			return nil, ErrChatBranchCycle
+		}
+		seen[id] = true
+		chain = append(chain, branch)
+		id = branch.ParentBranchID
+	}
+
+	history := branchMessages(messages, MainChatBranch)
+	for i := len(chain) - 1; i >= 0; i-- {
+		branch := chain[i]
+		history = historyUpTo(history, branch.ForkMessageID)
+		history = append(history, branchMessages(messages, branch.ID)...)
+	}
+	return history, nil
+}
+
+func branchMessages(messages []ChatMessage, branchID string) []ChatMessage {
+	own := []ChatMessage{}
+	for _, message := range messages {
+		if message.BranchID == branchID {
+			own = append(own, message)
+		}
+	}
+	return own
+}
+
+// historyUpTo keeps the messages up to and including messageID, an empty ID
+// keeps nothing
+func historyUpTo(history []ChatMessage, messageID string) []ChatMessage {
+	for i, message := range history {
+		if message.ID == messageID {
+			return append([]ChatMessage{}, history[:i+1]...)
+		}
+	}
+	return []ChatMessage{}
+}
+
+// ChatForkPoint returns the message a fork that replaces messageID continues
+// from, which is the message before it in the branch history
+func ChatForkPoint(history []ChatMessage, messageID string) (string, error) {
+	for i, message := range history {
+		if message.ID != messageID {
+			continue
+		}
+		if message.Role != "user" {
+			return "", ErrChatMessageNotEditable
+		}
+		if i == 0 {
+			return "", nil
+		}
+		return history[i-1].ID, nil
+	}
+	return "", ErrChatMessageNotEditable
+}
+
+func (db database) GetChatBranches(chatID string) ([]ChatBranch, error) {
+	var branches []ChatBranch
+	if err := db.db.Where("chat_id = ?", chatID).Order("created_at ASC").Find(&branches).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat branches: %w", err)
+	}
+	return branches, nil
+}
+
+func chatBranchHistory(tx *gorm.DB, chatID string, branchID string) ([]ChatMessage, error) {
+	var messages []ChatMessage
+	if err := tx.Where("chat_id = ?", chatID).Order("timestamp ASC").Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat messages: %w", err)
+	}
+	var branches []ChatBranch
+	if err := tx.Where("chat_id = ?", chatID).Find(&branches).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat branches: %w", err)
+	}
+	return ChatBranchHistory(messages, branches, branchID)
+}
+
+// GetChatBranchHistory returns the messages of one branch of a chat
+func (db database) GetChatBranchHistory(chatID string, branchID string) ([]ChatMessage, error) {
+	return chatBranchHistory(db.db, chatID, branchID)
+}
+
+func (db database) GetChatMessageByID(id string) (ChatMessage, error) {
+	var message ChatMessage
+	if err := db.db.Where("id = ?", id).First(&message).Error; err != nil {
+		return ChatMessage{}, fmt.Errorf("message not found: %w", err)
+	}
+	return message, nil
+}
+
+// ForkChatMessage edits a user message by starting a branch from the message
+// before it with the new text, the original branch is kept as it was. The new
+// branch becomes the active branch of the chat.
+func (db database) ForkChatMessage(chatID string, messageID string, text string, pubkey string) (ChatBranch, ChatMessage, error) {
+	var branch ChatBranch
+	var created ChatMessage
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		var original ChatMessage
+		if err := tx.Where("id = ? AND chat_id = ?", messageID, chatID).First(&original).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrChatMessageNotEditable
+			}
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		history, err := chatBranchHistory(tx, chatID, original.BranchID)
+		if err != nil {
+			return err
+		}
+		forkMessageID, err := ChatForkPoint(history, messageID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		branch = ChatBranch{
+			ID:              xid.New().String(),
+			ChatID:          chatID,
+			ParentBranchID:  original.BranchID,
+			ForkMessageID:   forkMessageID,
+			EditedMessageID: messageID,
+			CreatedBy:       pubkey,
+			CreatedAt:       now,
+		}
+		if err := tx.Create(&branch).Error; err != nil {
+			return fmt.Errorf("failed to create chat branch: %w", err)
+		}
+
+		created = ChatMessage{
+			ID:           xid.New().String(),
+			ChatID:       chatID,
+			Message:      sanitizeText(text),
+			PDFURL:       original.PDFURL,
+			Role:         "user",
+			Timestamp:    now,
+			ContextTags:  original.ContextTags,
+			Status:       "sending",
+			Source:       "user",
+			BranchID:     branch.ID,
+			EditedFromID: messageID,
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return fmt.Errorf("failed to create chat message: %w", err)
+		}
+
+		if err := tx.Model(&Chat{}).Where("id = ?", chatID).Updates(map[string]interface{}{
+			"active_branch_id": branch.ID,
+			"updated_at":       now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to activate chat branch: %w", err)
+		}
+		return nil
+	})
+
+	return branch, created, err
+}
+
+// SetActiveChatBranch switches the branch a chat continues on
+func (db database) SetActiveChatBranch(chatID string, branchID string) (Chat, error) {
+	if branchID != MainChatBranch {
+		var count int64
+		if err := db.db.Model(&ChatBranch{}).Where("id = ? AND chat_id = ?", branchID, chatID).Count(&count).Error; err != nil {
+			return Chat{}, fmt.Errorf("failed to fetch chat branch: %w", err)
+		}
+		if count == 0 {
+			return Chat{}, fmt.Errorf("%w: %s", ErrChatBranchNotFound, branchID)
+		}
+	}
+
+	if err := db.db.Model(&Chat{}).Where("id = ?", chatID).Updates(map[string]interface{}{
+		"active_branch_id": branchID,
+		"updated_at":       time.Now(),
+	}).Error; err != nil {
+		return Chat{}, fmt.Errorf("failed to activate chat branch: %w", err)
+	}
+	return db.GetChatByChatID(chatID)
+}
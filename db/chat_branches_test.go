@@ -0,0 +1,90 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func branchMessageIDs(messages []ChatMessage) []string {
+	ids := []string{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestChatBranchHistory(t *testing.T) {
+	messages := []ChatMessage{
+		{ID: "u1", Role: "user"},
+		{ID: "a1", Role: "assistant"},
+		{ID: "u2", Role: "user"},
+		{ID: "a2", Role: "assistant"},
+		{ID: "b1u2", Role: "user", BranchID: "b1", EditedFromID: "u2"},
+		{ID: "b1a2", Role: "assistant", BranchID: "b1"},
+		{ID: "b2u1", Role: "user", BranchID: "b2", EditedFromID: "u1"},
+		{ID: "b3u2", Role: "user", BranchID: "b3", EditedFromID: "b1u2"},
+	}
+	branches := []ChatBranch{
+		{ID: "b1", ForkMessageID: "a1"},
+		{ID: "b2"},
+		{ID: "b3", ParentBranchID: "b1", ForkMessageID: "a1"},
+	}
+
+	t.Run("main branch", func(t *testing.T) {
+		history, err := ChatBranchHistory(messages, branches, MainChatBranch)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a1", "u2", "a2"}, branchMessageIDs(history))
+	})
+
+	t.Run("edited branch", func(t *testing.T) {
+		history, err := ChatBranchHistory(messages, branches, "b1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a1", "b1u2", "b1a2"}, branchMessageIDs(history))
+	})
+
+	t.Run("first message edited", func(t *testing.T) {
+		history, err := ChatBranchHistory(messages, branches, "b2")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b2u1"}, branchMessageIDs(history))
+	})
+
+	t.Run("nested branch", func(t *testing.T) {
+		history, err := ChatBranchHistory(messages, branches, "b3")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u1", "a1", "b3u2"}, branchMessageIDs(history))
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		_, err := ChatBranchHistory(messages, branches, "missing")
+		assert.True(t, errors.Is(err, ErrChatBranchNotFound))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := ChatBranchHistory(nil, []ChatBranch{{ID: "x", ParentBranchID: "y"}, {ID: "y", ParentBranchID: "x"}}, "x")
+		assert.True(t, errors.Is(err, ErrChatBranchCycle))
+	})
+}
+
+func TestChatForkPoint(t *testing.T) {
+	history := []ChatMessage{
+		{ID: "u1", Role: "user"},
+		{ID: "a1", Role: "assistant"},
+		{ID: "u2", Role: "user"},
+	}
+
+	fork, err := ChatForkPoint(history, "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, "a1", fork)
+
+	fork, err = ChatForkPoint(history, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, "", fork)
+
+	_, err = ChatForkPoint(history, "a1")
+	assert.True(t, errors.Is(err, ErrChatMessageNotEditable))
+
+	_, err = ChatForkPoint(history, "missing")
+	assert.True(t, errors.Is(err, ErrChatMessageNotEditable))
+}
@@ -0,0 +1,347 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultChatHistorySize = 20
+	MaxChatHistorySize     = 200
+	// until the workflow summarizes them, the messages that left the window are
+	// sent as a truncated transcript, each message is cut to a line and only
+	// the newest lines within this size are kept
+	chatDigestMaxChars  = 6000
+	chatDigestLineChars = 280
+	// a summary request folds at most this many of the oldest messages not
+	// summarized yet, the rest follow with the next requests
+	chatSummaryBatchSize = 50
+)
+
+var (
+	ErrChatContextSettings = fmt.Errorf("history size must be between 0 and %d and the token budget can't be negative", MaxChatHistorySize)
+	ErrChatMessageNotFound = errors.New("chat message not found")
+	ErrArtifactNotFound    = errors.New("artifact not found")
+	ErrChatSummaryStale    = errors.New("the chat already has a summary of later messages")
+)
+
+type ChatContextSettings struct {
+	HistorySize int `json:"historySize"`
+	TokenBudget int `json:"tokenBudget"`
+}
+
+// ChatContextSettingsFor reads the settings of a workspace chat workflow,
+// workspaces without one get the defaults
+func ChatContextSettingsFor(workflow *ChatWorkflow) ChatContextSettings {
+	settings := ChatContextSettings{HistorySize: DefaultChatHistorySize}
+	if workflow == nil {
+		return settings
+	}
+	if workflow.HistorySize > 0 {
+		settings.HistorySize = workflow.HistorySize
+	}
+	if workflow.TokenBudget > 0 {
+		settings.TokenBudget = workflow.TokenBudget
+	}
+	return settings
+}
+
+func ValidateChatContextSettings(settings ChatContextSettings) error {
+	if settings.HistorySize < 0 || settings.HistorySize > MaxChatHistorySize || settings.TokenBudget < 0 {
+		return ErrChatContextSettings
+	}
+	return nil
+}
+
+// EstimateTokens counts roughly four characters a token
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+type ChatContextEntry struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatSummaryRequest asks the workflow to fold Messages, the messages of a
+// branch that left the context window since Summary was written, into a new
+// summary and post it back to WebhookURL with BranchID and ThroughID
+type ChatSummaryRequest struct {
+	BranchID   string             `json:"branchId"`
+	ThroughID  string             `json:"throughId"`
+	Summary    string             `json:"summary"`
+	Messages   []ChatContextEntry `json:"messages"`
+	WebhookURL string             `json:"webhookUrl"`
+}
+
+// ChatContext is what is sent to the workflow besides the new message
+type ChatContext struct {
+	History []ChatContextEntry `json:"history"`
+	// Pinned messages and artifacts outside of History
+	Pinned []ChatContextEntry `json:"pinned"`
+	// Summary is the workflow's summary of the messages before the window,
+	// Digest a transcript of the ones it doesn't cover yet
+	Summary string `json:"summary"`
+	Digest  string `json:"digest"`
+	// Tokens is the estimate of everything above
+	Tokens int `json:"tokens"`
+	// SummaryRequest is set while messages before the window aren't summarized
+	SummaryRequest *ChatSummaryRequest `json:"summaryRequest,omitempty"`
+}
+
+// ChatContextWindowStart is the index of the first message inside the history
+// window, the messages before it are left to the summary
+func ChatContextWindowStart(history []ChatMessage, settings ChatContextSettings) int {
+	size := settings.HistorySize
+	if size <= 0 {
+		size = DefaultChatHistorySize
+	}
+	if len(history) > size {
+		return len(history) - size
+	}
+	return 0
+}
+
+// BuildChatContext picks the context for a new message on a branch. Pinned
+// messages and artifacts and the summary of the chat are always sent, the
+// window of recent messages is then filled newest first while it fits the
+// token budget. Artifacts that don't fit are named instead of inlined.
+func BuildChatContext(chat Chat, branchID string, history []ChatMessage, artifacts []Artifact, settings ChatContextSettings) ChatContext {
+	byMessage := map[string][]Artifact{}
+	for _, artifact := range artifacts {
+		byMessage[artifact.MessageID] = append(byMessage[artifact.MessageID], artifact)
+	}
+
+	chatContext := ChatContext{History: []ChatContextEntry{}, Pinned: []ChatContextEntry{}}
+
+	start := ChatContextWindowStart(history, settings)
+	summary, pending := PendingChatSummary(chat, branchID, history[:start])
+	chatContext.Summary = summary
+	if len(pending) > 0 {
+		chatContext.Digest = DigestChatMessages(pending)
+		batch := pending
+		if len(batch) > chatSummaryBatchSize {
+			batch = batch[:chatSummaryBatchSize]
+		}
+		entries := []ChatContextEntry{}
+		for _, message := range batch {
+			entries = append(entries, ChatContextEntry{Role: string(message.Role), Content: message.Message})
+		}
+		chatContext.SummaryRequest = &ChatSummaryRequest{
+			BranchID:  branchID,
+			ThroughID: batch[len(batch)-1].ID,
+			Summary:   summary,
+			Messages:  entries,
+		}
+	}
+	chatContext.Tokens = EstimateTokens(chatContext.Summary) + EstimateTokens(chatContext.Digest)
+
+	inlined := map[uuid.UUID]bool{}
+
+	// newest first so the budget goes to the latest messages
+	window := []ChatContextEntry{}
+	windowIDs := map[string]bool{}
+	for i := len(history) - 1; i >= start; i-- {
+		message := history[i]
+		content := message.Message
+		tokens := EstimateTokens(content)
+		if settings.TokenBudget > 0 && chatContext.Tokens+tokens > settings.TokenBudget && !message.Pinned {
+			break
+		}
+
+		fits, omitted := []Artifact{}, []Artifact{}
+		for _, artifact := range byMessage[message.ID] {
+			artifactTokens := EstimateTokens(chatArtifactContent(artifact))
+			if settings.TokenBudget == 0 || artifact.Pinned || chatContext.Tokens+tokens+artifactTokens <= settings.TokenBudget {
+				fits = append(fits, artifact)
+				tokens += artifactTokens
+				inlined[artifact.ID] = true
+			} else {
+				omitted = append(omitted, artifact)
+			}
+		}
+		content += "\nArtifacts: " + chatArtifactsJSON(fits)
+		if len(omitted) > 0 {
+			names := []string{}
+			for _, artifact := range omitted {
+				names = append(names, fmt.Sprintf("%s (%s)", artifact.ID, artifact.Type))
+			}
+			content += "\nOmitted artifacts: " + strings.Join(names, ", ")
+		}
+
+		chatContext.Tokens += tokens
+		window = append(window, ChatContextEntry{Role: string(message.Role), Content: content})
+		windowIDs[message.ID] = true
+	}
+	for i := len(window) - 1; i >= 0; i-- {
+		chatContext.History = append(chatContext.History, window[i])
+	}
+
+	for _, message := range history {
+		if message.Pinned && !windowIDs[message.ID] {
+			content := message.Message + "\nArtifacts: " + chatArtifactsJSON(byMessage[message.ID])
+			for _, artifact := range byMessage[message.ID] {
+				inlined[artifact.ID] = true
+			}
+			chatContext.Tokens += EstimateTokens(content)
+			chatContext.Pinned = append(chatContext.Pinned, ChatContextEntry{Role: string(message.Role), Content: content})
+		}
+	}
+	for _, message := range history {
+		for _, artifact := range byMessage[message.ID] {
+			if artifact.Pinned && !inlined[artifact.ID] {
+				content := chatArtifactsJSON([]Artifact{artifact})
+				chatContext.Tokens += EstimateTokens(content)
+				chatContext.Pinned = append(chatContext.Pinned, ChatContextEntry{Role: "artifact", Content: content})
+			}
+		}
+	}
+	return chatContext
+}
+
+func chatArtifactContent(artifact Artifact) string {
+	content, err := json.Marshal(artifact.Content)
+	if err != nil {
+		return "{}"
+	}
+	return string(content)
+}
+
+func chatArtifactsJSON(artifacts []Artifact) string {
+	list := []map[string]string{}
+	for _, artifact := range artifacts {
+		list = append(list, map[string]string{
+			"artifactId": artifact.ID.String(),
+			"content":    chatArtifactContent(artifact),
+		})
+	}
+	artifactJSON, err := json.Marshal(map[string]interface{}{"artifacts": list})
+	if err != nil {
+		return `{"artifacts": []}`
+	}
+	return string(artifactJSON)
+}
+
+// PendingChatSummary splits the messages that left the context window of a
+// branch into the summary the workflow wrote of them and the ones after it.
+// A summary of another branch, or of messages that are no longer before the
+// window, is not used.
+func PendingChatSummary(chat Chat, branchID string, older []ChatMessage) (summary string, pending []ChatMessage) {
+	if chat.Summary == "" || chat.SummaryBranchID != branchID || chat.SummaryThroughID == "" {
+		return "", older
+	}
+	for i, message := range older {
+		if message.ID == chat.SummaryThroughID {
+			return chat.Summary, older[i+1:]
+		}
+	}
+	return "", older
+}
+
+// DigestChatMessages turns messages into a transcript of a line per message,
+// cut to a fixed length, and drops its oldest lines past the size limit
+func DigestChatMessages(messages []ChatMessage) string {
+	lines := []string{}
+	for _, message := range messages {
+		text := strings.Join(strings.Fields(message.Message), " ")
+		if text == "" {
+			continue
+		}
+		if utf8.RuneCountInString(text) > chatDigestLineChars {
+			text = string([]rune(text)[:chatDigestLineChars]) + "…"
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", message.Role, text))
+	}
+
+	size := 0
+	keep := len(lines)
+	for keep > 0 && size+len(lines[keep-1])+1 <= chatDigestMaxChars {
+		size += len(lines[keep-1]) + 1
+		keep--
+	}
+	return strings.Join(lines[keep:], "\n")
+}
+
+// UpdateChatSummary stores the summary the workflow wrote of the messages of
+// a branch up to and including throughID. A summary that arrives after one of
+// later messages of the same branch is refused with ErrChatSummaryStale.
+func (db database) UpdateChatSummary(chatID string, branchID string, throughID string, summary string) (Chat, error) {
+	var chat Chat
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", chatID).First(&chat).Error; err != nil {
+			return fmt.Errorf("failed to fetch chat: %w", err)
+		}
+
+		var through ChatMessage
+		if err := tx.Where("id = ? AND chat_id = ?", throughID, chatID).First(&through).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrChatMessageNotFound
+			}
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		if chat.SummaryBranchID == branchID && chat.SummaryThroughID != "" && chat.SummaryThroughID != throughID {
+			var current ChatMessage
+			if err := tx.Where("id = ?", chat.SummaryThroughID).Limit(1).Find(&current).Error; err != nil {
+				return fmt.Errorf("failed to fetch message: %w", err)
+			}
+			if current.ID != "" && current.Timestamp.After(through.Timestamp) {
+				return ErrChatSummaryStale
+			}
+		}
+
+		chat.Summary = summary
+		chat.SummaryBranchID = branchID
+		chat.SummaryThroughID = throughID
+		if err := tx.Model(&Chat{}).Where("id = ?", chatID).Updates(map[string]interface{}{
+			"summary":            summary,
+			"summary_branch_id":  branchID,
+			"summary_through_id": throughID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update chat summary: %w", err)
+		}
+		return nil
+	})
+	return chat, err
+}
+
+func (db database) SetChatMessagePinned(chatID string, messageID string, pinned bool) (ChatMessage, error) {
+	var message ChatMessage
+	if err := db.db.Where("id = ? AND chat_id = ?", messageID, chatID).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ChatMessage{}, ErrChatMessageNotFound
+		}
+		return ChatMessage{}, fmt.Errorf("failed to fetch message: %w", err)
+	}
+	if err := db.db.Model(&message).Update("pinned", pinned).Error; err != nil {
+		return ChatMessage{}, fmt.Errorf("failed to pin message: %w", err)
+	}
+	message.Pinned = pinned
+	return message, nil
+}
+
+func (db database) SetArtifactPinned(id uuid.UUID, pinned bool) (*Artifact, error) {
+	var artifact Artifact
+	if err := db.db.Where("id = ?", id).First(&artifact).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArtifactNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch artifact: %w", err)
+	}
+	if err := db.db.Model(&artifact).Updates(map[string]interface{}{
+		"pinned":     pinned,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to pin artifact: %w", err)
+	}
+	artifact.Pinned = pinned
+	return &artifact, nil
+}
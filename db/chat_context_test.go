@@ -0,0 +1,163 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func contextMessages(n int) []ChatMessage {
+	messages := []ChatMessage{}
+	for i := 0; i < n; i++ {
+		role := ChatRole("user")
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages = append(messages, ChatMessage{ID: string(rune('a' + i)), Role: role, Message: strings.Repeat(string(rune('a'+i)), 40)})
+	}
+	return messages
+}
+
+func TestChatContextSettings(t *testing.T) {
+	assert.Equal(t, ChatContextSettings{HistorySize: DefaultChatHistorySize}, ChatContextSettingsFor(nil))
+	assert.Equal(t, ChatContextSettings{HistorySize: 5, TokenBudget: 100}, ChatContextSettingsFor(&ChatWorkflow{HistorySize: 5, TokenBudget: 100}))
+
+	assert.NoError(t, ValidateChatContextSettings(ChatContextSettings{}))
+	assert.Error(t, ValidateChatContextSettings(ChatContextSettings{HistorySize: MaxChatHistorySize + 1}))
+	assert.Error(t, ValidateChatContextSettings(ChatContextSettings{TokenBudget: -1}))
+}
+
+func TestBuildChatContext(t *testing.T) {
+	t.Run("history window", func(t *testing.T) {
+		chatContext := BuildChatContext(Chat{}, MainChatBranch, contextMessages(6), nil, ChatContextSettings{HistorySize: 3})
+		assert.Len(t, chatContext.History, 3)
+		assert.True(t, strings.HasPrefix(chatContext.History[0].Content, "dddd"))
+		assert.Equal(t, "assistant", chatContext.History[2].Role)
+		assert.Contains(t, chatContext.History[2].Content, "\nArtifacts: {\"artifacts\":[]}")
+	})
+
+	t.Run("token budget keeps the newest messages", func(t *testing.T) {
+		// each message is 10 tokens and the summary 2
+		chat := Chat{Summary: "earlier", SummaryThroughID: "a"}
+		chatContext := BuildChatContext(chat, MainChatBranch, contextMessages(7), nil, ChatContextSettings{HistorySize: 6, TokenBudget: 25})
+		assert.Len(t, chatContext.History, 2)
+		assert.True(t, strings.HasPrefix(chatContext.History[0].Content, "ffff"))
+		assert.Equal(t, "earlier", chatContext.Summary)
+		assert.Nil(t, chatContext.SummaryRequest)
+		assert.Equal(t, 22, chatContext.Tokens)
+	})
+
+	t.Run("messages left out of the summary are sent and requested", func(t *testing.T) {
+		chat := Chat{Summary: "earlier", SummaryThroughID: "b"}
+		chatContext := BuildChatContext(chat, MainChatBranch, contextMessages(6), nil, ChatContextSettings{HistorySize: 2})
+		assert.Equal(t, "earlier", chatContext.Summary)
+		assert.Equal(t, "- user: "+strings.Repeat("c", 40)+"\n- assistant: "+strings.Repeat("d", 40), chatContext.Digest)
+		assert.NotNil(t, chatContext.SummaryRequest)
+		assert.Equal(t, "d", chatContext.SummaryRequest.ThroughID)
+		assert.Equal(t, "earlier", chatContext.SummaryRequest.Summary)
+		assert.Len(t, chatContext.SummaryRequest.Messages, 2)
+	})
+
+	t.Run("summary requests are sent in batches", func(t *testing.T) {
+		messages := contextMessages(chatSummaryBatchSize + 10)
+		chatContext := BuildChatContext(Chat{}, MainChatBranch, messages, nil, ChatContextSettings{HistorySize: 2})
+		assert.Empty(t, chatContext.Summary)
+		assert.Len(t, chatContext.SummaryRequest.Messages, chatSummaryBatchSize)
+		assert.Equal(t, messages[chatSummaryBatchSize-1].ID, chatContext.SummaryRequest.ThroughID)
+	})
+
+	t.Run("artifacts over budget are named", func(t *testing.T) {
+		messages := contextMessages(2)
+		big := Artifact{ID: uuid.New(), MessageID: "b", Type: TextArtifact, Content: PropertyMap{"content": strings.Repeat("x", 400)}}
+		chatContext := BuildChatContext(Chat{}, MainChatBranch, messages, []Artifact{big}, ChatContextSettings{HistorySize: 2, TokenBudget: 40})
+		assert.Len(t, chatContext.History, 2)
+		assert.Contains(t, chatContext.History[1].Content, "Omitted artifacts: "+big.ID.String()+" (text)")
+	})
+
+	t.Run("pinned context is always sent", func(t *testing.T) {
+		messages := contextMessages(6)
+		messages[0].Pinned = true
+		pinned := Artifact{ID: uuid.New(), MessageID: "b", Type: VisualArtifact, Pinned: true, Content: PropertyMap{"url": "https://example.com"}}
+		chatContext := BuildChatContext(Chat{}, MainChatBranch, messages, []Artifact{pinned}, ChatContextSettings{HistorySize: 2, TokenBudget: 1})
+		assert.Empty(t, chatContext.History)
+		assert.Len(t, chatContext.Pinned, 2)
+		assert.Equal(t, "user", chatContext.Pinned[0].Role)
+		assert.Equal(t, "artifact", chatContext.Pinned[1].Role)
+		assert.Contains(t, chatContext.Pinned[1].Content, pinned.ID.String())
+	})
+}
+
+func TestPendingChatSummary(t *testing.T) {
+	messages := contextMessages(4)
+
+	summary, pending := PendingChatSummary(Chat{}, MainChatBranch, messages[:2])
+	assert.Empty(t, summary)
+	assert.Len(t, pending, 2)
+
+	chat := Chat{Summary: "summary", SummaryThroughID: "b"}
+	summary, pending = PendingChatSummary(chat, MainChatBranch, messages[:2])
+	assert.Equal(t, "summary", summary)
+	assert.Empty(t, pending)
+
+	summary, pending = PendingChatSummary(chat, MainChatBranch, messages[:3])
+	assert.Equal(t, "summary", summary)
+	assert.Equal(t, []ChatMessage{messages[2]}, pending)
+
+	// another branch, or a summary past the window, isn't used
+	summary, pending = PendingChatSummary(chat, "branch", messages[:3])
+	assert.Empty(t, summary)
+	assert.Len(t, pending, 3)
+	summary, pending = PendingChatSummary(chat, MainChatBranch, messages[:1])
+	assert.Empty(t, summary)
+	assert.Len(t, pending, 1)
+}
+
+func TestDigestChatMessages(t *testing.T) {
+	long := ChatMessage{Role: "assistant", Message: strings.Repeat("word ", 100)}
+	digest := DigestChatMessages([]ChatMessage{long, {Role: "user", Message: "  "}})
+	assert.True(t, strings.HasSuffix(digest, "…"))
+	assert.NotContains(t, digest, "\n")
+
+	lines := []ChatMessage{}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, long)
+	}
+	assert.LessOrEqual(t, len(DigestChatMessages(lines)), chatDigestMaxChars)
+}
+
+func TestUpdateChatSummary(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	chat, err := TestDB.AddChat(&Chat{ID: uuid.New().String(), WorkspaceID: "summary_workspace", Title: "Summary Chat"})
+	assert.NoError(t, err)
+	now := time.Now()
+	first, err := TestDB.AddChatMessage(&ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: "first", Role: "user", Timestamp: now.Add(-time.Minute)})
+	assert.NoError(t, err)
+	second, err := TestDB.AddChatMessage(&ChatMessage{ID: uuid.New().String(), ChatID: chat.ID, Message: "second", Role: "assistant", Timestamp: now})
+	assert.NoError(t, err)
+
+	t.Run("Should store the summary of a branch", func(t *testing.T) {
+		updated, err := TestDB.UpdateChatSummary(chat.ID, MainChatBranch, second.ID, "both messages")
+		assert.NoError(t, err)
+		assert.Equal(t, "both messages", updated.Summary)
+
+		saved, err := TestDB.GetChatByChatID(chat.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "both messages", saved.Summary)
+		assert.Equal(t, second.ID, saved.SummaryThroughID)
+	})
+
+	t.Run("Should refuse a summary of earlier messages", func(t *testing.T) {
+		_, err := TestDB.UpdateChatSummary(chat.ID, MainChatBranch, first.ID, "first message")
+		assert.ErrorIs(t, err, ErrChatSummaryStale)
+	})
+
+	t.Run("Should refuse a message of another chat", func(t *testing.T) {
+		_, err := TestDB.UpdateChatSummary(chat.ID, MainChatBranch, uuid.New().String(), "unknown")
+		assert.ErrorIs(t, err, ErrChatMessageNotFound)
+	})
+}
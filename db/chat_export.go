@@ -0,0 +1,209 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrChatShareLinkNotFound = errors.New("chat share link not found")
+	ErrChatShareLinkExpired  = errors.New("chat share link has expired")
+)
+
+const (
+	DefaultChatShareHours = 7 * 24
+	MaxChatShareHours     = 90 * 24
+)
+
+func (link ChatShareLink) Active(now time.Time) bool {
+	return link.RevokedAt == nil && now.Before(link.ExpiresAt)
+}
+
+type ChatExportMessage struct {
+	ChatMessage
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+type ChatExport struct {
+	Chat       Chat                `json:"chat"`
+	BranchID   string              `json:"branchId"`
+	Messages   []ChatExportMessage `json:"messages"`
+	ExportedAt time.Time           `json:"exportedAt"`
+}
+
+// GetChatExport collects the messages of a chat branch with their artifacts
+func (db database) GetChatExport(chatID string, branchID string) (ChatExport, error) {
+	chat, err := db.GetChatByChatID(chatID)
+	if err != nil {
+		return ChatExport{}, err
+	}
+
+	messages, err := db.GetChatBranchHistory(chatID, branchID)
+	if err != nil {
+		return ChatExport{}, err
+	}
+
+	artifacts, err := db.GetAllArtifactsByChatID(chatID)
+	if err != nil {
+		return ChatExport{}, err
+	}
+
+	return BuildChatExport(chat, branchID, messages, artifacts, time.Now()), nil
+}
+
+// BuildChatExport pairs messages with their artifacts, oldest artifact first
+func BuildChatExport(chat Chat, branchID string, messages []ChatMessage, artifacts []Artifact, now time.Time) ChatExport {
+	byMessage := map[string][]Artifact{}
+	for _, artifact := range artifacts {
+		byMessage[artifact.MessageID] = append(byMessage[artifact.MessageID], artifact)
+	}
+
+	export := ChatExport{Chat: chat, BranchID: branchID, Messages: []ChatExportMessage{}, ExportedAt: now}
+	for _, message := range messages {
+		messageArtifacts := byMessage[message.ID]
+		sort.SliceStable(messageArtifacts, func(i, j int) bool {
+			return messageArtifacts[i].CreatedAt.Before(messageArtifacts[j].CreatedAt)
+		})
+		if messageArtifacts == nil {
+			messageArtifacts = []Artifact{}
+		}
+		export.Messages = append(export.Messages, ChatExportMessage{ChatMessage: message, Artifacts: messageArtifacts})
+	}
+	return export
+}
+
+// ChatExportMarkdown renders an export as a markdown transcript
+func ChatExportMarkdown(export ChatExport) string {
+	var sb strings.Builder
+
+	title := export.Chat.Title
+	if title == "" {
+		title = "Chat " + export.Chat.ID
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "- Chat: %s\n", export.Chat.ID)
+	fmt.Fprintf(&sb, "- Workspace: %s\n", export.Chat.WorkspaceID)
+	if export.BranchID != MainChatBranch {
+		fmt.Fprintf(&sb, "- Branch: %s\n", export.BranchID)
+	}
+	fmt.Fprintf(&sb, "- Exported: %s\n", export.ExportedAt.UTC().Format(time.RFC3339))
+
+	for _, message := range export.Messages {
+		fmt.Fprintf(&sb, "\n## %s · %s\n\n", chatRoleTitle(message.Role), message.Timestamp.UTC().Format(time.RFC3339))
+
+		if len(message.ContextTags) > 0 {
+			tags := []string{}
+			for _, tag := range message.ContextTags {
+				tags = append(tags, fmt.Sprintf("%s:%s", tag.Type, tag.ID))
+			}
+			fmt.Fprintf(&sb, "_Context: %s_\n\n", strings.Join(tags, ", "))
+		}
+		if message.PDFURL != "" {
+			fmt.Fprintf(&sb, "_Attachment: %s_\n\n", message.PDFURL)
+		}
+		sb.WriteString(strings.TrimSpace(message.Message))
+		sb.WriteString("\n")
+
+		for _, artifact := range message.Artifacts {
+			fmt.Fprintf(&sb, "\n### Artifact: %s\n\n", artifact.Type)
+			if text, ok := artifact.Content["content"].(string); ok && artifact.Type == TextArtifact {
+				sb.WriteString(strings.TrimSpace(text))
+				sb.WriteString("\n")
+				continue
+			}
+			content, err := json.MarshalIndent(artifact.Content, "", "  ")
+			if err != nil {
+				content = []byte("{}")
+			}
+			fmt.Fprintf(&sb, "```json\n%s\n```\n", content)
+		}
+	}
+	return sb.String()
+}
+
+func chatRoleTitle(role ChatRole) string {
+	if role == "" {
+		return "Message"
+	}
+	return strings.ToUpper(string(role[:1])) + string(role[1:])
+}
+
+// ClampChatShareHours applies the default and maximum lifetime of share links
+func ClampChatShareHours(hours int) int {
+	if hours <= 0 {
+		return DefaultChatShareHours
+	}
+	if hours > MaxChatShareHours {
+		return MaxChatShareHours
+	}
+	return hours
+}
+
+func newChatShareToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func (db database) CreateChatShareLink(link ChatShareLink) (ChatShareLink, error) {
+	token, err := newChatShareToken()
+	if err != nil {
+		return ChatShareLink{}, fmt.Errorf("failed to create share token: %w", err)
+	}
+
+	link.ID = xid.New().String()
+	link.Token = token
+	link.CreatedAt = time.Now()
+	link.RevokedAt = nil
+	if err := db.db.Create(&link).Error; err != nil {
+		return ChatShareLink{}, fmt.Errorf("failed to create chat share link: %w", err)
+	}
+	return link, nil
+}
+
+func (db database) GetChatShareLinks(chatID string) ([]ChatShareLink, error) {
+	var links []ChatShareLink
+	if err := db.db.Where("chat_id = ?", chatID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch chat share links: %w", err)
+	}
+	return links, nil
+}
+
+// GetChatShareLinkByToken returns a link that can still be used
+func (db database) GetChatShareLinkByToken(token string) (ChatShareLink, error) {
+	var link ChatShareLink
+	if err := db.db.Where("token = ?", token).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ChatShareLink{}, ErrChatShareLinkNotFound
+		}
+		return ChatShareLink{}, fmt.Errorf("failed to fetch chat share link: %w", err)
+	}
+	if !link.Active(time.Now()) {
+		return link, ErrChatShareLinkExpired
+	}
+	return link, nil
+}
+
+func (db database) RevokeChatShareLink(chatID string, id string) error {
+	result := db.db.Model(&ChatShareLink{}).
+		Where("id = ? AND chat_id = ? AND revoked_at IS NULL", id, chatID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke chat share link: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrChatShareLinkNotFound
+	}
+	return nil
+}
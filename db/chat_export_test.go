@@ -0,0 +1,52 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildChatExport(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	chat := Chat{ID: "chat1", WorkspaceID: "ws1", Title: "Auth flow"}
+	messages := []ChatMessage{
+		{ID: "m1", Role: "user", Message: "Explain the login", Timestamp: now, ContextTags: []ContextTag{{Type: ProductBriefContext, ID: "ws1"}}},
+		{ID: "m2", Role: "assistant", Message: "It signs a challenge", Timestamp: now.Add(time.Minute)},
+	}
+	artifacts := []Artifact{
+		{ID: uuid.New(), MessageID: "m2", Type: VisualArtifact, Content: PropertyMap{"url": "https://example.com"}, CreatedAt: now.Add(2 * time.Minute)},
+		{ID: uuid.New(), MessageID: "m2", Type: TextArtifact, Content: PropertyMap{"content": "func login() {}"}, CreatedAt: now.Add(time.Minute)},
+		{ID: uuid.New(), MessageID: "other", Type: TextArtifact, Content: PropertyMap{"content": "elsewhere"}},
+	}
+
+	export := BuildChatExport(chat, MainChatBranch, messages, artifacts, now)
+	assert.Len(t, export.Messages, 2)
+	assert.Empty(t, export.Messages[0].Artifacts)
+	assert.Len(t, export.Messages[1].Artifacts, 2)
+	assert.Equal(t, TextArtifact, export.Messages[1].Artifacts[0].Type)
+
+	markdown := ChatExportMarkdown(export)
+	assert.True(t, strings.HasPrefix(markdown, "# Auth flow\n"))
+	assert.Contains(t, markdown, "## User · 2025-03-01T12:00:00Z")
+	assert.Contains(t, markdown, "_Context: productBrief:ws1_")
+	assert.Contains(t, markdown, "### Artifact: text\n\nfunc login() {}\n")
+	assert.Contains(t, markdown, "```json\n{\n  \"url\": \"https://example.com\"\n}\n```")
+	assert.NotContains(t, markdown, "elsewhere")
+	assert.NotContains(t, markdown, "- Branch:")
+}
+
+func TestChatShareLinkActive(t *testing.T) {
+	now := time.Now()
+	revoked := now.Add(-time.Minute)
+
+	assert.True(t, ChatShareLink{ExpiresAt: now.Add(time.Hour)}.Active(now))
+	assert.False(t, ChatShareLink{ExpiresAt: now.Add(-time.Hour)}.Active(now))
+	assert.False(t, ChatShareLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &revoked}.Active(now))
+
+	assert.Equal(t, DefaultChatShareHours, ClampChatShareHours(0))
+	assert.Equal(t, 5, ClampChatShareHours(5))
+	assert.Equal(t, MaxChatShareHours, ClampChatShareHours(MaxChatShareHours+1))
+}
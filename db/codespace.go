@@ -20,9 +20,14 @@ func (db database) CreateCodeSpaceMap(codeSpace CodeSpaceMap) (CodeSpaceMap, err
 		now := time.Now()
 		existingMap.CodeSpaceURL = codeSpace.CodeSpaceURL
 		existingMap.Username = codeSpace.Username
-		existingMap.GithubPat = codeSpace.GithubPat
 		existingMap.BaseBranch = codeSpace.BaseBranch
-		existingMap.PoolAPIKey = codeSpace.PoolAPIKey
+		// secrets are write only, an empty value keeps the stored one
+		if codeSpace.GithubPat != "" {
+			existingMap.GithubPat = codeSpace.GithubPat
+		}
+		if codeSpace.PoolAPIKey != "" {
+			existingMap.PoolAPIKey = codeSpace.PoolAPIKey
+		}
 		existingMap.UpdatedAt = now
 		
 		db.db.Save(&existingMap)
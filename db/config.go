@@ -109,9 +109,28 @@ func InitDB() {
 	db.AutoMigrate(&BountyStake{})
 	db.AutoMigrate(&ChatWorkflowStatus{})
 	db.AutoMigrate(&BountyStakeProcess{})
+	db.AutoMigrate(&WorkspaceOwnershipTransfer{})
+	db.AutoMigrate(&WorkspaceOwnershipAudit{})
+	db.AutoMigrate(&TrashItem{})
+	db.AutoMigrate(&TicketVersion{})
+	db.AutoMigrate(&TicketWorkflowRule{})
+	db.AutoMigrate(&BoardPosition{})
+	db.AutoMigrate(&BoardColumnConfig{})
+	db.AutoMigrate(&AcceptanceCriterion{})
+	db.AutoMigrate(&AcceptanceCriterionLink{})
+	db.AutoMigrate(&TicketPlanRevision{})
+	db.AutoMigrate(&TicketPlanApproval{})
+	db.AutoMigrate(&TextRevision{})
+	db.AutoMigrate(&ChatBranch{})
+	db.AutoMigrate(&ChatShareLink{})
+	db.AutoMigrate(&SSEClient{})
+	db.AutoMigrate(&WebhookDelivery{})
+	db.AutoMigrate(&WebhookAttempt{})
 
 	DB.MigrateTablesWithOrgUuid()
 	DB.MigrateOrganizationToWorkspace()
+	DB.EncryptSecretColumns()
+	DB.MigrateSearchColumns()
 
 	people := DB.GetAllPeople()
 	for _, p := range people {
@@ -410,7 +429,7 @@ func CheckUser(userRoles []WorkspaceUserRoles, pubkey string) bool {
 func UserHasAccess(pubKeyFromAuth string, uuid string, role string) bool {
 	org := DB.GetWorkspaceByUuid(uuid)
 	var hasRole bool = false
-	if pubKeyFromAuth != org.OwnerPubKey {
+	if !org.IsOwner(pubKeyFromAuth) {
 		userRoles := DB.GetUserRoles(uuid, pubKeyFromAuth)
 		hasRole = RolesCheck(userRoles, role)
 		return hasRole
@@ -421,7 +440,7 @@ func UserHasAccess(pubKeyFromAuth string, uuid string, role string) bool {
 func (db database) UserHasAccess(pubKeyFromAuth string, uuid string, role string) bool {
 	org := db.getWorkspaceByUuid(uuid)
 	var hasRole bool = false
-	if pubKeyFromAuth != org.OwnerPubKey {
+	if !org.IsOwner(pubKeyFromAuth) {
 		userRoles := db.getUserRoles(uuid, pubKeyFromAuth)
 		hasRole = RolesCheck(userRoles, role)
 		return hasRole
@@ -432,7 +451,7 @@ func (db database) UserHasAccess(pubKeyFromAuth string, uuid string, role string
 func (ch configHandler) UserHasAccess(pubKeyFromAuth string, uuid string, role string) bool {
 	org := ch.db.GetWorkspaceByUuid(uuid)
 	var hasRole bool = false
-	if pubKeyFromAuth != org.OwnerPubKey {
+	if !org.IsOwner(pubKeyFromAuth) {
 		userRoles := ch.db.GetUserRoles(uuid, pubKeyFromAuth)
 		hasRole = RolesCheck(userRoles, role)
 		return hasRole
@@ -443,7 +462,7 @@ func (ch configHandler) UserHasAccess(pubKeyFromAuth string, uuid string, role s
 func (ch configHandler) UserHasManageBountyRoles(pubKeyFromAuth string, uuid string) bool {
 	var manageRolesCount = len(ManageBountiesGroup)
 	org := ch.db.GetWorkspaceByUuid(uuid)
-	if pubKeyFromAuth != org.OwnerPubKey {
+	if !org.IsOwner(pubKeyFromAuth) {
 		userRoles := ch.db.GetUserRoles(uuid, pubKeyFromAuth)
 
 		for _, role := range ManageBountiesGroup {
@@ -464,7 +483,7 @@ func (ch configHandler) UserHasManageBountyRoles(pubKeyFromAuth string, uuid str
 func (db database) UserHasManageBountyRoles(pubKeyFromAuth string, uuid string) bool {
 	var manageRolesCount = len(ManageBountiesGroup)
 	org := db.getWorkspaceByUuid(uuid)
-	if pubKeyFromAuth != org.OwnerPubKey {
+	if !org.IsOwner(pubKeyFromAuth) {
 		userRoles := db.getUserRoles(uuid, pubKeyFromAuth)
 
 		for _, role := range ManageBountiesGroup {
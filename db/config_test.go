@@ -103,6 +103,24 @@ func TestUserHasAccess(t *testing.T) {
 			t.Errorf("Expected UserHasAccess to return false for user without required role, got true")
 		}
 	})
+
+	t.Run("Should test that a workspace co-owner bypasses the role check", func(t *testing.T) {
+		coOwnerConfig := NewDatabaseConfig(mockDB)
+		coOwnerConfig.getWorkspaceByUuid = func(uuid string) Workspace {
+			return Workspace{
+				Uuid:        uuid,
+				OwnerPubKey: "org_admin",
+				CoOwners:    []string{"co_owner"},
+			}
+		}
+		coOwnerConfig.getUserRoles = func(uuid string, pubkey string) []WorkspaceUserRoles {
+			return []WorkspaceUserRoles{}
+		}
+
+		assert.True(t, coOwnerConfig.UserHasAccess("co_owner", "workspace_uuid", "DELETE BOUNTY"))
+		assert.True(t, coOwnerConfig.UserHasManageBountyRoles("co_owner", "workspace_uuid"))
+		assert.False(t, coOwnerConfig.UserHasAccess("user_pubkey", "workspace_uuid", "DELETE BOUNTY"))
+	})
 }
 
 func TestUserHasManageBountyRoles(t *testing.T) {
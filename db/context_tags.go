@@ -0,0 +1,218 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// MaxContextTags is how many tags one message can resolve
+const MaxContextTags = 20
+
+var (
+	ErrContextTagType     = errors.New("unknown context tag type")
+	ErrContextTagNotFound = errors.New("context tag not found in this workspace")
+)
+
+// ResolvedContextTag is the text a context tag stands for
+type ResolvedContextTag struct {
+	Type      ContextTagType `json:"type"`
+	ID        string         `json:"id"`
+	Title     string         `json:"title"`
+	Content   string         `json:"content"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// ResolveContextTags resolves the tags of a message within its workspace.
+// Duplicates are skipped, tags past MaxContextTags are dropped and tags that
+// can't be resolved carry their error instead of content.
+func (db database) ResolveContextTags(workspaceID string, tags []ContextTag) []ResolvedContextTag {
+	resolved := []ResolvedContextTag{}
+	seen := map[ContextTag]bool{}
+	for _, tag := range tags {
+		if seen[tag] || len(resolved) == MaxContextTags {
+			continue
+		}
+		seen[tag] = true
+
+		result, err := db.resolveContextTag(workspaceID, tag)
+		if err != nil {
+			result = ResolvedContextTag{Type: tag.Type, ID: tag.ID, Error: err.Error()}
+		}
+		resolved = append(resolved, result)
+	}
+	return resolved
+}
+
+func (db database) resolveContextTag(workspaceID string, tag ContextTag) (ResolvedContextTag, error) {
+	result := ResolvedContextTag{Type: tag.Type, ID: tag.ID}
+	notFound := func(err error) error {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrContextTagNotFound
+		}
+		return fmt.Errorf("failed to resolve %s context: %w", tag.Type, err)
+	}
+	// phases and stories belong to the workspace through their feature
+	workspaceFeatures := db.db.Model(&WorkspaceFeatures{}).Select("uuid").Where("workspace_uuid = ?", workspaceID)
+
+	switch tag.Type {
+	case ProductBriefContext:
+		brief, err := db.GetProductBrief(workspaceID)
+		if err != nil {
+			return result, notFound(gorm.ErrRecordNotFound)
+		}
+		result.Title = "Product brief"
+		result.Content = brief
+
+	case FeatureBriefContext:
+		var feature WorkspaceFeatures
+		if err := db.db.Where("uuid = ? AND workspace_uuid = ?", tag.ID, workspaceID).First(&feature).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = feature.Name
+		result.Content = contextSections(
+			"Feature", feature.Name,
+			"Brief", feature.Brief,
+			"Requirements", feature.Requirements,
+			"Architecture", feature.Architecture,
+		)
+
+	case SchematicContext:
+		var workspace Workspace
+		if err := db.db.Where("uuid = ?", workspaceID).First(&workspace).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = "Schematic"
+		result.Content = contextSections(
+			"Schematic URL", workspace.SchematicUrl,
+			"Schematic image", workspace.SchematicImg,
+		)
+
+	case TicketContext:
+		var ticket Tickets
+		if err := db.db.Where("uuid = ? AND workspace_uuid = ?", tag.ID, workspaceID).First(&ticket).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = ticket.Name
+		result.Content = contextSections(
+			"Ticket", ticket.Name,
+			"Status", string(ticket.Status),
+			"Description", ticket.Description,
+		)
+
+	case PhaseContext:
+		var phase FeaturePhase
+		if err := db.db.Where("uuid = ? AND feature_uuid IN (?)", tag.ID, workspaceFeatures).First(&phase).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = phase.Name
+		result.Content = contextSections(
+			"Phase", phase.Name,
+			"Purpose", phase.PhasePurpose,
+			"Outcome", phase.PhaseOutcome,
+			"Scope", phase.PhaseScope,
+			"Design", phase.PhaseDesign,
+		)
+
+	case StoryContext:
+		var story FeatureStory
+		if err := db.db.Where("uuid = ? AND feature_uuid IN (?)", tag.ID, workspaceFeatures).First(&story).Error; err != nil {
+			return result, notFound(err)
+		}
+		var criteria []AcceptanceCriterion
+		db.db.Where("story_uuid = ?", story.Uuid).Order("position ASC").Find(&criteria)
+
+		lines := []string{}
+		for _, criterion := range criteria {
+			lines = append(lines, "- "+criterion.Description)
+		}
+		result.Title = story.Description
+		result.Content = contextSections(
+			"Story", story.Description,
+			"Acceptance criteria", strings.Join(lines, "\n"),
+		)
+
+	case SnippetContext:
+		id, err := strconv.ParseUint(tag.ID, 10, 64)
+		if err != nil {
+			return result, ErrContextTagNotFound
+		}
+		var snippet TextSnippet
+		if err := db.db.Where("id = ? AND workspace_uuid = ?", id, workspaceID).First(&snippet).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = snippet.Title
+		result.Content = snippet.Snippet
+
+	case FileContext:
+		id, err := strconv.ParseUint(tag.ID, 10, 64)
+		if err != nil {
+			return result, ErrContextTagNotFound
+		}
+		var file FileAsset
+		if err := db.db.Where("id = ? AND workspace_id = ? AND deleted_at IS NULL", id, workspaceID).First(&file).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = file.OriginFilename
+		result.Content = contextSections(
+			"File", file.OriginFilename,
+			"Type", file.MimeType,
+			"Size", fmt.Sprintf("%d bytes", file.FileSize),
+			"URL", file.StoragePath,
+		)
+
+	case RepositoryContext:
+		var repository WorkspaceRepositories
+		if err := db.db.Where("uuid = ? AND workspace_uuid = ?", tag.ID, workspaceID).First(&repository).Error; err != nil {
+			return result, notFound(err)
+		}
+		result.Title = repository.Name
+		result.Content = contextSections(
+			"Repository", repository.Name,
+			"URL", repository.Url,
+		)
+
+	default:
+		return result, ErrContextTagType
+	}
+	return result, nil
+}
+
+// contextSections joins label and text pairs, skipping empty texts
+func contextSections(pairs ...string) string {
+	sections := []string{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if text := strings.TrimSpace(pairs[i+1]); text != "" {
+			sections = append(sections, pairs[i]+": "+text)
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// LimitContextTags cuts each tag to perTag characters and all of them to total,
+// later tags get what the earlier ones left
+func LimitContextTags(tags []ResolvedContextTag, perTag int, total int) []ResolvedContextTag {
+	limited := make([]ResolvedContextTag, len(tags))
+	remaining := total
+	for i, tag := range tags {
+		limit := perTag
+		if remaining < limit {
+			limit = remaining
+		}
+		if limit < 0 {
+			limit = 0
+		}
+		if utf8.RuneCountInString(tag.Content) > limit {
+			tag.Content = string([]rune(tag.Content)[:limit])
+			tag.Truncated = true
+		}
+		remaining -= utf8.RuneCountInString(tag.Content)
+		limited[i] = tag
+	}
+	return limited
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextSections(t *testing.T) {
+	assert.Equal(t, "Phase: Build\n\nScope: API only", contextSections("Phase", "Build", "Purpose", "  ", "Scope", "API only"))
+	assert.Equal(t, "", contextSections())
+}
+
+func TestLimitContextTags(t *testing.T) {
+	tags := []ResolvedContextTag{
+		{Type: TicketContext, ID: "t1", Content: strings.Repeat("a", 10)},
+		{Type: SnippetContext, ID: "1", Content: strings.Repeat("é", 8)},
+		{Type: StoryContext, ID: "s1", Content: "story"},
+		{Type: FileContext, ID: "2", Error: ErrContextTagNotFound.Error()},
+	}
+
+	limited := LimitContextTags(tags, 6, 10)
+	assert.Equal(t, "aaaaaa", limited[0].Content)
+	assert.True(t, limited[0].Truncated)
+	assert.Equal(t, "éééé", limited[1].Content)
+	assert.True(t, limited[1].Truncated)
+	assert.Equal(t, "", limited[2].Content)
+	assert.True(t, limited[2].Truncated)
+	assert.False(t, limited[3].Truncated)
+	assert.Equal(t, ErrContextTagNotFound.Error(), limited[3].Error)
+
+	// the input is left as it was
+	assert.Len(t, tags[0].Content, 10)
+}
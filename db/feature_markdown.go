@@ -0,0 +1,706 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/xid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFeatureMarkdownEmpty = errors.New("markdown document has no feature content")
+	ErrFeatureMarkdownName  = errors.New("markdown document needs a '# Feature name' heading to create a feature")
+	ErrFeatureSpecNotFound  = errors.New("feature not found in workspace")
+)
+
+// FeatureSpec is a feature as written in a markdown document:
+//
+//	# Feature name
+//	## Brief / ## Requirements / ## Architecture   free text
+//	## Stories                                     one bullet per story, nested bullets are its acceptance criteria
+//	## Phase: Name                                 text is the phase purpose, task list items are tickets
+//
+// Text before the first section is read as the brief.
+type FeatureSpec struct {
+	Name         string             `json:"name"`
+	Brief        string             `json:"brief"`
+	Requirements string             `json:"requirements"`
+	Architecture string             `json:"architecture"`
+	Stories      []FeatureSpecStory `json:"stories"`
+	Phases       []FeatureSpecPhase `json:"phases"`
+}
+
+type FeatureSpecStory struct {
+	Description string   `json:"description"`
+	Criteria    []string `json:"criteria"`
+}
+
+type FeatureSpecPhase struct {
+	Name    string              `json:"name"`
+	Purpose string              `json:"purpose"`
+	Tickets []FeatureSpecTicket `json:"tickets"`
+}
+
+type FeatureSpecTicket struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Done        bool   `json:"done"`
+}
+
+type specSection int
+
+const (
+	specNone specSection = iota
+	specBrief
+	specRequirements
+	specArchitecture
+	specStories
+	specPhase
+)
+
+// markdownIndent counts the leading spaces of a line, tabs count as four
+func markdownIndent(line string) int {
+	indent := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4
+		default:
+			return indent
+		}
+	}
+	return indent
+}
+
+// markdownListItem returns the text of a bullet list item
+func markdownListItem(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, bullet := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(trimmed, bullet) {
+			return strings.TrimSpace(trimmed[len(bullet):]), true
+		}
+	}
+	return "", false
+}
+
+// markdownTaskItem returns the text and the checked state of a task list item
+func markdownTaskItem(line string) (string, bool, bool) {
+	item, ok := markdownListItem(line)
+	if !ok || len(item) < 3 || item[0] != '[' || item[2] != ']' {
+		return "", false, false
+	}
+	switch item[1] {
+	case ' ':
+		return strings.TrimSpace(item[3:]), false, true
+	case 'x', 'X':
+		return strings.TrimSpace(item[3:]), true, true
+	}
+	return "", false, false
+}
+
+// markdownHeading returns the level and text of an ATX heading
+func markdownHeading(line string) (int, string) {
+	if markdownIndent(line) > 3 {
+		return 0, ""
+	}
+	trimmed := strings.TrimSpace(line)
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(trimmed) && trimmed[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(trimmed[level:], "#"))
+}
+
+func specSectionOf(title string) (specSection, string) {
+	lower := strings.ToLower(title)
+	switch lower {
+	case "brief", "product brief", "overview":
+		return specBrief, ""
+	case "requirements":
+		return specRequirements, ""
+	case "architecture":
+		return specArchitecture, ""
+	case "stories", "user stories":
+		return specStories, ""
+	}
+	if strings.HasPrefix(lower, "phase:") {
+		return specPhase, strings.TrimSpace(title[len("phase:"):])
+	}
+	return specPhase, title
+}
+
+// unescapeMarkdownText drops the escape written in front of text lines that
+// would otherwise read as a heading
+func unescapeMarkdownText(line string) string {
+	if strings.HasPrefix(strings.TrimLeft(line, " "), `\#`) {
+		return strings.Replace(line, `\#`, "#", 1)
+	}
+	return line
+}
+
+func joinSpecText(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// ParseFeatureMarkdown reads a feature spec document. Lines that do not fit
+// the section they are in are skipped and reported as warnings.
+func ParseFeatureMarkdown(markdown string) (FeatureSpec, []string, error) {
+	spec := FeatureSpec{Stories: []FeatureSpecStory{}, Phases: []FeatureSpecPhase{}}
+	warnings := []string{}
+
+	text := map[specSection][]string{}
+	section := specNone
+	var phase *FeatureSpecPhase
+	var purpose []string
+	var story *FeatureSpecStory
+	var ticket *FeatureSpecTicket
+	var ticketLines []string
+	ticketIndent := 0
+	fenced := false
+
+	flushTicket := func() {
+		if ticket != nil {
+			ticket.Description = joinSpecText(ticketLines)
+			phase.Tickets = append(phase.Tickets, *ticket)
+		}
+		ticket = nil
+		ticketLines = nil
+	}
+	flushPhase := func() {
+		flushTicket()
+		if phase != nil {
+			phase.Purpose = joinSpecText(purpose)
+			spec.Phases = append(spec.Phases, *phase)
+		}
+		phase = nil
+		purpose = nil
+	}
+	flushStory := func() {
+		if story != nil {
+			spec.Stories = append(spec.Stories, *story)
+		}
+		story = nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for n, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") || strings.HasPrefix(strings.TrimSpace(line), "~~~") {
+			fenced = !fenced
+		}
+
+		if level, title := markdownHeading(line); !fenced && level > 0 && level <= 2 {
+			if level == 1 {
+				if spec.Name == "" {
+					spec.Name = title
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("line %d: only the first '#' heading names the feature, %q is read as a section", n+1, title))
+			}
+			flushPhase()
+			flushStory()
+
+			var name string
+			section, name = specSectionOf(title)
+			if section == specPhase {
+				if name == "" {
+					warnings = append(warnings, fmt.Sprintf("line %d: phase heading without a name is skipped", n+1))
+					section = specNone
+					continue
+				}
+				phase = &FeatureSpecPhase{Name: name, Tickets: []FeatureSpecTicket{}}
+			}
+			continue
+		}
+
+		switch section {
+		case specNone, specBrief:
+			text[specBrief] = append(text[specBrief], unescapeMarkdownText(line))
+		case specRequirements, specArchitecture:
+			text[section] = append(text[section], unescapeMarkdownText(line))
+
+		case specStories:
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			item, isItem := markdownListItem(line)
+			indent := markdownIndent(line)
+			switch {
+			case isItem && indent < 2:
+				flushStory()
+				story = &FeatureSpecStory{Description: item, Criteria: []string{}}
+			case isItem && story != nil:
+				story.Criteria = append(story.Criteria, item)
+			case indent >= 2 && story != nil:
+				// continuation of the last story or criterion
+				if last := len(story.Criteria) - 1; last >= 0 {
+					story.Criteria[last] += " " + strings.TrimSpace(line)
+				} else {
+					story.Description += " " + strings.TrimSpace(line)
+				}
+			default:
+				warnings = append(warnings, fmt.Sprintf("line %d: stories are written as bullet list items, skipped %q", n+1, strings.TrimSpace(line)))
+			}
+
+		case specPhase:
+			indent := markdownIndent(line)
+			if name, done, isTask := markdownTaskItem(line); isTask && indent < 2 && !fenced {
+				flushTicket()
+				if name == "" {
+					warnings = append(warnings, fmt.Sprintf("line %d: ticket without a name is skipped", n+1))
+					continue
+				}
+				ticket = &FeatureSpecTicket{Name: name, Done: done}
+				ticketIndent = indent + 2
+				continue
+			}
+			if ticket != nil && (indent >= ticketIndent || strings.TrimSpace(line) == "" || fenced) {
+				if len(line) >= ticketIndent && strings.TrimSpace(line[:ticketIndent]) == "" {
+					line = line[ticketIndent:]
+				}
+				ticketLines = append(ticketLines, unescapeMarkdownText(line))
+				continue
+			}
+			flushTicket()
+			purpose = append(purpose, unescapeMarkdownText(line))
+		}
+	}
+	flushPhase()
+	flushStory()
+
+	spec.Brief = joinSpecText(text[specBrief])
+	spec.Requirements = joinSpecText(text[specRequirements])
+	spec.Architecture = joinSpecText(text[specArchitecture])
+
+	if spec.Name == "" && spec.Brief == "" && spec.Requirements == "" && spec.Architecture == "" &&
+		len(spec.Stories) == 0 && len(spec.Phases) == 0 {
+		return spec, warnings, ErrFeatureMarkdownEmpty
+	}
+	return spec, warnings, nil
+}
+
+// escapeMarkdownText keeps text lines from being read back as headings
+func escapeMarkdownText(text string) string {
+	lines := strings.Split(text, "\n")
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") || strings.HasPrefix(strings.TrimSpace(line), "~~~") {
+			fenced = !fenced
+		}
+		if level, _ := markdownHeading(line); !fenced && level > 0 && level <= 2 {
+			lines[i] = strings.Replace(line, "#", `\#`, 1)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func indentMarkdown(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderFeatureMarkdown writes a feature in the format ParseFeatureMarkdown
+// reads. Phases keep their priority order and tickets their sequence, only
+// the latest version of each ticket is written.
+func RenderFeatureMarkdown(feature WorkspaceFeatures, phases []FeaturePhase, stories []FeatureStory, tickets []Tickets) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", strings.TrimSpace(feature.Name))
+
+	for _, section := range []struct{ title, text string }{
+		{"Brief", feature.Brief},
+		{"Requirements", feature.Requirements},
+		{"Architecture", feature.Architecture},
+	} {
+		if text := strings.TrimSpace(section.text); text != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", section.title, escapeMarkdownText(text))
+		}
+	}
+
+	if len(stories) > 0 {
+		b.WriteString("\n## Stories\n\n")
+		sorted := append([]FeatureStory{}, stories...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority < sorted[j].Priority })
+		for _, story := range sorted {
+			fmt.Fprintf(&b, "- %s\n", strings.Join(strings.Fields(story.Description), " "))
+			for _, criterion := range story.AcceptanceCriteria {
+				fmt.Fprintf(&b, "  - %s\n", strings.Join(strings.Fields(criterion.Description), " "))
+			}
+		}
+	}
+
+	byPhase := map[string][]Tickets{}
+	for _, ticket := range LatestTicketsByGroup(tickets) {
+		byPhase[ticket.PhaseUUID] = append(byPhase[ticket.PhaseUUID], ticket)
+	}
+
+	sortedPhases := append([]FeaturePhase{}, phases...)
+	sort.SliceStable(sortedPhases, func(i, j int) bool { return sortedPhases[i].Priority < sortedPhases[j].Priority })
+	for _, phase := range sortedPhases {
+		fmt.Fprintf(&b, "\n## Phase: %s\n", strings.TrimSpace(phase.Name))
+		if purpose := strings.TrimSpace(phase.PhasePurpose); purpose != "" {
+			fmt.Fprintf(&b, "\n%s\n", escapeMarkdownText(purpose))
+		}
+
+		phaseTickets := byPhase[phase.Uuid]
+		sort.SliceStable(phaseTickets, func(i, j int) bool { return phaseTickets[i].Sequence < phaseTickets[j].Sequence })
+		if len(phaseTickets) > 0 {
+			b.WriteString("\n")
+		}
+		for _, ticket := range phaseTickets {
+			check := " "
+			if ticket.Status == CompletedTicket {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", check, strings.Join(strings.Fields(ticket.Name), " "))
+			if description := strings.TrimSpace(ticket.Description); description != "" {
+				fmt.Fprintf(&b, "%s\n", indentMarkdown(escapeMarkdownText(description), "  "))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// FeatureImportPlan is what an import writes. Phases are matched to the
+// existing phases of the feature by name, stories and tickets that already
+// exist under the same text are skipped so a document can be imported again.
+type FeatureImportPlan struct {
+	Feature        WorkspaceFeatures `json:"feature"`
+	NewFeature     bool              `json:"new_feature"`
+	Phases         []PhaseImportPlan `json:"phases"`
+	Stories        []FeatureStory    `json:"stories"`
+	SkippedStories []string          `json:"skipped_stories"`
+	Warnings       []string          `json:"warnings"`
+	Applied        bool              `json:"applied"`
+}
+
+type PhaseImportPlan struct {
+	Phase          FeaturePhase `json:"phase"`
+	Existing       bool         `json:"existing"`
+	PurposeSet     bool         `json:"purpose_set"`
+	Tickets        []Tickets    `json:"tickets"`
+	SkippedTickets []string     `json:"skipped_tickets"`
+}
+
+func specKey(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// PlanFeatureImport turns a parsed spec into the entities to create. A nil
+// existing feature creates a new one in the workspace.
+func PlanFeatureImport(spec FeatureSpec, workspaceUuid string, pubkey string, existing *WorkspaceFeatures, phases []FeaturePhase, stories []FeatureStory, tickets []Tickets, now time.Time) (FeatureImportPlan, error) {
+	plan := FeatureImportPlan{
+		Phases:         []PhaseImportPlan{},
+		Stories:        []FeatureStory{},
+		SkippedStories: []string{},
+		Warnings:       []string{},
+	}
+
+	if existing == nil {
+		if strings.TrimSpace(spec.Name) == "" {
+			return plan, ErrFeatureMarkdownName
+		}
+		plan.NewFeature = true
+		plan.Feature = WorkspaceFeatures{
+			Uuid:          xid.New().String(),
+			WorkspaceUuid: workspaceUuid,
+			FeatStatus:    ActiveFeature,
+			CreatedBy:     pubkey,
+			Created:       &now,
+		}
+	} else {
+		plan.Feature = *existing
+		plan.Feature.UpdatedBy = pubkey
+	}
+	plan.Feature.Updated = &now
+	if spec.Name != "" {
+		plan.Feature.Name = strings.TrimSpace(spec.Name)
+	}
+	if spec.Brief != "" {
+		plan.Feature.Brief = spec.Brief
+	}
+	if spec.Requirements != "" {
+		plan.Feature.Requirements = spec.Requirements
+	}
+	if spec.Architecture != "" {
+		plan.Feature.Architecture = spec.Architecture
+	}
+
+	knownStories := map[string]bool{}
+	storyPriority := 0
+	for _, story := range stories {
+		knownStories[specKey(story.Description)] = true
+		if story.Priority >= storyPriority {
+			storyPriority = story.Priority + 1
+		}
+	}
+	for _, spec := range spec.Stories {
+		key := specKey(spec.Description)
+		if knownStories[key] {
+			plan.SkippedStories = append(plan.SkippedStories, spec.Description)
+			continue
+		}
+		knownStories[key] = true
+
+		story := FeatureStory{
+			Uuid:               xid.New().String(),
+			FeatureUuid:        plan.Feature.Uuid,
+			Description:        spec.Description,
+			Priority:           storyPriority,
+			Created:            &now,
+			Updated:            &now,
+			CreatedBy:          pubkey,
+			AcceptanceCriteria: []AcceptanceCriterion{},
+		}
+		for i, criterion := range spec.Criteria {
+			story.AcceptanceCriteria = append(story.AcceptanceCriteria, AcceptanceCriterion{
+				StoryUuid:   story.Uuid,
+				FeatureUuid: plan.Feature.Uuid,
+				Description: criterion,
+				Position:    i,
+			})
+		}
+		plan.Stories = append(plan.Stories, story)
+		storyPriority++
+	}
+
+	existingPhases := map[string]FeaturePhase{}
+	phasePriority := 0
+	for _, phase := range phases {
+		existingPhases[specKey(phase.Name)] = phase
+		if phase.Priority >= phasePriority {
+			phasePriority = phase.Priority + 1
+		}
+	}
+	latest := LatestTicketsByGroup(tickets)
+
+	planned := map[string]int{}
+	for _, specPhase := range spec.Phases {
+		key := specKey(specPhase.Name)
+		index, seen := planned[key]
+		if seen {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("phase %q is written twice, its tickets are merged", specPhase.Name))
+		} else {
+			phasePlan := PhaseImportPlan{Tickets: []Tickets{}, SkippedTickets: []string{}}
+			if phase, ok := existingPhases[key]; ok {
+				phasePlan.Existing = true
+				phasePlan.Phase = phase
+				if phase.PhasePurpose == "" && specPhase.Purpose != "" {
+					phasePlan.Phase.PhasePurpose = specPhase.Purpose
+					phasePlan.Phase.UpdatedBy = pubkey
+					phasePlan.Phase.Updated = &now
+					phasePlan.PurposeSet = true
+				}
+			} else {
+				phasePlan.Phase = FeaturePhase{
+					Uuid:         xid.New().String(),
+					FeatureUuid:  plan.Feature.Uuid,
+					Name:         strings.TrimSpace(specPhase.Name),
+					Priority:     phasePriority,
+					PhasePurpose: specPhase.Purpose,
+					Created:      &now,
+					Updated:      &now,
+					CreatedBy:    pubkey,
+				}
+				phasePriority++
+			}
+			index = len(plan.Phases)
+			planned[key] = index
+			plan.Phases = append(plan.Phases, phasePlan)
+		}
+
+		phasePlan := &plan.Phases[index]
+		knownTickets := map[string]bool{}
+		sequence := 0
+		for _, ticket := range latest {
+			if ticket.PhaseUUID != phasePlan.Phase.Uuid {
+				continue
+			}
+			knownTickets[specKey(ticket.Name)] = true
+			if ticket.Sequence >= sequence {
+				sequence = ticket.Sequence + 1
+			}
+		}
+		for _, ticket := range phasePlan.Tickets {
+			knownTickets[specKey(ticket.Name)] = true
+			if ticket.Sequence >= sequence {
+				sequence = ticket.Sequence + 1
+			}
+		}
+
+		for _, specTicket := range specPhase.Tickets {
+			if knownTickets[specKey(specTicket.Name)] {
+				phasePlan.SkippedTickets = append(phasePlan.SkippedTickets, specTicket.Name)
+				continue
+			}
+			knownTickets[specKey(specTicket.Name)] = true
+
+			status := DraftTicket
+			if specTicket.Done {
+				status = CompletedTicket
+			}
+			id := uuid.New()
+			group := id
+			author := HumanAuthor
+			authorID := pubkey
+			phasePlan.Tickets = append(phasePlan.Tickets, Tickets{
+				UUID:          id,
+				TicketGroup:   &group,
+				WorkspaceUuid: plan.Feature.WorkspaceUuid,
+				FeatureUUID:   plan.Feature.Uuid,
+				PhaseUUID:     phasePlan.Phase.Uuid,
+				Name:          specTicket.Name,
+				Description:   specTicket.Description,
+				Sequence:      sequence,
+				DependsOn:     []string{},
+				Status:        status,
+				Version:       1,
+				Author:        &author,
+				AuthorID:      &authorID,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
+			sequence++
+		}
+	}
+
+	return plan, nil
+}
+
+// ImportFeatureMarkdown parses a spec document and imports it into a new
+// feature of the workspace, or into an existing one when featureUuid is set.
+// A preview returns the plan without writing it.
+func (db database) ImportFeatureMarkdown(workspaceUuid string, featureUuid string, pubkey string, markdown string, preview bool) (FeatureImportPlan, error) {
+	spec, warnings, err := ParseFeatureMarkdown(markdown)
+	if err != nil {
+		return FeatureImportPlan{Warnings: warnings}, err
+	}
+
+	var existing *WorkspaceFeatures
+	var phases []FeaturePhase
+	var stories []FeatureStory
+	var tickets []Tickets
+	if featureUuid != "" {
+		feature := db.GetFeatureByUuid(featureUuid)
+		if feature.Uuid == "" || feature.WorkspaceUuid != workspaceUuid {
+			return FeatureImportPlan{Warnings: warnings}, fmt.Errorf("%w: %s", ErrFeatureSpecNotFound, featureUuid)
+		}
+		existing = &feature
+		phases = db.GetPhasesByFeatureUuid(featureUuid)
+		if stories, err = db.GetFeatureStoriesByFeatureUuid(featureUuid); err != nil {
+			return FeatureImportPlan{Warnings: warnings}, fmt.Errorf("failed to fetch stories: %w", err)
+		}
+		if tickets, err = db.GetTicketsByFeatureUUID(featureUuid); err != nil {
+			return FeatureImportPlan{Warnings: warnings}, err
+		}
+	}
+
+	plan, err := PlanFeatureImport(spec, workspaceUuid, pubkey, existing, phases, stories, tickets, time.Now())
+	plan.Warnings = append(warnings, plan.Warnings...)
+	if err != nil || preview {
+		return plan, err
+	}
+
+	err = db.db.Transaction(func(tx *gorm.DB) error {
+		if plan.NewFeature {
+			if err := tx.Create(&plan.Feature).Error; err != nil {
+				return fmt.Errorf("failed to create feature: %w", err)
+			}
+		} else if err := tx.Model(&WorkspaceFeatures{}).Where("uuid = ?", plan.Feature.Uuid).Updates(map[string]interface{}{
+			"name":         plan.Feature.Name,
+			"brief":        plan.Feature.Brief,
+			"requirements": plan.Feature.Requirements,
+			"architecture": plan.Feature.Architecture,
+			"updated":      plan.Feature.Updated,
+			"updated_by":   plan.Feature.UpdatedBy,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update feature: %w", err)
+		}
+
+		for _, phasePlan := range plan.Phases {
+			if !phasePlan.Existing {
+				if err := tx.Create(&phasePlan.Phase).Error; err != nil {
+					return fmt.Errorf("failed to create phase: %w", err)
+				}
+			} else if phasePlan.PurposeSet {
+				if err := tx.Model(&FeaturePhase{}).Where("uuid = ?", phasePlan.Phase.Uuid).Updates(map[string]interface{}{
+					"phase_purpose": phasePlan.Phase.PhasePurpose,
+					"updated":       phasePlan.Phase.Updated,
+					"updated_by":    pubkey,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to update phase: %w", err)
+				}
+			}
+			if len(phasePlan.Tickets) > 0 {
+				if err := tx.Create(&phasePlan.Tickets).Error; err != nil {
+					return fmt.Errorf("failed to create tickets: %w", err)
+				}
+			}
+		}
+
+		for i := range plan.Stories {
+			story := plan.Stories[i]
+			criteria := story.AcceptanceCriteria
+			if err := tx.Create(&story).Error; err != nil {
+				return fmt.Errorf("failed to create story: %w", err)
+			}
+			story.AcceptanceCriteria = criteria
+			saved, err := saveStoryCriteria(tx, story)
+			if err != nil {
+				return fmt.Errorf("failed to save acceptance criteria: %w", err)
+			}
+			plan.Stories[i].AcceptanceCriteria = saved
+		}
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	for _, phasePlan := range plan.Phases {
+		for _, ticket := range phasePlan.Tickets {
+			db.recordTicketVersion(ticket)
+		}
+	}
+	if existing != nil {
+		db.recordTextRevisions(TextRevisionFeature, plan.Feature.Uuid, featureRevisionText(*existing), featureRevisionText(plan.Feature), pubkey, existing.Updated)
+	} else {
+		db.recordTextRevisions(TextRevisionFeature, plan.Feature.Uuid, featureRevisionText(WorkspaceFeatures{}), featureRevisionText(plan.Feature), pubkey, nil)
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// ExportFeatureMarkdown writes a feature with its phases, stories and latest
+// tickets as a spec document
+func (db database) ExportFeatureMarkdown(featureUuid string) (string, error) {
+	feature := db.GetFeatureByUuid(featureUuid)
+	if feature.Uuid == "" {
+		return "", fmt.Errorf("%w: %s", ErrFeatureSpecNotFound, featureUuid)
+	}
+
+	stories, err := db.GetFeatureStoriesByFeatureUuid(featureUuid)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch stories: %w", err)
+	}
+	tickets, err := db.GetTicketsByFeatureUUID(featureUuid)
+	if err != nil {
+		return "", err
+	}
+
+	return RenderFeatureMarkdown(feature, db.GetPhasesByFeatureUuid(featureUuid), stories, tickets), nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const featureSpecMarkdown = `# Payments
+
+Let hunters get paid in one click.
+
+## Requirements
+
+- Invoices are paid from the workspace budget
+- ## is not a heading inside a list
+
+## Architecture
+
+` + "```" + `
+## not a section inside a fence
+` + "```" + `
+
+## Stories
+
+- As a hunter I want to be paid
+  when my work is accepted
+  - payment is sent on approval
+  - the hunter is notified
+- As an admin I want to see the budget
+stray text
+
+## Phase: MVP
+
+Ship the basics first.
+
+- [ ] Create invoice
+  Call the invoice endpoint.
+
+  ### Notes
+  keep it small
+- [x] Add budget table
+
+## Polish
+- [ ] Notifications
+`
+
+func TestParseFeatureMarkdown(t *testing.T) {
+	spec, warnings, err := ParseFeatureMarkdown(featureSpecMarkdown)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Payments", spec.Name)
+	assert.Equal(t, "Let hunters get paid in one click.", spec.Brief)
+	assert.Contains(t, spec.Requirements, "- ## is not a heading inside a list")
+	assert.Equal(t, "```\n## not a section inside a fence\n```", spec.Architecture)
+
+	assert.Len(t, spec.Stories, 2)
+	assert.Equal(t, "As a hunter I want to be paid when my work is accepted", spec.Stories[0].Description)
+	assert.Equal(t, []string{"payment is sent on approval", "the hunter is notified"}, spec.Stories[0].Criteria)
+	assert.Len(t, warnings, 1)
+
+	assert.Len(t, spec.Phases, 2)
+	mvp := spec.Phases[0]
+	assert.Equal(t, "MVP", mvp.Name)
+	assert.Equal(t, "Ship the basics first.", mvp.Purpose)
+	assert.Len(t, mvp.Tickets, 2)
+	assert.Equal(t, "Create invoice", mvp.Tickets[0].Name)
+	assert.Equal(t, "Call the invoice endpoint.\n\n### Notes\nkeep it small", mvp.Tickets[0].Description)
+	assert.False(t, mvp.Tickets[0].Done)
+	assert.True(t, mvp.Tickets[1].Done)
+	assert.Equal(t, "Polish", spec.Phases[1].Name)
+
+	_, _, err = ParseFeatureMarkdown("  \n\n")
+	assert.ErrorIs(t, err, ErrFeatureMarkdownEmpty)
+}
+
+func TestRenderFeatureMarkdown(t *testing.T) {
+	feature := WorkspaceFeatures{Uuid: "feature", Name: "Payments", Brief: "One click\n## pay", Architecture: "Uses LND"}
+	phases := []FeaturePhase{
+		{Uuid: "later", Name: "Later", Priority: 1},
+		{Uuid: "mvp", Name: "MVP", Priority: 0, PhasePurpose: "Basics"},
+	}
+	stories := []FeatureStory{{Description: "As a hunter I want to be paid", AcceptanceCriteria: []AcceptanceCriterion{{Description: "paid on approval"}}}}
+
+	group := uuid.New()
+	tickets := []Tickets{
+		{UUID: uuid.New(), TicketGroup: &group, PhaseUUID: "mvp", Name: "old name", Version: 1, Sequence: 2},
+		{UUID: uuid.New(), TicketGroup: &group, PhaseUUID: "mvp", Name: "Create invoice", Description: "Call it\n# twice", Version: 2, Sequence: 2},
+		newGraphTicket("Budget table", 1, CompletedTicket),
+	}
+	tickets[2].PhaseUUID = "mvp"
+
+	markdown := RenderFeatureMarkdown(feature, phases, stories, tickets)
+	assert.Contains(t, markdown, "## Phase: MVP\n\nBasics\n\n- [x] Budget table\n- [ ] Create invoice\n  Call it\n  \\# twice\n")
+	assert.NotContains(t, markdown, "old name")
+
+	spec, warnings, err := ParseFeatureMarkdown(markdown)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "One click\n## pay", spec.Brief)
+	assert.Equal(t, "Uses LND", spec.Architecture)
+	assert.Equal(t, []string{"paid on approval"}, spec.Stories[0].Criteria)
+	assert.Equal(t, []string{"MVP", "Later"}, []string{spec.Phases[0].Name, spec.Phases[1].Name})
+	assert.Equal(t, "Call it\n# twice", spec.Phases[0].Tickets[1].Description)
+	assert.True(t, spec.Phases[0].Tickets[0].Done)
+}
+
+func TestPlanFeatureImport(t *testing.T) {
+	spec, _, err := ParseFeatureMarkdown(featureSpecMarkdown)
+	assert.NoError(t, err)
+	now := time.Now()
+
+	t.Run("Should create a new feature", func(t *testing.T) {
+		plan, err := PlanFeatureImport(spec, "workspace", "pubkey", nil, nil, nil, nil, now)
+		assert.NoError(t, err)
+		assert.True(t, plan.NewFeature)
+		assert.Equal(t, "workspace", plan.Feature.WorkspaceUuid)
+		assert.Len(t, plan.Stories, 2)
+		assert.Len(t, plan.Stories[0].AcceptanceCriteria, 2)
+		assert.Len(t, plan.Phases, 2)
+
+		ticket := plan.Phases[0].Tickets[1]
+		assert.Equal(t, CompletedTicket, ticket.Status)
+		assert.Equal(t, 1, ticket.Sequence)
+		assert.Equal(t, plan.Phases[0].Phase.Uuid, ticket.PhaseUUID)
+		assert.Equal(t, ticket.UUID, *ticket.TicketGroup)
+	})
+
+	t.Run("Should need a name for a new feature", func(t *testing.T) {
+		_, err := PlanFeatureImport(FeatureSpec{Brief: "text"}, "workspace", "pubkey", nil, nil, nil, nil, now)
+		assert.ErrorIs(t, err, ErrFeatureMarkdownName)
+	})
+
+	t.Run("Should skip what the feature already has", func(t *testing.T) {
+		feature := WorkspaceFeatures{Uuid: "feature", WorkspaceUuid: "workspace", Name: "Payments"}
+		phases := []FeaturePhase{{Uuid: "mvp", FeatureUuid: "feature", Name: "mvp", Priority: 3}}
+		stories := []FeatureStory{{Uuid: "story", Description: "As an admin I want to see  the budget", Priority: 4}}
+		existing := newGraphTicket("create invoice", 7, InProgressTicket)
+		existing.PhaseUUID = "mvp"
+
+		plan, err := PlanFeatureImport(spec, "workspace", "pubkey", &feature, phases, stories, []Tickets{existing}, now)
+		assert.NoError(t, err)
+		assert.False(t, plan.NewFeature)
+		assert.Equal(t, "Let hunters get paid in one click.", plan.Feature.Brief)
+
+		assert.Len(t, plan.Stories, 1)
+		assert.Equal(t, 5, plan.Stories[0].Priority)
+		assert.Equal(t, []string{"As an admin I want to see the budget"}, plan.SkippedStories)
+
+		mvp := plan.Phases[0]
+		assert.True(t, mvp.Existing)
+		assert.True(t, mvp.PurposeSet)
+		assert.Equal(t, []string{"Create invoice"}, mvp.SkippedTickets)
+		assert.Len(t, mvp.Tickets, 1)
+		assert.Equal(t, 8, mvp.Tickets[0].Sequence)
+
+		assert.False(t, plan.Phases[1].Existing)
+		assert.Equal(t, 4, plan.Phases[1].Phase.Priority)
+	})
+}
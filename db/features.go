@@ -78,6 +78,13 @@ func (db database) CreateOrEditFeature(m WorkspaceFeatures) (WorkspaceFeatures,
 	}
 
 	db.db.Model(&WorkspaceFeatures{}).Where("uuid = ?", m.Uuid).First(&m)
+
+	changedBy := m.UpdatedBy
+	if result.RowsAffected == 0 || changedBy == "" {
+		changedBy = m.CreatedBy
+	}
+	db.recordTextRevisions(TextRevisionFeature, m.Uuid, featureRevisionText(existing), featureRevisionText(m), changedBy, existing.Updated)
+
 	return m, nil
 }
 
@@ -179,9 +186,24 @@ func (db database) CreateOrEditFeatureStory(story FeatureStory) (FeatureStory, e
 		db.db.Model(&FeatureStory{}).Where("uuid = ?", story.Uuid).Updates(story)
 	}
 
+	// criteria left out of the request stay as they are
+	if story.AcceptanceCriteria != nil {
+		if err := db.db.Transaction(func(tx *gorm.DB) error {
+			_, err := saveStoryCriteria(tx, story)
+			return err
+		}); err != nil {
+			return story, fmt.Errorf("failed to save acceptance criteria: %w", err)
+		}
+	}
+
 	db.db.Model(&FeatureStory{}).Where("uuid = ?", story.Uuid).Find(&story)
 
-	return story, nil
+	stories := []FeatureStory{story}
+	if err := db.attachStoryCriteria(stories); err != nil {
+		return story, err
+	}
+
+	return stories[0], nil
 }
 
 func (db database) GetFeatureStoriesByFeatureUuid(featureUuid string) ([]FeatureStory, error) {
@@ -194,6 +216,9 @@ func (db database) GetFeatureStoriesByFeatureUuid(featureUuid string) ([]Feature
 	for i := range stories {
 		stories[i].Description = strings.TrimSpace(stories[i].Description)
 	}
+	if err := db.attachStoryCriteria(stories); err != nil {
+		return nil, err
+	}
 	return stories, nil
 }
 
@@ -203,15 +228,35 @@ func (db database) GetFeatureStoryByUuid(featureUuid, storyUuid string) (Feature
 	if result.RowsAffected == 0 {
 		return story, errors.New("no story found")
 	}
-	return story, nil
+
+	stories := []FeatureStory{story}
+	if err := db.attachStoryCriteria(stories); err != nil {
+		return story, err
+	}
+	return stories[0], nil
 }
 
+// DeleteFeatureStoryByUuid deletes a story along with its acceptance criteria
+// and their links, all or nothing
 func (db database) DeleteFeatureStoryByUuid(featureUuid, storyUuid string) error {
-	result := db.db.Where("feature_uuid = ? AND uuid = ?", featureUuid, storyUuid).Delete(&FeatureStory{})
-	if result.RowsAffected == 0 {
-		return errors.New("no story found to delete")
-	}
-	return nil
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("feature_uuid = ? AND uuid = ?", featureUuid, storyUuid).Delete(&FeatureStory{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete story: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("no story found to delete")
+		}
+
+		criteria := tx.Model(&AcceptanceCriterion{}).Select("uuid").Where("story_uuid = ?", storyUuid)
+		if err := tx.Where("criterion_uuid IN (?)", criteria).Delete(&AcceptanceCriterionLink{}).Error; err != nil {
+			return fmt.Errorf("failed to delete acceptance criterion links: %w", err)
+		}
+		if err := tx.Where("story_uuid = ?", storyUuid).Delete(&AcceptanceCriterion{}).Error; err != nil {
+			return fmt.Errorf("failed to delete acceptance criteria: %w", err)
+		}
+		return nil
+	})
 }
 
 func (db database) GetBountiesByFeatureAndPhaseUuid(featureUuid string, phaseUuid string, r *http.Request) ([]NewBounty, error) {
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrFileQuotaExceeded = errors.New("workspace storage quota exceeded")
+
+// fileAssetReferenced matches assets that a chat message or an artifact still links to
+const fileAssetReferenced = `file_assets.storage_path <> '' AND (
+	EXISTS (SELECT 1 FROM chat_messages WHERE chat_messages.pdf_url = file_assets.storage_path)
+	OR EXISTS (SELECT 1 FROM artifacts WHERE strpos(artifacts.content::text, file_assets.storage_path) > 0))`
+
+// CheckFileQuota returns ErrFileQuotaExceeded when a file of the given size
+// does not fit in the quota, a quota of 0 or less is unlimited
+func CheckFileQuota(used int64, size int64, quota int64) error {
+	if quota <= 0 {
+		return nil
+	}
+	if used+size > quota {
+		return fmt.Errorf("%w: %d of %d bytes used, file is %d bytes", ErrFileQuotaExceeded, used, quota, size)
+	}
+	return nil
+}
+
+// GetWorkspaceFileUsage sums the size of the files a workspace still keeps in storage
+func (db database) GetWorkspaceFileUsage(workspaceID string) (int64, error) {
+	var used int64
+	if err := db.db.Model(&FileAsset{}).
+		Where("workspace_id = ? AND status != ?", workspaceID, DeletedFileStatus).
+		Select("COALESCE(SUM(file_size), 0)").
+		Scan(&used).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum workspace file usage: %w", err)
+	}
+	return used, nil
+}
+
+// ArchiveFileAssets archives active assets last referenced before the cutoff
+// that nothing links to anymore. Archived assets that are linked again and
+// stale assets that are still linked get their reference time refreshed.
+func (db database) ArchiveFileAssets(before time.Time) (int64, int64, error) {
+	var archived, reactivated int64
+	now := time.Now()
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&FileAsset{}).
+			Where("status = ?", ArchivedFileStatus).
+			Where(fileAssetReferenced).
+			Updates(map[string]interface{}{
+				"status":          ActiveFileStatus,
+				"archived_at":     nil,
+				"last_referenced": now,
+				"updated_at":      now,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to reactivate file assets: %w", result.Error)
+		}
+		reactivated = result.RowsAffected
+
+		if err := tx.Model(&FileAsset{}).
+			Where("status = ? AND last_referenced < ?", ActiveFileStatus, before).
+			Where(fileAssetReferenced).
+			Updates(map[string]interface{}{
+				"last_referenced": now,
+				"updated_at":      now,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to refresh file asset references: %w", err)
+		}
+
+		result = tx.Model(&FileAsset{}).
+			Where("status = ? AND last_referenced < ?", ActiveFileStatus, before).
+			Updates(map[string]interface{}{
+				"status":      ArchivedFileStatus,
+				"archived_at": now,
+				"updated_at":  now,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to archive file assets: %w", result.Error)
+		}
+		archived = result.RowsAffected
+		return nil
+	})
+
+	return archived, reactivated, err
+}
+
+// GetExpiredFileAssets returns assets archived before the cutoff that are
+// still unreferenced, oldest first
+func (db database) GetExpiredFileAssets(before time.Time, limit int) ([]FileAsset, error) {
+	var assets []FileAsset
+	if err := db.db.
+		Where("status = ? AND archived_at < ?", ArchivedFileStatus, before).
+		Where("NOT (" + fileAssetReferenced + ")").
+		Order("archived_at ASC").
+		Limit(limit).
+		Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch expired file assets: %w", err)
+	}
+	return assets, nil
+}
@@ -0,0 +1,14 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFileQuota(t *testing.T) {
+	assert.NoError(t, CheckFileQuota(90, 10, 100))
+	assert.True(t, errors.Is(CheckFileQuota(90, 11, 100), ErrFileQuotaExceeded))
+	assert.NoError(t, CheckFileQuota(1<<40, 1, 0))
+}
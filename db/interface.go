@@ -96,6 +96,7 @@ type Database interface {
 	GetWorkspaceUsersCount(uuid string) int64
 	GetWorkspaceBountyCount(uuid string) int64
 	GetWorkspaceUser(pubkey string, workspace_uuid string) WorkspaceUsers
+	IsWorkspaceMember(pubkey string, workspaceUuid string) bool
 	CreateWorkspaceUser(orgUser WorkspaceUsers) WorkspaceUsers
 	DeleteWorkspaceUser(orgUser WorkspaceUsersData, org string) WorkspaceUsersData
 	GetBountyRoles() []BountyRoles
@@ -370,4 +371,83 @@ type Database interface {
 	GetAllBountyStakeProcesses() ([]BountyStakeProcess, error)
 	UpdateBountyStakeProcess(id uuid.UUID, updates map[string]interface{}) (*BountyStakeProcess, error)
 	DeleteBountyStakeProcess(id uuid.UUID) error
+	CreateOwnershipTransfer(transfer *WorkspaceOwnershipTransfer) (*WorkspaceOwnershipTransfer, error)
+	GetOwnershipTransferByID(id uuid.UUID) (*WorkspaceOwnershipTransfer, error)
+	GetOwnershipTransfersByWorkspace(workspaceUuid string) ([]WorkspaceOwnershipTransfer, error)
+	GetPendingOwnershipTransfersForPubkey(pubkey string) ([]WorkspaceOwnershipTransfer, error)
+	AcceptOwnershipTransfer(id uuid.UUID) (Workspace, error)
+	CloseOwnershipTransfer(id uuid.UUID, actorPubkey string, status OwnershipTransferStatus) (*WorkspaceOwnershipTransfer, error)
+	AddWorkspaceCoOwner(workspaceUuid string, pubkey string, actorPubkey string) (Workspace, error)
+	RemoveWorkspaceCoOwner(workspaceUuid string, pubkey string, actorPubkey string) (Workspace, error)
+	GetWorkspaceOwnershipAudit(workspaceUuid string) ([]WorkspaceOwnershipAudit, error)
+	TrashWorkspace(workspaceUuid string, deletedBy string) error
+	TrashFeature(featureUuid string, deletedBy string) error
+	TrashFeaturePhase(featureUuid string, phaseUuid string, deletedBy string) error
+	TrashTicketGroup(ticketGroupUUID uuid.UUID, deletedBy string) error
+	TrashBounty(pubkey string, created string, deletedBy string) (NewBounty, error)
+	GetTrashByWorkspace(workspaceUuid string) ([]TrashItem, error)
+	GetTrashItemByID(id uuid.UUID) (*TrashItem, error)
+	RestoreTrashItem(id uuid.UUID) (*TrashItem, error)
+	PurgeExpiredTrash() (int64, error)
+	ExportWorkspace(workspaceUuid string) (WorkspaceArchive, error)
+	ImportWorkspace(archive WorkspaceArchive, options WorkspaceImportOptions) (WorkspaceArchive, []ImportConflict, error)
+	GetTicketVersions(ticketGroup uuid.UUID) ([]TicketVersion, error)
+	GetTicketVersion(ticketGroup uuid.UUID, version int) (TicketVersion, error)
+	RevertTicketVersion(ticketGroup uuid.UUID, version int, author Author, authorID string) (Tickets, error)
+	GetPhaseTicketGraph(featureUUID string, phaseUUID string) (PhaseTicketGraph, error)
+	CheckTicketSequence(featureUUID string, phaseUUID string, group uuid.UUID, sequence int) error
+	GetTicketWorkflowRules(workspaceUuid string) ([]TicketWorkflowRule, error)
+	SetTicketWorkflowRules(workspaceUuid string, rules []TicketWorkflowRule) ([]TicketWorkflowRule, error)
+	CheckTicketTransition(pubkey string, from TicketStatus, ticket Tickets) (*TicketWorkflowRule, error)
+	GetWorkspaceBoard(workspaceUuid string, filter BoardFilter) (Board, error)
+	SetBoardColumnLimits(workspaceUuid string, limits []BoardColumnConfig) ([]BoardColumnConfig, error)
+	MoveBoardCard(workspaceUuid string, pubkey string, move BoardMove) (BoardMoveResult, error)
+	GetWorkspaceRoadmap(workspaceUuid string) (Roadmap, error)
+	GetAcceptanceCriterionLinks(featureUuid string, itemType BoardItemType, itemKey string) ([]AcceptanceCriterionLink, error)
+	SetAcceptanceCriterionLinks(featureUuid string, itemType BoardItemType, itemKey string, criteria []string, pubkey string) ([]AcceptanceCriterionLink, error)
+	GetFeatureTraceability(featureUuid string) (FeatureTraceability, error)
+	SubmitTicketPlan(planUUID uuid.UUID, pubkey string) (TicketPlan, error)
+	ReviewTicketPlan(planUUID uuid.UUID, reviewer string, decision PlanDecision, comment string) (TicketPlan, TicketPlanApproval, error)
+	ReopenTicketPlan(planUUID uuid.UUID, pubkey string) (TicketPlan, error)
+	GetTicketPlanRevisions(planUUID uuid.UUID) ([]TicketPlanRevision, error)
+	GetTicketPlanApprovals(planUUID uuid.UUID) ([]TicketPlanApproval, error)
+	CompareApprovedTicketPlan(planUUID uuid.UUID) (TicketPlanExecution, error)
+	BulkUpdateTickets(pubkey string, request BulkTicketRequest) (BulkTicketResponse, error)
+	ImportFeatureMarkdown(workspaceUuid string, featureUuid string, pubkey string, markdown string, preview bool) (FeatureImportPlan, error)
+	ExportFeatureMarkdown(featureUuid string) (string, error)
+	GetTextRevisions(entityType TextRevisionEntity, entityUuid string, field string) ([]TextRevision, error)
+	GetTextRevision(entityType TextRevisionEntity, entityUuid string, field string, version int) (TextRevision, error)
+	CompareTextRevisions(entityType TextRevisionEntity, entityUuid string, field string, from int, to int) (TextRevisionDiff, error)
+	RestoreTextRevision(entityType TextRevisionEntity, entityUuid string, field string, version int, pubkey string) (TextRevision, error)
+	GetWorkspaceFileUsage(workspaceID string) (int64, error)
+	ArchiveFileAssets(before time.Time) (int64, int64, error)
+	GetExpiredFileAssets(before time.Time, limit int) ([]FileAsset, error)
+	GetSSEMessageLogsAfter(chatID string, after *SSEMessageLog, limit int) ([]SSEMessageLog, error)
+	SearchWorkspace(workspaceID string, params WorkspaceSearchParams) (WorkspaceSearchResult, error)
+	GetChatBranches(chatID string) ([]ChatBranch, error)
+	GetChatBranchHistory(chatID string, branchID string) ([]ChatMessage, error)
+	GetChatMessageByID(id string) (ChatMessage, error)
+	ForkChatMessage(chatID string, messageID string, text string, pubkey string) (ChatBranch, ChatMessage, error)
+	SetActiveChatBranch(chatID string, branchID string) (Chat, error)
+	GetChatExport(chatID string, branchID string) (ChatExport, error)
+	CreateChatShareLink(link ChatShareLink) (ChatShareLink, error)
+	GetChatShareLinks(chatID string) ([]ChatShareLink, error)
+	GetChatShareLinkByToken(token string) (ChatShareLink, error)
+	RevokeChatShareLink(chatID string, id string) error
+	UpdateChatSummary(chatID string, branchID string, throughID string, summary string) (Chat, error)
+	SetChatMessagePinned(chatID string, messageID string, pinned bool) (ChatMessage, error)
+	SetArtifactPinned(id uuid.UUID, pinned bool) (*Artifact, error)
+	ResolveContextTags(workspaceID string, tags []ContextTag) []ResolvedContextTag
+	SaveSSEClient(chatID, url, webhookURL, lastEventID string) (*SSEClient, error)
+	UpdateSSEClientLastEventID(chatID, url, lastEventID string) error
+	UpdateSSEClientStatus(chatID, url string, status SSEClientStatus, lastError string) error
+	GetSSEClients(status SSEClientStatus) ([]SSEClient, error)
+	CreateWebhookDelivery(endpoint, event, payload string, nextAttemptAt time.Time) (*WebhookDelivery, error)
+	SaveWebhookAttempt(delivery WebhookDelivery, attempt WebhookAttempt) error
+	ClaimDueWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	ClaimWebhookDelivery(id uuid.UUID, now time.Time, lease time.Duration) (*WebhookDelivery, error)
+	GetWebhookDeliveryByID(id uuid.UUID) (*WebhookDelivery, error)
+	GetWebhookDeliveries(endpoint string, status WebhookDeliveryStatus, limit int, offset int) ([]WebhookDelivery, int64, error)
+	GetWebhookAttempts(deliveryID uuid.UUID) ([]WebhookAttempt, error)
+	GetBountyByTicketGroup(ticketGroup uuid.UUID) (NewBounty, error)
 }
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var ErrRoadmapDates = errors.New("target date can not be before the start date")
+
+// RoadmapProgress rolls the latest ticket versions and the bounties of a
+// feature or phase into one percentage. Every item is weighted by how far
+// along the board columns its status is, so a ticket in TEST counts for more
+// than one in READY and a paid bounty counts as done.
+type RoadmapProgress struct {
+	Percent                int `json:"percent"`
+	TicketsTotal           int `json:"tickets_total"`
+	TicketsCompleted       int `json:"tickets_completed"`
+	BountiesTotal          int `json:"bounties_total"`
+	BountiesCountCompleted int `json:"bounties_count_completed"`
+	BountiesCountAssigned  int `json:"bounties_count_assigned"`
+	BountiesCountOpen      int `json:"bounties_count_open"`
+}
+
+type RoadmapPhase struct {
+	FeaturePhase
+	Progress    RoadmapProgress `json:"progress"`
+	Scheduled   bool            `json:"scheduled"`
+	Overdue     bool            `json:"overdue"`
+	DaysOverdue int             `json:"days_overdue"`
+}
+
+// RoadmapFeature is a feature with its phases on the timeline. TimelineStart
+// and TimelineEnd fall back to the phase dates when the feature has none.
+type RoadmapFeature struct {
+	WorkspaceFeatures
+	Phases        []RoadmapPhase  `json:"phases"`
+	Progress      RoadmapProgress `json:"progress"`
+	TimelineStart *time.Time      `json:"timeline_start"`
+	TimelineEnd   *time.Time      `json:"timeline_end"`
+	Scheduled     bool            `json:"scheduled"`
+	Overdue       bool            `json:"overdue"`
+	DaysOverdue   int             `json:"days_overdue"`
+	OverduePhases int             `json:"overdue_phases"`
+}
+
+// Roadmap lays the features of a workspace out between the earliest start
+// and the latest target date. Unscheduled features are listed after the
+// scheduled ones.
+type Roadmap struct {
+	WorkspaceUuid string           `json:"workspace_uuid"`
+	Start         *time.Time       `json:"start"`
+	End           *time.Time       `json:"end"`
+	Features      []RoadmapFeature `json:"features"`
+	OverdueCount  int              `json:"overdue_count"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
+
+// ValidateRoadmapDates rejects a target date before the start date
+func ValidateRoadmapDates(start *time.Time, target *time.Time) error {
+	if start != nil && target != nil && target.Before(*start) {
+		return fmt.Errorf("%w: %s is before %s", ErrRoadmapDates, target.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// roadmapStatusWeight is the share of the work a status stands for
+func roadmapStatusWeight(status TicketStatus) float64 {
+	if status == "" {
+		status = DraftTicket
+	}
+	for i, s := range BoardStatuses {
+		if s == status {
+			return float64(i) / float64(len(BoardStatuses)-1)
+		}
+	}
+	return 0
+}
+
+// RoadmapProgressOf computes the progress of the given latest ticket versions
+// and bounties
+func RoadmapProgressOf(tickets []Tickets, bounties []NewBounty) RoadmapProgress {
+	progress := RoadmapProgress{
+		TicketsTotal:  len(tickets),
+		BountiesTotal: len(bounties),
+	}
+
+	total := 0.0
+	for _, ticket := range tickets {
+		total += roadmapStatusWeight(ticket.Status)
+		if ticket.Status == CompletedTicket {
+			progress.TicketsCompleted++
+		}
+	}
+	for _, bounty := range bounties {
+		total += roadmapStatusWeight(bountyBoardStatus(bounty))
+		switch BountyCardStatus(bounty) {
+		case StatusPaid, StatusComplete:
+			progress.BountiesCountCompleted++
+		case StatusTodo:
+			progress.BountiesCountOpen++
+		default:
+			progress.BountiesCountAssigned++
+		}
+	}
+
+	if items := len(tickets) + len(bounties); items > 0 {
+		progress.Percent = int(total * 100 / float64(items))
+	}
+	return progress
+}
+
+// roadmapOverdue is true when the target date has passed and the work is not
+// done, the second value is the number of whole days past the target
+func roadmapOverdue(target *time.Time, done bool, now time.Time) (bool, int) {
+	if target == nil || done || !now.After(*target) {
+		return false, 0
+	}
+	return true, int(now.Sub(*target).Hours() / 24)
+}
+
+func earliestDate(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+func latestDate(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// BuildRoadmap groups the phases, tickets and bounties under their features
+// and computes progress and overdue flags at now. Archived features are left
+// out.
+func BuildRoadmap(workspaceUuid string, features []WorkspaceFeatures, phases []FeaturePhase, tickets []Tickets, bounties []NewBounty, now time.Time) Roadmap {
+	phaseFeature := map[string]string{}
+	phasesOf := map[string][]FeaturePhase{}
+	for _, phase := range phases {
+		phaseFeature[phase.Uuid] = phase.FeatureUuid
+		phasesOf[phase.FeatureUuid] = append(phasesOf[phase.FeatureUuid], phase)
+	}
+
+	ticketsOf := map[string][]Tickets{}
+	for _, ticket := range LatestTicketsByGroup(tickets) {
+		ticketsOf[ticket.FeatureUUID] = append(ticketsOf[ticket.FeatureUUID], ticket)
+	}
+	bountiesOf := map[string][]NewBounty{}
+	for _, bounty := range bounties {
+		feature := bounty.FeatureUuid
+		if owner, ok := phaseFeature[bounty.PhaseUuid]; ok {
+			feature = owner
+		}
+		bountiesOf[feature] = append(bountiesOf[feature], bounty)
+	}
+
+	roadmap := Roadmap{
+		WorkspaceUuid: workspaceUuid,
+		Features:      []RoadmapFeature{},
+		GeneratedAt:   now,
+	}
+
+	for _, feature := range features {
+		if feature.FeatStatus == ArchivedFeature {
+			continue
+		}
+
+		featureTickets := ticketsOf[feature.Uuid]
+		featureBounties := bountiesOf[feature.Uuid]
+		item := RoadmapFeature{
+			WorkspaceFeatures: feature,
+			Phases:            []RoadmapPhase{},
+			Progress:          RoadmapProgressOf(featureTickets, featureBounties),
+			TimelineStart:     feature.StartDate,
+			TimelineEnd:       feature.TargetDate,
+		}
+		item.BountiesCountCompleted = item.Progress.BountiesCountCompleted
+		item.BountiesCountAssigned = item.Progress.BountiesCountAssigned
+		item.BountiesCountOpen = item.Progress.BountiesCountOpen
+
+		featurePhases := phasesOf[feature.Uuid]
+		sort.SliceStable(featurePhases, func(i, j int) bool {
+			return featurePhases[i].Priority < featurePhases[j].Priority
+		})
+		for _, phase := range featurePhases {
+			var phaseTickets []Tickets
+			for _, ticket := range featureTickets {
+				if ticket.PhaseUUID == phase.Uuid {
+					phaseTickets = append(phaseTickets, ticket)
+				}
+			}
+			var phaseBounties []NewBounty
+			for _, bounty := range featureBounties {
+				if bounty.PhaseUuid == phase.Uuid {
+					phaseBounties = append(phaseBounties, bounty)
+				}
+			}
+
+			entry := RoadmapPhase{
+				FeaturePhase: phase,
+				Progress:     RoadmapProgressOf(phaseTickets, phaseBounties),
+				Scheduled:    phase.StartDate != nil || phase.TargetDate != nil,
+			}
+			done := feature.FeatStatus == CompletedFeature || entry.Progress.Percent == 100
+			entry.Overdue, entry.DaysOverdue = roadmapOverdue(phase.TargetDate, done, now)
+			if entry.Overdue {
+				item.OverduePhases++
+			}
+			if feature.StartDate == nil {
+				item.TimelineStart = earliestDate(item.TimelineStart, phase.StartDate)
+			}
+			if feature.TargetDate == nil {
+				item.TimelineEnd = latestDate(item.TimelineEnd, phase.TargetDate)
+			}
+			item.Phases = append(item.Phases, entry)
+		}
+
+		item.Scheduled = item.TimelineStart != nil || item.TimelineEnd != nil
+		done := feature.FeatStatus == CompletedFeature || item.Progress.Percent == 100
+		item.Overdue, item.DaysOverdue = roadmapOverdue(item.TimelineEnd, done, now)
+		if item.Overdue {
+			roadmap.OverdueCount++
+		}
+
+		roadmap.Start = earliestDate(roadmap.Start, item.TimelineStart)
+		roadmap.End = latestDate(roadmap.End, item.TimelineEnd)
+		roadmap.Features = append(roadmap.Features, item)
+	}
+
+	sort.SliceStable(roadmap.Features, func(i, j int) bool {
+		a, b := roadmap.Features[i], roadmap.Features[j]
+		if a.Scheduled != b.Scheduled {
+			return a.Scheduled
+		}
+		as, bs := earliestDate(a.TimelineStart, a.TimelineEnd), earliestDate(b.TimelineStart, b.TimelineEnd)
+		if as != nil && bs != nil && !as.Equal(*bs) {
+			return as.Before(*bs)
+		}
+		return a.Priority < b.Priority
+	})
+
+	return roadmap
+}
+
+// GetWorkspaceRoadmap loads the features, phases, tickets and bounties of a
+// workspace and lays them out on the roadmap
+func (db database) GetWorkspaceRoadmap(workspaceUuid string) (Roadmap, error) {
+	var features []WorkspaceFeatures
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).Order("priority ASC").Find(&features).Error; err != nil {
+		return Roadmap{}, fmt.Errorf("failed to fetch roadmap features: %w", err)
+	}
+	featureUuids := make([]string, 0, len(features))
+	for _, feature := range features {
+		featureUuids = append(featureUuids, feature.Uuid)
+	}
+	if len(featureUuids) == 0 {
+		return BuildRoadmap(workspaceUuid, nil, nil, nil, nil, time.Now()), nil
+	}
+
+	var phases []FeaturePhase
+	if err := db.db.Where("feature_uuid IN ?", featureUuids).Order("created ASC").Find(&phases).Error; err != nil {
+		return Roadmap{}, fmt.Errorf("failed to fetch roadmap phases: %w", err)
+	}
+	phaseUuids := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		phaseUuids = append(phaseUuids, phase.Uuid)
+	}
+
+	// every version of the tickets is loaded so a group whose latest version
+	// moved to another feature does not count here through an older one
+	var tickets []Tickets
+	if err := db.db.Where("feature_uuid IN ? OR ticket_group IN (?)", featureUuids,
+		db.db.Model(&Tickets{}).Select("ticket_group").Where("feature_uuid IN ?", featureUuids),
+	).Find(&tickets).Error; err != nil {
+		return Roadmap{}, fmt.Errorf("failed to fetch roadmap tickets: %w", err)
+	}
+	tickets = LatestTicketsByGroup(tickets)
+
+	var bounties []NewBounty
+	query := db.db.Where("show = true AND feature_uuid IN ?", featureUuids)
+	if len(phaseUuids) > 0 {
+		query = db.db.Where("show = true AND (feature_uuid IN ? OR phase_uuid IN ?)", featureUuids, phaseUuids)
+	}
+	if err := query.Find(&bounties).Error; err != nil {
+		return Roadmap{}, fmt.Errorf("failed to fetch roadmap bounties: %w", err)
+	}
+
+	return BuildRoadmap(workspaceUuid, features, phases, tickets, bounties, time.Now()), nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRoadmap(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		date := now.AddDate(0, 0, d)
+		return &date
+	}
+
+	late := WorkspaceFeatures{Uuid: "late", Name: "late", Priority: 2, FeatStatus: ActiveFeature}
+	planned := WorkspaceFeatures{Uuid: "planned", Name: "planned", Priority: 1, FeatStatus: ActiveFeature, StartDate: day(5), TargetDate: day(30)}
+	backlog := WorkspaceFeatures{Uuid: "backlog", Name: "backlog", FeatStatus: BacklogFeature}
+	archived := WorkspaceFeatures{Uuid: "archived", Name: "archived", FeatStatus: ArchivedFeature, TargetDate: day(-30)}
+
+	phases := []FeaturePhase{
+		{Uuid: "late-2", FeatureUuid: "late", Priority: 2, StartDate: day(-5), TargetDate: day(-2)},
+		{Uuid: "late-1", FeatureUuid: "late", Priority: 1, StartDate: day(-20), TargetDate: day(-10)},
+	}
+
+	done := newGraphTicket("done", 0, CompletedTicket)
+	done.FeatureUUID, done.PhaseUUID = "late", "late-1"
+	inTest := newGraphTicket("testing", 1, TestTicket)
+	inTest.FeatureUUID, inTest.PhaseUUID = "late", "late-2"
+	bounties := []NewBounty{
+		{ID: 1, FeatureUuid: "late", PhaseUuid: "late-1", Paid: true},
+		{ID: 2, PhaseUuid: "late-2", Assignee: "hunter"},
+		{ID: 3, FeatureUuid: "planned"},
+	}
+
+	roadmap := BuildRoadmap("workspace", []WorkspaceFeatures{late, planned, backlog, archived}, phases, []Tickets{done, inTest}, bounties, now)
+
+	t.Run("Should order scheduled features on the timeline and leave out archived ones", func(t *testing.T) {
+		assert.Len(t, roadmap.Features, 3)
+		assert.Equal(t, []string{"late", "planned", "backlog"}, []string{roadmap.Features[0].Uuid, roadmap.Features[1].Uuid, roadmap.Features[2].Uuid})
+		assert.Equal(t, day(-20), roadmap.Start)
+		assert.Equal(t, day(30), roadmap.End)
+		assert.False(t, roadmap.Features[2].Scheduled)
+	})
+
+	t.Run("Should roll up progress from tickets and bounties", func(t *testing.T) {
+		feature := roadmap.Features[0]
+		assert.Equal(t, []string{"late-1", "late-2"}, []string{feature.Phases[0].Uuid, feature.Phases[1].Uuid})
+		assert.Equal(t, 100, feature.Phases[0].Progress.Percent)
+		assert.Equal(t, 2, feature.Progress.TicketsTotal)
+		assert.Equal(t, 1, feature.Progress.TicketsCompleted)
+		assert.Equal(t, 1, feature.BountiesCountCompleted)
+		assert.Equal(t, 1, feature.BountiesCountAssigned)
+		assert.Equal(t, 16, roadmap.Features[1].Progress.Percent)
+		assert.Equal(t, 1, roadmap.Features[1].BountiesCountOpen)
+	})
+
+	t.Run("Should flag overdue features and phases", func(t *testing.T) {
+		feature := roadmap.Features[0]
+		assert.Equal(t, day(-2), feature.TimelineEnd)
+		assert.True(t, feature.Overdue)
+		assert.Equal(t, 2, feature.DaysOverdue)
+		assert.False(t, feature.Phases[0].Overdue)
+		assert.True(t, feature.Phases[1].Overdue)
+		assert.Equal(t, 1, feature.OverduePhases)
+		assert.False(t, roadmap.Features[1].Overdue)
+		assert.Equal(t, 1, roadmap.OverdueCount)
+	})
+
+	t.Run("Should count only the latest version of a ticket", func(t *testing.T) {
+		reopened := done
+		reopened.UUID = uuid.New()
+		reopened.Version = 2
+		reopened.Status = InProgressTicket
+		moved := inTest
+		moved.UUID = uuid.New()
+		moved.Version = 2
+		moved.FeatureUUID, moved.PhaseUUID = "planned", ""
+
+		versioned := BuildRoadmap("workspace", []WorkspaceFeatures{late, planned}, phases, []Tickets{done, reopened, inTest, moved}, nil, now)
+		feature := versioned.Features[0]
+		assert.Equal(t, "late", feature.Uuid)
+		assert.Equal(t, 1, feature.Progress.TicketsTotal, "the version moved to another feature is not counted")
+		assert.Equal(t, 0, feature.Progress.TicketsCompleted)
+		assert.Equal(t, RoadmapProgressOf([]Tickets{reopened}, nil).Percent, feature.Progress.Percent)
+		assert.True(t, feature.Overdue)
+		assert.Equal(t, 1, versioned.Features[1].Progress.TicketsTotal)
+	})
+
+	t.Run("Should reject a target date before the start date", func(t *testing.T) {
+		assert.ErrorIs(t, ValidateRoadmapDates(day(2), day(1)), ErrRoadmapDates)
+		assert.NoError(t, ValidateRoadmapDates(day(1), nil))
+	})
+}
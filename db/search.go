@@ -0,0 +1,279 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+var ErrSearchQuery = errors.New("search query is required")
+
+type SearchKind string
+
+const (
+	MessageSearch  SearchKind = "message"
+	ArtifactSearch SearchKind = "artifact"
+	SnippetSearch  SearchKind = "snippet"
+)
+
+var SearchKinds = []SearchKind{MessageSearch, ArtifactSearch, SnippetSearch}
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// ts_headline wraps matches in these so excerpts can be split without
+// trusting any markup in the searched text
+const (
+	searchMatchStart = "\x02"
+	searchMatchStop  = "\x03"
+)
+
+// searchColumns are generated tsvector columns, unlike the tsv columns of
+// tribes and bots postgres keeps them current on every write
+var searchColumns = []struct {
+	table      string
+	expression string
+}{
+	{table: "chat_messages", expression: `to_tsvector('english', coalesce(message, ''))`},
+	{table: "artifacts", expression: `jsonb_to_tsvector('english', content, '["string"]')`},
+	{table: "text_snippets", expression: `setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(snippet, '')), 'B')`},
+}
+
+// MigrateSearchColumns adds the full text search columns and their indexes
+func (db database) MigrateSearchColumns() {
+	for _, sc := range searchColumns {
+		if !db.db.Migrator().HasTable(sc.table) {
+			continue
+		}
+		if !db.db.Migrator().HasColumn(sc.table, "tsv") {
+			if err := db.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN tsv tsvector GENERATED ALWAYS AS (%s) STORED`, sc.table, sc.expression)).Error; err != nil {
+				logger.Log.Error("[search] failed to add %s.tsv: %v", sc.table, err)
+				continue
+			}
+		}
+		if err := db.db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_tsv ON %s USING GIN (tsv)`, sc.table, sc.table)).Error; err != nil {
+			logger.Log.Error("[search] failed to index %s.tsv: %v", sc.table, err)
+		}
+	}
+}
+
+type WorkspaceSearchParams struct {
+	Query  string       `json:"query"`
+	Kinds  []SearchKind `json:"kinds"`
+	ChatID string       `json:"chat_id"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+type SearchExcerptPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+type WorkspaceSearchHit struct {
+	Kind         SearchKind          `json:"kind"`
+	ChatID       string              `json:"chat_id,omitempty"`
+	ChatTitle    string              `json:"chat_title,omitempty"`
+	MessageID    string              `json:"message_id,omitempty"`
+	ArtifactID   string              `json:"artifact_id,omitempty"`
+	SnippetID    uint                `json:"snippet_id,omitempty"`
+	Title        string              `json:"title,omitempty"`
+	Excerpt      string              `json:"excerpt"`
+	ExcerptParts []SearchExcerptPart `json:"excerpt_parts"`
+	Rank         float64             `json:"rank"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+type WorkspaceSearchResult struct {
+	Query  string               `json:"query"`
+	Total  int64                `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+	Hits   []WorkspaceSearchHit `json:"hits"`
+}
+
+// NormalizeSearchParams trims the query, drops unknown kinds and clamps the page
+func NormalizeSearchParams(params WorkspaceSearchParams) (WorkspaceSearchParams, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return params, ErrSearchQuery
+	}
+
+	kinds := []SearchKind{}
+	for _, known := range SearchKinds {
+		for _, kind := range params.Kinds {
+			if kind == known {
+				kinds = append(kinds, known)
+				break
+			}
+		}
+	}
+	if len(kinds) == 0 {
+		kinds = append(kinds, SearchKinds...)
+	}
+	// snippets don't belong to a chat
+	if params.ChatID != "" {
+		filtered := []SearchKind{}
+		for _, kind := range kinds {
+			if kind != SnippetSearch {
+				filtered = append(filtered, kind)
+			}
+		}
+		kinds = filtered
+	}
+	params.Kinds = kinds
+
+	if params.Limit <= 0 {
+		params.Limit = DefaultSearchLimit
+	}
+	if params.Limit > MaxSearchLimit {
+		params.Limit = MaxSearchLimit
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+	return params, nil
+}
+
+// SplitSearchHeadline turns a ts_headline result into plain text and its
+// matched and unmatched parts
+func SplitSearchHeadline(headline string) (string, []SearchExcerptPart) {
+	parts := []SearchExcerptPart{}
+	var plain strings.Builder
+
+	match := false
+	for headline != "" {
+		marker := searchMatchStart
+		if match {
+			marker = searchMatchStop
+		}
+		text := headline
+		if i := strings.Index(headline, marker); i >= 0 {
+			text = headline[:i]
+			headline = headline[i+len(marker):]
+		} else {
+			headline = ""
+		}
+		if text != "" {
+			parts = append(parts, SearchExcerptPart{Text: text, Match: match})
+			plain.WriteString(text)
+		}
+		match = !match
+	}
+	return plain.String(), parts
+}
+
+type searchRow struct {
+	Kind       SearchKind
+	ChatID     string
+	ChatTitle  string
+	MessageID  string
+	ArtifactID string
+	SnippetID  uint
+	Title      string
+	Headline   string
+	Rank       float64
+	CreatedAt  time.Time
+}
+
+const searchHeadlineOptions = "StartSel=\"\x02\", StopSel=\"\x03\", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// SearchWorkspace runs a ranked full text search over the chat messages,
+// artifacts and snippets of a workspace. The SQL uses named arguments only,
+// a ? would be read as a positional one.
+func (db database) SearchWorkspace(workspaceID string, params WorkspaceSearchParams) (WorkspaceSearchResult, error) {
+	params, err := NormalizeSearchParams(params)
+	if err != nil {
+		return WorkspaceSearchResult{}, err
+	}
+
+	result := WorkspaceSearchResult{Query: params.Query, Limit: params.Limit, Offset: params.Offset, Hits: []WorkspaceSearchHit{}}
+	if len(params.Kinds) == 0 {
+		return result, nil
+	}
+
+	chatFilter := ""
+	if params.ChatID != "" {
+		chatFilter = " AND chats.id = @chat_id"
+	}
+
+	selects := []string{}
+	for _, kind := range params.Kinds {
+		switch kind {
+		case MessageSearch:
+			selects = append(selects, `SELECT 'message' AS kind, chats.id AS chat_id, chats.title AS chat_title,
+				chat_messages.id AS message_id, '' AS artifact_id, 0 AS snippet_id, '' AS title,
+				ts_headline('english', chat_messages.message, q, @options) AS headline,
+				ts_rank(chat_messages.tsv, q) AS rank, chat_messages.timestamp AS created_at
+			FROM chat_messages
+			JOIN chats ON chats.id = chat_messages.chat_id
+			CROSS JOIN search_query
+			WHERE chats.workspace_id = @workspace AND chat_messages.tsv @@ q`+chatFilter)
+		case ArtifactSearch:
+			selects = append(selects, `SELECT 'artifact' AS kind, chats.id AS chat_id, chats.title AS chat_title,
+				chat_messages.id AS message_id, artifacts.id::text AS artifact_id, 0 AS snippet_id, '' AS title,
+				ts_headline('english', coalesce((SELECT string_agg(value #>> '{}', ' ')
+					FROM jsonb_path_query(artifacts.content, 'strict $.**') AS value
+					WHERE jsonb_typeof(value) = 'string'), ''), q, @options) AS headline,
+				ts_rank(artifacts.tsv, q) AS rank, artifacts.created_at AS created_at
+			FROM artifacts
+			JOIN chat_messages ON chat_messages.id = artifacts.message_id
+			JOIN chats ON chats.id = chat_messages.chat_id
+			CROSS JOIN search_query
+			WHERE chats.workspace_id = @workspace AND artifacts.tsv @@ q`+chatFilter)
+		case SnippetSearch:
+			selects = append(selects, `SELECT 'snippet' AS kind, '' AS chat_id, '' AS chat_title,
+				'' AS message_id, '' AS artifact_id, text_snippets.id AS snippet_id, text_snippets.title AS title,
+				ts_headline('english', text_snippets.snippet, q, @options) AS headline,
+				ts_rank(text_snippets.tsv, q) AS rank, text_snippets.date_created AS created_at
+			FROM text_snippets
+			CROSS JOIN search_query
+			WHERE text_snippets.workspace_uuid = @workspace AND text_snippets.tsv @@ q`)
+		}
+	}
+
+	union := "WITH search_query AS (SELECT websearch_to_tsquery('english', @query) AS q) " + strings.Join(selects, " UNION ALL ")
+	named := map[string]interface{}{
+		"query":     params.Query,
+		"workspace": workspaceID,
+		"chat_id":   params.ChatID,
+		"options":   searchHeadlineOptions,
+	}
+
+	if err := db.db.Raw("SELECT COUNT(*) FROM ("+union+") AS hits", named).Scan(&result.Total).Error; err != nil {
+		return result, fmt.Errorf("failed to count search hits: %w", err)
+	}
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	var rows []searchRow
+	named["limit"] = params.Limit
+	named["offset"] = params.Offset
+	if err := db.db.Raw(union+" ORDER BY rank DESC, created_at DESC LIMIT @limit OFFSET @offset", named).Scan(&rows).Error; err != nil {
+		return result, fmt.Errorf("failed to search workspace: %w", err)
+	}
+
+	for _, row := range rows {
+		excerpt, parts := SplitSearchHeadline(row.Headline)
+		result.Hits = append(result.Hits, WorkspaceSearchHit{
+			Kind:         row.Kind,
+			ChatID:       row.ChatID,
+			ChatTitle:    row.ChatTitle,
+			MessageID:    row.MessageID,
+			ArtifactID:   row.ArtifactID,
+			SnippetID:    row.SnippetID,
+			Title:        row.Title,
+			Excerpt:      excerpt,
+			ExcerptParts: parts,
+			Rank:         row.Rank,
+			CreatedAt:    row.CreatedAt,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSearchParams(t *testing.T) {
+	_, err := NormalizeSearchParams(WorkspaceSearchParams{Query: "  "})
+	assert.ErrorIs(t, err, ErrSearchQuery)
+
+	params, err := NormalizeSearchParams(WorkspaceSearchParams{Query: " deploy ", Kinds: []SearchKind{"unknown"}, Limit: 500, Offset: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy", params.Query)
+	assert.Equal(t, SearchKinds, params.Kinds)
+	assert.Equal(t, MaxSearchLimit, params.Limit)
+	assert.Equal(t, 0, params.Offset)
+
+	params, _ = NormalizeSearchParams(WorkspaceSearchParams{Query: "deploy", Kinds: []SearchKind{SnippetSearch, MessageSearch}, ChatID: "chat"})
+	assert.Equal(t, []SearchKind{MessageSearch}, params.Kinds)
+	assert.Equal(t, DefaultSearchLimit, params.Limit)
+}
+
+func TestSplitSearchHeadline(t *testing.T) {
+	excerpt, parts := SplitSearchHeadline("the \x02deploy\x03 <b>failed</b> on \x02deploy\x03")
+	assert.Equal(t, "the deploy <b>failed</b> on deploy", excerpt)
+	assert.Equal(t, []SearchExcerptPart{
+		{Text: "the "},
+		{Text: "deploy", Match: true},
+		{Text: " <b>failed</b> on "},
+		{Text: "deploy", Match: true},
+	}, parts)
+
+	excerpt, parts = SplitSearchHeadline("")
+	assert.Equal(t, "", excerpt)
+	assert.Empty(t, parts)
+}
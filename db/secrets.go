@@ -0,0 +1,336 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// encryptedPrefix marks a column value produced by the envelope encryption
+// layer. Values without it are treated as legacy plaintext.
+const encryptedPrefix = "enc:v1:"
+
+type SecretsKeyring struct {
+	mu       sync.RWMutex
+	keys     map[string][]byte
+	activeID string
+}
+
+var Secrets = &SecretsKeyring{keys: map[string][]byte{}}
+
+// InitSecrets loads the key encryption keys from the environment.
+//
+// SECRETS_ENCRYPTION_KEYS is a comma separated list of id:base64key pairs,
+// each key 32 bytes long. SECRETS_ACTIVE_KEY_ID selects the key used for new
+// writes; older keys stay available for decryption so they can be rotated out.
+func InitSecrets() {
+	keys := os.Getenv("SECRETS_ENCRYPTION_KEYS")
+	activeID := os.Getenv("SECRETS_ACTIVE_KEY_ID")
+
+	if keys == "" {
+		logger.Log.Info("[secrets] SECRETS_ENCRYPTION_KEYS not set, secrets will be stored in plaintext")
+		return
+	}
+
+	if err := Secrets.Load(keys, activeID); err != nil {
+		panic(err)
+	}
+}
+
+// Load parses a key list and replaces the keyring content
+func (k *SecretsKeyring) Load(keyList string, activeID string) error {
+	keys := map[string][]byte{}
+	firstID := ""
+
+	for _, entry := range strings.Split(keyList, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return errors.New("invalid secrets key entry, expected id:base64key")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid secrets key %s: %w", parts[0], err)
+		}
+		if len(key) != 32 {
+			return fmt.Errorf("secrets key %s must be 32 bytes", parts[0])
+		}
+
+		if firstID == "" {
+			firstID = parts[0]
+		}
+		keys[parts[0]] = key
+	}
+
+	if activeID == "" {
+		activeID = firstID
+	}
+	if _, ok := keys[activeID]; !ok {
+		return fmt.Errorf("active secrets key %s not found", activeID)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.activeID = activeID
+	return nil
+}
+
+func (k *SecretsKeyring) Enabled() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.activeID != ""
+}
+
+func (k *SecretsKeyring) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.activeID
+}
+
+// Encrypt seals the plaintext with a fresh data key, and wraps that data key
+// with the active key encryption key
+func (k *SecretsKeyring) Encrypt(plaintext string) (string, error) {
+	k.mu.RLock()
+	activeID := k.activeID
+	kek := k.keys[activeID]
+	k.mu.RUnlock()
+
+	if activeID == "" {
+		return plaintext, nil
+	}
+
+	dek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := sealAESGCM(kek, dek)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := sealAESGCM(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + activeID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value produced by Encrypt, plaintext values are returned as is
+func (k *SecretsKeyring) Decrypt(value string) (string, error) {
+	if !IsEncryptedSecret(value) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted secret")
+	}
+
+	k.mu.RLock()
+	kek, ok := k.keys[parts[0]]
+	k.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("secrets key %s not found", parts[0])
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+
+	dek, err := openAESGCM(kek, wrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := openAESGCM(dek, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsReencryption reports whether a stored value is plaintext or sealed with
+// a key other than the active one
+func (k *SecretsKeyring) NeedsReencryption(value string) bool {
+	if value == "" || !k.Enabled() {
+		return false
+	}
+	if !IsEncryptedSecret(value) {
+		return true
+	}
+	return !strings.HasPrefix(value, encryptedPrefix+k.ActiveKeyID()+":")
+}
+
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+func sealAESGCM(key []byte, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openAESGCM(key []byte, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted secret is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// EncryptedString is a string column that is encrypted at rest
+type EncryptedString string
+
+// Value ...
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	return Secrets.Encrypt(string(s))
+}
+
+// Scan ...
+func (s *EncryptedString) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return errors.New("type assertion to string failed")
+	}
+
+	plaintext, err := Secrets.Decrypt(value)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+type secretColumn struct {
+	table  string
+	key    string
+	column string
+}
+
+var secretColumns = []secretColumn{
+	{table: "code_space_maps", key: "id", column: "github_pat"},
+	{table: "code_space_maps", key: "id", column: "pool_api_key"},
+	{table: "workspace_code_graphs", key: "id", column: "secret_alias"},
+}
+
+// EncryptSecretColumns encrypts legacy plaintext secrets and re-encrypts values
+// sealed with a rotated out key, so every row ends up under the active key
+func (db database) EncryptSecretColumns() {
+	if !Secrets.Enabled() {
+		return
+	}
+
+	for _, sc := range secretColumns {
+		if !db.db.Migrator().HasTable(sc.table) {
+			continue
+		}
+
+		type row struct {
+			Key   string
+			Value string
+		}
+		var rows []row
+		err := db.db.Table(sc.table).
+			Select(fmt.Sprintf("%s::text AS key, %s AS value", sc.key, sc.column)).
+			Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", sc.column, sc.column)).
+			Scan(&rows).Error
+		if err != nil {
+			logger.Log.Error("[secrets] failed to read %s.%s: %v", sc.table, sc.column, err)
+			continue
+		}
+
+		updated := 0
+		for _, r := range rows {
+			if !Secrets.NeedsReencryption(r.Value) {
+				continue
+			}
+
+			plaintext, err := Secrets.Decrypt(r.Value)
+			if err != nil {
+				logger.Log.Error("[secrets] failed to decrypt %s.%s for %s: %v", sc.table, sc.column, r.Key, err)
+				continue
+			}
+
+			ciphertext, err := Secrets.Encrypt(plaintext)
+			if err != nil {
+				logger.Log.Error("[secrets] failed to encrypt %s.%s for %s: %v", sc.table, sc.column, r.Key, err)
+				continue
+			}
+
+			if err := db.db.Table(sc.table).Where(fmt.Sprintf("%s = ?", sc.key), r.Key).
+				UpdateColumn(sc.column, ciphertext).Error; err != nil {
+				logger.Log.Error("[secrets] failed to update %s.%s for %s: %v", sc.table, sc.column, r.Key, err)
+				continue
+			}
+			updated++
+		}
+
+		if updated > 0 {
+			logger.Log.Info("[secrets] encrypted %d values in %s.%s", updated, sc.table, sc.column)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSecretsKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestSecretsKeyring(t *testing.T) {
+	t.Run("should return plaintext when no key is configured", func(t *testing.T) {
+		keyring := &SecretsKeyring{keys: map[string][]byte{}}
+
+		value, err := keyring.Encrypt("ghp_token")
+		assert.NoError(t, err)
+		assert.Equal(t, "ghp_token", value)
+		assert.False(t, keyring.NeedsReencryption(value))
+	})
+
+	t.Run("should encrypt and decrypt a value", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a'), ""))
+
+		value, err := keyring.Encrypt("ghp_token")
+		assert.NoError(t, err)
+		assert.True(t, IsEncryptedSecret(value))
+		assert.NotContains(t, value, "ghp_token")
+
+		plaintext, err := keyring.Decrypt(value)
+		assert.NoError(t, err)
+		assert.Equal(t, "ghp_token", plaintext)
+	})
+
+	t.Run("should produce a different ciphertext on every call", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a'), ""))
+
+		first, _ := keyring.Encrypt("ghp_token")
+		second, _ := keyring.Encrypt("ghp_token")
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("should pass legacy plaintext through decrypt", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a'), ""))
+
+		plaintext, err := keyring.Decrypt("legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", plaintext)
+		assert.True(t, keyring.NeedsReencryption("legacy"))
+	})
+
+	t.Run("should decrypt with a rotated key and flag the value for re-encryption", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a'), ""))
+		oldValue, err := keyring.Encrypt("ghp_token")
+		assert.NoError(t, err)
+
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a')+",k2:"+testSecretsKey('b'), "k2"))
+		assert.True(t, keyring.NeedsReencryption(oldValue))
+
+		plaintext, err := keyring.Decrypt(oldValue)
+		assert.NoError(t, err)
+		assert.Equal(t, "ghp_token", plaintext)
+
+		newValue, err := keyring.Encrypt(plaintext)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(newValue, encryptedPrefix+"k2:"))
+		assert.False(t, keyring.NeedsReencryption(newValue))
+	})
+
+	t.Run("should fail when the key is unknown", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a'), ""))
+		value, _ := keyring.Encrypt("ghp_token")
+
+		assert.NoError(t, keyring.Load("k2:"+testSecretsKey('b'), ""))
+		_, err := keyring.Decrypt(value)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail on tampered ciphertext", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.NoError(t, keyring.Load("k1:"+testSecretsKey('a'), ""))
+		value, _ := keyring.Encrypt("ghp_token")
+
+		parts := strings.Split(value, ":")
+		ciphertext, _ := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+		ciphertext[len(ciphertext)-1] ^= 0xff
+		parts[len(parts)-1] = base64.StdEncoding.EncodeToString(ciphertext)
+
+		_, err := keyring.Decrypt(strings.Join(parts, ":"))
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject invalid key configuration", func(t *testing.T) {
+		keyring := &SecretsKeyring{}
+		assert.Error(t, keyring.Load("k1", ""))
+		assert.Error(t, keyring.Load("k1:"+base64.StdEncoding.EncodeToString([]byte("short")), ""))
+		assert.Error(t, keyring.Load("k1:"+testSecretsKey('a'), "missing"))
+	})
+}
+
+func TestCodeSpaceMapRedacted(t *testing.T) {
+	codeSpace := CodeSpaceMap{
+		WorkspaceID: "workspace",
+		GithubPat:   "ghp_token",
+	}
+
+	redacted := codeSpace.Redacted()
+	assert.Equal(t, EncryptedString(""), redacted.GithubPat)
+	assert.Equal(t, EncryptedString(""), redacted.PoolAPIKey)
+	assert.True(t, redacted.HasGithubPat)
+	assert.False(t, redacted.HasPoolAPIKey)
+	assert.Equal(t, "workspace", redacted.WorkspaceID)
+}
+
+func TestWorkspaceCodeGraphRedacted(t *testing.T) {
+	codeGraph := WorkspaceCodeGraph{Name: "graph", SecretAlias: "{{secret}}"}
+
+	redacted := codeGraph.Redacted()
+	assert.Equal(t, EncryptedString(""), redacted.SecretAlias)
+	assert.True(t, redacted.HasSecretAlias)
+	assert.Equal(t, "graph", redacted.Name)
+	assert.False(t, WorkspaceCodeGraph{}.Redacted().HasSecretAlias)
+}
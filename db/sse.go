@@ -189,3 +189,25 @@ func (db database) DeleteOldSSEMessageLogs(maxAge time.Duration) (int64, error)
 
 	return result.RowsAffected, nil
 }
+
+// GetSSEMessageLogsAfter returns the logs of a chat in the order they were
+// stored, starting after the given log or from the beginning when it is nil
+func (db database) GetSSEMessageLogsAfter(chatID string, after *SSEMessageLog, limit int) ([]SSEMessageLog, error) {
+	if chatID == "" {
+		return nil, errors.New("chat ID is required")
+	}
+
+	query := db.db.Where("chat_id = ?", chatID)
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var messageLogs []SSEMessageLog
+	if err := query.Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&messageLogs).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve SSE message logs for chat %s: %w", chatID, err)
+	}
+
+	return messageLogs, nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// SaveSSEClient stores a subscription as active, starting it again for a chat
+// and URL replaces the previous record
+func (db database) SaveSSEClient(chatID, url, webhookURL, lastEventID string) (*SSEClient, error) {
+	if chatID == "" || url == "" {
+		return nil, errors.New("chat ID and SSE URL are required")
+	}
+
+	now := time.Now()
+	client := &SSEClient{
+		ID:          uuid.New(),
+		ChatID:      chatID,
+		URL:         url,
+		WebhookURL:  webhookURL,
+		LastEventID: lastEventID,
+		Status:      SSEClientActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := db.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "url"}},
+		DoUpdates: clause.AssignmentColumns([]string{"webhook_url", "last_event_id", "status", "last_error", "updated_at"}),
+	}).Create(client).Error; err != nil {
+		return nil, fmt.Errorf("failed to save SSE client: %w", err)
+	}
+	return client, nil
+}
+
+func (db database) UpdateSSEClientLastEventID(chatID, url, lastEventID string) error {
+	if err := db.db.Model(&SSEClient{}).
+		Where("chat_id = ? AND url = ?", chatID, url).
+		Updates(map[string]interface{}{
+			"last_event_id": lastEventID,
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update SSE client last event ID: %w", err)
+	}
+	return nil
+}
+
+func (db database) UpdateSSEClientStatus(chatID, url string, status SSEClientStatus, lastError string) error {
+	if err := db.db.Model(&SSEClient{}).
+		Where("chat_id = ? AND url = ?", chatID, url).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": lastError,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update SSE client status: %w", err)
+	}
+	return nil
+}
+
+// GetSSEClients lists the stored subscriptions, newest first, all of them
+// when status is empty
+func (db database) GetSSEClients(status SSEClientStatus) ([]SSEClient, error) {
+	query := db.db.Model(&SSEClient{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var clients []SSEClient
+	if err := query.Order("updated_at DESC").Find(&clients).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve SSE clients: %w", err)
+	}
+	return clients, nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEClients(t *testing.T) {
+	InitTestDB()
+	TestDB.db.Exec("DELETE FROM sse_clients")
+	defer TestDB.db.Exec("DELETE FROM sse_clients")
+
+	_, err := TestDB.SaveSSEClient("", "https://source.com/sse", "https://target.com/webhook", "")
+	assert.Error(t, err)
+
+	_, err = TestDB.SaveSSEClient("chat1", "https://source.com/sse", "https://target.com/webhook", "")
+	require.NoError(t, err)
+	require.NoError(t, TestDB.UpdateSSEClientLastEventID("chat1", "https://source.com/sse", "42"))
+	require.NoError(t, TestDB.UpdateSSEClientStatus("chat1", "https://source.com/sse", SSEClientFailed, "unreachable"))
+
+	clients, err := TestDB.GetSSEClients(SSEClientFailed)
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	assert.Equal(t, "42", clients[0].LastEventID)
+	assert.Equal(t, "unreachable", clients[0].LastError)
+
+	// starting the same subscription again makes it active and keeps one record
+	_, err = TestDB.SaveSSEClient("chat1", "https://source.com/sse", "https://target.com/webhook", "42")
+	require.NoError(t, err)
+	clients, err = TestDB.GetSSEClients("")
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	assert.Equal(t, SSEClientActive, clients[0].Status)
+	assert.Empty(t, clients[0].LastError)
+
+	active, err := TestDB.GetSSEClients(SSEClientActive)
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+}
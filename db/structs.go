@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/utils"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
@@ -468,6 +469,8 @@ type NewBounty struct {
 	MaxStakers              int                    `gorm:"default:1" json:"max_stakers"`
 	CurrentStakers          int                    `gorm:"default:0" json:"current_stakers"`
 	Stakes                  []BountyStake          `gorm:"foreignKey:BountyID" json:"stakes,omitempty"`
+	// TicketGroup is the ticket the bounty was created from
+	TicketGroup *uuid.UUID `gorm:"type:uuid;index;default:null" json:"ticket_group,omitempty"`
 }
 
 type BountyOwners struct {
@@ -569,6 +572,95 @@ type Workspace struct {
 	Tactics      string     `json:"tactics"`
 	SchematicUrl string     `json:"schematic_url"`
 	SchematicImg string     `json:"schematic_img"`
+	UpdatedBy    string     `json:"updated_by"`
+	// CoOwners have the same rights as the owner, except for transferring
+	// ownership and managing the co-owners
+	CoOwners pq.StringArray `gorm:"type:text[]" json:"co_owners"`
+}
+
+// IsOwner reports whether the pubkey is the workspace owner or one of its co-owners.
+// Every owner check goes through it, except ownership transfers and adding or
+// removing co-owners, which compare against OwnerPubKey.
+func (w Workspace) IsOwner(pubkey string) bool {
+	if pubkey == "" {
+		return false
+	}
+	if w.OwnerPubKey == pubkey {
+		return true
+	}
+	for _, coOwner := range w.CoOwners {
+		if coOwner == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+type OwnershipTransferStatus string
+
+const (
+	OwnershipTransferPending   OwnershipTransferStatus = "PENDING"
+	OwnershipTransferAccepted  OwnershipTransferStatus = "ACCEPTED"
+	OwnershipTransferRejected  OwnershipTransferStatus = "REJECTED"
+	OwnershipTransferCancelled OwnershipTransferStatus = "CANCELLED"
+)
+
+type WorkspaceOwnershipTransfer struct {
+	ID            uuid.UUID               `json:"id" gorm:"primaryKey;type:uuid"`
+	WorkspaceUuid string                  `json:"workspace_uuid" gorm:"index;not null"`
+	FromPubkey    string                  `json:"from_pubkey" gorm:"not null"`
+	ToPubkey      string                  `json:"to_pubkey" gorm:"index;not null"`
+	KeepAsCoOwner bool                    `json:"keep_as_co_owner"`
+	Status        OwnershipTransferStatus `json:"status" gorm:"type:varchar(20);default:'PENDING'"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+	ResolvedAt    *time.Time              `json:"resolved_at"`
+}
+
+type OwnershipAuditAction string
+
+const (
+	AuditTransferInitiated OwnershipAuditAction = "TRANSFER_INITIATED"
+	AuditTransferAccepted  OwnershipAuditAction = "TRANSFER_ACCEPTED"
+	AuditTransferRejected  OwnershipAuditAction = "TRANSFER_REJECTED"
+	AuditTransferCancelled OwnershipAuditAction = "TRANSFER_CANCELLED"
+	AuditCoOwnerAdded      OwnershipAuditAction = "CO_OWNER_ADDED"
+	AuditCoOwnerRemoved    OwnershipAuditAction = "CO_OWNER_REMOVED"
+)
+
+// WorkspaceOwnershipAudit records every change to who owns a workspace
+type WorkspaceOwnershipAudit struct {
+	ID            uuid.UUID            `json:"id" gorm:"primaryKey;type:uuid"`
+	WorkspaceUuid string               `json:"workspace_uuid" gorm:"index;not null"`
+	Action        OwnershipAuditAction `json:"action" gorm:"type:varchar(30);not null"`
+	ActorPubkey   string               `json:"actor_pubkey" gorm:"not null"`
+	TargetPubkey  string               `json:"target_pubkey"`
+	TransferID    *uuid.UUID           `json:"transfer_id,omitempty" gorm:"type:uuid"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+type TrashEntityType string
+
+const (
+	TrashWorkspace TrashEntityType = "workspace"
+	TrashFeature   TrashEntityType = "feature"
+	TrashPhase     TrashEntityType = "phase"
+	TrashTicket    TrashEntityType = "ticket"
+	TrashBounty    TrashEntityType = "bounty"
+)
+
+// TrashItem keeps a snapshot of a deleted record, and the records removed
+// with it, until it is restored or purged after the retention window
+type TrashItem struct {
+	ID            uuid.UUID       `json:"id" gorm:"primaryKey;type:uuid"`
+	WorkspaceUuid string          `json:"workspace_uuid" gorm:"index"`
+	EntityType    TrashEntityType `json:"entity_type" gorm:"type:varchar(20);not null"`
+	EntityID      string          `json:"entity_id" gorm:"index;not null"`
+	Name          string          `json:"name"`
+	Snapshot      PropertyMap     `json:"-" gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	DeletedBy     string          `json:"deleted_by"`
+	TrashedAt     time.Time       `json:"trashed_at"`
+	PurgeAfter    time.Time       `json:"purge_after" gorm:"index"`
 }
 
 type WorkspaceShort struct {
@@ -614,16 +706,25 @@ type WorkspaceRepositories struct {
 }
 
 type WorkspaceCodeGraph struct {
-	ID            uint       `json:"id"`
-	Uuid          string     `gorm:"not null" json:"uuid"`
-	WorkspaceUuid string     `gorm:"not null" json:"workspace_uuid"`
-	Name          string     `gorm:"not null" json:"name"`
-	Url           string     `json:"url"`
-	SecretAlias   string     `json:"secret_alias"`
-	Created       *time.Time `json:"created"`
-	Updated       *time.Time `json:"updated"`
-	CreatedBy     string     `json:"created_by"`
-	UpdatedBy     string     `json:"updated_by"`
+	ID            uint   `json:"id"`
+	Uuid          string `gorm:"not null" json:"uuid"`
+	WorkspaceUuid string `gorm:"not null" json:"workspace_uuid"`
+	Name          string `gorm:"not null" json:"name"`
+	Url           string `json:"url"`
+	// SecretAlias is encrypted at rest and never returned by the API
+	SecretAlias    EncryptedString `json:"secret_alias,omitempty"`
+	HasSecretAlias bool            `json:"has_secret_alias" gorm:"-"`
+	Created        *time.Time      `json:"created"`
+	Updated        *time.Time      `json:"updated"`
+	CreatedBy      string          `json:"created_by"`
+	UpdatedBy      string          `json:"updated_by"`
+}
+
+// Redacted strips the secret alias from a code graph, keeping only whether it is set
+func (c WorkspaceCodeGraph) Redacted() WorkspaceCodeGraph {
+	c.HasSecretAlias = c.SecretAlias != ""
+	c.SecretAlias = ""
+	return c
 }
 
 type FeatureStatus string
@@ -653,6 +754,8 @@ type WorkspaceFeatures struct {
 	BountiesCountAssigned  int           `gorm:"-" json:"bounties_count_assigned"`
 	BountiesCountOpen      int           `gorm:"-" json:"bounties_count_open"`
 	FeatStatus             FeatureStatus `gorm:"type:varchar(20);default:'active';not null" json:"feat_status"`
+	StartDate              *time.Time    `json:"start_date"`
+	TargetDate             *time.Time    `json:"target_date"`
 }
 
 type FeaturePhase struct {
@@ -664,6 +767,8 @@ type FeaturePhase struct {
 	PhaseOutcome string     `json:"phase_outcome,omitempty" gorm:"default:null"`
 	PhaseScope   string     `json:"phase_scope,omitempty" gorm:"default:null"`
 	PhaseDesign  string     `json:"phase_design,omitempty" gorm:"default:null"`
+	StartDate    *time.Time `json:"start_date"`
+	TargetDate   *time.Time `json:"target_date"`
 	Created      *time.Time `json:"created"`
 	Updated      *time.Time `json:"updated"`
 	CreatedBy    string     `json:"created_by"`
@@ -783,6 +888,33 @@ type FeatureStory struct {
 	Updated     *time.Time `json:"updated"`
 	CreatedBy   string     `json:"created_by"`
 	UpdatedBy   string     `json:"updated_by"`
+
+	AcceptanceCriteria []AcceptanceCriterion `gorm:"-" json:"acceptance_criteria"`
+}
+
+// AcceptanceCriterion is one checkable condition of a feature story
+type AcceptanceCriterion struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Uuid        string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"uuid"`
+	StoryUuid   string     `gorm:"type:varchar(255);index;not null" json:"story_uuid"`
+	FeatureUuid string     `gorm:"type:varchar(255);index;not null" json:"feature_uuid"`
+	Description string     `gorm:"type:text;not null" json:"description"`
+	Position    int        `gorm:"not null;default:0" json:"position"`
+	Created     *time.Time `json:"created"`
+	Updated     *time.Time `json:"updated"`
+}
+
+// AcceptanceCriterionLink records that a ticket group or a bounty satisfies an
+// acceptance criterion. Tickets are keyed by their group so the link follows
+// every version.
+type AcceptanceCriterionLink struct {
+	ID            uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CriterionUuid string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_criterion_link" json:"criterion_uuid"`
+	FeatureUuid   string        `gorm:"type:varchar(255);index;not null" json:"feature_uuid"`
+	ItemType      BoardItemType `gorm:"type:varchar(20);not null;uniqueIndex:idx_criterion_link" json:"item_type"`
+	ItemKey       string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_criterion_link" json:"item_key"`
+	CreatedBy     string        `json:"created_by"`
+	Created       time.Time     `json:"created"`
 }
 
 type BudgetHistoryData struct {
@@ -1015,6 +1147,83 @@ type BountyCard struct {
 	Status       BountyStatus      `json:"status"`
 }
 
+type BoardItemType string
+
+const (
+	BoardTicket BoardItemType = "ticket"
+	BoardBounty BoardItemType = "bounty"
+)
+
+// BoardPosition keeps the order of a card inside its board column
+type BoardPosition struct {
+	ID            uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkspaceUuid string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_board_item" json:"workspace_uuid"`
+	ItemType      BoardItemType `gorm:"type:varchar(20);not null;uniqueIndex:idx_board_item" json:"item_type"`
+	ItemKey       string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_board_item" json:"item_key"`
+	Position      int           `gorm:"not null;default:0" json:"position"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// BoardColumnConfig holds the work in progress limit of a board column, zero
+// means unlimited
+type BoardColumnConfig struct {
+	ID            uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkspaceUuid string       `gorm:"type:varchar(255);not null;uniqueIndex:idx_board_column" json:"workspace_uuid"`
+	Status        TicketStatus `gorm:"type:varchar(50);not null;uniqueIndex:idx_board_column" json:"status"`
+	WipLimit      int          `gorm:"not null;default:0" json:"wip_limit"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+type BoardCard struct {
+	ItemType    BoardItemType `json:"item_type"`
+	ItemKey     string        `json:"item_key"`
+	TicketUUID  *uuid.UUID    `json:"ticket_uuid,omitempty"`
+	TicketGroup *uuid.UUID    `json:"ticket_group,omitempty"`
+	BountyID    uint          `json:"bounty_id,omitempty"`
+	Title       string        `json:"title"`
+	Status      TicketStatus  `json:"status"`
+	Position    int           `json:"position"`
+	FeatureUUID string        `json:"feature_uuid"`
+	PhaseUUID   string        `json:"phase_uuid"`
+	Assignee    string        `json:"assignee,omitempty"`
+	Category    string        `json:"category,omitempty"`
+	Amount      int64         `json:"amount,omitempty"`
+}
+
+type BoardColumn struct {
+	Status    TicketStatus `json:"status"`
+	WipLimit  int          `json:"wip_limit"`
+	Count     int          `json:"count"`
+	OverLimit bool         `json:"over_limit"`
+	Cards     []BoardCard  `json:"cards"`
+}
+
+type Board struct {
+	WorkspaceUuid string        `json:"workspace_uuid"`
+	Columns       []BoardColumn `json:"columns"`
+}
+
+type BoardFilter struct {
+	FeatureUUID string `json:"feature_uuid"`
+	PhaseUUID   string `json:"phase_uuid"`
+	Assignee    string `json:"assignee"`
+	Category    string `json:"category"`
+}
+
+type BoardMove struct {
+	ItemType BoardItemType `json:"item_type"`
+	ItemKey  string        `json:"item_key"`
+	ToStatus TicketStatus  `json:"to_status"`
+	Position int           `json:"position"`
+}
+
+type BoardMoveResult struct {
+	Card       BoardCard           `json:"card"`
+	FromStatus TicketStatus        `json:"from_status"`
+	Rule       *TicketWorkflowRule `json:"-"`
+	Ticket     *Tickets            `json:"-"`
+}
+
 type WfRequestStatus string
 
 const (
@@ -1088,6 +1297,7 @@ type Tickets struct {
 	Name          string            `gorm:"type:varchar(255)" json:"name"`
 	Sequence      int               `gorm:"type:integer;index:composite_index;default:0" json:"sequence"`
 	Dependency    []int             `gorm:"type:integer[]" json:"dependency"`
+	DependsOn     pq.StringArray    `gorm:"type:uuid[];default:'{}'" json:"depends_on"`
 	Description   string            `gorm:"type:text" json:"description"`
 	Status        TicketStatus      `gorm:"type:varchar(50);default:'DRAFT'" json:"status"`
 	Version       int               `gorm:"type:integer;default:0" json:"version"`
@@ -1100,6 +1310,54 @@ type Tickets struct {
 	UpdatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
 }
 
+type TicketWorkflowAction string
+
+const (
+	TicketActionCreateBounty TicketWorkflowAction = "CREATE_BOUNTY"
+)
+
+// TicketWorkflowRule allows tickets of a workspace to move from one status to
+// another. Workspaces without rules accept any transition.
+type TicketWorkflowRule struct {
+	ID             uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkspaceUuid  string               `gorm:"type:varchar(255);not null;uniqueIndex:idx_workflow_transition" json:"workspace_uuid"`
+	FromStatus     TicketStatus         `gorm:"type:varchar(50);not null;uniqueIndex:idx_workflow_transition" json:"from_status"`
+	ToStatus       TicketStatus         `gorm:"type:varchar(50);not null;uniqueIndex:idx_workflow_transition" json:"to_status"`
+	RequiredRole   string               `gorm:"type:varchar(100)" json:"required_role,omitempty"`
+	RequiredFields pq.StringArray       `gorm:"type:text[]" json:"required_fields"`
+	Action         TicketWorkflowAction `gorm:"type:varchar(50)" json:"action,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// TicketVersion keeps the content of every version of a ticket group, so
+// edits made in place or by the AI review flow never lose earlier text
+type TicketVersion struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	TicketGroup uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_ticket_group_version" json:"ticket_group"`
+	TicketUUID  uuid.UUID    `gorm:"type:uuid;not null" json:"ticket_uuid"`
+	Version     int          `gorm:"not null;uniqueIndex:idx_ticket_group_version" json:"version"`
+	Name        string       `gorm:"type:varchar(255)" json:"name"`
+	Description string       `gorm:"type:text" json:"description"`
+	Status      TicketStatus `gorm:"type:varchar(50)" json:"status"`
+	Sequence    int          `json:"sequence"`
+	Amount      *int64       `gorm:"type:bigint" json:"amount,omitempty"`
+	Category    *Category    `gorm:"type:varchar(50)" json:"category,omitempty"`
+	Author      *Author      `gorm:"type:varchar(50)" json:"author,omitempty"`
+	AuthorID    *string      `gorm:"type:varchar(255)" json:"author_id,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type TicketVersionDiff struct {
+	TicketGroup     uuid.UUID        `json:"ticket_group"`
+	From            TicketVersion    `json:"from"`
+	To              TicketVersion    `json:"to"`
+	NameChanged     bool             `json:"name_changed"`
+	StatusChanged   bool             `json:"status_changed"`
+	DescriptionDiff []utils.DiffLine `json:"description_diff"`
+	Unified         string           `json:"unified"`
+}
+
 type TicketArrayItem struct {
 	TicketName        string `json:"ticket_name"`
 	TicketDescription string `json:"ticket_description"`
@@ -1137,6 +1395,12 @@ const (
 	ProductBriefContext ContextTagType = "productBrief"
 	FeatureBriefContext ContextTagType = "featureBrief"
 	SchematicContext    ContextTagType = "schematic"
+	TicketContext       ContextTagType = "ticket"
+	PhaseContext        ContextTagType = "phase"
+	StoryContext        ContextTagType = "story"
+	SnippetContext      ContextTagType = "snippet"
+	FileContext         ContextTagType = "file"
+	RepositoryContext   ContextTagType = "repository"
 )
 
 type ContextTag struct {
@@ -1173,9 +1437,14 @@ type ChatMessage struct {
 	PDFURL      string            `json:"pdf_url,omitempty"`
 	Role        ChatRole          `json:"role"`
 	Timestamp   time.Time         `json:"timestamp"`
-	ContextTags []ContextTag      `json:"contextTags" gorm:"type:jsonb"`
+	ContextTags []ContextTag      `json:"contextTags" gorm:"type:jsonb;serializer:json"`
 	Status      ChatMessageStatus `json:"status"`
 	Source      ChatSource        `json:"source"`
+	// BranchID is empty for messages of the main branch
+	BranchID     string `json:"branchId,omitempty" gorm:"index;default:''"`
+	EditedFromID string `json:"editedFromId,omitempty"`
+	// Pinned messages are always sent as context
+	Pinned bool `json:"pinned" gorm:"default:false"`
 }
 
 type ChatStatus string
@@ -1186,12 +1455,46 @@ const (
 )
 
 type Chat struct {
-	ID          string     `json:"id" gorm:"primaryKey"`
-	WorkspaceID string     `json:"workspaceId" gorm:"index"`
-	Title       string     `json:"title"`
-	Status      ChatStatus `json:"status" gorm:"default:active"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID             string     `json:"id" gorm:"primaryKey"`
+	WorkspaceID    string     `json:"workspaceId" gorm:"index"`
+	Title          string     `json:"title"`
+	Status         ChatStatus `json:"status" gorm:"default:active"`
+	ActiveBranchID string     `json:"activeBranchId"`
+	// Summary is written by the chat workflow for the messages of
+	// SummaryBranchID up to and including SummaryThroughID that fell out of
+	// the context window
+	Summary          string    `json:"summary" gorm:"type:text"`
+	SummaryBranchID  string    `json:"summaryBranchId"`
+	SummaryThroughID string    `json:"summaryThroughId"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// ChatBranch is a fork of a chat made by editing a user message. It shares
+// the history of its parent branch up to ForkMessageID and continues with its
+// own messages.
+type ChatBranch struct {
+	ID              string    `json:"id" gorm:"primaryKey"`
+	ChatID          string    `json:"chatId" gorm:"index;not null"`
+	ParentBranchID  string    `json:"parentBranchId"`
+	ForkMessageID   string    `json:"forkMessageId"`
+	EditedMessageID string    `json:"editedMessageId"`
+	CreatedBy       string    `json:"createdBy"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// ChatShareLink gives read only access to one branch of a chat until it
+// expires or is revoked. Workspace only links still need a workspace user.
+type ChatShareLink struct {
+	ID            string     `json:"id" gorm:"primaryKey"`
+	Token         string     `json:"token" gorm:"uniqueIndex;not null"`
+	ChatID        string     `json:"chatId" gorm:"index;not null"`
+	BranchID      string     `json:"branchId"`
+	WorkspaceOnly bool       `json:"workspaceOnly"`
+	CreatedBy     string     `json:"createdBy"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	RevokedAt     *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
 }
 
 type ChatWorkflowStatus struct {
@@ -1314,12 +1617,21 @@ type FileAsset struct {
 	Status         FileStatus `json:"status" gorm:"type:varchar(20);default:'active'"`
 	UploadedBy     string     `json:"uploadedBy"`
 	StoragePath    string     `json:"storagePath"`
+	StorageBackend string     `json:"storageBackend"`
+	StorageKey     string     `json:"storageKey"`
 	WorkspaceID    string     `json:"workspaceId" gorm:"index"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	UpdatedAt      time.Time  `json:"updatedAt"`
+	ArchivedAt     *time.Time `json:"archivedAt,omitempty" gorm:"index"`
 	DeletedAt      *time.Time `json:"deletedAt,omitempty" gorm:"index"`
 }
 
+type FileLifecycleResult struct {
+	Archived    int64 `json:"archived"`
+	Reactivated int64 `json:"reactivated"`
+	Deleted     int64 `json:"deleted"`
+}
+
 type ListFileAssetsParams struct {
 	Status             *FileStatus `form:"status"`
 	MimeType           *string     `form:"mimeType"`
@@ -1334,8 +1646,10 @@ type ListFileAssetsParams struct {
 type PlanStatus string
 
 const (
-	DraftPlan    PlanStatus = "DRAFT"
-	ApprovedPlan PlanStatus = "APPROVED"
+	DraftPlan         PlanStatus = "DRAFT"
+	PendingReviewPlan PlanStatus = "PENDING_REVIEW"
+	ApprovedPlan      PlanStatus = "APPROVED"
+	RejectedPlan      PlanStatus = "REJECTED"
 )
 
 type TicketPlan struct {
@@ -1354,6 +1668,71 @@ type TicketPlan struct {
 	UpdatedBy     string            `gorm:"type:varchar(255)" json:"updated_by"`
 	CreatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"created_at"`
 	UpdatedAt     time.Time         `gorm:"type:timestamp;default:current_timestamp" json:"updated_at"`
+
+	Reviewers         pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"reviewers"`
+	RequiredApprovals int            `gorm:"type:integer;not null;default:1" json:"required_approvals"`
+	ApprovedVersion   int            `gorm:"type:integer;default:0" json:"approved_version"`
+	ApprovedAt        *time.Time     `json:"approved_at,omitempty"`
+}
+
+// TicketPlanRevision is a snapshot of a plan version together with the ticket
+// version every group was at when the revision was saved
+type TicketPlanRevision struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	PlanUUID       uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_plan_revision" json:"plan_uuid"`
+	Version        int            `gorm:"not null;uniqueIndex:idx_plan_revision" json:"version"`
+	Name           string         `gorm:"type:varchar(255)" json:"name"`
+	Description    string         `gorm:"type:text" json:"description"`
+	TicketGroups   pq.StringArray `gorm:"type:uuid[];not null;default:'{}'" json:"ticket_groups"`
+	TicketVersions pq.Int64Array  `gorm:"type:integer[];not null;default:'{}'" json:"ticket_versions"`
+	CreatedBy      string         `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type PlanDecision string
+
+const (
+	PlanApprove PlanDecision = "APPROVE"
+	PlanReject  PlanDecision = "REJECT"
+)
+
+// TicketPlanApproval is the decision of a reviewer on one version of a plan
+type TicketPlanApproval struct {
+	ID        uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	PlanUUID  uuid.UUID    `gorm:"type:uuid;not null;index" json:"plan_uuid"`
+	Version   int          `gorm:"not null" json:"version"`
+	Reviewer  string       `gorm:"type:varchar(255);not null" json:"reviewer"`
+	Decision  PlanDecision `gorm:"type:varchar(20);not null" json:"decision"`
+	Comment   string       `gorm:"type:text" json:"comment"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type TextRevisionEntity string
+
+const (
+	TextRevisionFeature   TextRevisionEntity = "feature"
+	TextRevisionWorkspace TextRevisionEntity = "workspace"
+)
+
+// TextRevision is one saved state of a long text field of a feature (brief,
+// requirements, architecture) or a workspace (mission, tactics)
+type TextRevision struct {
+	ID           uint               `gorm:"primaryKey;autoIncrement" json:"id"`
+	EntityType   TextRevisionEntity `gorm:"type:varchar(20);not null;uniqueIndex:idx_text_revision" json:"entity_type"`
+	EntityUuid   string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_text_revision" json:"entity_uuid"`
+	Field        string             `gorm:"type:varchar(50);not null;uniqueIndex:idx_text_revision" json:"field"`
+	Version      int                `gorm:"not null;uniqueIndex:idx_text_revision" json:"version"`
+	Content      string             `gorm:"type:text" json:"content"`
+	ChangedBy    string             `gorm:"type:varchar(255)" json:"changed_by"`
+	RestoredFrom *int               `json:"restored_from,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type TextRevisionDiff struct {
+	From    TextRevision     `json:"from"`
+	To      TextRevision     `json:"to"`
+	Diff    []utils.DiffLine `json:"diff"`
+	Unified string           `json:"unified"`
 }
 
 type StubTicket struct {
@@ -1476,6 +1855,7 @@ type Artifact struct {
 	MessageID string       `json:"message_id" gorm:"index"`
 	Type      ArtifactType `json:"type" gorm:"type:varchar(20);not null"`
 	Content   PropertyMap  `json:"content" gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	Pinned    bool         `json:"pinned" gorm:"default:false"`
 	CreatedAt time.Time    `json:"created_at" gorm:"type:timestamp;default:current_timestamp"`
 	UpdatedAt time.Time    `json:"updated_at" gorm:"type:timestamp;default:current_timestamp"`
 }
@@ -1518,10 +1898,14 @@ type FeatureCall struct {
 }
 
 type ChatWorkflow struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	WorkspaceID string    `json:"workspaceId" gorm:"index;not null"`
-	URL         string    `json:"url" gorm:"type:text;not null"`
-	StackworkID string    `json:"stackworkId" gorm:"column:stackwork_id"`
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	WorkspaceID string `json:"workspaceId" gorm:"index;not null"`
+	URL         string `json:"url" gorm:"type:text;not null"`
+	StackworkID string `json:"stackworkId" gorm:"column:stackwork_id"`
+	// HistorySize is how many recent messages are sent, 0 uses the default
+	HistorySize int `json:"historySize"`
+	// TokenBudget caps the estimated tokens of the history, 0 is no cap
+	TokenBudget int       `json:"tokenBudget"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
@@ -1593,6 +1977,67 @@ type SSEMessageLog struct {
 	Status    SSEMessageStatus `gorm:"type:varchar(10);default:'new'" json:"status"`
 }
 
+type SSEClientStatus string
+
+const (
+	SSEClientActive   SSEClientStatus = "active"
+	SSEClientStopped  SSEClientStatus = "stopped"
+	SSEClientFailed   SSEClientStatus = "failed"
+	SSEClientFinished SSEClientStatus = "finished"
+)
+
+// SSEClient is a remote SSE subscription, active ones are resumed on boot
+type SSEClient struct {
+	ID          uuid.UUID       `gorm:"primaryKey;type:uuid" json:"id"`
+	ChatID      string          `gorm:"uniqueIndex:idx_sse_client_chat_url;not null" json:"chat_id"`
+	URL         string          `gorm:"uniqueIndex:idx_sse_client_chat_url;not null" json:"url"`
+	WebhookURL  string          `gorm:"not null" json:"webhook_url"`
+	LastEventID string          `json:"last_event_id"`
+	Status      SSEClientStatus `gorm:"type:varchar(10);index;default:'active'" json:"status"`
+	LastError   string          `gorm:"type:text" json:"last_error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDead deliveries ran out of attempts and wait for a replay
+	WebhookDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is an outbound webhook call, Payload is the exact body that
+// is signed and sent on every attempt. AttemptBase is the number of attempts
+// made before the last replay, the retry budget counts from there.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"primaryKey;type:uuid" json:"id"`
+	Endpoint       string                `gorm:"index;not null" json:"endpoint"`
+	Event          string                `gorm:"not null" json:"event"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(10);index;default:'pending'" json:"status"`
+	Attempts       int                   `json:"attempts"`
+	AttemptBase    int                   `json:"attempt_base"`
+	LastStatusCode int                   `json:"last_status_code"`
+	LastError      string                `gorm:"type:text" json:"last_error"`
+	NextAttemptAt  *time.Time            `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+type WebhookAttempt struct {
+	ID         uuid.UUID `gorm:"primaryKey;type:uuid" json:"id"`
+	DeliveryID uuid.UUID `gorm:"type:uuid;index;not null" json:"delivery_id"`
+	Endpoint   string    `gorm:"index;not null" json:"endpoint"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `gorm:"type:text" json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type CodeSpaceMap struct {
 	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
 	CreatedAt    time.Time `json:"createdAt"`
@@ -1601,10 +2046,21 @@ type CodeSpaceMap struct {
 	CodeSpaceURL string    `json:"codeSpaceURL"`
 	UserPubkey   string    `json:"userPubkey" gorm:"index"`
 	Username     string    `json:"username,omitempty"`
-	// TODO: Encrypt this field
-	GithubPat    string    `json:"githubPat,omitempty" gorm:"column:github_pat"`
-	BaseBranch string `json:"baseBranch"`
-	PoolAPIKey string `json:"poolAPIKey,omitempty" gorm:"column:pool_api_key"`
+	// GithubPat and PoolAPIKey are encrypted at rest and never returned by the API
+	GithubPat     EncryptedString `json:"githubPat,omitempty" gorm:"column:github_pat"`
+	BaseBranch    string          `json:"baseBranch"`
+	PoolAPIKey    EncryptedString `json:"poolAPIKey,omitempty" gorm:"column:pool_api_key"`
+	HasGithubPat  bool            `json:"hasGithubPat" gorm:"-"`
+	HasPoolAPIKey bool            `json:"hasPoolAPIKey" gorm:"-"`
+}
+
+// Redacted strips the secrets from a codespace mapping, keeping only whether they are set
+func (c CodeSpaceMap) Redacted() CodeSpaceMap {
+	c.HasGithubPat = c.GithubPat != ""
+	c.HasPoolAPIKey = c.PoolAPIKey != ""
+	c.GithubPat = ""
+	c.PoolAPIKey = ""
+	return c
 }
 
 type StakeStatus string
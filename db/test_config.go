@@ -94,6 +94,24 @@ func InitTestDB() {
 	db.AutoMigrate(&BountyStake{})
 	db.AutoMigrate(&ChatWorkflowStatus{})
 	db.AutoMigrate(&BountyStakeProcess{})
+	db.AutoMigrate(&WorkspaceOwnershipTransfer{})
+	db.AutoMigrate(&WorkspaceOwnershipAudit{})
+	db.AutoMigrate(&TrashItem{})
+	db.AutoMigrate(&TicketVersion{})
+	db.AutoMigrate(&TicketWorkflowRule{})
+	db.AutoMigrate(&BoardPosition{})
+	db.AutoMigrate(&BoardColumnConfig{})
+	db.AutoMigrate(&AcceptanceCriterion{})
+	db.AutoMigrate(&AcceptanceCriterionLink{})
+	db.AutoMigrate(&TicketPlanRevision{})
+	db.AutoMigrate(&TicketPlanApproval{})
+	db.AutoMigrate(&TextRevision{})
+	db.AutoMigrate(&ChatBranch{})
+	db.AutoMigrate(&ChatShareLink{})
+	db.AutoMigrate(&SSEClient{})
+	db.AutoMigrate(&WebhookDelivery{})
+	db.AutoMigrate(&WebhookAttempt{})
+	TestDB.MigrateSearchColumns()
 	
 	people := TestDB.GetAllPeople()
 	for _, p := range people {
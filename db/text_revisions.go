@@ -0,0 +1,278 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTextRevisionNotFound = errors.New("text revision not found")
+	ErrTextRevisionField    = errors.New("field has no revision history")
+	ErrTextRevisionEntity   = errors.New("revised entity not found")
+)
+
+// textRevisionFields lists the tracked fields of each entity, the field name
+// is also its column
+var textRevisionFields = map[TextRevisionEntity][]string{
+	TextRevisionFeature:   {"brief", "requirements", "architecture"},
+	TextRevisionWorkspace: {"mission", "tactics"},
+}
+
+func IsTextRevisionField(entityType TextRevisionEntity, field string) bool {
+	for _, f := range textRevisionFields[entityType] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func featureRevisionText(feature WorkspaceFeatures) map[string]string {
+	return map[string]string{
+		"brief":        feature.Brief,
+		"requirements": feature.Requirements,
+		"architecture": feature.Architecture,
+	}
+}
+
+func workspaceRevisionText(workspace Workspace) map[string]string {
+	return map[string]string{
+		"mission": workspace.Mission,
+		"tactics": workspace.Tactics,
+	}
+}
+
+// PlanTextRevisions returns the revisions to record for the fields that
+// changed between before and after. latest holds the last recorded version of
+// each field, a field without history first gets its previous text recorded
+// so the overwritten content can be restored.
+func PlanTextRevisions(entityType TextRevisionEntity, entityUuid string, before, after map[string]string, latest map[string]int, changedBy string, since time.Time, now time.Time) []TextRevision {
+	revisions := []TextRevision{}
+	for _, field := range textRevisionFields[entityType] {
+		if before[field] == after[field] {
+			continue
+		}
+		version := latest[field]
+		if version == 0 && before[field] != "" {
+			version++
+			revisions = append(revisions, TextRevision{
+				EntityType: entityType,
+				EntityUuid: entityUuid,
+				Field:      field,
+				Version:    version,
+				Content:    before[field],
+				CreatedAt:  since,
+			})
+		}
+		version++
+		revisions = append(revisions, TextRevision{
+			EntityType: entityType,
+			EntityUuid: entityUuid,
+			Field:      field,
+			Version:    version,
+			Content:    after[field],
+			ChangedBy:  changedBy,
+			CreatedAt:  now,
+		})
+	}
+	return revisions
+}
+
+func latestTextRevisions(tx *gorm.DB, entityType TextRevisionEntity, entityUuid string) (map[string]int, error) {
+	var rows []struct {
+		Field   string
+		Version int
+	}
+	err := tx.Model(&TextRevision{}).
+		Select("field, MAX(version) AS version").
+		Where("entity_type = ? AND entity_uuid = ?", entityType, entityUuid).
+		Group("field").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]int{}
+	for _, row := range rows {
+		latest[row.Field] = row.Version
+	}
+	return latest, nil
+}
+
+func saveTextRevisions(tx *gorm.DB, entityType TextRevisionEntity, entityUuid string, before, after map[string]string, changedBy string, since *time.Time) ([]TextRevision, error) {
+	latest, err := latestTextRevisions(tx, entityType, entityUuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest revisions: %w", err)
+	}
+
+	now := time.Now()
+	baseline := now
+	if since != nil {
+		baseline = *since
+	}
+	revisions := PlanTextRevisions(entityType, entityUuid, before, after, latest, changedBy, baseline, now)
+	if len(revisions) == 0 {
+		return revisions, nil
+	}
+	if err := tx.Create(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to save revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// recordTextRevisions keeps the history of the long text fields after an
+// entity was saved. A failure is logged, it does not undo the save.
+func (db database) recordTextRevisions(entityType TextRevisionEntity, entityUuid string, before, after map[string]string, changedBy string, since *time.Time) {
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		_, err := saveTextRevisions(tx, entityType, entityUuid, before, after, changedBy, since)
+		return err
+	})
+	if err != nil {
+		logger.Log.Error("[revisions] failed to record %s %s revisions: %v", entityType, entityUuid, err)
+	}
+}
+
+// GetTextRevisions lists the revisions of an entity, newest first. An empty
+// field lists every tracked field.
+func (db database) GetTextRevisions(entityType TextRevisionEntity, entityUuid string, field string) ([]TextRevision, error) {
+	if field != "" && !IsTextRevisionField(entityType, field) {
+		return nil, fmt.Errorf("%w: %s %s", ErrTextRevisionField, entityType, field)
+	}
+
+	query := db.db.Where("entity_type = ? AND entity_uuid = ?", entityType, entityUuid)
+	if field != "" {
+		query = query.Where("field = ?", field)
+	}
+	revisions := []TextRevision{}
+	if err := query.Order("field ASC, version DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].CreatedAt.After(revisions[j].CreatedAt)
+	})
+	return revisions, nil
+}
+
+func (db database) GetTextRevision(entityType TextRevisionEntity, entityUuid string, field string, version int) (TextRevision, error) {
+	if !IsTextRevisionField(entityType, field) {
+		return TextRevision{}, fmt.Errorf("%w: %s %s", ErrTextRevisionField, entityType, field)
+	}
+
+	var revision TextRevision
+	result := db.db.Where("entity_type = ? AND entity_uuid = ? AND field = ? AND version = ?", entityType, entityUuid, field, version).
+		Limit(1).Find(&revision)
+	if result.Error != nil {
+		return revision, fmt.Errorf("failed to fetch revision: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return revision, fmt.Errorf("%w: %s %s version %d", ErrTextRevisionNotFound, entityType, field, version)
+	}
+	return revision, nil
+}
+
+func DiffTextRevisions(from TextRevision, to TextRevision) TextRevisionDiff {
+	diff := utils.LineDiff(from.Content, to.Content)
+	return TextRevisionDiff{
+		From:    from,
+		To:      to,
+		Diff:    diff,
+		Unified: utils.UnifiedDiff(diff),
+	}
+}
+
+// CompareTextRevisions diffs two revisions of a field, a zero to compares
+// with the latest revision
+func (db database) CompareTextRevisions(entityType TextRevisionEntity, entityUuid string, field string, from int, to int) (TextRevisionDiff, error) {
+	if to == 0 {
+		latest, err := latestTextRevisions(db.db, entityType, entityUuid)
+		if err != nil {
+			return TextRevisionDiff{}, fmt.Errorf("failed to fetch latest revisions: %w", err)
+		}
+		to = latest[field]
+	}
+
+	fromRevision, err := db.GetTextRevision(entityType, entityUuid, field, from)
+	if err != nil {
+		return TextRevisionDiff{}, err
+	}
+	toRevision, err := db.GetTextRevision(entityType, entityUuid, field, to)
+	if err != nil {
+		return TextRevisionDiff{}, err
+	}
+	return DiffTextRevisions(fromRevision, toRevision), nil
+}
+
+// RestoreTextRevision writes the content of an old revision back to its field
+// and records it as a new revision
+func (db database) RestoreTextRevision(entityType TextRevisionEntity, entityUuid string, field string, version int, pubkey string) (TextRevision, error) {
+	target, err := db.GetTextRevision(entityType, entityUuid, field, version)
+	if err != nil {
+		return TextRevision{}, err
+	}
+
+	var restored TextRevision
+	err = db.db.Transaction(func(tx *gorm.DB) error {
+		var before map[string]string
+		var since *time.Time
+		now := time.Now()
+		switch entityType {
+		case TextRevisionFeature:
+			var feature WorkspaceFeatures
+			if err := tx.Where("uuid = ?", entityUuid).Limit(1).Find(&feature).Error; err != nil {
+				return err
+			}
+			if feature.Uuid == "" {
+				return ErrTextRevisionEntity
+			}
+			before, since = featureRevisionText(feature), feature.Updated
+			if err := tx.Model(&WorkspaceFeatures{}).Where("uuid = ?", entityUuid).Updates(map[string]interface{}{
+				field:        target.Content,
+				"updated":    now,
+				"updated_by": pubkey,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to restore feature %s: %w", field, err)
+			}
+		case TextRevisionWorkspace:
+			var workspace Workspace
+			if err := tx.Where("uuid = ?", entityUuid).Limit(1).Find(&workspace).Error; err != nil {
+				return err
+			}
+			if workspace.Uuid == "" {
+				return ErrTextRevisionEntity
+			}
+			before, since = workspaceRevisionText(workspace), workspace.Updated
+			if err := tx.Model(&Workspace{}).Where("uuid = ?", entityUuid).Updates(map[string]interface{}{
+				field:        target.Content,
+				"updated":    now,
+				"updated_by": pubkey,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to restore workspace %s: %w", field, err)
+			}
+		}
+
+		after := map[string]string{}
+		for k, v := range before {
+			after[k] = v
+		}
+		after[field] = target.Content
+		revisions, err := saveTextRevisions(tx, entityType, entityUuid, before, after, pubkey, since)
+		if err != nil {
+			return err
+		}
+		if len(revisions) == 0 {
+			// the field already holds this content
+			restored = target
+			return nil
+		}
+
+		restored = revisions[len(revisions)-1]
+		restored.RestoredFrom = &target.Version
+		return tx.Model(&TextRevision{}).Where("id = ?", restored.ID).Update("restored_from", target.Version).Error
+	})
+	return restored, err
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanTextRevisions(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	t.Run("Should keep the overwritten text of a field without history", func(t *testing.T) {
+		before := featureRevisionText(WorkspaceFeatures{Brief: "hand written", Architecture: "same"})
+		after := featureRevisionText(WorkspaceFeatures{Brief: "generated", Architecture: "same"})
+
+		revisions := PlanTextRevisions(TextRevisionFeature, "feature", before, after, map[string]int{}, "editor", since, now)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, TextRevision{EntityType: TextRevisionFeature, EntityUuid: "feature", Field: "brief", Version: 1, Content: "hand written", CreatedAt: since}, revisions[0])
+		assert.Equal(t, 2, revisions[1].Version)
+		assert.Equal(t, "generated", revisions[1].Content)
+		assert.Equal(t, "editor", revisions[1].ChangedBy)
+	})
+
+	t.Run("Should continue the history of each field", func(t *testing.T) {
+		before := workspaceRevisionText(Workspace{Mission: "old", Tactics: ""})
+		after := workspaceRevisionText(Workspace{Mission: "new", Tactics: "first"})
+
+		revisions := PlanTextRevisions(TextRevisionWorkspace, "workspace", before, after, map[string]int{"mission": 4}, "owner", since, now)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, "mission", revisions[0].Field)
+		assert.Equal(t, 5, revisions[0].Version)
+		assert.Equal(t, "tactics", revisions[1].Field)
+		assert.Equal(t, 1, revisions[1].Version)
+		assert.Equal(t, "first", revisions[1].Content)
+	})
+
+	t.Run("Should record nothing when no text changed", func(t *testing.T) {
+		text := featureRevisionText(WorkspaceFeatures{Brief: "brief"})
+		assert.Empty(t, PlanTextRevisions(TextRevisionFeature, "feature", text, text, nil, "editor", since, now))
+	})
+}
+
+func TestDiffTextRevisions(t *testing.T) {
+	from := TextRevision{Field: "brief", Version: 1, Content: "keep\nremove"}
+	to := TextRevision{Field: "brief", Version: 2, Content: "keep\nadd"}
+
+	diff := DiffTextRevisions(from, to)
+	assert.Equal(t, []utils.DiffLine{
+		{Op: utils.DiffEqual, Text: "keep"},
+		{Op: utils.DiffDelete, Text: "remove"},
+		{Op: utils.DiffInsert, Text: "add"},
+	}, diff.Diff)
+	assert.Equal(t, "  keep\n- remove\n+ add\n", diff.Unified)
+
+	assert.True(t, IsTextRevisionField(TextRevisionWorkspace, "tactics"))
+	assert.False(t, IsTextRevisionField(TextRevisionWorkspace, "brief"))
+}
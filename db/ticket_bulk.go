@@ -0,0 +1,416 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxBulkTickets caps how many tickets one bulk request can touch
+const MaxBulkTickets = 100
+
+type BulkTicketAction string
+
+const (
+	BulkTicketUpdate BulkTicketAction = "update"
+	BulkTicketDelete BulkTicketAction = "delete"
+)
+
+var (
+	ErrBulkTicketRequest  = errors.New("invalid bulk ticket request")
+	ErrBulkTicketFailed   = errors.New("bulk ticket operation was rolled back")
+	ErrBulkTicketNoAccess = errors.New("no access to the workspace of the ticket")
+	ErrBulkTicketPhase    = errors.New("phase does not belong to the feature of the ticket's workspace")
+)
+
+// BulkTicketRequest applies the same change to a set of tickets. Fields left
+// out of an update are kept, a phase or feature change clears the dependencies
+// since they only hold inside one phase.
+type BulkTicketRequest struct {
+	TicketUUIDs     []string         `json:"ticket_uuids"`
+	Action          BulkTicketAction `json:"action"`
+	Status          *TicketStatus    `json:"status,omitempty"`
+	Category        *Category        `json:"category,omitempty"`
+	Amount          *int64           `json:"amount,omitempty"`
+	FeatureUUID     *string          `json:"feature_uuid,omitempty"`
+	PhaseUUID       *string          `json:"phase_uuid,omitempty"`
+	SourceWebsocket string           `json:"source_websocket,omitempty"`
+}
+
+type BulkTicketResult struct {
+	TicketUUID    string              `json:"ticket_uuid"`
+	TicketGroup   string              `json:"ticket_group,omitempty"`
+	WorkspaceUuid string              `json:"workspace_uuid,omitempty"`
+	Success       bool                `json:"success"`
+	Error         string              `json:"error,omitempty"`
+	FromStatus    TicketStatus        `json:"from_status,omitempty"`
+	Ticket        *Tickets            `json:"ticket,omitempty"`
+	Card          *BoardCard          `json:"-"`
+	Rule          *TicketWorkflowRule `json:"-"`
+	Err           error               `json:"-"`
+}
+
+// BulkTicketResponse holds one result per requested ticket. Applied is only
+// set when every ticket succeeded, otherwise nothing was changed.
+type BulkTicketResponse struct {
+	Action  BulkTicketAction   `json:"action"`
+	Applied bool               `json:"applied"`
+	Results []BulkTicketResult `json:"results"`
+}
+
+// ValidateBulkTicketRequest checks the shape of a bulk request before any
+// ticket is read
+func ValidateBulkTicketRequest(request BulkTicketRequest) error {
+	if len(request.TicketUUIDs) == 0 {
+		return fmt.Errorf("%w: no tickets given", ErrBulkTicketRequest)
+	}
+	if len(request.TicketUUIDs) > MaxBulkTickets {
+		return fmt.Errorf("%w: at most %d tickets can be changed at once", ErrBulkTicketRequest, MaxBulkTickets)
+	}
+	for _, id := range request.TicketUUIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: %q is not a ticket UUID", ErrBulkTicketRequest, id)
+		}
+	}
+
+	switch request.Action {
+	case BulkTicketDelete:
+		return nil
+	case BulkTicketUpdate:
+	default:
+		return fmt.Errorf("%w: action must be update or delete", ErrBulkTicketRequest)
+	}
+
+	if request.Status == nil && request.Category == nil && request.Amount == nil &&
+		request.FeatureUUID == nil && request.PhaseUUID == nil {
+		return fmt.Errorf("%w: nothing to update", ErrBulkTicketRequest)
+	}
+	if request.Status != nil && !IsValidTicketStatus(*request.Status) {
+		return fmt.Errorf("%w: invalid ticket status %q", ErrBulkTicketRequest, *request.Status)
+	}
+	if request.Amount != nil && *request.Amount < 0 {
+		return fmt.Errorf("%w: amount can not be negative", ErrBulkTicketRequest)
+	}
+	if request.FeatureUUID != nil && request.PhaseUUID == nil {
+		return fmt.Errorf("%w: a feature change needs the phase to move the tickets to", ErrBulkTicketRequest)
+	}
+	return nil
+}
+
+// applyBulkTicketUpdate returns the ticket with the fields of the request set
+func applyBulkTicketUpdate(ticket Tickets, request BulkTicketRequest) Tickets {
+	if request.Status != nil {
+		ticket.Status = *request.Status
+	}
+	if request.Category != nil {
+		category := *request.Category
+		ticket.Category = &category
+	}
+	if request.Amount != nil {
+		amount := *request.Amount
+		ticket.Amount = &amount
+	}
+	moved := false
+	if request.FeatureUUID != nil && *request.FeatureUUID != ticket.FeatureUUID {
+		ticket.FeatureUUID = *request.FeatureUUID
+		moved = true
+	}
+	if request.PhaseUUID != nil && *request.PhaseUUID != ticket.PhaseUUID {
+		ticket.PhaseUUID = *request.PhaseUUID
+		moved = true
+	}
+	if moved {
+		ticket.DependsOn = []string{}
+	}
+	return ticket
+}
+
+// CheckBulkWipLimits reports the columns a set of status changes would push
+// over their WIP limit. Columns already over their limit only fail when they
+// grow.
+func CheckBulkWipLimits(board Board, moves map[string]TicketStatus) map[TicketStatus]error {
+	current := map[string]TicketStatus{}
+	for _, column := range board.Columns {
+		for _, card := range column.Cards {
+			if card.ItemType == BoardTicket {
+				current[card.ItemKey] = card.Status
+			}
+		}
+	}
+
+	delta := map[TicketStatus]int{}
+	for key, to := range moves {
+		from, ok := current[key]
+		if ok && from == to {
+			continue
+		}
+		if ok {
+			delta[from]--
+		}
+		delta[to]++
+	}
+
+	failed := map[TicketStatus]error{}
+	for _, column := range board.Columns {
+		if column.WipLimit <= 0 || delta[column.Status] <= 0 {
+			continue
+		}
+		if column.Count+delta[column.Status] > column.WipLimit {
+			failed[column.Status] = fmt.Errorf("%w: %s allows %d cards", ErrBoardWipLimit, column.Status, column.WipLimit)
+		}
+	}
+	return failed
+}
+
+func (db database) bulkTicketWorkspace(tx *gorm.DB, ticket Tickets) string {
+	if ticket.WorkspaceUuid != "" || ticket.FeatureUUID == "" {
+		return ticket.WorkspaceUuid
+	}
+	var feature WorkspaceFeatures
+	tx.Where("uuid = ?", ticket.FeatureUUID).Limit(1).Find(&feature)
+	return feature.WorkspaceUuid
+}
+
+// checkBulkTicketPhase makes sure the phase a ticket is moved to belongs to a
+// feature of the ticket's workspace
+func checkBulkTicketPhase(tx *gorm.DB, ticket Tickets, workspaceUuid string) error {
+	var phase FeaturePhase
+	if err := tx.Where("uuid = ?", ticket.PhaseUUID).Limit(1).Find(&phase).Error; err != nil {
+		return fmt.Errorf("failed to fetch phase: %w", err)
+	}
+	if phase.Uuid == "" || phase.FeatureUuid != ticket.FeatureUUID {
+		return ErrBulkTicketPhase
+	}
+	var feature WorkspaceFeatures
+	if err := tx.Where("uuid = ?", ticket.FeatureUUID).Limit(1).Find(&feature).Error; err != nil {
+		return fmt.Errorf("failed to fetch feature: %w", err)
+	}
+	if feature.Uuid == "" || feature.WorkspaceUuid != workspaceUuid {
+		return ErrBulkTicketPhase
+	}
+	return nil
+}
+
+// bulkTicketOrder returns the indexes of a batch so that a ticket comes after
+// the tickets of the batch it depends on, otherwise keeping the request order.
+// A dependency check then sees the new state of those tickets.
+func bulkTicketOrder(tickets []Tickets) []int {
+	index := map[string]int{}
+	for i, ticket := range tickets {
+		if ticket.UUID != uuid.Nil {
+			index[ticketGroupOf(ticket).String()] = i
+		}
+	}
+
+	order := make([]int, 0, len(tickets))
+	visited := make([]bool, len(tickets))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, dep := range tickets[i].DependsOn {
+			if j, ok := index[dep]; ok {
+				visit(j)
+			}
+		}
+		order = append(order, i)
+	}
+	for i := range tickets {
+		visit(i)
+	}
+	return order
+}
+
+// BulkUpdateTickets applies a bulk request to the latest version of every
+// requested ticket group in one transaction. When one ticket fails the whole
+// batch is rolled back and ErrBulkTicketFailed is returned next to the
+// results, which carry the reason of each failure.
+func (db database) BulkUpdateTickets(pubkey string, request BulkTicketRequest) (BulkTicketResponse, error) {
+	response := BulkTicketResponse{Action: request.Action, Results: []BulkTicketResult{}}
+	if err := ValidateBulkTicketRequest(request); err != nil {
+		return response, err
+	}
+
+	results := make([]BulkTicketResult, len(request.TicketUUIDs))
+	failed := false
+	fail := func(i int, err error) {
+		results[i].Err = err
+		results[i].Error = err.Error()
+		failed = true
+	}
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		latest := make([]Tickets, len(results))
+		seen := map[uuid.UUID]int{}
+		workspaces := map[string]bool{}
+		for i, id := range request.TicketUUIDs {
+			results[i].TicketUUID = id
+
+			var ticket Tickets
+			if err := tx.Where("uuid = ?", id).Limit(1).Find(&ticket).Error; err != nil {
+				return fmt.Errorf("failed to fetch ticket: %w", err)
+			}
+			if ticket.UUID == uuid.Nil {
+				fail(i, errors.New("ticket not found"))
+				continue
+			}
+			group := ticketGroupOf(ticket)
+			results[i].TicketGroup = group.String()
+			if first, ok := seen[group]; ok {
+				fail(i, fmt.Errorf("ticket group is already changed by %s", request.TicketUUIDs[first]))
+				continue
+			}
+			seen[group] = i
+
+			if err := tx.Where("ticket_group = ? OR uuid = ?", group, group).Order("version DESC").Limit(1).Find(&ticket).Error; err != nil {
+				return fmt.Errorf("failed to fetch latest ticket version: %w", err)
+			}
+			latest[i] = ticket
+
+			workspaceUuid := db.bulkTicketWorkspace(tx, ticket)
+			results[i].WorkspaceUuid = workspaceUuid
+			if !db.IsWorkspaceMember(pubkey, workspaceUuid) {
+				fail(i, ErrBulkTicketNoAccess)
+				continue
+			}
+			workspaces[workspaceUuid] = true
+		}
+
+		// the board lock is shared with card moves so WIP counts stay accurate,
+		// workspaces are locked in order to avoid deadlocks between batches
+		locked := make([]string, 0, len(workspaces))
+		for ws := range workspaces {
+			locked = append(locked, ws)
+		}
+		sort.Strings(locked)
+		for _, ws := range locked {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "board:"+ws).Error; err != nil {
+				return fmt.Errorf("failed to lock board: %w", err)
+			}
+		}
+
+		boards := map[string]Board{}
+		if request.Action == BulkTicketUpdate && request.Status != nil {
+			for _, ws := range locked {
+				board, err := loadBoard(tx, ws, BoardFilter{})
+				if err != nil {
+					return err
+				}
+				boards[ws] = board
+			}
+		}
+
+		now := time.Now()
+		moves := map[string]map[string]TicketStatus{}
+		for _, i := range bulkTicketOrder(latest) {
+			if results[i].Err != nil {
+				continue
+			}
+			ticket := latest[i]
+			results[i].FromStatus = ticket.Status
+
+			if request.Action == BulkTicketDelete {
+				if err := db.trashTicketGroup(tx, ticketGroupOf(ticket), pubkey); err != nil {
+					fail(i, err)
+					continue
+				}
+				results[i].Ticket = &ticket
+				continue
+			}
+
+			updated := applyBulkTicketUpdate(ticket, request)
+			if updated.PhaseUUID != ticket.PhaseUUID || updated.FeatureUUID != ticket.FeatureUUID {
+				if err := checkBulkTicketPhase(tx, updated, results[i].WorkspaceUuid); err != nil {
+					fail(i, err)
+					continue
+				}
+			}
+
+			rule, err := db.CheckTicketTransition(pubkey, ticket.Status, updated)
+			if err != nil {
+				fail(i, err)
+				continue
+			}
+			results[i].Rule = rule
+			if err := checkTicketDependencies(tx, updated); err != nil {
+				fail(i, err)
+				continue
+			}
+
+			// like a card move, the update is a new version of the ticket
+			if err := keepTicketVersion(tx, ticket); err != nil {
+				return fmt.Errorf("failed to record ticket version: %w", err)
+			}
+			version, err := nextTicketVersion(tx, updated)
+			if err != nil {
+				return err
+			}
+			updated.Version = version
+			updated.UpdatedAt = now
+			if err := tx.Model(&Tickets{}).Where("uuid = ?", updated.UUID).Updates(map[string]interface{}{
+				"status":       updated.Status,
+				"category":     updated.Category,
+				"amount":       updated.Amount,
+				"feature_uuid": updated.FeatureUUID,
+				"phase_uuid":   updated.PhaseUUID,
+				"depends_on":   updated.DependsOn,
+				"version":      updated.Version,
+				"updated_at":   updated.UpdatedAt,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update ticket: %w", err)
+			}
+			if err := saveTicketVersion(tx, updated); err != nil {
+				return fmt.Errorf("failed to record ticket version: %w", err)
+			}
+			results[i].Ticket = &updated
+
+			if updated.Status != ticket.Status {
+				ws := results[i].WorkspaceUuid
+				if moves[ws] == nil {
+					moves[ws] = map[string]TicketStatus{}
+				}
+				moves[ws][ticketGroupOf(updated).String()] = updated.Status
+			}
+		}
+
+		for ws, wsMoves := range moves {
+			over := CheckBulkWipLimits(boards[ws], wsMoves)
+			if len(over) == 0 {
+				continue
+			}
+			for i := range results {
+				if results[i].Err == nil && results[i].WorkspaceUuid == ws && results[i].Ticket != nil {
+					if err, ok := over[results[i].Ticket.Status]; ok && results[i].Ticket.Status != results[i].FromStatus {
+						fail(i, err)
+					}
+				}
+			}
+		}
+
+		if failed {
+			return ErrBulkTicketFailed
+		}
+		return nil
+	})
+
+	response.Results = results
+	if err != nil {
+		return response, err
+	}
+	response.Applied = true
+
+	for i := range response.Results {
+		response.Results[i].Success = true
+		if response.Results[i].Ticket != nil {
+			card := ticketBoardCard(*response.Results[i].Ticket)
+			response.Results[i].Card = &card
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBulkTicketRequest(t *testing.T) {
+	status := InProgressTicket
+	invalid := TicketStatus("NOPE")
+	feature := "feature"
+	negative := int64(-1)
+	ids := []string{uuid.New().String()}
+
+	t.Run("Should accept updates and deletes", func(t *testing.T) {
+		assert.NoError(t, ValidateBulkTicketRequest(BulkTicketRequest{TicketUUIDs: ids, Action: BulkTicketUpdate, Status: &status}))
+		assert.NoError(t, ValidateBulkTicketRequest(BulkTicketRequest{TicketUUIDs: ids, Action: BulkTicketDelete}))
+	})
+
+	t.Run("Should reject malformed requests", func(t *testing.T) {
+		tooMany := make([]string, MaxBulkTickets+1)
+		for i := range tooMany {
+			tooMany[i] = uuid.New().String()
+		}
+
+		requests := []BulkTicketRequest{
+			{Action: BulkTicketDelete},
+			{TicketUUIDs: tooMany, Action: BulkTicketDelete},
+			{TicketUUIDs: []string{"not-a-uuid"}, Action: BulkTicketDelete},
+			{TicketUUIDs: ids, Action: "archive"},
+			{TicketUUIDs: ids, Action: BulkTicketUpdate},
+			{TicketUUIDs: ids, Action: BulkTicketUpdate, Status: &invalid},
+			{TicketUUIDs: ids, Action: BulkTicketUpdate, Amount: &negative},
+			{TicketUUIDs: ids, Action: BulkTicketUpdate, FeatureUUID: &feature},
+		}
+		for _, request := range requests {
+			assert.ErrorIs(t, ValidateBulkTicketRequest(request), ErrBulkTicketRequest)
+		}
+	})
+}
+
+func TestApplyBulkTicketUpdate(t *testing.T) {
+	ticket := Tickets{UUID: uuid.New(), Status: DraftTicket, FeatureUUID: "feature", PhaseUUID: "phase", DependsOn: []string{uuid.New().String()}}
+	status := ReadyTicket
+	category := Design
+	amount := int64(500)
+
+	updated := applyBulkTicketUpdate(ticket, BulkTicketRequest{Status: &status, Category: &category, Amount: &amount})
+	assert.Equal(t, ReadyTicket, updated.Status)
+	assert.Equal(t, Design, *updated.Category)
+	assert.Equal(t, int64(500), *updated.Amount)
+	assert.Len(t, updated.DependsOn, 1)
+
+	phase := "other-phase"
+	moved := applyBulkTicketUpdate(ticket, BulkTicketRequest{PhaseUUID: &phase})
+	assert.Equal(t, "other-phase", moved.PhaseUUID)
+	assert.NotNil(t, moved.DependsOn)
+	assert.Len(t, moved.DependsOn, 0)
+	assert.Equal(t, DraftTicket, moved.Status)
+}
+
+func TestCheckBulkWipLimits(t *testing.T) {
+	first := newGraphTicket("first", 0, DraftTicket)
+	second := newGraphTicket("second", 1, DraftTicket)
+	busy := newGraphTicket("busy", 2, InProgressTicket)
+	limits := []BoardColumnConfig{{Status: InProgressTicket, WipLimit: 2}}
+	board := BuildBoard("workspace", []Tickets{first, second, busy}, nil, nil, limits, BoardFilter{})
+
+	t.Run("Should allow moves within the limit", func(t *testing.T) {
+		failed := CheckBulkWipLimits(board, map[string]TicketStatus{ticketGroupOf(first).String(): InProgressTicket})
+		assert.Empty(t, failed)
+	})
+
+	t.Run("Should count every move of the batch", func(t *testing.T) {
+		failed := CheckBulkWipLimits(board, map[string]TicketStatus{
+			ticketGroupOf(first).String():  InProgressTicket,
+			ticketGroupOf(second).String(): InProgressTicket,
+		})
+		assert.ErrorIs(t, failed[InProgressTicket], ErrBoardWipLimit)
+	})
+
+	t.Run("Should free a slot for moves out of the column", func(t *testing.T) {
+		failed := CheckBulkWipLimits(board, map[string]TicketStatus{
+			ticketGroupOf(first).String():  InProgressTicket,
+			ticketGroupOf(second).String(): InProgressTicket,
+			ticketGroupOf(busy).String():   TestTicket,
+		})
+		assert.Empty(t, failed)
+	})
+}
+
+func TestBulkTicketOrder(t *testing.T) {
+	first := newGraphTicket("first", 0, DraftTicket)
+	second := newGraphTicket("second", 1, DraftTicket, first)
+	third := newGraphTicket("third", 2, DraftTicket, second)
+	other := newGraphTicket("other", 3, DraftTicket)
+
+	t.Run("Should put dependencies of the batch first", func(t *testing.T) {
+		assert.Equal(t, []int{2, 1, 0, 3}, bulkTicketOrder([]Tickets{third, second, first, other}))
+	})
+
+	t.Run("Should keep the request order otherwise", func(t *testing.T) {
+		assert.Equal(t, []int{0, 1, 2}, bulkTicketOrder([]Tickets{other, first, {}}))
+	})
+
+	t.Run("Should not loop on a cycle", func(t *testing.T) {
+		first.DependsOn = append(first.DependsOn, third.TicketGroup.String())
+		assert.ElementsMatch(t, []int{0, 1, 2}, bulkTicketOrder([]Tickets{first, second, third}))
+	})
+}
+
+func TestBulkUpdateTicketsRecordsVersion(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{Uuid: uuid.New().String(), Name: "Bulk Version Workspace", OwnerPubKey: "bulk_owner"}
+	TestDB.db.Create(&workspace)
+	feature := WorkspaceFeatures{Uuid: uuid.New().String(), WorkspaceUuid: workspace.Uuid, Name: "Bulk Version Feature"}
+	TestDB.CreateOrEditFeature(feature)
+
+	group := uuid.New()
+	ticket := Tickets{
+		UUID:          group,
+		TicketGroup:   &group,
+		WorkspaceUuid: workspace.Uuid,
+		FeatureUUID:   feature.Uuid,
+		Name:          "Bulk",
+		Status:        DraftTicket,
+		Version:       1,
+	}
+	_, err := TestDB.CreateOrEditTicket(&ticket)
+	assert.NoError(t, err)
+
+	status := ReadyTicket
+	response, err := TestDB.BulkUpdateTickets(workspace.OwnerPubKey, BulkTicketRequest{
+		TicketUUIDs: []string{group.String()},
+		Action:      BulkTicketUpdate,
+		Status:      &status,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Results[0].Ticket.Version)
+
+	versions, err := TestDB.GetTicketVersions(group)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, DraftTicket, versions[0].Status)
+	assert.Equal(t, ReadyTicket, versions[1].Status)
+}
+
+func TestBulkUpdateTicketsDependencies(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{Uuid: uuid.New().String(), Name: "Bulk Dependency Workspace", OwnerPubKey: "bulk_dependency_owner"}
+	TestDB.db.Create(&workspace)
+	feature := WorkspaceFeatures{Uuid: uuid.New().String(), WorkspaceUuid: workspace.Uuid, Name: "Bulk Dependency Feature"}
+	TestDB.CreateOrEditFeature(feature)
+	phase := FeaturePhase{Uuid: uuid.New().String(), FeatureUuid: feature.Uuid, Name: "Bulk Dependency Phase"}
+	TestDB.CreateOrEditFeaturePhase(phase)
+
+	newTicket := func(name string, status TicketStatus, dependsOn ...string) Tickets {
+		group := uuid.New()
+		ticket := Tickets{
+			UUID:          group,
+			TicketGroup:   &group,
+			WorkspaceUuid: workspace.Uuid,
+			FeatureUUID:   feature.Uuid,
+			PhaseUUID:     phase.Uuid,
+			Name:          name,
+			Status:        status,
+			Version:       1,
+			DependsOn:     append([]string{}, dependsOn...),
+		}
+		_, err := TestDB.CreateOrEditTicket(&ticket)
+		assert.NoError(t, err)
+		return ticket
+	}
+
+	t.Run("Should check a dependent against its dependency changed in the same batch", func(t *testing.T) {
+		dependency := newTicket("Dependency", CompletedTicket)
+		dependent := newTicket("Dependent", ReadyTicket, dependency.UUID.String())
+
+		// reopening the dependency leaves nothing for the dependent to start on,
+		// even though the dependent is listed first
+		status := InProgressTicket
+		response, err := TestDB.BulkUpdateTickets(workspace.OwnerPubKey, BulkTicketRequest{
+			TicketUUIDs: []string{dependent.UUID.String(), dependency.UUID.String()},
+			Action:      BulkTicketUpdate,
+			Status:      &status,
+		})
+		assert.ErrorIs(t, err, ErrBulkTicketFailed)
+		assert.False(t, response.Applied)
+		assert.ErrorIs(t, response.Results[0].Err, ErrTicketDependenciesIncomplete)
+		assert.NoError(t, response.Results[1].Err)
+
+		saved, err := TestDB.GetTicket(dependency.UUID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, CompletedTicket, saved.Status)
+	})
+
+	t.Run("Should apply a batch holding a dependency and its dependent", func(t *testing.T) {
+		dependency := newTicket("Done Dependency", CompletedTicket)
+		dependent := newTicket("Ready Dependent", ReadyTicket, dependency.UUID.String())
+
+		category := Design
+		response, err := TestDB.BulkUpdateTickets(workspace.OwnerPubKey, BulkTicketRequest{
+			TicketUUIDs: []string{dependent.UUID.String(), dependency.UUID.String()},
+			Action:      BulkTicketUpdate,
+			Category:    &category,
+		})
+		assert.NoError(t, err)
+		assert.True(t, response.Applied)
+
+		status := InProgressTicket
+		response, err = TestDB.BulkUpdateTickets(workspace.OwnerPubKey, BulkTicketRequest{
+			TicketUUIDs: []string{dependent.UUID.String()},
+			Action:      BulkTicketUpdate,
+			Status:      &status,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, InProgressTicket, response.Results[0].Ticket.Status)
+	})
+}
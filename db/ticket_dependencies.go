@@ -0,0 +1,382 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTicketDependencyCycle        = errors.New("ticket dependencies form a cycle")
+	ErrTicketDependencyUnknown      = errors.New("ticket dependency is not a ticket group of the same phase")
+	ErrTicketDependenciesIncomplete = errors.New("ticket dependencies are not completed")
+	ErrTicketSequenceOrder          = errors.New("ticket sequence conflicts with its dependencies")
+)
+
+type TicketGraphNode struct {
+	TicketGroup uuid.UUID    `json:"ticket_group"`
+	TicketUUID  uuid.UUID    `json:"ticket_uuid"`
+	Name        string       `json:"name"`
+	Status      TicketStatus `json:"status"`
+	Sequence    int          `json:"sequence"`
+	DependsOn   []string     `json:"depends_on"`
+	Level       int          `json:"level"`
+	Blocked     bool         `json:"blocked"`
+	Critical    bool         `json:"critical"`
+}
+
+// PhaseTicketGraph is the order tickets of a phase can be worked in. The
+// critical path is the longest chain of tickets that are not completed yet.
+type PhaseTicketGraph struct {
+	FeatureUUID        string            `json:"feature_uuid"`
+	PhaseUUID          string            `json:"phase_uuid"`
+	Order              []TicketGraphNode `json:"order"`
+	CriticalPath       []uuid.UUID       `json:"critical_path"`
+	CriticalPathLength int               `json:"critical_path_length"`
+}
+
+// LatestTicketsByGroup keeps the highest version of every ticket group
+func LatestTicketsByGroup(tickets []Tickets) []Tickets {
+	latest := map[uuid.UUID]Tickets{}
+	for _, ticket := range tickets {
+		group := ticketGroupOf(ticket)
+		if current, ok := latest[group]; !ok || ticket.Version > current.Version {
+			latest[group] = ticket
+		}
+	}
+
+	result := make([]Tickets, 0, len(latest))
+	for _, ticket := range latest {
+		result = append(result, ticket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Sequence != result[j].Sequence {
+			return result[i].Sequence < result[j].Sequence
+		}
+		return ticketGroupOf(result[i]).String() < ticketGroupOf(result[j]).String()
+	})
+	return result
+}
+
+// findTicketCycle returns the groups of a dependency cycle, or nil
+func findTicketCycle(deps map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var stack []string
+	var cycle []string
+
+	var visit func(node string) bool
+	visit = func(node string) bool {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, dep := range deps[node] {
+			switch state[dep] {
+			case visiting:
+				for i, n := range stack {
+					if n == dep {
+						cycle = append(append([]string{}, stack[i:]...), dep)
+						return true
+					}
+				}
+			case unvisited:
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = done
+		return false
+	}
+
+	nodes := make([]string, 0, len(deps))
+	for node := range deps {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		if state[node] == unvisited && visit(node) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// BuildPhaseTicketGraph orders the latest version of each ticket group so every
+// ticket comes after its dependencies, breaking ties by sequence
+func BuildPhaseTicketGraph(tickets []Tickets) (PhaseTicketGraph, error) {
+	graph := PhaseTicketGraph{Order: []TicketGraphNode{}, CriticalPath: []uuid.UUID{}}
+	latest := LatestTicketsByGroup(tickets)
+	if len(latest) > 0 {
+		graph.FeatureUUID = latest[0].FeatureUUID
+		graph.PhaseUUID = latest[0].PhaseUUID
+	}
+
+	nodes := map[string]*TicketGraphNode{}
+	deps := map[string][]string{}
+	for _, ticket := range latest {
+		group := ticketGroupOf(ticket)
+		nodes[group.String()] = &TicketGraphNode{
+			TicketGroup: group,
+			TicketUUID:  ticket.UUID,
+			Name:        ticket.Name,
+			Status:      ticket.Status,
+			Sequence:    ticket.Sequence,
+			DependsOn:   []string{},
+		}
+	}
+	for _, ticket := range latest {
+		group := ticketGroupOf(ticket).String()
+		for _, dep := range ticket.DependsOn {
+			if _, ok := nodes[dep]; ok {
+				deps[group] = append(deps[group], dep)
+				nodes[group].DependsOn = append(nodes[group].DependsOn, dep)
+			}
+		}
+	}
+
+	if cycle := findTicketCycle(deps); cycle != nil {
+		return graph, fmt.Errorf("%w: %s", ErrTicketDependencyCycle, strings.Join(cycle, " -> "))
+	}
+
+	dependents := map[string][]string{}
+	remaining := map[string]int{}
+	for group := range nodes {
+		remaining[group] = len(deps[group])
+		for _, dep := range deps[group] {
+			dependents[dep] = append(dependents[dep], group)
+		}
+	}
+
+	less := func(a, b string) bool {
+		if nodes[a].Sequence != nodes[b].Sequence {
+			return nodes[a].Sequence < nodes[b].Sequence
+		}
+		return a < b
+	}
+	var ready []string
+	for group, count := range remaining {
+		if count == 0 {
+			ready = append(ready, group)
+		}
+	}
+
+	// dist holds the number of open tickets on the longest chain ending at a node
+	dist := map[string]int{}
+	prev := map[string]string{}
+	var order []string
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		group := ready[0]
+		ready = ready[1:]
+		order = append(order, group)
+
+		node := nodes[group]
+		weight := 1
+		if node.Status == CompletedTicket {
+			weight = 0
+		}
+		best := 0
+		for _, dep := range deps[group] {
+			if nodes[dep].Level+1 > node.Level {
+				node.Level = nodes[dep].Level + 1
+			}
+			if nodes[dep].Status != CompletedTicket {
+				node.Blocked = true
+			}
+			if dist[dep] > best || (dist[dep] == best && prev[group] == "") {
+				best = dist[dep]
+				prev[group] = dep
+			}
+		}
+		dist[group] = best + weight
+
+		for _, next := range dependents[group] {
+			remaining[next]--
+			if remaining[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	end := ""
+	for _, group := range order {
+		if dist[group] > 0 && (end == "" || dist[group] > dist[end]) {
+			end = group
+		}
+	}
+	var path []string
+	for group := end; group != ""; group = prev[group] {
+		if nodes[group].Status != CompletedTicket {
+			path = append([]string{group}, path...)
+			nodes[group].Critical = true
+		}
+	}
+
+	for _, group := range order {
+		graph.Order = append(graph.Order, *nodes[group])
+	}
+	for _, group := range path {
+		graph.CriticalPath = append(graph.CriticalPath, nodes[group].TicketGroup)
+	}
+	graph.CriticalPathLength = len(path)
+
+	return graph, nil
+}
+
+// ValidateTicketDependencies checks that a ticket only depends on other ticket
+// groups of its phase and that saving it keeps the phase free of cycles
+func ValidateTicketDependencies(ticket Tickets, phaseTickets []Tickets) error {
+	group := ticketGroupOf(ticket)
+	if len(ticket.DependsOn) == 0 {
+		return nil
+	}
+	if ticket.PhaseUUID == "" {
+		return fmt.Errorf("%w: ticket has no phase", ErrTicketDependencyUnknown)
+	}
+
+	others := []Tickets{}
+	known := map[string]bool{}
+	for _, t := range LatestTicketsByGroup(phaseTickets) {
+		if ticketGroupOf(t) == group {
+			continue
+		}
+		others = append(others, t)
+		known[ticketGroupOf(t).String()] = true
+	}
+	for _, dep := range ticket.DependsOn {
+		if dep == group.String() {
+			return fmt.Errorf("%w: %s", ErrTicketDependencyCycle, dep)
+		}
+		if !known[dep] {
+			return fmt.Errorf("%w: %s", ErrTicketDependencyUnknown, dep)
+		}
+	}
+
+	_, err := BuildPhaseTicketGraph(append(others, ticket))
+	return err
+}
+
+// CheckTicketDependenciesCompleted fails when any dependency of the ticket is
+// not COMPLETED yet
+func CheckTicketDependenciesCompleted(ticket Tickets, phaseTickets []Tickets) error {
+	status := map[string]TicketStatus{}
+	names := map[string]string{}
+	for _, t := range LatestTicketsByGroup(phaseTickets) {
+		status[ticketGroupOf(t).String()] = t.Status
+		names[ticketGroupOf(t).String()] = t.Name
+	}
+
+	var open []string
+	for _, dep := range ticket.DependsOn {
+		if s, ok := status[dep]; ok && s != CompletedTicket {
+			open = append(open, fmt.Sprintf("%s (%s)", names[dep], s))
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("%w: %s", ErrTicketDependenciesIncomplete, strings.Join(open, ", "))
+	}
+	return nil
+}
+
+// CheckTicketSequenceOrder fails when moving a ticket group to the sequence
+// would place it before a dependency or after a ticket depending on it
+func CheckTicketSequenceOrder(phaseTickets []Tickets, group uuid.UUID, sequence int) error {
+	latest := LatestTicketsByGroup(phaseTickets)
+	var moved *Tickets
+	for i := range latest {
+		if ticketGroupOf(latest[i]) == group {
+			moved = &latest[i]
+		}
+	}
+	if moved == nil {
+		return nil
+	}
+
+	groups := map[string]Tickets{}
+	for _, t := range latest {
+		groups[ticketGroupOf(t).String()] = t
+	}
+	for _, dep := range moved.DependsOn {
+		if t, ok := groups[dep]; ok && t.Sequence >= sequence {
+			return fmt.Errorf("%w: %q depends on %q which has sequence %d", ErrTicketSequenceOrder, moved.Name, t.Name, t.Sequence)
+		}
+	}
+	for _, t := range latest {
+		for _, dep := range t.DependsOn {
+			if dep == group.String() && t.Sequence <= sequence {
+				return fmt.Errorf("%w: %q with sequence %d depends on %q", ErrTicketSequenceOrder, t.Name, t.Sequence, moved.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkTicketDependencies runs before a ticket is saved. The dependencies and
+// status left out of a partial update are taken from the latest saved version.
+// Both reads go through tx so a transaction sees its own earlier changes.
+func checkTicketDependencies(tx *gorm.DB, ticket Tickets) error {
+	group := ticketGroupOf(ticket)
+
+	var previous Tickets
+	tx.Where("ticket_group = ? OR uuid = ?", group, group).Order("version DESC").Limit(1).Find(&previous)
+
+	effective := ticket
+	if effective.DependsOn == nil {
+		effective.DependsOn = previous.DependsOn
+	}
+	if effective.Status == "" {
+		effective.Status = previous.Status
+	}
+	if effective.PhaseUUID == "" {
+		effective.PhaseUUID = previous.PhaseUUID
+		effective.FeatureUUID = previous.FeatureUUID
+	}
+
+	if len(effective.DependsOn) == 0 {
+		return nil
+	}
+
+	var phaseTickets []Tickets
+	if effective.PhaseUUID != "" {
+		if err := tx.Where("phase_uuid = ?", effective.PhaseUUID).Find(&phaseTickets).Error; err != nil {
+			return fmt.Errorf("failed to fetch phase tickets: %w", err)
+		}
+	}
+
+	if err := ValidateTicketDependencies(effective, phaseTickets); err != nil {
+		return err
+	}
+	if effective.Status == InProgressTicket && previous.Status != InProgressTicket {
+		return CheckTicketDependenciesCompleted(effective, phaseTickets)
+	}
+	return nil
+}
+
+func (db database) GetPhaseTicketGraph(featureUUID string, phaseUUID string) (PhaseTicketGraph, error) {
+	tickets, err := db.GetTicketsByPhaseUUID(featureUUID, phaseUUID)
+	if err != nil {
+		return PhaseTicketGraph{}, err
+	}
+
+	graph, err := BuildPhaseTicketGraph(tickets)
+	graph.FeatureUUID = featureUUID
+	graph.PhaseUUID = phaseUUID
+	return graph, err
+}
+
+func (db database) CheckTicketSequence(featureUUID string, phaseUUID string, group uuid.UUID, sequence int) error {
+	tickets, err := db.GetTicketsByPhaseUUID(featureUUID, phaseUUID)
+	if err != nil {
+		return err
+	}
+	return CheckTicketSequenceOrder(tickets, group, sequence)
+}
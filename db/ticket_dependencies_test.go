@@ -0,0 +1,106 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGraphTicket(name string, sequence int, status TicketStatus, dependsOn ...Tickets) Tickets {
+	group := uuid.New()
+	ticket := Tickets{
+		UUID:        uuid.New(),
+		TicketGroup: &group,
+		FeatureUUID: "feature",
+		PhaseUUID:   "phase",
+		Name:        name,
+		Sequence:    sequence,
+		Status:      status,
+		Version:     1,
+		DependsOn:   []string{},
+	}
+	for _, dep := range dependsOn {
+		ticket.DependsOn = append(ticket.DependsOn, dep.TicketGroup.String())
+	}
+	return ticket
+}
+
+func TestBuildPhaseTicketGraph(t *testing.T) {
+	design := newGraphTicket("design", 1, CompletedTicket)
+	api := newGraphTicket("api", 2, InProgressTicket, design)
+	ui := newGraphTicket("ui", 3, DraftTicket, design)
+	docs := newGraphTicket("docs", 0, DraftTicket)
+	release := newGraphTicket("release", 4, DraftTicket, api, ui)
+
+	t.Run("Should order tickets after their dependencies", func(t *testing.T) {
+		graph, err := BuildPhaseTicketGraph([]Tickets{release, ui, api, docs, design})
+		assert.NoError(t, err)
+
+		var names []string
+		for _, node := range graph.Order {
+			names = append(names, node.Name)
+		}
+		assert.Equal(t, []string{"docs", "design", "api", "ui", "release"}, names)
+		assert.Equal(t, 2, graph.Order[4].Level)
+		assert.True(t, graph.Order[4].Blocked)
+		assert.False(t, graph.Order[2].Blocked)
+
+		assert.Equal(t, []uuid.UUID{*api.TicketGroup, *release.TicketGroup}, graph.CriticalPath)
+		assert.Equal(t, 2, graph.CriticalPathLength)
+	})
+
+	t.Run("Should only use the latest version of a group", func(t *testing.T) {
+		older := api
+		older.UUID = uuid.New()
+		older.Version = 0
+		older.Name = "old api"
+		graph, err := BuildPhaseTicketGraph([]Tickets{older, api, design})
+		assert.NoError(t, err)
+		assert.Len(t, graph.Order, 2)
+		assert.Equal(t, "api", graph.Order[1].Name)
+	})
+
+	t.Run("Should report cycles", func(t *testing.T) {
+		cyclic := design
+		cyclic.DependsOn = []string{release.TicketGroup.String()}
+		_, err := BuildPhaseTicketGraph([]Tickets{cyclic, api, ui, release})
+		assert.ErrorIs(t, err, ErrTicketDependencyCycle)
+	})
+}
+
+func TestValidateTicketDependencies(t *testing.T) {
+	design := newGraphTicket("design", 1, ReadyTicket)
+	api := newGraphTicket("api", 2, DraftTicket, design)
+	phase := []Tickets{design, api}
+
+	assert.NoError(t, ValidateTicketDependencies(api, phase))
+
+	cyclic := design
+	cyclic.DependsOn = []string{api.TicketGroup.String()}
+	assert.ErrorIs(t, ValidateTicketDependencies(cyclic, phase), ErrTicketDependencyCycle)
+
+	self := api
+	self.DependsOn = []string{api.TicketGroup.String()}
+	assert.ErrorIs(t, ValidateTicketDependencies(self, phase), ErrTicketDependencyCycle)
+
+	unknown := api
+	unknown.DependsOn = []string{uuid.New().String()}
+	assert.ErrorIs(t, ValidateTicketDependencies(unknown, phase), ErrTicketDependencyUnknown)
+
+	assert.ErrorIs(t, CheckTicketDependenciesCompleted(api, phase), ErrTicketDependenciesIncomplete)
+	design.Status = CompletedTicket
+	assert.NoError(t, CheckTicketDependenciesCompleted(api, []Tickets{design, api}))
+}
+
+func TestCheckTicketSequenceOrder(t *testing.T) {
+	design := newGraphTicket("design", 1, ReadyTicket)
+	api := newGraphTicket("api", 2, DraftTicket, design)
+	release := newGraphTicket("release", 5, DraftTicket, api)
+	phase := []Tickets{design, api, release}
+
+	assert.NoError(t, CheckTicketSequenceOrder(phase, *api.TicketGroup, 3))
+	assert.ErrorIs(t, CheckTicketSequenceOrder(phase, *api.TicketGroup, 1), ErrTicketSequenceOrder)
+	assert.ErrorIs(t, CheckTicketSequenceOrder(phase, *api.TicketGroup, 5), ErrTicketSequenceOrder)
+	assert.NoError(t, CheckTicketSequenceOrder(phase, uuid.New(), 0))
+}
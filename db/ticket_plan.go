@@ -7,6 +7,8 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"github.com/stakwork/sphinx-tribes/logger"
 )
 
 func (db database) CreateOrEditTicketPlan(plan *TicketPlan) (*TicketPlan, error) {
@@ -35,6 +37,13 @@ func (db database) CreateOrEditTicketPlan(plan *TicketPlan) (*TicketPlan, error)
 			return nil, fmt.Errorf("failed to create ticket plan: %w", err)
 		}
 	} else {
+		if ticketPlanLocked(existingPlan) {
+			return nil, fmt.Errorf("%w: plan is %s", ErrTicketPlanLocked, existingPlan.Status)
+		}
+		// a changed plan needs a new review
+		if existingPlan.Status == RejectedPlan {
+			plan.Status = DraftPlan
+		}
 		plan.UpdatedAt = now
 		plan.Version = existingPlan.Version + 1
 		if err := db.db.Model(&existingPlan).Updates(plan).Error; err != nil {
@@ -47,6 +56,10 @@ func (db database) CreateOrEditTicketPlan(plan *TicketPlan) (*TicketPlan, error)
 		return nil, fmt.Errorf("failed to fetch updated ticket plan: %w", err)
 	}
 
+	if err := db.recordTicketPlanRevision(updatedPlan); err != nil {
+		logger.Log.Error("failed to record revision %d of ticket plan %s: %v", updatedPlan.Version, updatedPlan.UUID, err)
+	}
+
 	return &updatedPlan, nil
 }
 
@@ -0,0 +1,373 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTicketPlanNotFound    = errors.New("ticket plan not found")
+	ErrTicketPlanLocked      = errors.New("ticket plan is locked while it is in review or approved")
+	ErrTicketPlanNotInReview = errors.New("ticket plan is not waiting for review")
+	ErrTicketPlanNotReviewer = errors.New("only the designated reviewers can review the ticket plan")
+	ErrTicketPlanReviewers   = errors.New("ticket plan needs at least as many reviewers as required approvals")
+	ErrTicketPlanSelfReview  = errors.New("the creator or submitter of a ticket plan can't review it")
+	ErrTicketPlanNonMember   = errors.New("ticket plan reviewers must be members of its workspace")
+	ErrTicketPlanNotApproved = errors.New("ticket plan has never been approved")
+	ErrTicketPlanStatus      = errors.New("ticket plan can not change to that status")
+	ErrTicketPlanDecision    = errors.New("decision must be APPROVE or REJECT")
+	ErrTicketGroupLocked     = errors.New("ticket group is part of an approved ticket plan")
+)
+
+// TicketPlanGroupDrift compares a ticket group of the approved revision with
+// what is executing now
+type TicketPlanGroupDrift struct {
+	TicketGroup     string             `json:"ticket_group"`
+	ApprovedVersion int                `json:"approved_version"`
+	CurrentVersion  int                `json:"current_version"`
+	Status          TicketStatus       `json:"status"`
+	Changed         bool               `json:"changed"`
+	Added           bool               `json:"added"`
+	Removed         bool               `json:"removed"`
+	Diff            *TicketVersionDiff `json:"diff,omitempty"`
+}
+
+type TicketPlanExecution struct {
+	Plan     TicketPlan             `json:"plan"`
+	Approved TicketPlanRevision     `json:"approved"`
+	Groups   []TicketPlanGroupDrift `json:"groups"`
+	Drifted  bool                   `json:"drifted"`
+}
+
+func ticketPlanLocked(plan TicketPlan) bool {
+	return plan.Status == PendingReviewPlan || plan.Status == ApprovedPlan
+}
+
+// IsTicketPlanReviewer is true when the pubkey is one of the designated
+// reviewers of the plan
+func IsTicketPlanReviewer(plan TicketPlan, pubkey string) bool {
+	for _, reviewer := range plan.Reviewers {
+		if reviewer == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTicketPlanReviewers refuses a reviewer list naming the creator of the
+// plan or the one submitting it, nobody approves their own plan
+func CheckTicketPlanReviewers(plan TicketPlan, submitter string) error {
+	for _, reviewer := range plan.Reviewers {
+		if reviewer == plan.CreatedBy || reviewer == submitter {
+			return fmt.Errorf("%w: %s", ErrTicketPlanSelfReview, reviewer)
+		}
+	}
+	return nil
+}
+
+// EvaluatePlanApprovals counts the latest decision of every reviewer on the
+// current version of the plan. Decisions of people who are no longer
+// reviewers and decisions on older versions do not count.
+func EvaluatePlanApprovals(plan TicketPlan, approvals []TicketPlanApproval) (int, bool) {
+	latest := map[string]TicketPlanApproval{}
+	for _, approval := range approvals {
+		if approval.Version != plan.Version || !IsTicketPlanReviewer(plan, approval.Reviewer) {
+			continue
+		}
+		if current, ok := latest[approval.Reviewer]; !ok || !approval.CreatedAt.Before(current.CreatedAt) {
+			latest[approval.Reviewer] = approval
+		}
+	}
+
+	approved := 0
+	rejected := false
+	for _, approval := range latest {
+		switch approval.Decision {
+		case PlanApprove:
+			approved++
+		case PlanReject:
+			rejected = true
+		}
+	}
+	return approved, rejected
+}
+
+// TicketPlanRevisionFrom snapshots a plan with the latest version of each of
+// its ticket groups, zero when a group has no tickets
+func TicketPlanRevisionFrom(plan TicketPlan, latest map[string]Tickets) TicketPlanRevision {
+	revision := TicketPlanRevision{
+		PlanUUID:       plan.UUID,
+		Version:        plan.Version,
+		Name:           plan.Name,
+		Description:    plan.Description,
+		TicketGroups:   append([]string{}, plan.TicketGroups...),
+		TicketVersions: make([]int64, 0, len(plan.TicketGroups)),
+		CreatedBy:      plan.UpdatedBy,
+		CreatedAt:      plan.UpdatedAt,
+	}
+	if revision.CreatedBy == "" {
+		revision.CreatedBy = plan.CreatedBy
+	}
+	for _, group := range plan.TicketGroups {
+		revision.TicketVersions = append(revision.TicketVersions, int64(latest[group].Version))
+	}
+	return revision
+}
+
+// ComparePlanRevision lines the groups of the approved revision up against
+// the current groups of the plan and their latest tickets
+func ComparePlanRevision(approved TicketPlanRevision, plan TicketPlan, latest map[string]Tickets) []TicketPlanGroupDrift {
+	current := map[string]bool{}
+	for _, group := range plan.TicketGroups {
+		current[group] = true
+	}
+
+	groups := []TicketPlanGroupDrift{}
+	seen := map[string]bool{}
+	for i, group := range approved.TicketGroups {
+		seen[group] = true
+		drift := TicketPlanGroupDrift{TicketGroup: group}
+		if i < len(approved.TicketVersions) {
+			drift.ApprovedVersion = int(approved.TicketVersions[i])
+		}
+		ticket, exists := latest[group]
+		if exists {
+			drift.CurrentVersion = ticket.Version
+			drift.Status = ticket.Status
+		}
+		drift.Removed = !current[group] || !exists
+		drift.Changed = drift.Removed || drift.CurrentVersion != drift.ApprovedVersion
+		groups = append(groups, drift)
+	}
+	for _, group := range plan.TicketGroups {
+		if seen[group] {
+			continue
+		}
+		ticket := latest[group]
+		groups = append(groups, TicketPlanGroupDrift{
+			TicketGroup:    group,
+			CurrentVersion: ticket.Version,
+			Status:         ticket.Status,
+			Changed:        true,
+			Added:          true,
+		})
+	}
+	return groups
+}
+
+// latestPlanTickets loads the latest ticket of each group keyed by group
+func latestPlanTickets(tx *gorm.DB, groups []string) (map[string]Tickets, error) {
+	latest := map[string]Tickets{}
+	if len(groups) == 0 {
+		return latest, nil
+	}
+	var tickets []Tickets
+	if err := tx.Where("ticket_group IN ?", groups).Find(&tickets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch plan tickets: %w", err)
+	}
+	for _, ticket := range LatestTicketsByGroup(tickets) {
+		latest[ticketGroupOf(ticket).String()] = ticket
+	}
+	return latest, nil
+}
+
+func (db database) recordTicketPlanRevision(plan TicketPlan) error {
+	latest, err := latestPlanTickets(db.db, plan.TicketGroups)
+	if err != nil {
+		return err
+	}
+	revision := TicketPlanRevisionFrom(plan, latest)
+	return db.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "plan_uuid"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "ticket_groups", "ticket_versions", "created_by", "created_at"}),
+	}).Create(&revision).Error
+}
+
+// updateTicketPlanStatus locks the plan row, checks it and applies the change
+func (db database) updateTicketPlanStatus(planUUID uuid.UUID, change func(tx *gorm.DB, plan *TicketPlan) error) (TicketPlan, error) {
+	var plan TicketPlan
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", planUUID).Limit(1).Find(&plan)
+		if result.Error != nil {
+			return fmt.Errorf("failed to fetch ticket plan: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrTicketPlanNotFound
+		}
+		if err := change(tx, &plan); err != nil {
+			return err
+		}
+		plan.UpdatedAt = time.Now()
+		return tx.Model(&TicketPlan{}).Where("uuid = ?", planUUID).Updates(map[string]interface{}{
+			"status":           plan.Status,
+			"approved_version": plan.ApprovedVersion,
+			"approved_at":      plan.ApprovedAt,
+			"updated_by":       plan.UpdatedBy,
+			"updated_at":       plan.UpdatedAt,
+		}).Error
+	})
+	return plan, err
+}
+
+// SubmitTicketPlan asks the reviewers of a draft or rejected plan for approval
+func (db database) SubmitTicketPlan(planUUID uuid.UUID, pubkey string) (TicketPlan, error) {
+	return db.updateTicketPlanStatus(planUUID, func(tx *gorm.DB, plan *TicketPlan) error {
+		if plan.Status != DraftPlan && plan.Status != RejectedPlan && plan.Status != "" {
+			return fmt.Errorf("%w: %s to %s", ErrTicketPlanStatus, plan.Status, PendingReviewPlan)
+		}
+		if plan.RequiredApprovals < 1 || len(plan.Reviewers) < plan.RequiredApprovals {
+			return fmt.Errorf("%w: %d reviewers for %d approvals", ErrTicketPlanReviewers, len(plan.Reviewers), plan.RequiredApprovals)
+		}
+		if err := CheckTicketPlanReviewers(*plan, pubkey); err != nil {
+			return err
+		}
+		plan.Status = PendingReviewPlan
+		plan.UpdatedBy = pubkey
+		return nil
+	})
+}
+
+// ReviewTicketPlan records the decision of a reviewer on the current version.
+// One rejection sends the plan back, it is approved once enough reviewers
+// approved it.
+func (db database) ReviewTicketPlan(planUUID uuid.UUID, reviewer string, decision PlanDecision, comment string) (TicketPlan, TicketPlanApproval, error) {
+	var approval TicketPlanApproval
+	if decision != PlanApprove && decision != PlanReject {
+		return TicketPlan{}, approval, ErrTicketPlanDecision
+	}
+
+	plan, err := db.updateTicketPlanStatus(planUUID, func(tx *gorm.DB, plan *TicketPlan) error {
+		if plan.Status != PendingReviewPlan {
+			return fmt.Errorf("%w: plan is %s", ErrTicketPlanNotInReview, plan.Status)
+		}
+		if reviewer == plan.CreatedBy {
+			return fmt.Errorf("%w: %s", ErrTicketPlanSelfReview, reviewer)
+		}
+		if !IsTicketPlanReviewer(*plan, reviewer) {
+			return ErrTicketPlanNotReviewer
+		}
+
+		approval = TicketPlanApproval{
+			PlanUUID:  plan.UUID,
+			Version:   plan.Version,
+			Reviewer:  reviewer,
+			Decision:  decision,
+			Comment:   comment,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&approval).Error; err != nil {
+			return fmt.Errorf("failed to save ticket plan review: %w", err)
+		}
+
+		var approvals []TicketPlanApproval
+		if err := tx.Where("plan_uuid = ? AND version = ?", plan.UUID, plan.Version).Find(&approvals).Error; err != nil {
+			return fmt.Errorf("failed to fetch ticket plan reviews: %w", err)
+		}
+		approved, rejected := EvaluatePlanApprovals(*plan, approvals)
+		switch {
+		case rejected:
+			plan.Status = RejectedPlan
+		case approved >= plan.RequiredApprovals:
+			now := time.Now()
+			plan.Status = ApprovedPlan
+			plan.ApprovedVersion = plan.Version
+			plan.ApprovedAt = &now
+		}
+		plan.UpdatedBy = reviewer
+		return nil
+	})
+	return plan, approval, err
+}
+
+// ReopenTicketPlan puts a plan in review or approved back into draft so it can
+// be edited. The last approved revision is kept for comparison.
+func (db database) ReopenTicketPlan(planUUID uuid.UUID, pubkey string) (TicketPlan, error) {
+	return db.updateTicketPlanStatus(planUUID, func(tx *gorm.DB, plan *TicketPlan) error {
+		if !ticketPlanLocked(*plan) {
+			return fmt.Errorf("%w: %s to %s", ErrTicketPlanStatus, plan.Status, DraftPlan)
+		}
+		plan.Status = DraftPlan
+		plan.UpdatedBy = pubkey
+		return nil
+	})
+}
+
+func (db database) GetTicketPlanRevisions(planUUID uuid.UUID) ([]TicketPlanRevision, error) {
+	revisions := []TicketPlanRevision{}
+	if err := db.db.Where("plan_uuid = ?", planUUID).Order("version ASC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket plan revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (db database) GetTicketPlanApprovals(planUUID uuid.UUID) ([]TicketPlanApproval, error) {
+	approvals := []TicketPlanApproval{}
+	if err := db.db.Where("plan_uuid = ?", planUUID).Order("created_at ASC").Find(&approvals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket plan reviews: %w", err)
+	}
+	return approvals, nil
+}
+
+// CompareApprovedTicketPlan shows how the tickets of a plan moved on since
+// its last approval, with a diff for every changed group
+func (db database) CompareApprovedTicketPlan(planUUID uuid.UUID) (TicketPlanExecution, error) {
+	var execution TicketPlanExecution
+	result := db.db.Where("uuid = ?", planUUID).Limit(1).Find(&execution.Plan)
+	if result.Error != nil {
+		return execution, fmt.Errorf("failed to fetch ticket plan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return execution, ErrTicketPlanNotFound
+	}
+	if execution.Plan.ApprovedVersion == 0 {
+		return execution, ErrTicketPlanNotApproved
+	}
+
+	result = db.db.Where("plan_uuid = ? AND version = ?", planUUID, execution.Plan.ApprovedVersion).Limit(1).Find(&execution.Approved)
+	if result.Error != nil {
+		return execution, fmt.Errorf("failed to fetch approved revision: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return execution, fmt.Errorf("%w: revision %d is missing", ErrTicketPlanNotApproved, execution.Plan.ApprovedVersion)
+	}
+
+	groups := append(append([]string{}, execution.Approved.TicketGroups...), execution.Plan.TicketGroups...)
+	latest, err := latestPlanTickets(db.db, groups)
+	if err != nil {
+		return execution, err
+	}
+
+	execution.Groups = ComparePlanRevision(execution.Approved, execution.Plan, latest)
+	for i := range execution.Groups {
+		drift := &execution.Groups[i]
+		if drift.Changed {
+			execution.Drifted = true
+		}
+		ticket, ok := latest[drift.TicketGroup]
+		if !drift.Changed || drift.Added || !ok || drift.ApprovedVersion == 0 {
+			continue
+		}
+		approved, err := db.GetTicketVersion(ticketGroupOf(ticket), drift.ApprovedVersion)
+		if err != nil {
+			continue
+		}
+		diff := DiffTicketVersions(approved, ticketVersionFrom(ticket))
+		drift.Diff = &diff
+	}
+
+	return execution, nil
+}
+
+// ticketGroupLocked is true when an approved plan holds the ticket group
+func (db database) ticketGroupLocked(tx *gorm.DB, ticketGroup uuid.UUID) (bool, error) {
+	var count int64
+	err := tx.Model(&TicketPlan{}).
+		Where("status = ? AND ? = ANY(ticket_groups)", ApprovedPlan, ticketGroup).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatePlanApprovals(t *testing.T) {
+	plan := TicketPlan{Version: 2, Reviewers: pq.StringArray{"alice", "bob", "carol"}, RequiredApprovals: 2}
+	now := time.Now()
+
+	t.Run("Should count the latest decision of each reviewer on the current version", func(t *testing.T) {
+		approved, rejected := EvaluatePlanApprovals(plan, []TicketPlanApproval{
+			{Version: 1, Reviewer: "alice", Decision: PlanApprove, CreatedAt: now},
+			{Version: 2, Reviewer: "bob", Decision: PlanReject, CreatedAt: now},
+			{Version: 2, Reviewer: "bob", Decision: PlanApprove, CreatedAt: now.Add(time.Minute)},
+			{Version: 2, Reviewer: "carol", Decision: PlanApprove, CreatedAt: now},
+			{Version: 2, Reviewer: "mallory", Decision: PlanApprove, CreatedAt: now},
+		})
+		assert.Equal(t, 2, approved)
+		assert.False(t, rejected)
+	})
+
+	t.Run("Should report a standing rejection", func(t *testing.T) {
+		approved, rejected := EvaluatePlanApprovals(plan, []TicketPlanApproval{
+			{Version: 2, Reviewer: "alice", Decision: PlanApprove, CreatedAt: now},
+			{Version: 2, Reviewer: "carol", Decision: PlanReject, CreatedAt: now},
+		})
+		assert.Equal(t, 1, approved)
+		assert.True(t, rejected)
+	})
+}
+
+func TestComparePlanRevision(t *testing.T) {
+	kept, edited, dropped, added := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	latest := map[string]Tickets{
+		kept.String():   {TicketGroup: &kept, Version: 1, Status: InProgressTicket},
+		edited.String(): {TicketGroup: &edited, Version: 3, Status: ReadyTicket},
+		added.String():  {TicketGroup: &added, Version: 1, Status: DraftTicket},
+	}
+
+	approvedPlan := TicketPlan{
+		UUID:         uuid.New(),
+		Version:      4,
+		Name:         "plan",
+		TicketGroups: pq.StringArray{kept.String(), edited.String(), dropped.String()},
+	}
+	revision := TicketPlanRevisionFrom(approvedPlan, map[string]Tickets{
+		kept.String():    latest[kept.String()],
+		edited.String():  {Version: 2},
+		dropped.String(): {Version: 1},
+	})
+	assert.Equal(t, pq.Int64Array{1, 2, 1}, revision.TicketVersions)
+
+	current := approvedPlan
+	current.TicketGroups = pq.StringArray{kept.String(), edited.String(), added.String()}
+	groups := ComparePlanRevision(revision, current, latest)
+
+	assert.Len(t, groups, 4)
+	assert.False(t, groups[0].Changed)
+	assert.True(t, groups[1].Changed)
+	assert.Equal(t, 2, groups[1].ApprovedVersion)
+	assert.Equal(t, 3, groups[1].CurrentVersion)
+	assert.True(t, groups[2].Removed)
+	assert.True(t, groups[3].Added)
+	assert.Equal(t, added.String(), groups[3].TicketGroup)
+}
+
+func TestCheckTicketPlanReviewers(t *testing.T) {
+	plan := TicketPlan{CreatedBy: "alice", Reviewers: pq.StringArray{"bob", "carol"}}
+	assert.NoError(t, CheckTicketPlanReviewers(plan, "alice"))
+	assert.ErrorIs(t, CheckTicketPlanReviewers(plan, "bob"), ErrTicketPlanSelfReview)
+
+	plan.Reviewers = pq.StringArray{"alice"}
+	assert.ErrorIs(t, CheckTicketPlanReviewers(plan, "bob"), ErrTicketPlanSelfReview)
+}
+
+func TestReviewOwnTicketPlan(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	plan := TicketPlan{
+		UUID:              uuid.New(),
+		WorkspaceUuid:     uuid.New().String(),
+		Name:              "Self reviewed plan",
+		Status:            DraftPlan,
+		CreatedBy:         "alice",
+		Reviewers:         pq.StringArray{"alice"},
+		RequiredApprovals: 1,
+	}
+	_, err := TestDB.CreateOrEditTicketPlan(&plan)
+	assert.NoError(t, err)
+	defer TestDB.db.Where("uuid = ?", plan.UUID).Delete(&TicketPlan{})
+
+	_, err = TestDB.SubmitTicketPlan(plan.UUID, "alice")
+	assert.ErrorIs(t, err, ErrTicketPlanSelfReview)
+
+	// a plan that got into review with its creator as reviewer still can't be
+	// approved by them
+	TestDB.db.Model(&TicketPlan{}).Where("uuid = ?", plan.UUID).Update("status", PendingReviewPlan)
+	reviewed, _, err := TestDB.ReviewTicketPlan(plan.UUID, "alice", PlanApprove, "")
+	assert.ErrorIs(t, err, ErrTicketPlanSelfReview)
+	assert.NotEqual(t, ApprovedPlan, reviewed.Status)
+
+	approvals, err := TestDB.GetTicketPlanApprovals(plan.UUID)
+	assert.NoError(t, err)
+	assert.Empty(t, approvals)
+}
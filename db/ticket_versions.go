@@ -0,0 +1,225 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTicketVersionNotFound = errors.New("ticket version not found")
+
+// ticketGroupOf returns the group a ticket's versions are kept under, tickets
+// without a group are their own group
+func ticketGroupOf(ticket Tickets) uuid.UUID {
+	if ticket.TicketGroup != nil && *ticket.TicketGroup != uuid.Nil {
+		return *ticket.TicketGroup
+	}
+	return ticket.UUID
+}
+
+func ticketVersionFrom(ticket Tickets) TicketVersion {
+	createdAt := ticket.UpdatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return TicketVersion{
+		ID:          uuid.New(),
+		TicketGroup: ticketGroupOf(ticket),
+		TicketUUID:  ticket.UUID,
+		Version:     ticket.Version,
+		Name:        ticket.Name,
+		Description: ticket.Description,
+		Status:      ticket.Status,
+		Sequence:    ticket.Sequence,
+		Amount:      ticket.Amount,
+		Category:    ticket.Category,
+		Author:      ticket.Author,
+		AuthorID:    ticket.AuthorID,
+		CreatedAt:   createdAt,
+	}
+}
+
+// recordTicketVersion stores the ticket content under its version, saving the
+// same version twice keeps the latest content
+func (db database) recordTicketVersion(ticket Tickets) {
+	if err := saveTicketVersion(db.db, ticket); err != nil {
+		logger.Log.Error("[tickets] failed to record version %d of ticket %s: %v", ticket.Version, ticket.UUID, err)
+	}
+}
+
+func saveTicketVersion(tx *gorm.DB, ticket Tickets) error {
+	version := ticketVersionFrom(ticket)
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "ticket_group"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"ticket_uuid", "name", "description", "status", "sequence",
+			"amount", "category", "author", "author_id", "created_at",
+		}),
+	}).Create(&version).Error
+}
+
+// keepTicketVersion records the current version of a ticket unless it already
+// is, so it stays in the history when the ticket is changed in place
+func keepTicketVersion(tx *gorm.DB, ticket Tickets) error {
+	version := ticketVersionFrom(ticket)
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ticket_group"}, {Name: "version"}},
+		DoNothing: true,
+	}).Create(&version).Error
+}
+
+// nextTicketVersion returns the version after the highest one of the ticket's
+// group, counting both ticket rows and recorded versions
+func nextTicketVersion(tx *gorm.DB, ticket Tickets) (int, error) {
+	group := ticketGroupOf(ticket)
+
+	var rowVersion, recordedVersion int
+	if err := tx.Model(&Tickets{}).Where("ticket_group = ? OR uuid = ?", group, group).
+		Select("COALESCE(MAX(version), 0)").Scan(&rowVersion).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch latest ticket version: %w", err)
+	}
+	if err := tx.Model(&TicketVersion{}).Where("ticket_group = ?", group).
+		Select("COALESCE(MAX(version), 0)").Scan(&recordedVersion).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch latest recorded version: %w", err)
+	}
+
+	if recordedVersion > rowVersion {
+		return recordedVersion + 1, nil
+	}
+	return rowVersion + 1, nil
+}
+
+// GetTicketVersions lists every version of a ticket group, oldest first.
+// Ticket rows written before versions were recorded are included as well.
+func (db database) GetTicketVersions(ticketGroup uuid.UUID) ([]TicketVersion, error) {
+	var versions []TicketVersion
+	if err := db.db.Where("ticket_group = ?", ticketGroup).Order("version ASC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket versions: %w", err)
+	}
+
+	var tickets []Tickets
+	if err := db.db.Where("ticket_group = ? OR uuid = ?", ticketGroup, ticketGroup).Find(&tickets).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tickets by group: %w", err)
+	}
+
+	recorded := make(map[int]bool, len(versions))
+	for _, version := range versions {
+		recorded[version.Version] = true
+	}
+	for _, ticket := range tickets {
+		if ticketGroupOf(ticket) != ticketGroup || recorded[ticket.Version] {
+			continue
+		}
+		recorded[ticket.Version] = true
+		versions = append(versions, ticketVersionFrom(ticket))
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	return versions, nil
+}
+
+func (db database) GetTicketVersion(ticketGroup uuid.UUID, version int) (TicketVersion, error) {
+	versions, err := db.GetTicketVersions(ticketGroup)
+	if err != nil {
+		return TicketVersion{}, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return TicketVersion{}, fmt.Errorf("%w: version %d of group %s", ErrTicketVersionNotFound, version, ticketGroup)
+}
+
+func DiffTicketVersions(from TicketVersion, to TicketVersion) TicketVersionDiff {
+	diff := utils.LineDiff(from.Description, to.Description)
+	return TicketVersionDiff{
+		TicketGroup:     to.TicketGroup,
+		From:            from,
+		To:              to,
+		NameChanged:     from.Name != to.Name,
+		StatusChanged:   from.Status != to.Status,
+		DescriptionDiff: diff,
+		Unified:         utils.UnifiedDiff(diff),
+	}
+}
+
+// RevertTicketVersion writes the content of an old version as a new version on
+// top of the latest ticket. Workflow fields such as status and sequence are
+// kept from the latest ticket.
+func (db database) RevertTicketVersion(ticketGroup uuid.UUID, version int, author Author, authorID string) (Tickets, error) {
+	target, err := db.GetTicketVersion(ticketGroup, version)
+	if err != nil {
+		return Tickets{}, err
+	}
+
+	versions, err := db.GetTicketVersions(ticketGroup)
+	if err != nil {
+		return Tickets{}, err
+	}
+	nextVersion := versions[len(versions)-1].Version + 1
+
+	var latest Tickets
+	result := db.db.Where("ticket_group = ? OR uuid = ?", ticketGroup, ticketGroup).
+		Order("version DESC").
+		First(&latest)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return Tickets{}, fmt.Errorf("%w: group %s has no ticket", ErrTicketVersionNotFound, ticketGroup)
+		}
+		return Tickets{}, fmt.Errorf("failed to fetch latest ticket: %w", result.Error)
+	}
+
+	group := ticketGroup
+	reverted := Tickets{
+		UUID:          uuid.New(),
+		TicketGroup:   &group,
+		WorkspaceUuid: latest.WorkspaceUuid,
+		FeatureUUID:   latest.FeatureUUID,
+		PhaseUUID:     latest.PhaseUUID,
+		Name:          target.Name,
+		Sequence:      latest.Sequence,
+		Dependency:    latest.Dependency,
+		DependsOn:     latest.DependsOn,
+		Description:   target.Description,
+		Status:        latest.Status,
+		Version:       nextVersion,
+		Author:        &author,
+		AuthorID:      &authorID,
+		Amount:        target.Amount,
+		Category:      target.Category,
+	}
+
+	if latest.FeatureUUID == "" {
+		// workspace drafts have no feature and are versioned in place
+		reverted.UUID = latest.UUID
+		reverted.TicketGroup = latest.TicketGroup
+		reverted.UpdatedAt = time.Now()
+		if err := db.db.Model(&latest).Omit(clause.Associations).Updates(map[string]interface{}{
+			"name":        reverted.Name,
+			"description": reverted.Description,
+			"amount":      reverted.Amount,
+			"category":    reverted.Category,
+			"version":     reverted.Version,
+			"author":      reverted.Author,
+			"author_id":   reverted.AuthorID,
+			"updated_at":  reverted.UpdatedAt,
+		}).Error; err != nil {
+			return Tickets{}, fmt.Errorf("failed to revert ticket: %w", err)
+		}
+		db.recordTicketVersion(reverted)
+		return db.GetTicket(latest.UUID.String())
+	}
+
+	return db.CreateOrEditTicket(&reverted)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTicketVersions(t *testing.T) {
+	group := uuid.New()
+	ticket := Tickets{UUID: uuid.New(), Name: "ticket", Description: "first\nsecond", Status: DraftTicket, Version: 1}
+
+	t.Run("Should keep versions of ungrouped tickets under the ticket UUID", func(t *testing.T) {
+		assert.Equal(t, ticket.UUID, ticketVersionFrom(ticket).TicketGroup)
+
+		grouped := ticket
+		grouped.TicketGroup = &group
+		assert.Equal(t, group, ticketVersionFrom(grouped).TicketGroup)
+	})
+
+	t.Run("Should diff descriptions line by line", func(t *testing.T) {
+		from := ticketVersionFrom(ticket)
+		to := from
+		to.Version = 2
+		to.Description = "first\nthird"
+		to.Status = ReadyTicket
+
+		diff := DiffTicketVersions(from, to)
+		assert.False(t, diff.NameChanged)
+		assert.True(t, diff.StatusChanged)
+		assert.Equal(t, []utils.DiffLine{
+			{Op: utils.DiffEqual, Text: "first"},
+			{Op: utils.DiffDelete, Text: "second"},
+			{Op: utils.DiffInsert, Text: "third"},
+		}, diff.DescriptionDiff)
+	})
+}
+
+func TestTicketVersionHistory(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{Uuid: uuid.New().String(), Name: "Version Workspace", OwnerPubKey: "version_owner"}
+	TestDB.db.Create(&workspace)
+	feature := WorkspaceFeatures{Uuid: uuid.New().String(), WorkspaceUuid: workspace.Uuid, Name: "Version Feature"}
+	TestDB.CreateOrEditFeature(feature)
+
+	group := uuid.New()
+	human := HumanAuthor
+	agent := AgentAuthor
+	owner := "version_owner"
+	ticket := Tickets{
+		UUID:          group,
+		TicketGroup:   &group,
+		WorkspaceUuid: workspace.Uuid,
+		FeatureUUID:   feature.Uuid,
+		Name:          "Original",
+		Description:   "original description",
+		Version:       1,
+		Author:        &human,
+		AuthorID:      &owner,
+	}
+	_, err := TestDB.CreateOrEditTicket(&ticket)
+	assert.NoError(t, err)
+
+	review := ticket
+	review.UUID = uuid.New()
+	review.Description = "rewritten by review"
+	review.Version = 2
+	review.Author = &agent
+	review.AuthorID = nil
+	_, err = TestDB.CreateOrEditTicket(&review)
+	assert.NoError(t, err)
+
+	versions, err := TestDB.GetTicketVersions(group)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "original description", versions[0].Description)
+	assert.Equal(t, AgentAuthor, *versions[1].Author)
+
+	reverted, err := TestDB.RevertTicketVersion(group, 1, HumanAuthor, owner)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, reverted.Version)
+	assert.Equal(t, "original description", reverted.Description)
+
+	versions, _ = TestDB.GetTicketVersions(group)
+	assert.Len(t, versions, 3)
+
+	_, err = TestDB.RevertTicketVersion(group, 9, HumanAuthor, owner)
+	assert.ErrorIs(t, err, ErrTicketVersionNotFound)
+}
+
+func TestMoveBoardCardRecordsVersion(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{Uuid: uuid.New().String(), Name: "Board Version Workspace", OwnerPubKey: "board_owner"}
+	TestDB.db.Create(&workspace)
+	feature := WorkspaceFeatures{Uuid: uuid.New().String(), WorkspaceUuid: workspace.Uuid, Name: "Board Version Feature"}
+	TestDB.CreateOrEditFeature(feature)
+
+	group := uuid.New()
+	ticket := Tickets{
+		UUID:          group,
+		TicketGroup:   &group,
+		WorkspaceUuid: workspace.Uuid,
+		FeatureUUID:   feature.Uuid,
+		Name:          "Moved",
+		Status:        DraftTicket,
+		Version:       1,
+	}
+	_, err := TestDB.CreateOrEditTicket(&ticket)
+	assert.NoError(t, err)
+
+	result, err := TestDB.MoveBoardCard(workspace.Uuid, workspace.OwnerPubKey, BoardMove{
+		ItemType: BoardTicket,
+		ItemKey:  group.String(),
+		ToStatus: ReadyTicket,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Ticket.Version)
+
+	versions, err := TestDB.GetTicketVersions(group)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, DraftTicket, versions[0].Status, "the version the card left keeps its status")
+	assert.Equal(t, ReadyTicket, versions[1].Status)
+}
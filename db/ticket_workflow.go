@@ -0,0 +1,222 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// TicketTransitionError explains why a ticket can not move to a status
+type TicketTransitionError struct {
+	From          TicketStatus `json:"from"`
+	To            TicketStatus `json:"to"`
+	Reason        string       `json:"reason"`
+	RequiredRole  string       `json:"required_role,omitempty"`
+	MissingFields []string     `json:"missing_fields,omitempty"`
+	Allowed       []string     `json:"allowed,omitempty"`
+}
+
+func (e *TicketTransitionError) Error() string {
+	return fmt.Sprintf("ticket can not move from %s to %s: %s", e.From, e.To, e.Reason)
+}
+
+var ticketWorkflowFields = map[string]func(ticket Tickets) bool{
+	"name":        func(t Tickets) bool { return strings.TrimSpace(t.Name) != "" },
+	"description": func(t Tickets) bool { return strings.TrimSpace(t.Description) != "" },
+	"amount":      func(t Tickets) bool { return t.Amount != nil && *t.Amount > 0 },
+	"category":    func(t Tickets) bool { return t.Category != nil && *t.Category != "" },
+	"phase_uuid":  func(t Tickets) bool { return t.PhaseUUID != "" },
+}
+
+// DefaultTicketWorkflow is the DRAFT to COMPLETED flow offered to workspaces
+// that have not configured their own rules, each step can also go back one
+func DefaultTicketWorkflow() []TicketWorkflowRule {
+	flow := []TicketStatus{DraftTicket, ReadyTicket, InProgressTicket, TestTicket, DeployTicket, PayTicket, CompletedTicket}
+	rules := []TicketWorkflowRule{}
+	for i := 0; i < len(flow)-1; i++ {
+		forward := TicketWorkflowRule{FromStatus: flow[i], ToStatus: flow[i+1], RequiredFields: pq.StringArray{}}
+		switch flow[i+1] {
+		case ReadyTicket:
+			forward.RequiredFields = pq.StringArray{"name", "description"}
+		case PayTicket:
+			forward.RequiredRole = PayBounty
+			forward.RequiredFields = pq.StringArray{"amount"}
+			forward.Action = TicketActionCreateBounty
+		case CompletedTicket:
+			forward.RequiredRole = PayBounty
+		}
+		rules = append(rules, forward)
+		if flow[i+1] != CompletedTicket {
+			rules = append(rules, TicketWorkflowRule{FromStatus: flow[i+1], ToStatus: flow[i], RequiredFields: pq.StringArray{}})
+		}
+	}
+	return rules
+}
+
+func ValidateTicketWorkflowRules(rules []TicketWorkflowRule) error {
+	seen := map[string]bool{}
+	for _, rule := range rules {
+		if !IsValidTicketStatus(rule.FromStatus) || !IsValidTicketStatus(rule.ToStatus) {
+			return fmt.Errorf("invalid transition %s -> %s", rule.FromStatus, rule.ToStatus)
+		}
+		if rule.FromStatus == rule.ToStatus {
+			return fmt.Errorf("transition %s -> %s does not change the status", rule.FromStatus, rule.ToStatus)
+		}
+		key := string(rule.FromStatus) + "->" + string(rule.ToStatus)
+		if seen[key] {
+			return fmt.Errorf("transition %s is defined twice", key)
+		}
+		seen[key] = true
+
+		if rule.RequiredRole != "" {
+			known := false
+			for _, role := range ConfigBountyRoles {
+				if role.Name == rule.RequiredRole {
+					known = true
+				}
+			}
+			if !known {
+				return fmt.Errorf("unknown role %q", rule.RequiredRole)
+			}
+		}
+		for _, field := range rule.RequiredFields {
+			if _, ok := ticketWorkflowFields[field]; !ok {
+				return fmt.Errorf("unknown required field %q", field)
+			}
+		}
+		if rule.Action != "" && rule.Action != TicketActionCreateBounty {
+			return fmt.Errorf("unknown action %q", rule.Action)
+		}
+	}
+	return nil
+}
+
+// EvaluateTicketTransition returns the rule allowing the ticket to move from
+// the status, or a TicketTransitionError. Without rules every move is allowed.
+func EvaluateTicketTransition(rules []TicketWorkflowRule, from TicketStatus, ticket Tickets, hasRole func(role string) bool) (*TicketWorkflowRule, error) {
+	to := ticket.Status
+	if len(rules) == 0 || to == "" || from == to {
+		return nil, nil
+	}
+
+	var rule *TicketWorkflowRule
+	var allowed []string
+	for i := range rules {
+		if rules[i].FromStatus != from {
+			continue
+		}
+		allowed = append(allowed, string(rules[i].ToStatus))
+		if rules[i].ToStatus == to {
+			rule = &rules[i]
+		}
+	}
+	if rule == nil {
+		reason := "the workspace workflow does not allow this transition"
+		if len(allowed) == 0 {
+			reason = fmt.Sprintf("tickets in %s can not change status", from)
+		}
+		return nil, &TicketTransitionError{From: from, To: to, Reason: reason, Allowed: allowed}
+	}
+
+	if rule.RequiredRole != "" && !hasRole(rule.RequiredRole) {
+		return nil, &TicketTransitionError{
+			From:         from,
+			To:           to,
+			Reason:       fmt.Sprintf("the %s role is required", rule.RequiredRole),
+			RequiredRole: rule.RequiredRole,
+		}
+	}
+
+	var missing []string
+	for _, field := range rule.RequiredFields {
+		if check, ok := ticketWorkflowFields[field]; ok && !check(ticket) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &TicketTransitionError{
+			From:          from,
+			To:            to,
+			Reason:        fmt.Sprintf("required fields are missing: %s", strings.Join(missing, ", ")),
+			MissingFields: missing,
+		}
+	}
+
+	return rule, nil
+}
+
+func (db database) GetTicketWorkflowRules(workspaceUuid string) ([]TicketWorkflowRule, error) {
+	var rules []TicketWorkflowRule
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket workflow: %w", err)
+	}
+	return rules, nil
+}
+
+// SetTicketWorkflowRules replaces the workflow of a workspace, an empty list
+// removes it
+func (db database) SetTicketWorkflowRules(workspaceUuid string, rules []TicketWorkflowRule) ([]TicketWorkflowRule, error) {
+	if err := ValidateTicketWorkflowRules(rules); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_uuid = ?", workspaceUuid).Delete(&TicketWorkflowRule{}).Error; err != nil {
+			return err
+		}
+		for i := range rules {
+			rules[i].ID = 0
+			rules[i].WorkspaceUuid = workspaceUuid
+			rules[i].CreatedAt = now
+			rules[i].UpdatedAt = now
+			if rules[i].RequiredFields == nil {
+				rules[i].RequiredFields = pq.StringArray{}
+			}
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save ticket workflow: %w", err)
+	}
+
+	return rules, nil
+}
+
+// CheckTicketTransition evaluates the workflow of the ticket's workspace for
+// the pubkey moving it. Workspace owners hold every role.
+func (db database) CheckTicketTransition(pubkey string, from TicketStatus, ticket Tickets) (*TicketWorkflowRule, error) {
+	if ticket.Status == "" || ticket.Status == from {
+		return nil, nil
+	}
+
+	workspaceUuid := ticket.WorkspaceUuid
+	if workspaceUuid == "" && ticket.FeatureUUID != "" {
+		workspaceUuid = db.GetFeatureByUuid(ticket.FeatureUUID).WorkspaceUuid
+	}
+	if workspaceUuid == "" {
+		return nil, nil
+	}
+
+	rules, err := db.GetTicketWorkflowRules(workspaceUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateTicketTransition(rules, from, ticket, func(role string) bool {
+		return db.UserHasAccess(pubkey, workspaceUuid, role)
+	})
+}
+
+func IsTicketTransitionError(err error) (*TicketTransitionError, bool) {
+	var transitionErr *TicketTransitionError
+	ok := errors.As(err, &transitionErr)
+	return transitionErr, ok
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateTicketTransition(t *testing.T) {
+	rules := DefaultTicketWorkflow()
+	assert.NoError(t, ValidateTicketWorkflowRules(rules))
+
+	amount := int64(1000)
+	ticket := Tickets{Name: "ticket", Description: "description"}
+	noRoles := func(string) bool { return false }
+	allRoles := func(string) bool { return true }
+
+	t.Run("Should accept any transition without rules", func(t *testing.T) {
+		moved := ticket
+		moved.Status = CompletedTicket
+		rule, err := EvaluateTicketTransition(nil, DraftTicket, moved, noRoles)
+		assert.NoError(t, err)
+		assert.Nil(t, rule)
+	})
+
+	t.Run("Should reject transitions the workflow does not define", func(t *testing.T) {
+		moved := ticket
+		moved.Status = DeployTicket
+		_, err := EvaluateTicketTransition(rules, DraftTicket, moved, allRoles)
+		transitionErr, ok := IsTicketTransitionError(err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{string(ReadyTicket)}, transitionErr.Allowed)
+	})
+
+	t.Run("Should explain missing roles and fields", func(t *testing.T) {
+		moved := ticket
+		moved.Status = PayTicket
+
+		_, err := EvaluateTicketTransition(rules, DeployTicket, moved, noRoles)
+		transitionErr, _ := IsTicketTransitionError(err)
+		assert.Equal(t, PayBounty, transitionErr.RequiredRole)
+
+		_, err = EvaluateTicketTransition(rules, DeployTicket, moved, allRoles)
+		transitionErr, _ = IsTicketTransitionError(err)
+		assert.Equal(t, []string{"amount"}, transitionErr.MissingFields)
+		assert.Contains(t, err.Error(), "DEPLOY to PAY")
+
+		moved.Amount = &amount
+		rule, err := EvaluateTicketTransition(rules, DeployTicket, moved, allRoles)
+		assert.NoError(t, err)
+		assert.Equal(t, TicketActionCreateBounty, rule.Action)
+	})
+
+	t.Run("Should validate configured rules", func(t *testing.T) {
+		assert.Error(t, ValidateTicketWorkflowRules([]TicketWorkflowRule{{FromStatus: DraftTicket, ToStatus: "DONE"}}))
+		assert.Error(t, ValidateTicketWorkflowRules([]TicketWorkflowRule{{FromStatus: DraftTicket, ToStatus: ReadyTicket, RequiredRole: "ADMIN"}}))
+		assert.Error(t, ValidateTicketWorkflowRules([]TicketWorkflowRule{{FromStatus: DraftTicket, ToStatus: ReadyTicket, RequiredFields: pq.StringArray{"owner"}}}))
+		assert.Error(t, ValidateTicketWorkflowRules([]TicketWorkflowRule{
+			{FromStatus: DraftTicket, ToStatus: ReadyTicket},
+			{FromStatus: DraftTicket, ToStatus: ReadyTicket},
+		}))
+	})
+}
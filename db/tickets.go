@@ -25,6 +25,10 @@ func (db database) CreateOrEditTicket(ticket *Tickets) (Tickets, error) {
 		return Tickets{}, errors.New("invalid ticket status")
 	}
 
+	if err := checkTicketDependencies(db.db, *ticket); err != nil {
+		return Tickets{}, err
+	}
+
 	var existingTicket Tickets
 	result := db.db.Where("uuid = ?", ticket.UUID).First(&existingTicket)
 
@@ -41,6 +45,7 @@ func (db database) CreateOrEditTicket(ticket *Tickets) (Tickets, error) {
 		if err := db.db.Create(&ticket).Error; err != nil {
 			return Tickets{}, fmt.Errorf("failed to create ticket: %w", err)
 		}
+		db.recordTicketVersion(*ticket)
 		return *ticket, nil
 	}
 
@@ -56,6 +61,7 @@ func (db database) CreateOrEditTicket(ticket *Tickets) (Tickets, error) {
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&updatedTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch updated ticket: %w", err)
 	}
+	db.recordTicketVersion(updatedTicket)
 
 	return updatedTicket, nil
 }
@@ -94,6 +100,10 @@ func (db database) UpdateTicket(ticket Tickets) (Tickets, error) {
 		return Tickets{}, errors.New("invalid ticket status")
 	}
 
+	if err := checkTicketDependencies(db.db, ticket); err != nil {
+		return Tickets{}, err
+	}
+
 	var existingTicket Tickets
 	result := db.db.Where("uuid = ?", ticket.UUID).First(&existingTicket)
 
@@ -110,6 +120,7 @@ func (db database) UpdateTicket(ticket Tickets) (Tickets, error) {
 			if err := db.db.Create(&ticket).Error; err != nil {
 				return Tickets{}, fmt.Errorf("failed to create ticket: %w", err)
 			}
+			db.recordTicketVersion(ticket)
 			return ticket, nil
 		}
 		return Tickets{}, fmt.Errorf("database error: %w", result.Error)
@@ -123,6 +134,7 @@ func (db database) UpdateTicket(ticket Tickets) (Tickets, error) {
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&updatedTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch updated ticket: %w", err)
 	}
+	db.recordTicketVersion(updatedTicket)
 
 	return updatedTicket, nil
 }
@@ -224,6 +236,8 @@ func (db database) CreateBountyFromTicket(ticket Tickets, pubkey string) (*NewBo
 		wantedType = string(*ticket.Category)
 	}
 
+	ticketGroup := ticketGroupOf(ticket)
+
 	bounty := &NewBounty{
 		Title:           ticket.Name,
 		Description:     ticket.Description,
@@ -238,6 +252,7 @@ func (db database) CreateBountyFromTicket(ticket Tickets, pubkey string) (*NewBo
 		Updated:         &now,
 		Show:            true,
 		CodingLanguages: pq.StringArray{},
+		TicketGroup:     &ticketGroup,
 	}
 
 	if err := db.db.Create(bounty).Error; err != nil {
@@ -245,6 +260,20 @@ func (db database) CreateBountyFromTicket(ticket Tickets, pubkey string) (*NewBo
 		return nil, fmt.Errorf("failed to create bounty: %w", err)
 	}
 
+	if err := db.copyTicketCriterionLinks(ticket, *bounty); err != nil {
+		logger.Log.Error("failed to link bounty %d to acceptance criteria: %v", bounty.ID, err)
+	}
+
+	return bounty, nil
+}
+
+// GetBountyByTicketGroup returns the bounty created from a ticket, with a zero
+// ID when there is none
+func (db database) GetBountyByTicketGroup(ticketGroup uuid.UUID) (NewBounty, error) {
+	var bounty NewBounty
+	if err := db.db.Where("ticket_group = ?", ticketGroup).Order("id ASC").Limit(1).Find(&bounty).Error; err != nil {
+		return NewBounty{}, fmt.Errorf("failed to get bounty of ticket group %s: %w", ticketGroup, err)
+	}
 	return bounty, nil
 }
 
@@ -321,6 +350,7 @@ func (db database) CreateWorkspaceDraftTicket(ticket *Tickets) (Tickets, error)
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&createdTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch created ticket: %w", err)
 	}
+	db.recordTicketVersion(createdTicket)
 
 	return createdTicket, nil
 }
@@ -337,15 +367,21 @@ func (db database) UpdateWorkspaceDraftTicket(ticket *Tickets) (Tickets, error)
 	ticket.UpdatedAt = time.Now()
 	ticket.Version = existingTicket.Version + 1
 
+	updates := map[string]interface{}{
+		"name":        ticket.Name,
+		"description": ticket.Description,
+		"status":      ticket.Status,
+		"updated_at":  ticket.UpdatedAt,
+		"version":     ticket.Version,
+	}
+	if ticket.Author != nil {
+		updates["author"] = ticket.Author
+		updates["author_id"] = ticket.AuthorID
+	}
+
 	if err := db.db.Model(&existingTicket).
 		Omit("Features", "FeaturePhase").
-		Updates(map[string]interface{}{
-			"name":        ticket.Name,
-			"description": ticket.Description,
-			"status":      ticket.Status,
-			"updated_at":  ticket.UpdatedAt,
-			"version":     ticket.Version,
-		}).Error; err != nil {
+		Updates(updates).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to update draft ticket: %w", err)
 	}
 
@@ -353,6 +389,7 @@ func (db database) UpdateWorkspaceDraftTicket(ticket *Tickets) (Tickets, error)
 	if err := db.db.Where("uuid = ?", ticket.UUID).First(&updatedTicket).Error; err != nil {
 		return Tickets{}, fmt.Errorf("failed to fetch updated ticket: %w", err)
 	}
+	db.recordTicketVersion(updatedTicket)
 
 	return updatedTicket, nil
 }
@@ -0,0 +1,515 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTrashParentMissing = errors.New("the parent of this item no longer exists, restore it first")
+	ErrTrashConflict      = errors.New("a record with the same identifier already exists")
+)
+
+func trashRetention() time.Duration {
+	days := config.TrashRetentionDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// toSnapshotValue converts a record to plain JSON values so it can be stored in a PropertyMap
+func toSnapshotValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func snapshotInto(snapshot PropertyMap, key string, out interface{}) error {
+	value, ok := snapshot[key]
+	if !ok {
+		return fmt.Errorf("trash snapshot has no %s", key)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func createTrashItem(tx *gorm.DB, item TrashItem, records map[string]interface{}) error {
+	snapshot := PropertyMap{}
+	for key, record := range records {
+		value, err := toSnapshotValue(record)
+		if err != nil {
+			return err
+		}
+		snapshot[key] = value
+	}
+
+	now := time.Now()
+	item.ID = uuid.New()
+	item.Snapshot = snapshot
+	item.TrashedAt = now
+	item.PurgeAfter = now.Add(trashRetention())
+	return tx.Create(&item).Error
+}
+
+// TrashWorkspace soft deletes a workspace like ProcessDeleteWorkspace, keeping
+// the cleared fields, members and roles so the workspace can be restored
+func (db database) TrashWorkspace(workspaceUuid string, deletedBy string) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		var workspace Workspace
+		if err := tx.Where("uuid = ?", workspaceUuid).First(&workspace).Error; err != nil {
+			return err
+		}
+
+		var users []WorkspaceUsers
+		if err := tx.Where("workspace_uuid = ?", workspaceUuid).Find(&users).Error; err != nil {
+			return err
+		}
+		var roles []WorkspaceUserRoles
+		if err := tx.Where("workspace_uuid = ?", workspaceUuid).Find(&roles).Error; err != nil {
+			return err
+		}
+
+		if err := createTrashItem(tx, TrashItem{
+			WorkspaceUuid: workspaceUuid,
+			EntityType:    TrashWorkspace,
+			EntityID:      workspaceUuid,
+			Name:          workspace.Name,
+			DeletedBy:     deletedBy,
+		}, map[string]interface{}{
+			"workspace": workspace,
+			"users":     users,
+			"roles":     roles,
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&Workspace{}).Where("uuid = ?", workspaceUuid).Updates(map[string]interface{}{
+			"website":     "",
+			"github":      "",
+			"description": "",
+			"show":        false,
+			"deleted":     true,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_uuid = ?", workspaceUuid).Delete(&WorkspaceUsers{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("workspace_uuid = ?", workspaceUuid).Delete(&WorkspaceUserRoles{}).Error
+	})
+}
+
+func (db database) TrashFeature(featureUuid string, deletedBy string) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		var feature WorkspaceFeatures
+		if err := tx.Where("uuid = ?", featureUuid).First(&feature).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("no feature found to delete")
+			}
+			return err
+		}
+
+		links, err := unlinkTrashed(tx, "feature_uuid", feature.Uuid)
+		if err != nil {
+			return err
+		}
+
+		if err := createTrashItem(tx, TrashItem{
+			WorkspaceUuid: feature.WorkspaceUuid,
+			EntityType:    TrashFeature,
+			EntityID:      feature.Uuid,
+			Name:          feature.Name,
+			DeletedBy:     deletedBy,
+		}, map[string]interface{}{
+			"feature": feature,
+			"links":   links,
+		}); err != nil {
+			return err
+		}
+
+		return tx.Where("uuid = ?", featureUuid).Delete(&WorkspaceFeatures{}).Error
+	})
+}
+
+func (db database) TrashFeaturePhase(featureUuid string, phaseUuid string, deletedBy string) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		var phase FeaturePhase
+		if err := tx.Where("feature_uuid = ? AND uuid = ?", featureUuid, phaseUuid).First(&phase).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("no phase found to delete")
+			}
+			return err
+		}
+
+		var feature WorkspaceFeatures
+		tx.Where("uuid = ?", featureUuid).Find(&feature)
+
+		links, err := unlinkTrashed(tx, "phase_uuid", phase.Uuid)
+		if err != nil {
+			return err
+		}
+
+		if err := createTrashItem(tx, TrashItem{
+			WorkspaceUuid: feature.WorkspaceUuid,
+			EntityType:    TrashPhase,
+			EntityID:      phase.Uuid,
+			Name:          phase.Name,
+			DeletedBy:     deletedBy,
+		}, map[string]interface{}{
+			"phase": phase,
+			"links": links,
+		}); err != nil {
+			return err
+		}
+
+		return tx.Where("feature_uuid = ? AND uuid = ?", featureUuid, phaseUuid).Delete(&FeaturePhase{}).Error
+	})
+}
+
+// trashLinks are the tickets and plans that pointed at a trashed feature or
+// phase, kept so that restoring it links them back
+type trashLinks struct {
+	Tickets      []string `json:"tickets"`
+	TicketGroups []string `json:"ticket_groups"`
+	Plans        []string `json:"plans"`
+}
+
+// unlinkTrashed clears column on the tickets and plans that point at value
+// and returns what it cleared
+func unlinkTrashed(tx *gorm.DB, column string, value string) (trashLinks, error) {
+	links := trashLinks{Tickets: []string{}, TicketGroups: []string{}, Plans: []string{}}
+
+	var tickets []Tickets
+	if err := tx.Select("uuid", "ticket_group").Where(column+" = ?", value).Find(&tickets).Error; err != nil {
+		return links, err
+	}
+	groups := map[string]bool{}
+	for _, ticket := range tickets {
+		links.Tickets = append(links.Tickets, ticket.UUID.String())
+		if ticket.TicketGroup != nil && !groups[ticket.TicketGroup.String()] {
+			groups[ticket.TicketGroup.String()] = true
+			links.TicketGroups = append(links.TicketGroups, ticket.TicketGroup.String())
+		}
+	}
+	if err := tx.Model(&TicketPlan{}).Where(column+" = ?", value).Pluck("uuid", &links.Plans).Error; err != nil {
+		return links, err
+	}
+
+	if err := tx.Model(&Tickets{}).Where(column+" = ?", value).Update(column, nil).Error; err != nil {
+		return links, err
+	}
+	return links, tx.Model(&TicketPlan{}).Where(column+" = ?", value).Update(column, nil).Error
+}
+
+// relinkTrashed points the tickets and plans unlinked by unlinkTrashed back
+// at value, including ticket versions created while it was in the trash.
+// Records linked elsewhere in the meantime are left alone.
+func relinkTrashed(tx *gorm.DB, snapshot PropertyMap, column string, value string) error {
+	if _, ok := snapshot["links"]; !ok {
+		return nil
+	}
+	var links trashLinks
+	if err := snapshotInto(snapshot, "links", &links); err != nil {
+		return err
+	}
+
+	if len(links.Tickets) > 0 || len(links.TicketGroups) > 0 {
+		if err := tx.Model(&Tickets{}).
+			Where(column+" IS NULL AND (uuid IN ? OR ticket_group IN ?)", nonEmpty(links.Tickets), nonEmpty(links.TicketGroups)).
+			Update(column, value).Error; err != nil {
+			return err
+		}
+	}
+	if len(links.Plans) > 0 {
+		return tx.Model(&TicketPlan{}).Where(column+" IS NULL AND uuid IN ?", links.Plans).Update(column, value).Error
+	}
+	return nil
+}
+
+// nonEmpty keeps an IN clause valid for uuid columns when ids is empty
+func nonEmpty(ids []string) []string {
+	if len(ids) == 0 {
+		return []string{uuid.Nil.String()}
+	}
+	return ids
+}
+
+// TrashTicketGroup moves every version of a ticket to the trash
+func (db database) TrashTicketGroup(ticketGroupUUID uuid.UUID, deletedBy string) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		return db.trashTicketGroup(tx, ticketGroupUUID, deletedBy)
+	})
+}
+
+func (db database) trashTicketGroup(tx *gorm.DB, ticketGroupUUID uuid.UUID, deletedBy string) error {
+	locked, err := db.ticketGroupLocked(tx, ticketGroupUUID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrTicketGroupLocked
+	}
+
+	var tickets []Tickets
+	if err := tx.Where("ticket_group = ?", ticketGroupUUID).Order("version DESC").Find(&tickets).Error; err != nil {
+		return err
+	}
+	if len(tickets) == 0 {
+		return errors.New("no tickets found in group")
+	}
+
+	latest := tickets[0]
+	if err := createTrashItem(tx, TrashItem{
+		WorkspaceUuid: latest.WorkspaceUuid,
+		EntityType:    TrashTicket,
+		EntityID:      ticketGroupUUID.String(),
+		Name:          latest.Name,
+		DeletedBy:     deletedBy,
+	}, map[string]interface{}{
+		"tickets": tickets,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Where("ticket_group = ?", ticketGroupUUID).Delete(&Tickets{}).Error
+}
+
+func (db database) TrashBounty(pubkey string, created string, deletedBy string) (NewBounty, error) {
+	var bounty NewBounty
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_id = ? AND created = ?", pubkey, created).First(&bounty).Error; err != nil {
+			return err
+		}
+
+		if err := createTrashItem(tx, TrashItem{
+			WorkspaceUuid: bounty.WorkspaceUuid,
+			EntityType:    TrashBounty,
+			EntityID:      fmt.Sprint(bounty.ID),
+			Name:          bounty.Title,
+			DeletedBy:     deletedBy,
+		}, map[string]interface{}{
+			"bounty": bounty,
+		}); err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", bounty.ID).Delete(&NewBounty{}).Error
+	})
+	if err != nil {
+		return NewBounty{}, err
+	}
+
+	return bounty, nil
+}
+
+func (db database) GetTrashByWorkspace(workspaceUuid string) ([]TrashItem, error) {
+	var items []TrashItem
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).Order("trashed_at DESC").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trash for workspace %s: %w", workspaceUuid, err)
+	}
+	return items, nil
+}
+
+func (db database) GetTrashItemByID(id uuid.UUID) (*TrashItem, error) {
+	var item TrashItem
+	if err := db.db.Where("id = ?", id).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("trash item with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get trash item: %w", err)
+	}
+	return &item, nil
+}
+
+// RestoreTrashItem puts the records back as they were when deleted and
+// removes the item from the trash
+func (db database) RestoreTrashItem(id uuid.UUID) (*TrashItem, error) {
+	var item TrashItem
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&item).Error; err != nil {
+			return err
+		}
+
+		var err error
+		switch item.EntityType {
+		case TrashWorkspace:
+			err = restoreWorkspace(tx, item.Snapshot)
+		case TrashFeature:
+			err = restoreFeature(tx, item.Snapshot)
+		case TrashPhase:
+			err = restorePhase(tx, item.Snapshot)
+		case TrashTicket:
+			err = restoreTickets(tx, item.Snapshot)
+		case TrashBounty:
+			err = restoreBounty(tx, item.Snapshot)
+		default:
+			err = fmt.Errorf("unknown trash entity type %s", item.EntityType)
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&TrashItem{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func recordExists(tx *gorm.DB, model interface{}, query string, args ...interface{}) bool {
+	var count int64
+	tx.Model(model).Where(query, args...).Count(&count)
+	return count > 0
+}
+
+func restoreWorkspace(tx *gorm.DB, snapshot PropertyMap) error {
+	var workspace Workspace
+	var users []WorkspaceUsers
+	var roles []WorkspaceUserRoles
+	if err := snapshotInto(snapshot, "workspace", &workspace); err != nil {
+		return err
+	}
+	if err := snapshotInto(snapshot, "users", &users); err != nil {
+		return err
+	}
+	if err := snapshotInto(snapshot, "roles", &roles); err != nil {
+		return err
+	}
+
+	if !recordExists(tx, &Workspace{}, "uuid = ?", workspace.Uuid) {
+		return ErrTrashParentMissing
+	}
+
+	if err := tx.Model(&Workspace{}).Where("uuid = ?", workspace.Uuid).Updates(map[string]interface{}{
+		"website":     workspace.Website,
+		"github":      workspace.Github,
+		"description": workspace.Description,
+		"show":        workspace.Show,
+		"deleted":     false,
+	}).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if recordExists(tx, &WorkspaceUsers{}, "workspace_uuid = ? AND owner_pub_key = ?", user.WorkspaceUuid, user.OwnerPubKey) {
+			continue
+		}
+		user.ID = 0
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, role := range roles {
+		if recordExists(tx, &WorkspaceUserRoles{}, "workspace_uuid = ? AND owner_pub_key = ? AND role = ?", role.WorkspaceUuid, role.OwnerPubKey, role.Role) {
+			continue
+		}
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func restoreFeature(tx *gorm.DB, snapshot PropertyMap) error {
+	var feature WorkspaceFeatures
+	if err := snapshotInto(snapshot, "feature", &feature); err != nil {
+		return err
+	}
+
+	if !recordExists(tx, &Workspace{}, "uuid = ? AND deleted = ?", feature.WorkspaceUuid, false) {
+		return ErrTrashParentMissing
+	}
+	if recordExists(tx, &WorkspaceFeatures{}, "uuid = ?", feature.Uuid) {
+		return ErrTrashConflict
+	}
+
+	if err := tx.Omit(clause.Associations).Create(&feature).Error; err != nil {
+		return err
+	}
+	return relinkTrashed(tx, snapshot, "feature_uuid", feature.Uuid)
+}
+
+func restorePhase(tx *gorm.DB, snapshot PropertyMap) error {
+	var phase FeaturePhase
+	if err := snapshotInto(snapshot, "phase", &phase); err != nil {
+		return err
+	}
+
+	if !recordExists(tx, &WorkspaceFeatures{}, "uuid = ?", phase.FeatureUuid) {
+		return ErrTrashParentMissing
+	}
+	if recordExists(tx, &FeaturePhase{}, "uuid = ?", phase.Uuid) {
+		return ErrTrashConflict
+	}
+
+	if err := tx.Create(&phase).Error; err != nil {
+		return err
+	}
+	return relinkTrashed(tx, snapshot, "phase_uuid", phase.Uuid)
+}
+
+func restoreTickets(tx *gorm.DB, snapshot PropertyMap) error {
+	var tickets []Tickets
+	if err := snapshotInto(snapshot, "tickets", &tickets); err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		if ticket.FeatureUUID != "" && !recordExists(tx, &WorkspaceFeatures{}, "uuid = ?", ticket.FeatureUUID) {
+			return ErrTrashParentMissing
+		}
+		if recordExists(tx, &Tickets{}, "uuid = ?", ticket.UUID) {
+			return ErrTrashConflict
+		}
+		if err := tx.Omit(clause.Associations).Create(&ticket).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func restoreBounty(tx *gorm.DB, snapshot PropertyMap) error {
+	var bounty NewBounty
+	if err := snapshotInto(snapshot, "bounty", &bounty); err != nil {
+		return err
+	}
+
+	if recordExists(tx, &NewBounty{}, "id = ?", bounty.ID) {
+		return ErrTrashConflict
+	}
+
+	return tx.Create(&bounty).Error
+}
+
+// PurgeExpiredTrash permanently drops trash items past their retention window
+func (db database) PurgeExpiredTrash() (int64, error) {
+	result := db.db.Where("purge_after <= ?", time.Now()).Delete(&TrashItem{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashSnapshot(t *testing.T) {
+	group := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+	ticket := Tickets{
+		UUID:          uuid.New(),
+		TicketGroup:   &group,
+		WorkspaceUuid: "workspace",
+		FeatureUUID:   "feature",
+		Name:          "ticket",
+		Status:        DraftTicket,
+		Version:       2,
+		CreatedAt:     now,
+	}
+
+	value, err := toSnapshotValue([]Tickets{ticket})
+	assert.NoError(t, err)
+
+	var restored []Tickets
+	assert.NoError(t, snapshotInto(PropertyMap{"tickets": value}, "tickets", &restored))
+	assert.Len(t, restored, 1)
+	assert.Equal(t, ticket.UUID, restored[0].UUID)
+	assert.Equal(t, group, *restored[0].TicketGroup)
+	assert.Equal(t, ticket.Version, restored[0].Version)
+	assert.True(t, now.Equal(restored[0].CreatedAt))
+
+	assert.Error(t, snapshotInto(PropertyMap{}, "tickets", &restored))
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{
+		Uuid:        uuid.New().String(),
+		Name:        "Trash Workspace",
+		OwnerPubKey: "trash_owner",
+		Description: "trash description",
+	}
+	TestDB.db.Create(&workspace)
+	TestDB.CreateWorkspaceUser(WorkspaceUsers{OwnerPubKey: "trash_member", WorkspaceUuid: workspace.Uuid})
+
+	feature := WorkspaceFeatures{
+		Uuid:          uuid.New().String(),
+		WorkspaceUuid: workspace.Uuid,
+		Name:          "Trash Feature",
+	}
+	TestDB.CreateOrEditFeature(feature)
+
+	phase := FeaturePhase{
+		Uuid:        uuid.New().String(),
+		FeatureUuid: feature.Uuid,
+		Name:        "Trash Phase",
+	}
+	TestDB.CreateOrEditFeaturePhase(phase)
+
+	t.Run("Should trash and restore a feature with its phases intact", func(t *testing.T) {
+		assert.NoError(t, TestDB.TrashFeature(feature.Uuid, "trash_owner"))
+		assert.Equal(t, uint(0), TestDB.GetFeatureByUuid(feature.Uuid).ID)
+
+		items, err := TestDB.GetTrashByWorkspace(workspace.Uuid)
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, TrashFeature, items[0].EntityType)
+		assert.True(t, items[0].PurgeAfter.After(time.Now()))
+
+		_, err = TestDB.RestoreTrashItem(items[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, feature.Name, TestDB.GetFeatureByUuid(feature.Uuid).Name)
+		assert.Len(t, TestDB.GetPhasesByFeatureUuid(feature.Uuid), 1)
+
+		items, _ = TestDB.GetTrashByWorkspace(workspace.Uuid)
+		assert.Len(t, items, 0)
+	})
+
+	t.Run("Should relink the tickets and plans of a restored feature and phase", func(t *testing.T) {
+		group := uuid.New()
+		ticket := Tickets{
+			UUID:          uuid.New(),
+			TicketGroup:   &group,
+			WorkspaceUuid: workspace.Uuid,
+			FeatureUUID:   feature.Uuid,
+			PhaseUUID:     phase.Uuid,
+			Name:          "Linked Ticket",
+		}
+		_, err := TestDB.CreateOrEditTicket(&ticket)
+		assert.NoError(t, err)
+		plan := TicketPlan{
+			UUID:          uuid.New(),
+			WorkspaceUuid: workspace.Uuid,
+			FeatureUUID:   feature.Uuid,
+			PhaseUUID:     phase.Uuid,
+			Name:          "Linked Plan",
+		}
+		_, err = TestDB.CreateOrEditTicketPlan(&plan)
+		assert.NoError(t, err)
+
+		assert.NoError(t, TestDB.TrashFeaturePhase(feature.Uuid, phase.Uuid, "trash_owner"))
+		assert.NoError(t, TestDB.TrashFeature(feature.Uuid, "trash_owner"))
+
+		unlinked, err := TestDB.GetTicket(ticket.UUID.String())
+		assert.NoError(t, err)
+		assert.Empty(t, unlinked.FeatureUUID)
+		assert.Empty(t, unlinked.PhaseUUID)
+
+		items, _ := TestDB.GetTrashByWorkspace(workspace.Uuid)
+		assert.Len(t, items, 2)
+		// the feature was trashed last, so it comes first
+		for _, item := range items {
+			_, err := TestDB.RestoreTrashItem(item.ID)
+			assert.NoError(t, err)
+		}
+
+		relinked, err := TestDB.GetTicket(ticket.UUID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, feature.Uuid, relinked.FeatureUUID)
+		assert.Equal(t, phase.Uuid, relinked.PhaseUUID)
+
+		relinkedPlan, err := TestDB.GetTicketPlan(plan.UUID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, feature.Uuid, relinkedPlan.FeatureUUID)
+		assert.Equal(t, phase.Uuid, relinkedPlan.PhaseUUID)
+	})
+
+	t.Run("Should not restore a phase whose feature is in the trash", func(t *testing.T) {
+		assert.NoError(t, TestDB.TrashFeaturePhase(feature.Uuid, phase.Uuid, "trash_owner"))
+		assert.NoError(t, TestDB.TrashFeature(feature.Uuid, "trash_owner"))
+
+		items, _ := TestDB.GetTrashByWorkspace(workspace.Uuid)
+		var phaseItem, featureItem TrashItem
+		for _, item := range items {
+			if item.EntityType == TrashPhase {
+				phaseItem = item
+			} else if item.EntityType == TrashFeature {
+				featureItem = item
+			}
+		}
+
+		_, err := TestDB.RestoreTrashItem(phaseItem.ID)
+		assert.ErrorIs(t, err, ErrTrashParentMissing)
+
+		_, err = TestDB.RestoreTrashItem(featureItem.ID)
+		assert.NoError(t, err)
+		_, err = TestDB.RestoreTrashItem(phaseItem.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should restore a workspace with its members", func(t *testing.T) {
+		assert.NoError(t, TestDB.TrashWorkspace(workspace.Uuid, "trash_owner"))
+		trashed := TestDB.GetWorkspaceByUuid(workspace.Uuid)
+		assert.True(t, trashed.Deleted)
+		assert.Equal(t, "", trashed.Description)
+
+		items, _ := TestDB.GetTrashByWorkspace(workspace.Uuid)
+		assert.Len(t, items, 1)
+		_, err := TestDB.RestoreTrashItem(items[0].ID)
+		assert.NoError(t, err)
+
+		restored := TestDB.GetWorkspaceByUuid(workspace.Uuid)
+		assert.False(t, restored.Deleted)
+		assert.Equal(t, "trash description", restored.Description)
+		assert.Equal(t, "trash_member", TestDB.GetWorkspaceUser("trash_member", workspace.Uuid).OwnerPubKey)
+	})
+
+	t.Run("Should purge only expired items", func(t *testing.T) {
+		assert.NoError(t, TestDB.TrashFeature(feature.Uuid, "trash_owner"))
+		items, _ := TestDB.GetTrashByWorkspace(workspace.Uuid)
+		assert.Len(t, items, 1)
+
+		purged, err := TestDB.PurgeExpiredTrash()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		TestDB.db.Model(&TrashItem{}).Where("id = ?", items[0].ID).Update("purge_after", time.Now().Add(-time.Hour))
+		purged, err = TestDB.PurgeExpiredTrash()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+	})
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryClaimed  = errors.New("webhook delivery is already being sent or waiting for its retry")
+)
+
+func (db database) CreateWebhookDelivery(endpoint, event, payload string, nextAttemptAt time.Time) (*WebhookDelivery, error) {
+	if endpoint == "" {
+		return nil, errors.New("webhook endpoint is required")
+	}
+
+	now := time.Now()
+	delivery := &WebhookDelivery{
+		ID:            uuid.New(),
+		Endpoint:      endpoint,
+		Event:         event,
+		Payload:       payload,
+		Status:        WebhookPending,
+		NextAttemptAt: &nextAttemptAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := db.db.Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// SaveWebhookAttempt logs an attempt and stores the state of the delivery
+// after it
+func (db database) SaveWebhookAttempt(delivery WebhookDelivery, attempt WebhookAttempt) error {
+	return db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return fmt.Errorf("failed to log webhook attempt: %w", err)
+		}
+		if err := tx.Model(&WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"delivered_at":     delivery.DeliveredAt,
+			"updated_at":       time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update webhook delivery: %w", err)
+		}
+		return nil
+	})
+}
+
+// ClaimDueWebhookDeliveries returns the pending deliveries due by now and
+// moves their next attempt lease into the future, so a delivery is only
+// picked up once while it is being sent
+func (db database) ClaimDueWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", WebhookPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return fmt.Errorf("failed to fetch due webhook deliveries: %w", err)
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		if err := tx.Model(&WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery takes the lease of one delivery so it can be sent right
+// away. Pending deliveries whose next attempt isn't due are refused, they are
+// being sent or will be retried. Delivered and dead ones get a fresh retry
+// budget on top of the attempts they already made.
+func (db database) ClaimWebhookDelivery(id uuid.UUID, now time.Time, lease time.Duration) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&delivery).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWebhookDeliveryNotFound
+			}
+			return fmt.Errorf("failed to fetch webhook delivery: %w", err)
+		}
+		if delivery.Status == WebhookPending && delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(now) {
+			return ErrWebhookDeliveryClaimed
+		}
+
+		if delivery.Status != WebhookPending {
+			delivery.AttemptBase = delivery.Attempts
+		}
+		next := now.Add(lease)
+		delivery.Status = WebhookPending
+		delivery.NextAttemptAt = &next
+		if err := tx.Model(&WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempt_base":    delivery.AttemptBase,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"updated_at":      now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to claim webhook delivery: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (db database) GetWebhookDeliveryByID(id uuid.UUID) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	if err := db.db.Where("id = ?", id).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries lists deliveries newest first, optionally of one
+// endpoint and status
+func (db database) GetWebhookDeliveries(endpoint string, status WebhookDeliveryStatus, limit int, offset int) ([]WebhookDelivery, int64, error) {
+	var deliveries []WebhookDelivery
+	var total int64
+
+	query := db.db.Model(&WebhookDelivery{})
+	if endpoint != "" {
+		query = query.Where("endpoint = ?", endpoint)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	if err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve webhook deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+func (db database) GetWebhookAttempts(deliveryID uuid.UUID) ([]WebhookAttempt, error) {
+	var attempts []WebhookAttempt
+	if err := db.db.Where("delivery_id = ?", deliveryID).Order("created_at ASC").Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve webhook attempts: %w", err)
+	}
+	return attempts, nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	InitTestDB()
+	TestDB.db.Exec("DELETE FROM webhook_attempts")
+	TestDB.db.Exec("DELETE FROM webhook_deliveries")
+	defer TestDB.db.Exec("DELETE FROM webhook_attempts")
+	defer TestDB.db.Exec("DELETE FROM webhook_deliveries")
+
+	_, err := TestDB.CreateWebhookDelivery("", "sse.events", "{}", time.Now())
+	assert.Error(t, err)
+
+	now := time.Now()
+	due, err := TestDB.CreateWebhookDelivery("https://target.com/webhook", "sse.events", "{}", now.Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = TestDB.CreateWebhookDelivery("https://target.com/webhook", "sse.events", "{}", now.Add(time.Hour))
+	require.NoError(t, err)
+
+	claimed, err := TestDB.ClaimDueWebhookDeliveries(now, 5*time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+
+	// claimed deliveries aren't due again until their lease ends
+	claimed, err = TestDB.ClaimDueWebhookDeliveries(now, 5*time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	due.Status = WebhookDead
+	due.Attempts = 1
+	due.LastStatusCode = 500
+	due.NextAttemptAt = nil
+	require.NoError(t, TestDB.SaveWebhookAttempt(*due, WebhookAttempt{
+		ID:         uuid.New(),
+		DeliveryID: due.ID,
+		Endpoint:   due.Endpoint,
+		Attempt:    1,
+		StatusCode: 500,
+		CreatedAt:  now,
+	}))
+
+	stored, err := TestDB.GetWebhookDeliveryByID(due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookDead, stored.Status)
+	assert.Nil(t, stored.NextAttemptAt)
+
+	attempts, err := TestDB.GetWebhookAttempts(due.ID)
+	require.NoError(t, err)
+	assert.Len(t, attempts, 1)
+
+	dead, total, err := TestDB.GetWebhookDeliveries("https://target.com/webhook", WebhookDead, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, dead, 1)
+
+	// a replay claims the dead delivery with a fresh retry budget, a second
+	// one is refused while the lease holds
+	replayed, err := TestDB.ClaimWebhookDelivery(due.ID, now, 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookPending, replayed.Status)
+	assert.Equal(t, 1, replayed.AttemptBase)
+	_, err = TestDB.ClaimWebhookDelivery(due.ID, now, 5*time.Minute)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryClaimed)
+	claimed, err = TestDB.ClaimDueWebhookDeliveries(now, 5*time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	_, err = TestDB.ClaimWebhookDelivery(uuid.New(), now, 5*time.Minute)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+
+	_, err = TestDB.GetWebhookDeliveryByID(uuid.New())
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+}
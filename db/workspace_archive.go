@@ -0,0 +1,557 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/xid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceArchiveVersion is bumped whenever the archive layout changes in a
+// way older importers can not read
+const WorkspaceArchiveVersion = 1
+
+// WorkspaceArchive is a portable copy of a workspace and everything that hangs
+// off it. Secrets such as code graph aliases and bounty unlock codes are left out.
+type WorkspaceArchive struct {
+	Version       int                       `json:"version"`
+	ExportedAt    time.Time                 `json:"exported_at"`
+	Workspace     Workspace                 `json:"workspace"`
+	Users         []WorkspaceUsers          `json:"users"`
+	Roles         []WorkspaceUserRoles      `json:"roles"`
+	Repositories  []WorkspaceRepositories   `json:"repositories"`
+	CodeGraphs    []WorkspaceCodeGraph      `json:"code_graphs"`
+	Features      []WorkspaceFeatures       `json:"features"`
+	Phases        []FeaturePhase            `json:"phases"`
+	Stories       []FeatureStory            `json:"stories"`
+	Criteria      []AcceptanceCriterion     `json:"acceptance_criteria"`
+	CriteriaLinks []AcceptanceCriterionLink `json:"criterion_links"`
+	Tickets       []Tickets                 `json:"tickets"`
+	TicketPlans   []TicketPlan              `json:"ticket_plans"`
+	Bounties      []NewBounty               `json:"bounties"`
+	Snippets      []TextSnippet             `json:"snippets"`
+	Activities    []Activity                `json:"activities"`
+}
+
+type ImportConflict struct {
+	Type     string `json:"type"`
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+	Blocking bool   `json:"blocking"`
+}
+
+type WorkspaceImportOptions struct {
+	// Name replaces the archived workspace name, which must be unique
+	Name string `json:"name"`
+	// PubkeyMap maps pubkeys from the source deployment to pubkeys on this one
+	PubkeyMap map[string]string `json:"pubkey_map"`
+	// DryRun reports the conflicts without writing anything
+	DryRun bool `json:"dry_run"`
+	// OwnerPubkey becomes the owner of the imported workspace
+	OwnerPubkey string `json:"-"`
+}
+
+var ErrWorkspaceImportConflict = errors.New("workspace import has blocking conflicts")
+
+func (db database) ExportWorkspace(workspaceUuid string) (WorkspaceArchive, error) {
+	archive := WorkspaceArchive{
+		Version:    WorkspaceArchiveVersion,
+		ExportedAt: time.Now(),
+	}
+
+	if err := db.db.Where("uuid = ?", workspaceUuid).First(&archive.Workspace).Error; err != nil {
+		return archive, fmt.Errorf("failed to get workspace %s: %w", workspaceUuid, err)
+	}
+
+	queries := []struct {
+		name  string
+		out   interface{}
+		query string
+	}{
+		{"users", &archive.Users, "workspace_uuid = ?"},
+		{"roles", &archive.Roles, "workspace_uuid = ?"},
+		{"repositories", &archive.Repositories, "workspace_uuid = ?"},
+		{"code graphs", &archive.CodeGraphs, "workspace_uuid = ?"},
+		{"features", &archive.Features, "workspace_uuid = ?"},
+		{"tickets", &archive.Tickets, "workspace_uuid = ?"},
+		{"ticket plans", &archive.TicketPlans, "workspace_uuid = ?"},
+		{"bounties", &archive.Bounties, "workspace_uuid = ?"},
+		{"snippets", &archive.Snippets, "workspace_uuid = ?"},
+		{"activities", &archive.Activities, "workspace = ?"},
+	}
+	for _, q := range queries {
+		if err := db.db.Where(q.query, workspaceUuid).Find(q.out).Error; err != nil {
+			return archive, fmt.Errorf("failed to export %s: %w", q.name, err)
+		}
+	}
+
+	featureUuids := make([]string, 0, len(archive.Features))
+	for _, feature := range archive.Features {
+		featureUuids = append(featureUuids, feature.Uuid)
+	}
+	if len(featureUuids) > 0 {
+		if err := db.db.Where("feature_uuid IN ?", featureUuids).Find(&archive.Phases).Error; err != nil {
+			return archive, fmt.Errorf("failed to export phases: %w", err)
+		}
+		if err := db.db.Where("feature_uuid IN ?", featureUuids).Find(&archive.Stories).Error; err != nil {
+			return archive, fmt.Errorf("failed to export stories: %w", err)
+		}
+		if err := db.db.Where("feature_uuid IN ?", featureUuids).Find(&archive.Criteria).Error; err != nil {
+			return archive, fmt.Errorf("failed to export acceptance criteria: %w", err)
+		}
+		if err := db.db.Where("feature_uuid IN ?", featureUuids).Find(&archive.CriteriaLinks).Error; err != nil {
+			return archive, fmt.Errorf("failed to export acceptance criterion links: %w", err)
+		}
+	}
+
+	for i := range archive.CodeGraphs {
+		archive.CodeGraphs[i].SecretAlias = ""
+	}
+	for i := range archive.Bounties {
+		archive.Bounties[i].UnlockCode = nil
+		archive.Bounties[i].Stakes = nil
+	}
+
+	return archive, nil
+}
+
+type archiveRemapper struct {
+	ids       map[string]string
+	pubkeys   map[string]string
+	importer  string
+	dropped   map[string]bool
+	conflicts []ImportConflict
+}
+
+// id returns the new identifier for an archived one, generating it on first use
+func (m *archiveRemapper) id(old string) string {
+	if old == "" {
+		return ""
+	}
+	if id, ok := m.ids[old]; ok {
+		return id
+	}
+	id := uuid.New().String()
+	m.ids[old] = id
+	return id
+}
+
+func (m *archiveRemapper) uuid(old uuid.UUID) uuid.UUID {
+	if old == uuid.Nil {
+		return old
+	}
+	return uuid.MustParse(m.id(old.String()))
+}
+
+// pubkey returns the mapped pubkey, or an empty one when the archived pubkey
+// is neither mapped nor the importer's own
+func (m *archiveRemapper) pubkey(old string) string {
+	if old == "" {
+		return ""
+	}
+	if pubkey, ok := m.pubkeys[old]; ok {
+		return pubkey
+	}
+	if old == m.importer {
+		return old
+	}
+	m.drop(old, "pubkey has no mapping, it is removed from the imported records")
+	return ""
+}
+
+func (m *archiveRemapper) drop(pubkey string, message string) {
+	if m.dropped[pubkey] {
+		return
+	}
+	m.dropped[pubkey] = true
+	m.conflicts = append(m.conflicts, ImportConflict{
+		Type:    "pubkey",
+		Key:     pubkey,
+		Message: message,
+	})
+}
+
+// RemapWorkspaceArchive gives every record in the archive a new identifier and
+// rewrites pubkeys through the mapping, so the archive can be imported next to
+// the workspace it came from. Only mapped pubkeys and the importer's own are
+// kept, the others are dropped and reported as conflicts. Bounties come in
+// unassigned and unpaid.
+func RemapWorkspaceArchive(archive WorkspaceArchive, options WorkspaceImportOptions, personExists func(pubkey string) bool, nameTaken func(name string) bool) (WorkspaceArchive, []ImportConflict) {
+	m := &archiveRemapper{
+		ids:      map[string]string{},
+		pubkeys:  map[string]string{},
+		importer: options.OwnerPubkey,
+		dropped:  map[string]bool{},
+	}
+
+	if archive.Version != WorkspaceArchiveVersion {
+		m.conflicts = append(m.conflicts, ImportConflict{
+			Type:     "version",
+			Key:      fmt.Sprint(archive.Version),
+			Message:  fmt.Sprintf("unsupported archive version, expected %d", WorkspaceArchiveVersion),
+			Blocking: true,
+		})
+		return archive, m.conflicts
+	}
+
+	for from, to := range options.PubkeyMap {
+		if to != "" && to != options.OwnerPubkey && !personExists(to) {
+			m.drop(from, fmt.Sprintf("pubkey is mapped to %s, which does not exist on this deployment, it is removed from the imported records", to))
+			to = ""
+		}
+		m.pubkeys[from] = to
+	}
+	if _, ok := m.pubkeys[archive.Workspace.OwnerPubKey]; !ok && options.OwnerPubkey != "" {
+		m.pubkeys[archive.Workspace.OwnerPubKey] = options.OwnerPubkey
+	}
+
+	// work on copies so the caller's archive is left untouched
+	archive.Repositories = append([]WorkspaceRepositories(nil), archive.Repositories...)
+	archive.CodeGraphs = append([]WorkspaceCodeGraph(nil), archive.CodeGraphs...)
+	archive.Features = append([]WorkspaceFeatures(nil), archive.Features...)
+	archive.Phases = append([]FeaturePhase(nil), archive.Phases...)
+	archive.Stories = append([]FeatureStory(nil), archive.Stories...)
+	archive.Criteria = append([]AcceptanceCriterion(nil), archive.Criteria...)
+	archive.CriteriaLinks = append([]AcceptanceCriterionLink(nil), archive.CriteriaLinks...)
+	archive.Tickets = append([]Tickets(nil), archive.Tickets...)
+	archive.TicketPlans = append([]TicketPlan(nil), archive.TicketPlans...)
+	archive.Bounties = append([]NewBounty(nil), archive.Bounties...)
+	archive.Snippets = append([]TextSnippet(nil), archive.Snippets...)
+	archive.Activities = append([]Activity(nil), archive.Activities...)
+
+	ws := archive.Workspace
+	oldWorkspaceUuid := ws.Uuid
+	ws.ID = 0
+	ws.Uuid = xid.New().String()
+	m.ids[oldWorkspaceUuid] = ws.Uuid
+	if options.Name != "" {
+		ws.Name = options.Name
+	}
+	if nameTaken(ws.Name) {
+		m.conflicts = append(m.conflicts, ImportConflict{
+			Type:     "workspace_name",
+			Key:      ws.Name,
+			Message:  "a workspace with this name already exists, pick another name",
+			Blocking: true,
+		})
+	}
+	if options.OwnerPubkey != "" {
+		ws.OwnerPubKey = options.OwnerPubkey
+	} else {
+		ws.OwnerPubKey = m.pubkey(ws.OwnerPubKey)
+	}
+	coOwners := []string{}
+	for _, coOwner := range ws.CoOwners {
+		coOwner = m.pubkey(coOwner)
+		if coOwner != "" && coOwner != ws.OwnerPubKey {
+			coOwners = append(coOwners, coOwner)
+		}
+	}
+	ws.CoOwners = coOwners
+	ws.UpdatedBy = m.pubkey(ws.UpdatedBy)
+	ws.Deleted = false
+	ws.Budget = 0
+	ws.BountyCount = 0
+	archive.Workspace = ws
+
+	users := []WorkspaceUsers{}
+	for _, user := range archive.Users {
+		user.OwnerPubKey = m.pubkey(user.OwnerPubKey)
+		if user.OwnerPubKey == "" || ws.IsOwner(user.OwnerPubKey) {
+			continue
+		}
+		user.ID = 0
+		user.WorkspaceUuid = ws.Uuid
+		users = append(users, user)
+	}
+	archive.Users = users
+
+	roles := []WorkspaceUserRoles{}
+	for _, role := range archive.Roles {
+		role.OwnerPubKey = m.pubkey(role.OwnerPubKey)
+		if role.OwnerPubKey == "" || ws.IsOwner(role.OwnerPubKey) {
+			continue
+		}
+		role.WorkspaceUuid = ws.Uuid
+		roles = append(roles, role)
+	}
+	archive.Roles = roles
+
+	for i := range archive.Repositories {
+		r := &archive.Repositories[i]
+		r.ID = 0
+		r.Uuid = m.id(r.Uuid)
+		r.WorkspaceUuid = ws.Uuid
+		r.CreatedBy = m.pubkey(r.CreatedBy)
+		r.UpdatedBy = m.pubkey(r.UpdatedBy)
+	}
+
+	for i := range archive.CodeGraphs {
+		c := &archive.CodeGraphs[i]
+		c.ID = 0
+		c.Uuid = m.id(c.Uuid)
+		c.WorkspaceUuid = ws.Uuid
+		c.SecretAlias = ""
+		c.CreatedBy = m.pubkey(c.CreatedBy)
+		c.UpdatedBy = m.pubkey(c.UpdatedBy)
+	}
+
+	for i := range archive.Features {
+		f := &archive.Features[i]
+		f.ID = 0
+		f.Uuid = m.id(f.Uuid)
+		f.WorkspaceUuid = ws.Uuid
+		f.CreatedBy = m.pubkey(f.CreatedBy)
+		f.UpdatedBy = m.pubkey(f.UpdatedBy)
+	}
+
+	for i := range archive.Phases {
+		p := &archive.Phases[i]
+		p.Uuid = m.id(p.Uuid)
+		p.FeatureUuid = m.id(p.FeatureUuid)
+		p.CreatedBy = m.pubkey(p.CreatedBy)
+		p.UpdatedBy = m.pubkey(p.UpdatedBy)
+	}
+
+	for i := range archive.Stories {
+		s := &archive.Stories[i]
+		s.ID = 0
+		s.Uuid = m.id(s.Uuid)
+		s.FeatureUuid = m.id(s.FeatureUuid)
+		s.CreatedBy = m.pubkey(s.CreatedBy)
+		s.UpdatedBy = m.pubkey(s.UpdatedBy)
+	}
+
+	for i := range archive.Criteria {
+		c := &archive.Criteria[i]
+		c.ID = 0
+		c.Uuid = m.id(c.Uuid)
+		c.StoryUuid = m.id(c.StoryUuid)
+		c.FeatureUuid = m.id(c.FeatureUuid)
+	}
+
+	// bounty links keep the exported bounty ID, ImportWorkspace points them
+	// at the new bounties once those have their IDs
+	for i := range archive.CriteriaLinks {
+		l := &archive.CriteriaLinks[i]
+		l.ID = 0
+		l.CriterionUuid = m.id(l.CriterionUuid)
+		l.FeatureUuid = m.id(l.FeatureUuid)
+		if l.ItemType == BoardTicket {
+			l.ItemKey = m.id(l.ItemKey)
+		}
+		l.CreatedBy = m.pubkey(l.CreatedBy)
+	}
+
+	for i := range archive.Tickets {
+		t := &archive.Tickets[i]
+		t.UUID = m.uuid(t.UUID)
+		if t.TicketGroup != nil {
+			group := m.uuid(*t.TicketGroup)
+			t.TicketGroup = &group
+		}
+		dependsOn := make([]string, 0, len(t.DependsOn))
+		for _, group := range t.DependsOn {
+			dependsOn = append(dependsOn, m.id(group))
+		}
+		t.DependsOn = dependsOn
+		t.WorkspaceUuid = ws.Uuid
+		t.FeatureUUID = m.id(t.FeatureUUID)
+		t.PhaseUUID = m.id(t.PhaseUUID)
+		if t.AuthorID != nil && t.Author != nil && *t.Author == HumanAuthor {
+			authorID := m.pubkey(*t.AuthorID)
+			t.AuthorID = nil
+			if authorID != "" {
+				t.AuthorID = &authorID
+			}
+		}
+	}
+
+	for i := range archive.TicketPlans {
+		p := &archive.TicketPlans[i]
+		p.UUID = m.uuid(p.UUID)
+		p.WorkspaceUuid = ws.Uuid
+		p.FeatureUUID = m.id(p.FeatureUUID)
+		p.PhaseUUID = m.id(p.PhaseUUID)
+		groups := make([]string, 0, len(p.TicketGroups))
+		for _, group := range p.TicketGroups {
+			groups = append(groups, m.id(group))
+		}
+		p.TicketGroups = groups
+		reviewers := make([]string, 0, len(p.Reviewers))
+		for _, reviewer := range p.Reviewers {
+			if reviewer = m.pubkey(reviewer); reviewer != "" {
+				reviewers = append(reviewers, reviewer)
+			}
+		}
+		p.Reviewers = reviewers
+		p.CreatedBy = m.pubkey(p.CreatedBy)
+		p.UpdatedBy = m.pubkey(p.UpdatedBy)
+	}
+
+	for i := range archive.Bounties {
+		b := &archive.Bounties[i]
+		b.ID = 0
+		b.WorkspaceUuid = ws.Uuid
+		b.FeatureUuid = m.id(b.FeatureUuid)
+		b.PhaseUuid = m.id(b.PhaseUuid)
+		if b.TicketGroup != nil {
+			group := m.uuid(*b.TicketGroup)
+			b.TicketGroup = &group
+		}
+		b.OwnerID = m.pubkey(b.OwnerID)
+		if b.OwnerID == "" {
+			b.OwnerID = ws.OwnerPubKey
+		}
+		b.UnlockCode = nil
+		b.Stakes = nil
+
+		// assignments and payments belong to the source deployment
+		b.Assignee = ""
+		b.AssignedDate = nil
+		b.AssignedHours = 0
+		b.CommitmentFee = 0
+		b.BountyExpires = ""
+		b.Completed = false
+		b.CompletionDate = nil
+		b.Paid = false
+		b.PaidDate = nil
+		b.MarkAsPaidDate = nil
+		b.PaymentPending = false
+		b.PaymentFailed = false
+		b.ProofOfWorkCount = 0
+		b.CurrentStakers = 0
+	}
+
+	for i := range archive.Snippets {
+		s := &archive.Snippets[i]
+		s.ID = 0
+		s.WorkspaceUUID = ws.Uuid
+	}
+
+	for i := range archive.Activities {
+		a := &archive.Activities[i]
+		a.ID = m.uuid(a.ID)
+		a.ThreadID = m.uuid(a.ThreadID)
+		a.Workspace = ws.Uuid
+		a.FeatureUUID = m.id(a.FeatureUUID)
+		a.PhaseUUID = m.id(a.PhaseUUID)
+		if a.Author == HumansAuthor {
+			a.AuthorRef = m.pubkey(a.AuthorRef)
+		}
+	}
+
+	return archive, m.conflicts
+}
+
+func HasBlockingConflict(conflicts []ImportConflict) bool {
+	for _, c := range conflicts {
+		if c.Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportWorkspace remaps the archive and writes it in a single transaction.
+// Nothing is written on a dry run or when a blocking conflict is found.
+func (db database) ImportWorkspace(archive WorkspaceArchive, options WorkspaceImportOptions) (WorkspaceArchive, []ImportConflict, error) {
+	personExists := func(pubkey string) bool {
+		return db.GetPersonByPubkey(pubkey).OwnerPubKey == pubkey
+	}
+	nameTaken := func(name string) bool {
+		return db.GetWorkspaceByName(name).Name == name
+	}
+
+	remapped, conflicts := RemapWorkspaceArchive(archive, options, personExists, nameTaken)
+	if HasBlockingConflict(conflicts) {
+		return remapped, conflicts, ErrWorkspaceImportConflict
+	}
+	if options.DryRun {
+		return remapped, conflicts, nil
+	}
+
+	now := time.Now()
+	remapped.Workspace.Created = &now
+	remapped.Workspace.Updated = &now
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&remapped.Workspace).Error; err != nil {
+			return fmt.Errorf("workspace: %w", err)
+		}
+
+		for i := range remapped.Bounties {
+			// bounties are addressed by their created timestamp, keep it unique
+			for {
+				var count int64
+				tx.Model(&NewBounty{}).Where("created = ?", remapped.Bounties[i].Created).Count(&count)
+				if count == 0 {
+					break
+				}
+				remapped.Bounties[i].Created++
+			}
+		}
+
+		batches := []struct {
+			name    string
+			records interface{}
+			size    int
+		}{
+			{"users", &remapped.Users, len(remapped.Users)},
+			{"roles", &remapped.Roles, len(remapped.Roles)},
+			{"repositories", &remapped.Repositories, len(remapped.Repositories)},
+			{"code graphs", &remapped.CodeGraphs, len(remapped.CodeGraphs)},
+			{"features", &remapped.Features, len(remapped.Features)},
+			{"phases", &remapped.Phases, len(remapped.Phases)},
+			{"stories", &remapped.Stories, len(remapped.Stories)},
+			{"acceptance criteria", &remapped.Criteria, len(remapped.Criteria)},
+			{"tickets", &remapped.Tickets, len(remapped.Tickets)},
+			{"ticket plans", &remapped.TicketPlans, len(remapped.TicketPlans)},
+			{"bounties", &remapped.Bounties, len(remapped.Bounties)},
+			{"snippets", &remapped.Snippets, len(remapped.Snippets)},
+			{"activities", &remapped.Activities, len(remapped.Activities)},
+		}
+		for _, batch := range batches {
+			if batch.size == 0 {
+				continue
+			}
+			if err := tx.Omit(clause.Associations).Create(batch.records).Error; err != nil {
+				return fmt.Errorf("%s: %w", batch.name, err)
+			}
+		}
+
+		// remapped bounties are in archive order
+		bountyIDs := map[string]string{}
+		for i, bounty := range archive.Bounties {
+			bountyIDs[strconv.FormatUint(uint64(bounty.ID), 10)] = strconv.FormatUint(uint64(remapped.Bounties[i].ID), 10)
+		}
+		links := make([]AcceptanceCriterionLink, 0, len(remapped.CriteriaLinks))
+		for _, link := range remapped.CriteriaLinks {
+			if link.ItemType == BoardBounty {
+				id, ok := bountyIDs[link.ItemKey]
+				if !ok {
+					continue
+				}
+				link.ItemKey = id
+			}
+			links = append(links, link)
+		}
+		remapped.CriteriaLinks = links
+		if len(links) > 0 {
+			if err := tx.Create(&remapped.CriteriaLinks).Error; err != nil {
+				return fmt.Errorf("acceptance criterion links: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return remapped, conflicts, fmt.Errorf("failed to import workspace: %w", err)
+	}
+
+	return remapped, conflicts, nil
+}
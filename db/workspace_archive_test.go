@@ -0,0 +1,125 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemapWorkspaceArchive(t *testing.T) {
+	group := uuid.New()
+	author := HumanAuthor
+	authorID := "old_member"
+	archive := WorkspaceArchive{
+		Version: WorkspaceArchiveVersion,
+		Workspace: Workspace{
+			ID:          7,
+			Uuid:        "old_workspace",
+			Name:        "Archive",
+			OwnerPubKey: "old_owner",
+			CoOwners:    []string{"old_member"},
+		},
+		Users: []WorkspaceUsers{
+			{ID: 1, OwnerPubKey: "old_member", WorkspaceUuid: "old_workspace"},
+			{ID: 2, OwnerPubKey: "ghost", WorkspaceUuid: "old_workspace"},
+		},
+		Roles: []WorkspaceUserRoles{
+			{Role: "EDIT ORGANIZATION", OwnerPubKey: "ghost", WorkspaceUuid: "old_workspace"},
+		},
+		Features: []WorkspaceFeatures{{ID: 3, Uuid: "old_feature", WorkspaceUuid: "old_workspace", CreatedBy: "old_owner"}},
+		Phases:   []FeaturePhase{{Uuid: "old_phase", FeatureUuid: "old_feature"}},
+		Tickets: []Tickets{{
+			UUID:          uuid.New(),
+			TicketGroup:   &group,
+			WorkspaceUuid: "old_workspace",
+			FeatureUUID:   "old_feature",
+			PhaseUUID:     "old_phase",
+			Author:        &author,
+			AuthorID:      &authorID,
+		}},
+		TicketPlans: []TicketPlan{{UUID: uuid.New(), FeatureUUID: "old_feature", TicketGroups: []string{group.String()}}},
+		Bounties: []NewBounty{
+			{ID: 4, OwnerID: "old_owner", WorkspaceUuid: "old_workspace", PhaseUuid: "old_phase"},
+			{ID: 5, OwnerID: "stranger", Assignee: "new_member_old", WorkspaceUuid: "old_workspace", Paid: true, Completed: true, PaymentPending: true},
+		},
+		Activities: []Activity{{ID: uuid.New(), Workspace: "old_workspace", Author: HumansAuthor, AuthorRef: "stranger"}},
+	}
+	options := WorkspaceImportOptions{
+		PubkeyMap:   map[string]string{"old_member": "new_member", "new_member_old": "new_member"},
+		OwnerPubkey: "importer",
+	}
+	personExists := func(pubkey string) bool { return pubkey != "ghost" }
+	nameTaken := func(name string) bool { return name == "Archive" }
+
+	t.Run("Should remap identifiers and pubkeys", func(t *testing.T) {
+		options := options
+		options.Name = "Imported"
+		remapped, conflicts := RemapWorkspaceArchive(archive, options, personExists, nameTaken)
+		assert.False(t, HasBlockingConflict(conflicts))
+
+		ws := remapped.Workspace
+		assert.Equal(t, uint(0), ws.ID)
+		assert.NotEqual(t, "old_workspace", ws.Uuid)
+		assert.Equal(t, "Imported", ws.Name)
+		assert.Equal(t, "importer", ws.OwnerPubKey)
+		assert.Equal(t, []string{"new_member"}, []string(ws.CoOwners))
+
+		assert.Len(t, remapped.Users, 0, "co-owners and unmapped pubkeys are not members")
+		assert.Len(t, remapped.Roles, 0)
+		assert.Len(t, conflicts, 2)
+		assert.Equal(t, "ghost", conflicts[0].Key)
+		assert.Equal(t, "stranger", conflicts[1].Key, "existing pubkeys are dropped too unless mapped")
+
+		feature := remapped.Features[0]
+		assert.NotEqual(t, "old_feature", feature.Uuid)
+		assert.Equal(t, ws.Uuid, feature.WorkspaceUuid)
+		assert.Equal(t, "importer", feature.CreatedBy)
+		assert.Equal(t, feature.Uuid, remapped.Phases[0].FeatureUuid)
+
+		ticket := remapped.Tickets[0]
+		assert.NotEqual(t, archive.Tickets[0].UUID, ticket.UUID)
+		assert.Equal(t, remapped.Phases[0].Uuid, ticket.PhaseUUID)
+		assert.Equal(t, "new_member", *ticket.AuthorID)
+		assert.Equal(t, ticket.TicketGroup.String(), remapped.TicketPlans[0].TicketGroups[0])
+		assert.Equal(t, "old_member", *archive.Tickets[0].AuthorID, "the source archive is left untouched")
+
+		bounty := remapped.Bounties[0]
+		assert.Equal(t, uint(0), bounty.ID)
+		assert.Equal(t, "importer", bounty.OwnerID)
+		assert.Equal(t, remapped.Phases[0].Uuid, bounty.PhaseUuid)
+
+		assigned := remapped.Bounties[1]
+		assert.Equal(t, "importer", assigned.OwnerID, "unmapped bounty owners fall back to the importer")
+		assert.Empty(t, assigned.Assignee)
+		assert.False(t, assigned.Paid)
+		assert.False(t, assigned.Completed)
+		assert.False(t, assigned.PaymentPending)
+
+		assert.Empty(t, remapped.Activities[0].AuthorRef)
+	})
+
+	t.Run("Should drop pubkeys mapped to a missing person", func(t *testing.T) {
+		options := options
+		options.Name = "Imported"
+		options.PubkeyMap = map[string]string{"old_member": "ghost"}
+		remapped, conflicts := RemapWorkspaceArchive(archive, options, personExists, nameTaken)
+		assert.False(t, HasBlockingConflict(conflicts))
+		assert.Empty(t, remapped.Workspace.CoOwners)
+		assert.Nil(t, remapped.Tickets[0].AuthorID)
+		assert.Equal(t, "old_member", conflicts[0].Key)
+	})
+
+	t.Run("Should block on a taken workspace name", func(t *testing.T) {
+		_, conflicts := RemapWorkspaceArchive(archive, options, personExists, nameTaken)
+		assert.True(t, HasBlockingConflict(conflicts))
+	})
+
+	t.Run("Should block on an unsupported version", func(t *testing.T) {
+		old := archive
+		old.Version = WorkspaceArchiveVersion + 1
+		_, conflicts := RemapWorkspaceArchive(old, options, personExists, nameTaken)
+		assert.True(t, HasBlockingConflict(conflicts))
+		assert.Equal(t, "version", conflicts[0].Type)
+	})
+}
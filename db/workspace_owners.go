@@ -0,0 +1,299 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOwnershipTransferExists     = errors.New("workspace already has a pending ownership transfer")
+	ErrOwnershipTransferNotPending = errors.New("ownership transfer is no longer pending")
+	ErrOwnershipTransferStale      = errors.New("workspace owner changed since the transfer was initiated")
+)
+
+func createOwnershipAudit(tx *gorm.DB, audit WorkspaceOwnershipAudit) error {
+	audit.ID = uuid.New()
+	audit.CreatedAt = time.Now()
+	return tx.Create(&audit).Error
+}
+
+func (db database) CreateOwnershipTransfer(transfer *WorkspaceOwnershipTransfer) (*WorkspaceOwnershipTransfer, error) {
+	if transfer.WorkspaceUuid == "" {
+		return nil, errors.New("workspace uuid is required")
+	}
+	if transfer.FromPubkey == "" || transfer.ToPubkey == "" {
+		return nil, errors.New("from and to pubkeys are required")
+	}
+
+	now := time.Now()
+	transfer.ID = uuid.New()
+	transfer.Status = OwnershipTransferPending
+	transfer.CreatedAt = now
+	transfer.UpdatedAt = now
+	transfer.ResolvedAt = nil
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		if err := tx.Model(&WorkspaceOwnershipTransfer{}).
+			Where("workspace_uuid = ? AND status = ?", transfer.WorkspaceUuid, OwnershipTransferPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrOwnershipTransferExists
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+
+		return createOwnershipAudit(tx, WorkspaceOwnershipAudit{
+			WorkspaceUuid: transfer.WorkspaceUuid,
+			Action:        AuditTransferInitiated,
+			ActorPubkey:   transfer.FromPubkey,
+			TargetPubkey:  transfer.ToPubkey,
+			TransferID:    &transfer.ID,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrOwnershipTransferExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create ownership transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (db database) GetOwnershipTransferByID(id uuid.UUID) (*WorkspaceOwnershipTransfer, error) {
+	var transfer WorkspaceOwnershipTransfer
+	if err := db.db.Where("id = ?", id).First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("ownership transfer with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get ownership transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+func (db database) GetOwnershipTransfersByWorkspace(workspaceUuid string) ([]WorkspaceOwnershipTransfer, error) {
+	var transfers []WorkspaceOwnershipTransfer
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).Order("created_at DESC").Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ownership transfers for workspace %s: %w", workspaceUuid, err)
+	}
+	return transfers, nil
+}
+
+func (db database) GetPendingOwnershipTransfersForPubkey(pubkey string) ([]WorkspaceOwnershipTransfer, error) {
+	var transfers []WorkspaceOwnershipTransfer
+	if err := db.db.Where("to_pubkey = ? AND status = ?", pubkey, OwnershipTransferPending).
+		Order("created_at DESC").Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ownership transfers for %s: %w", pubkey, err)
+	}
+	return transfers, nil
+}
+
+// AcceptOwnershipTransfer makes the recipient the workspace owner. The previous
+// owner is kept as a co-owner when the transfer asked for it.
+func (db database) AcceptOwnershipTransfer(id uuid.UUID) (Workspace, error) {
+	var workspace Workspace
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		var transfer WorkspaceOwnershipTransfer
+		if err := tx.Where("id = ?", id).First(&transfer).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&WorkspaceOwnershipTransfer{}).
+			Where("id = ? AND status = ?", id, OwnershipTransferPending).
+			Updates(map[string]interface{}{
+				"status":      OwnershipTransferAccepted,
+				"updated_at":  now,
+				"resolved_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOwnershipTransferNotPending
+		}
+
+		if err := tx.Where("uuid = ?", transfer.WorkspaceUuid).First(&workspace).Error; err != nil {
+			return err
+		}
+		if workspace.OwnerPubKey != transfer.FromPubkey {
+			return ErrOwnershipTransferStale
+		}
+
+		coOwners := []string{}
+		for _, coOwner := range workspace.CoOwners {
+			if coOwner != transfer.ToPubkey && coOwner != transfer.FromPubkey {
+				coOwners = append(coOwners, coOwner)
+			}
+		}
+		if transfer.KeepAsCoOwner {
+			coOwners = append(coOwners, transfer.FromPubkey)
+		}
+
+		workspace.OwnerPubKey = transfer.ToPubkey
+		workspace.CoOwners = coOwners
+		workspace.Updated = &now
+		if err := tx.Model(&Workspace{}).Where("uuid = ?", workspace.Uuid).Updates(map[string]interface{}{
+			"owner_pub_key": workspace.OwnerPubKey,
+			"co_owners":     workspace.CoOwners,
+			"updated":       now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return createOwnershipAudit(tx, WorkspaceOwnershipAudit{
+			WorkspaceUuid: transfer.WorkspaceUuid,
+			Action:        AuditTransferAccepted,
+			ActorPubkey:   transfer.ToPubkey,
+			TargetPubkey:  transfer.FromPubkey,
+			TransferID:    &transfer.ID,
+		})
+	})
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	return workspace, nil
+}
+
+// CloseOwnershipTransfer rejects or cancels a pending transfer
+func (db database) CloseOwnershipTransfer(id uuid.UUID, actorPubkey string, status OwnershipTransferStatus) (*WorkspaceOwnershipTransfer, error) {
+	var action OwnershipAuditAction
+	switch status {
+	case OwnershipTransferRejected:
+		action = AuditTransferRejected
+	case OwnershipTransferCancelled:
+		action = AuditTransferCancelled
+	default:
+		return nil, fmt.Errorf("invalid ownership transfer status %s", status)
+	}
+
+	var transfer WorkspaceOwnershipTransfer
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&WorkspaceOwnershipTransfer{}).
+			Where("id = ? AND status = ?", id, OwnershipTransferPending).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"updated_at":  now,
+				"resolved_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOwnershipTransferNotPending
+		}
+
+		if err := tx.Where("id = ?", id).First(&transfer).Error; err != nil {
+			return err
+		}
+
+		target := transfer.ToPubkey
+		if actorPubkey == transfer.ToPubkey {
+			target = transfer.FromPubkey
+		}
+
+		return createOwnershipAudit(tx, WorkspaceOwnershipAudit{
+			WorkspaceUuid: transfer.WorkspaceUuid,
+			Action:        action,
+			ActorPubkey:   actorPubkey,
+			TargetPubkey:  target,
+			TransferID:    &transfer.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func (db database) AddWorkspaceCoOwner(workspaceUuid string, pubkey string, actorPubkey string) (Workspace, error) {
+	var workspace Workspace
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Workspace{}).
+			Where("uuid = ? AND owner_pub_key <> ? AND NOT (? = ANY(COALESCE(co_owners, '{}')))", workspaceUuid, pubkey, pubkey).
+			Updates(map[string]interface{}{
+				"co_owners": gorm.Expr("array_append(COALESCE(co_owners, '{}'), ?)", pubkey),
+				"updated":   time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Where("uuid = ?", workspaceUuid).First(&workspace).Error; err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			// already an owner, nothing to record
+			return nil
+		}
+
+		return createOwnershipAudit(tx, WorkspaceOwnershipAudit{
+			WorkspaceUuid: workspaceUuid,
+			Action:        AuditCoOwnerAdded,
+			ActorPubkey:   actorPubkey,
+			TargetPubkey:  pubkey,
+		})
+	})
+	if err != nil {
+		return Workspace{}, fmt.Errorf("failed to add co-owner: %w", err)
+	}
+
+	return workspace, nil
+}
+
+func (db database) RemoveWorkspaceCoOwner(workspaceUuid string, pubkey string, actorPubkey string) (Workspace, error) {
+	var workspace Workspace
+
+	err := db.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Workspace{}).
+			Where("uuid = ? AND ? = ANY(COALESCE(co_owners, '{}'))", workspaceUuid, pubkey).
+			Updates(map[string]interface{}{
+				"co_owners": gorm.Expr("array_remove(co_owners, ?)", pubkey),
+				"updated":   time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Where("uuid = ?", workspaceUuid).First(&workspace).Error; err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		return createOwnershipAudit(tx, WorkspaceOwnershipAudit{
+			WorkspaceUuid: workspaceUuid,
+			Action:        AuditCoOwnerRemoved,
+			ActorPubkey:   actorPubkey,
+			TargetPubkey:  pubkey,
+		})
+	})
+	if err != nil {
+		return Workspace{}, fmt.Errorf("failed to remove co-owner: %w", err)
+	}
+
+	return workspace, nil
+}
+
+func (db database) GetWorkspaceOwnershipAudit(workspaceUuid string) ([]WorkspaceOwnershipAudit, error) {
+	var audits []WorkspaceOwnershipAudit
+	if err := db.db.Where("workspace_uuid = ?", workspaceUuid).Order("created_at DESC").Find(&audits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get ownership audit for workspace %s: %w", workspaceUuid, err)
+	}
+	return audits, nil
+}
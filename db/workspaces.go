@@ -52,10 +52,21 @@ func (db database) CreateOrEditWorkspace(m Workspace) (Workspace, error) {
 		return Workspace{}, errors.New("no pub key")
 	}
 
+	var existing Workspace
+	db.db.Model(&Workspace{}).Where("uuid = ?", m.Uuid).Limit(1).Find(&existing)
+
 	if db.db.Model(&m).Where("uuid = ?", m.Uuid).Updates(&m).RowsAffected == 0 {
 		db.db.Create(&m)
 	}
 
+	var saved Workspace
+	db.db.Model(&Workspace{}).Where("uuid = ?", m.Uuid).Limit(1).Find(&saved)
+	changedBy := m.UpdatedBy
+	if changedBy == "" {
+		changedBy = m.OwnerPubKey
+	}
+	db.recordTextRevisions(TextRevisionWorkspace, m.Uuid, workspaceRevisionText(existing), workspaceRevisionText(saved), changedBy, existing.Updated)
+
 	return m, nil
 }
 
@@ -134,6 +145,22 @@ func (db database) GetWorkspaceUser(pubkey string, workspace_uuid string) Worksp
 	return ms
 }
 
+// IsWorkspaceMember is true for the owner and the users of a workspace that
+// is not deleted
+func (db database) IsWorkspaceMember(pubkey string, workspaceUuid string) bool {
+	if pubkey == "" || workspaceUuid == "" {
+		return false
+	}
+	workspace := db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.ID == 0 || workspace.Deleted {
+		return false
+	}
+	if workspace.IsOwner(pubkey) {
+		return true
+	}
+	return db.GetWorkspaceUser(pubkey, workspaceUuid).OwnerPubKey == pubkey
+}
+
 func (db database) CreateWorkspaceUser(orgUser WorkspaceUsers) WorkspaceUsers {
 	db.db.Create(&orgUser)
 
@@ -168,7 +195,7 @@ func (db database) GetUserRoles(uuid string, pubkey string) []WorkspaceUserRoles
 
 func (db database) GetUserCreatedWorkspaces(pubkey string) []Workspace {
 	ms := []Workspace{}
-	db.db.Where("owner_pub_key = ?", pubkey).Where("deleted != ?", true).Find(&ms)
+	db.db.Where("owner_pub_key = ? OR ? = ANY(COALESCE(co_owners, '{}'))", pubkey, pubkey).Where("deleted != ?", true).Find(&ms)
 	return ms
 }
 
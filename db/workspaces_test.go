@@ -407,3 +407,126 @@ func TestGetUserCreatedWorkspaces(t *testing.T) {
 		assert.Less(t, duration.Milliseconds(), int64(1000), "Query should complete within 1 second")
 	})
 }
+
+func TestWorkspaceIsOwner(t *testing.T) {
+	workspace := Workspace{
+		OwnerPubKey: "owner",
+		CoOwners:    []string{"co_owner"},
+	}
+
+	assert.True(t, workspace.IsOwner("owner"))
+	assert.True(t, workspace.IsOwner("co_owner"))
+	assert.False(t, workspace.IsOwner("someone_else"))
+	assert.False(t, workspace.IsOwner(""))
+	assert.False(t, Workspace{}.IsOwner(""))
+}
+
+func TestWorkspaceOwnershipTransfer(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{
+		Uuid:        uuid.New().String(),
+		Name:        fmt.Sprintf("Test Transfer %d", rand.Intn(100000)),
+		OwnerPubKey: "transfer_owner",
+	}
+	TestDB.db.Create(&workspace)
+
+	t.Run("Should accept a transfer and keep the previous owner as co-owner", func(t *testing.T) {
+		transfer, err := TestDB.CreateOwnershipTransfer(&WorkspaceOwnershipTransfer{
+			WorkspaceUuid: workspace.Uuid,
+			FromPubkey:    "transfer_owner",
+			ToPubkey:      "transfer_recipient",
+			KeepAsCoOwner: true,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, OwnershipTransferPending, transfer.Status)
+
+		_, err = TestDB.CreateOwnershipTransfer(&WorkspaceOwnershipTransfer{
+			WorkspaceUuid: workspace.Uuid,
+			FromPubkey:    "transfer_owner",
+			ToPubkey:      "someone_else",
+		})
+		assert.ErrorIs(t, err, ErrOwnershipTransferExists)
+
+		updated, err := TestDB.AcceptOwnershipTransfer(transfer.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "transfer_recipient", updated.OwnerPubKey)
+		assert.True(t, updated.IsOwner("transfer_owner"))
+
+		_, err = TestDB.AcceptOwnershipTransfer(transfer.ID)
+		assert.ErrorIs(t, err, ErrOwnershipTransferNotPending)
+
+		stored := TestDB.GetWorkspaceByUuid(workspace.Uuid)
+		assert.Equal(t, "transfer_recipient", stored.OwnerPubKey)
+		assert.Equal(t, []string{"transfer_owner"}, []string(stored.CoOwners))
+	})
+
+	t.Run("Should reject a transfer without changing the owner", func(t *testing.T) {
+		transfer, err := TestDB.CreateOwnershipTransfer(&WorkspaceOwnershipTransfer{
+			WorkspaceUuid: workspace.Uuid,
+			FromPubkey:    "transfer_recipient",
+			ToPubkey:      "transfer_other",
+		})
+		assert.NoError(t, err)
+
+		closed, err := TestDB.CloseOwnershipTransfer(transfer.ID, "transfer_other", OwnershipTransferRejected)
+		assert.NoError(t, err)
+		assert.Equal(t, OwnershipTransferRejected, closed.Status)
+		assert.Equal(t, "transfer_recipient", TestDB.GetWorkspaceByUuid(workspace.Uuid).OwnerPubKey)
+	})
+
+	t.Run("Should add and remove co-owners", func(t *testing.T) {
+		updated, err := TestDB.AddWorkspaceCoOwner(workspace.Uuid, "transfer_co_owner", "transfer_recipient")
+		assert.NoError(t, err)
+		assert.True(t, updated.IsOwner("transfer_co_owner"))
+
+		updated, err = TestDB.AddWorkspaceCoOwner(workspace.Uuid, "transfer_co_owner", "transfer_recipient")
+		assert.NoError(t, err)
+		assert.Len(t, updated.CoOwners, 2)
+
+		updated, err = TestDB.RemoveWorkspaceCoOwner(workspace.Uuid, "transfer_co_owner", "transfer_recipient")
+		assert.NoError(t, err)
+		assert.False(t, updated.IsOwner("transfer_co_owner"))
+	})
+
+	t.Run("Should record every change in the audit trail", func(t *testing.T) {
+		audit, err := TestDB.GetWorkspaceOwnershipAudit(workspace.Uuid)
+		assert.NoError(t, err)
+
+		actions := []OwnershipAuditAction{}
+		for _, entry := range audit {
+			actions = append(actions, entry.Action)
+		}
+		assert.Equal(t, []OwnershipAuditAction{
+			AuditCoOwnerRemoved,
+			AuditCoOwnerAdded,
+			AuditTransferRejected,
+			AuditTransferInitiated,
+			AuditTransferAccepted,
+			AuditTransferInitiated,
+		}, actions)
+	})
+}
+
+func TestIsWorkspaceMember(t *testing.T) {
+	InitTestDB()
+	defer CloseTestDB()
+
+	workspace := Workspace{
+		Uuid:        uuid.New().String(),
+		Name:        "Member Workspace",
+		OwnerPubKey: "member_owner",
+	}
+	TestDB.db.Create(&workspace)
+	TestDB.CreateWorkspaceUser(WorkspaceUsers{OwnerPubKey: "member_user", WorkspaceUuid: workspace.Uuid})
+
+	assert.True(t, TestDB.IsWorkspaceMember("member_owner", workspace.Uuid))
+	assert.True(t, TestDB.IsWorkspaceMember("member_user", workspace.Uuid))
+	assert.False(t, TestDB.IsWorkspaceMember("member_stranger", workspace.Uuid))
+	assert.False(t, TestDB.IsWorkspaceMember("member_user", ""))
+	assert.False(t, TestDB.IsWorkspaceMember("member_user", uuid.New().String()))
+
+	TestDB.db.Model(&Workspace{}).Where("uuid = ?", workspace.Uuid).Update("deleted", true)
+	assert.False(t, TestDB.IsWorkspaceMember("member_owner", workspace.Uuid))
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type CriterionLinksRequest struct {
+	Criteria []string `json:"criteria"`
+}
+
+// criterionLinkItem reads the item type and key of a criterion link route
+func criterionLinkItem(r *http.Request) (db.BoardItemType, string, bool) {
+	itemType := db.BoardItemType(chi.URLParam(r, "item_type"))
+	itemKey := chi.URLParam(r, "item_key")
+	if (itemType != db.BoardTicket && itemType != db.BoardBounty) || itemKey == "" {
+		return "", "", false
+	}
+	return itemType, itemKey, true
+}
+
+// GetCriterionLinks godoc
+//
+//	@Summary		Get acceptance criterion links
+//	@Description	Get the acceptance criteria a ticket group or a bounty of the feature satisfies
+//	@Tags			Feature - Stories
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path	string	true	"Feature UUID"
+//	@Param			item_type		path	string	true	"ticket or bounty"
+//	@Param			item_key		path	string	true	"Ticket group or bounty ID"
+//	@Success		200				{array}	db.AcceptanceCriterionLink
+//	@Router			/features/{feature_uuid}/criteria/{item_type}/{item_key} [get]
+func (oh *featureHandler) GetCriterionLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	itemType, itemKey, ok := criterionLinkItem(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "item type must be ticket or bounty"})
+		return
+	}
+
+	links, err := oh.db.GetAcceptanceCriterionLinks(featureUuid, itemType, itemKey)
+	if err != nil {
+		logger.Log.Error("[criteria] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get acceptance criterion links"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+// SetCriterionLinks godoc
+//
+//	@Summary		Set acceptance criterion links
+//	@Description	Replace the acceptance criteria a ticket group or a bounty of the feature satisfies. Bounties created from a ticket start with the links of the ticket.
+//	@Tags			Feature - Stories
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path	string					true	"Feature UUID"
+//	@Param			item_type		path	string					true	"ticket or bounty"
+//	@Param			item_key		path	string					true	"Ticket group, ticket UUID or bounty ID"
+//	@Param			request			body	CriterionLinksRequest	true	"Criterion UUIDs"
+//	@Success		200				{array}	db.AcceptanceCriterionLink
+//	@Router			/features/{feature_uuid}/criteria/{item_type}/{item_key} [put]
+func (oh *featureHandler) SetCriterionLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	itemType, itemKey, ok := criterionLinkItem(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "item type must be ticket or bounty"})
+		return
+	}
+
+	var request CriterionLinksRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[criteria] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	links, err := oh.db.SetAcceptanceCriterionLinks(featureUuid, itemType, itemKey, request.Criteria, pubKeyFromAuth)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTraceItemNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, db.ErrAcceptanceCriterionUnknown):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			logger.Log.Error("[criteria] %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to save acceptance criterion links"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+// GetFeatureTraceability godoc
+//
+//	@Summary		Get feature traceability
+//	@Description	Report which stories of the feature are fully covered, partially covered or untouched by tickets and bounties
+//	@Tags			Feature - Stories
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string	true	"Feature UUID"
+//	@Success		200				{object}	db.FeatureTraceability
+//	@Router			/features/{feature_uuid}/traceability [get]
+func (oh *featureHandler) GetFeatureTraceability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	feature := oh.db.GetFeatureByUuid(featureUuid)
+	if feature.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Feature not found"})
+		return
+	}
+
+	report, err := oh.db.GetFeatureTraceability(featureUuid)
+	if err != nil {
+		logger.Log.Error("[criteria] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get traceability report"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
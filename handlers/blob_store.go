@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/storage"
+)
+
+// NewBlobStore builds the blob store of the given kind from the config, an
+// empty kind is the meme server
+func NewBlobStore(kind string, s3Prefix string) (storage.BlobStore, error) {
+	switch strings.ToLower(kind) {
+	case storage.LocalKind:
+		return storage.NewLocalStore(config.BlobLocalDir, config.BlobPublicUrl), nil
+	case storage.S3Kind:
+		if config.S3Client == nil {
+			return nil, errors.New("s3 client is not configured")
+		}
+		return storage.NewS3Store(config.S3Client, config.S3BucketName, s3Prefix, config.S3Url), nil
+	case storage.MemeKind, "":
+		return storage.NewMemeStore(config.MemeUrl, nil, MemeUploadToken), nil
+	}
+	return nil, fmt.Errorf("unknown blob store %q", kind)
+}
+
+// UploadStore is where chat and meme uploads are kept, see BLOB_STORE
+func UploadStore() storage.BlobStore {
+	store, err := NewBlobStore(config.BlobStore, "uploads")
+	if err != nil {
+		logger.Log.Error("Falling back to the meme server for uploads: %v", err)
+		return storage.NewMemeStore(config.MemeUrl, nil, MemeUploadToken)
+	}
+	return store
+}
+
+// ExportStore is where generated exports are kept, see EXPORT_BLOB_STORE
+func ExportStore() storage.BlobStore {
+	store, err := NewBlobStore(config.ExportBlobStore, config.S3FolderName)
+	if err != nil {
+		logger.Log.Error("Falling back to local storage for exports: %v", err)
+		return storage.NewLocalStore(config.BlobLocalDir, config.BlobPublicUrl)
+	}
+	return store
+}
+
+// MemeUploadToken signs a meme server challenge and returns the upload token
+func MemeUploadToken() (string, error) {
+	challenge := GetMemeChallenge()
+	signer := SignChallenge(challenge.Challenge)
+	mErr, mToken := GetMemeToken(challenge.Id, signer.Response.Sig)
+	if mErr != "" {
+		return "", errors.New(mErr)
+	}
+	if mToken.Token == "" {
+		return "", errors.New("meme server returned no token")
+	}
+	return mToken.Token, nil
+}
+
+// ServeBlob godoc
+//
+//	@Summary		Serve Blob
+//	@Description	Serve a file kept by the local blob store
+//	@Tags			Blobs
+//	@Produce		octet-stream
+//	@Param			key	path		string	true	"Blob key"
+//	@Success		200	{file}		file
+//	@Failure		404	{object}	map[string]string
+//	@Router			/blobs/{key} [get]
+func ServeBlob(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+	store := storage.NewLocalStore(config.BlobLocalDir, config.BlobPublicUrl)
+
+	body, err := store.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrBlobKey) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "blob not found"})
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Failed to read blob %s: %v", key, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "failed to read blob"})
+		return
+	}
+	defer body.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// only images are shown inline so uploaded pages can't run on our origin
+	if !strings.HasPrefix(contentType, "image/") || strings.HasPrefix(contentType, "image/svg") {
+		w.Header().Set("Content-Disposition", "attachment")
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
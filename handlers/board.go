@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/websocket"
+)
+
+type BoardLimitsRequest struct {
+	Limits []db.BoardColumnConfig `json:"limits"`
+}
+
+type BoardSubscribeRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// GetWorkspaceBoard godoc
+//
+//	@Summary		Get workspace board
+//	@Description	Tickets and bounties of a workspace grouped into status columns with WIP limits, optionally filtered by feature, phase, assignee and category
+//	@Tags			Workspaces - Board
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			feature_uuid	query		string	false	"Feature UUID"
+//	@Param			phase_uuid		query		string	false	"Phase UUID"
+//	@Param			assignee		query		string	false	"Assignee pubkey"
+//	@Param			category		query		string	false	"Category"
+//	@Success		200				{object}	db.Board
+//	@Router			/workspaces/{workspace_uuid}/board [get]
+func (oh *workspaceHandler) GetWorkspaceBoard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[board] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to the workspace board"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.BoardFilter{
+		FeatureUUID: query.Get("feature_uuid"),
+		PhaseUUID:   query.Get("phase_uuid"),
+		Assignee:    query.Get("assignee"),
+		Category:    query.Get("category"),
+	}
+
+	board, err := oh.db.GetWorkspaceBoard(workspaceUuid, filter)
+	if err != nil {
+		logger.Log.Error("[board] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get board"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(board)
+}
+
+// SetBoardLimits godoc
+//
+//	@Summary		Set board WIP limits
+//	@Description	Replace the work in progress limits of the workspace board columns, zero means unlimited
+//	@Tags			Workspaces - Board
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string				true	"Workspace UUID"
+//	@Param			request			body	BoardLimitsRequest	true	"Column limits"
+//	@Success		200				{array}	db.BoardColumnConfig
+//	@Router			/workspaces/{workspace_uuid}/board/limits [put]
+func (oh *workspaceHandler) SetBoardLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[board] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.userHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to edit the board"})
+		return
+	}
+
+	var request BoardLimitsRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[board] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	limits, err := oh.db.SetBoardColumnLimits(workspaceUuid, request.Limits)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(limits)
+}
+
+// MoveBoardCard godoc
+//
+//	@Summary		Move a board card
+//	@Description	Move a ticket to another status column and position in one atomic call. Bounties can only be reordered inside their column.
+//	@Tags			Workspaces - Board
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string			true	"Workspace UUID"
+//	@Param			move			body		db.BoardMove	true	"Card move"
+//	@Success		200				{object}	db.BoardMoveResult
+//	@Router			/workspaces/{workspace_uuid}/board/move [post]
+func (oh *workspaceHandler) MoveBoardCard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[board] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to the workspace board"})
+		return
+	}
+
+	var move db.BoardMove
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &move); err != nil {
+		logger.Log.Error("[board] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	result, err := oh.db.MoveBoardCard(workspaceUuid, pubKeyFromAuth, move)
+	if err != nil {
+		if writeTicketTransitionError(w, err) {
+			return
+		}
+		if status, ok := ticketDependencyErrorStatus(err); ok {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		switch {
+		case errors.Is(err, db.ErrBoardCardNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, db.ErrBoardWipLimit), errors.Is(err, db.ErrBoardBountyStatus):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			logger.Log.Error("[board] failed to move card: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to move card"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	var action map[string]interface{}
+	if result.Ticket != nil {
+		action = runTicketTransitionAction(oh.db, result.Rule, *result.Ticket, pubKeyFromAuth)
+	}
+
+	if err := websocket.WebsocketPool.SendBoardMessage(websocket.BoardMessage{
+		BroadcastType: "workspace",
+		WorkspaceUuid: workspaceUuid,
+		Action:        "move",
+		FromStatus:    result.FromStatus,
+		Card:          result.Card,
+	}); err != nil {
+		logger.Log.Error("[board] failed to push board update: %v", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"card":              result.Card,
+		"from_status":       result.FromStatus,
+		"transition_action": action,
+	})
+}
+
+// SubscribeBoard godoc
+//
+//	@Summary		Subscribe to board updates
+//	@Description	Register a websocket session to receive the card moves of the workspace board
+//	@Tags			Workspaces - Board
+//	@Accept			json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string					true	"Workspace UUID"
+//	@Param			request			body	BoardSubscribeRequest	true	"Websocket session"
+//	@Success		200
+//	@Router			/workspaces/{workspace_uuid}/board/subscribe [post]
+func (oh *workspaceHandler) SubscribeBoard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[board] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to the workspace board"})
+		return
+	}
+
+	var request BoardSubscribeRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil || request.SessionID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "session_id is required"})
+		return
+	}
+
+	if err := websocket.WebsocketPool.SubscribeBoard(workspaceUuid, request.SessionID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Subscribed to board updates"})
+}
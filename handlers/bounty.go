@@ -661,7 +661,7 @@ func (h *bountyHandler) DeleteBounty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	b, err := h.db.DeleteBounty(pubkey, created)
+	b, err := h.db.TrashBounty(pubkey, created, pubKeyFromAuth)
 	if err != nil {
 		logger.Log.Error("[bounty] failed to delete bounty: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -2043,23 +2043,7 @@ func (h *bountyHandler) GenerateBountyCardResponse(bounties []db.NewBounty) []db
 }
 
 func calculateBountyStatus(bounty db.NewBounty) db.BountyStatus {
-	if bounty.Paid {
-		return db.StatusPaid
-	}
-	if bounty.Completed || bounty.PaymentPending {
-		return db.StatusComplete
-	}
-	if bounty.Assignee == "" {
-		return db.StatusTodo
-	}
-	if bounty.Assignee != "" && bounty.ProofOfWorkCount == 0 {
-		return db.StatusInProgress
-	}
-	if bounty.Assignee != "" && bounty.ProofOfWorkCount > 0 {
-		return db.StatusInReview
-	}
-
-	return db.StatusTodo
+	return db.BountyCardStatus(bounty)
 }
 
 // AddProofOfWork godoc
@@ -19,13 +19,16 @@ import (
 
 	"github.com/stakwork/sphinx-tribes/auth"
 	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/storage"
 	"gorm.io/gorm"
 
 	"github.com/google/uuid"
 	"github.com/rs/xid"
+	"github.com/stakwork/sphinx-tribes/webhook"
 	"github.com/stakwork/sphinx-tribes/websocket"
 
 	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/sse"
 )
@@ -34,6 +37,7 @@ import (
 type ChatHandler struct {
 	httpClient *http.Client
 	db         db.Database
+	uploads    storage.BlobStore
 }
 
 // ChatResponse is the response format for chat requests
@@ -87,6 +91,7 @@ type SendMessageRequest struct {
 	SourceWebsocketID string `json:"sourceWebsocketId"`
 	WorkspaceUUID     string `json:"workspaceUUID"`
 	Mode              string `json:"mode,omitempty"`
+	BranchID          string `json:"branchId,omitempty"`
 }
 
 type BuildMessageRequest struct {
@@ -118,15 +123,19 @@ type ActionMessageRequest struct {
 }
 
 type ActionPayload struct {
-	ChatID            string              `json:"chatId"`
-	MessageID         string              `json:"messageId"`
-	Message           string              `json:"message"`
-	History           []map[string]string `json:"history"`
-	CodeGraph         string              `json:"codeGraph,omitempty"`
-	CodeGraphAlias    string              `json:"codeGraphAlias,omitempty"`
-	SourceWebsocketID string              `json:"sourceWebsocketId"`
-	WebhookURL        string              `json:"webhook_url"`
-	CodeSpaceURL      string              `json:"codeSpaceURL"`
+	ChatID            string                 `json:"chatId"`
+	MessageID         string                 `json:"messageId"`
+	Message           string                 `json:"message"`
+	History           []map[string]string    `json:"history"`
+	HistorySummary    string                 `json:"historySummary,omitempty"`
+	HistoryDigest     string                 `json:"historyDigest,omitempty"`
+	SummarizeHistory  *db.ChatSummaryRequest `json:"summarizeHistory,omitempty"`
+	PinnedContext     []map[string]string    `json:"pinnedContext,omitempty"`
+	CodeGraph         string                 `json:"codeGraph,omitempty"`
+	CodeGraphAlias    string                 `json:"codeGraphAlias,omitempty"`
+	SourceWebsocketID string                 `json:"sourceWebsocketId"`
+	WebhookURL        string                 `json:"webhook_url"`
+	CodeSpaceURL      string                 `json:"codeSpaceURL"`
 }
 
 type CreateOrEditChatRequest struct {
@@ -151,6 +160,8 @@ type ChatWorkflowRequest struct {
 	WorkspaceID string `json:"workspaceId"`
 	URL         string `json:"url"`
 	StackworkID string `json:"stackworkId,omitempty"`
+	HistorySize int    `json:"historySize,omitempty"`
+	TokenBudget int    `json:"tokenBudget,omitempty"`
 }
 
 type ChatWorkflowResponse struct {
@@ -206,6 +217,7 @@ func NewChatHandler(httpClient *http.Client, database db.Database) *ChatHandler
 	return &ChatHandler{
 		httpClient: httpClient,
 		db:         database,
+		uploads:    UploadStore(),
 	}
 }
 
@@ -392,21 +404,6 @@ func (ch *ChatHandler) ArchiveChat(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func buildArtifactList(artefacts []db.Artifact) []map[string]string {
-	formattedArtefacts := []map[string]string{}
-	for _, art := range artefacts {
-		contentJSON, err := json.Marshal(art.Content)
-		if err != nil {
-			contentJSON = []byte("{}")
-		}
-		formattedArtefacts = append(formattedArtefacts, map[string]string{
-			"artifactId": art.ID.String(),
-			"content":    string(contentJSON),
-		})
-	}
-	return formattedArtefacts
-}
-
 func buildVarsPayload(request SendMessageRequest, createdMessage *db.ChatMessage, messageHistory []map[string]string, context interface{}, user *db.Person, codeGraph *db.WorkspaceCodeGraph, codeSpace db.CodeSpaceMap, mode string) map[string]interface{} {
 	vars := map[string]interface{}{
 		"chatId":            request.ChatID,
@@ -429,12 +426,12 @@ func buildVarsPayload(request SendMessageRequest, createdMessage *db.ChatMessage
 		}
 		if mode == "Build" {
 			vars["2b_base_url"] = url
-			vars["secret"] = codeGraph.SecretAlias
+			vars["secret"] = codeGraph.SecretAlias.String()
 		} else {
 			vars["codeGraph"] = url
-			vars["codeGraphAlias"] = codeGraph.SecretAlias
+			vars["codeGraphAlias"] = codeGraph.SecretAlias.String()
 			vars["2b_base_url"] = url
-			vars["secret"] = codeGraph.SecretAlias
+			vars["secret"] = codeGraph.SecretAlias.String()
 		}
 	}
 
@@ -451,11 +448,11 @@ func buildVarsPayload(request SendMessageRequest, createdMessage *db.ChatMessage
 	}
 
 	if codeSpace.GithubPat != "" {
-		vars["token"] = codeSpace.GithubPat
+		vars["token"] = codeSpace.GithubPat.String()
 	}
 
 	if codeSpace.PoolAPIKey != "" {
-		vars["pool_api_key"] = codeSpace.PoolAPIKey
+		vars["pool_api_key"] = codeSpace.PoolAPIKey.String()
 	}
 
 	vars["query"] = request.Message
@@ -475,6 +472,7 @@ func buildVarsPayload(request SendMessageRequest, createdMessage *db.ChatMessage
 //	@Success		200		{object}	ChatResponse
 //	@Failure		400		{object}	ChatResponse
 //	@Failure		401		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
 //	@Failure		500		{object}	ChatResponse
 //	@Router			/hivechat/send [post]
 func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
@@ -513,6 +511,26 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(request.ContextTags) > 0 {
+		chat, err := ch.db.GetChatByChatID(request.ChatID)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Chat not found",
+			})
+			return
+		}
+		if !ch.db.IsWorkspaceMember(pubKeyFromAuth, chat.WorkspaceID) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Don't have access to this chat",
+			})
+			return
+		}
+	}
+
 	context, err := ch.db.GetProductBrief(request.WorkspaceUUID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -523,7 +541,22 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	history, err := ch.db.GetChatMessagesForChatID(request.ChatID)
+	branchID := chatBranchParam(request.BranchID)
+	if request.BranchID == "" {
+		if chat, err := ch.db.GetChatByChatID(request.ChatID); err == nil {
+			branchID = chat.ActiveBranchID
+		}
+	}
+
+	history, err := ch.db.GetChatBranchHistory(request.ChatID, branchID)
+	if errors.Is(err, db.ErrChatBranchNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat branch not found",
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
@@ -533,39 +566,17 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	start := 0
-	if len(history) > 20 {
-		start = len(history) - 20
-	}
-	recentHistory := history[start:]
-
-	messageHistory := make([]map[string]string, len(recentHistory))
-	for i, msg := range recentHistory {
-		artefacts, err := ch.db.GetArtifactsByMessageID(msg.ID)
-		if err != nil {
-			artefacts = []db.Artifact{}
-		}
-
-		artifactJSON, err := json.Marshal(map[string]interface{}{"artifacts": buildArtifactList(artefacts)})
-		if err != nil {
-			artifactJSON = []byte(`{"artifacts": []}`)
-		}
-
-		messageHistory[i] = map[string]string{
-			"role":    string(msg.Role),
-			"content": msg.Message + "\nArtifacts: " + string(artifactJSON),
-		}
-	}
-
 	message := &db.ChatMessage{
-		ID:        xid.New().String(),
-		ChatID:    request.ChatID,
-		Message:   request.Message,
-		PDFURL:    request.PDFURL,
-		Role:      "user",
-		Timestamp: time.Now(),
-		Status:    "sending",
-		Source:    "user",
+		ID:          xid.New().String(),
+		ChatID:      request.ChatID,
+		Message:     request.Message,
+		PDFURL:      request.PDFURL,
+		Role:        "user",
+		Timestamp:   time.Now(),
+		Status:      "sending",
+		Source:      "user",
+		BranchID:    branchID,
+		ContextTags: requestContextTags(request),
 	}
 
 	createdMessage, err := ch.db.AddChatMessage(message)
@@ -578,6 +589,83 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	projectID, err := ch.dispatchChatMessage(request, &createdMessage, history, context, &user, pubKeyFromAuth)
+	if errors.Is(err, errStakworkKeyMissing) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		createdMessage.Status = "error"
+		ch.db.UpdateChatMessage(&createdMessage)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to process message: %v", err),
+		})
+		return
+	}
+
+	notifyChatMessageSent(request.SourceWebsocketID, projectID, createdMessage)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Message sent successfully",
+		Data:    createdMessage,
+	})
+}
+
+var errStakworkKeyMissing = errors.New("environment variable is not set")
+
+// buildChatContext picks the history, pinned context and summary sent with a
+// message on a branch. Messages that left the window but aren't summarized yet
+// come with a request for the workflow to post a new summary back.
+func (ch *ChatHandler) buildChatContext(chatID string, workspaceID string, branchID string, history []db.ChatMessage) db.ChatContext {
+	workflow, err := ch.db.GetChatWorkflowByWorkspaceID(workspaceID)
+	if err != nil {
+		workflow = nil
+	}
+	settings := db.ChatContextSettingsFor(workflow)
+
+	artifacts, err := ch.db.GetAllArtifactsByChatID(chatID)
+	if err != nil {
+		artifacts = []db.Artifact{}
+	}
+
+	chat, err := ch.db.GetChatByChatID(chatID)
+	if err != nil {
+		chat = db.Chat{ID: chatID}
+	}
+
+	chatContext := db.BuildChatContext(chat, branchID, history, artifacts, settings)
+	if chatContext.SummaryRequest != nil {
+		chatContext.SummaryRequest.WebhookURL = fmt.Sprintf("%s/hivechat/%s/summary", os.Getenv("HOST"), chatID)
+	}
+	return chatContext
+}
+
+func chatContextEntries(entries []db.ChatContextEntry) []map[string]string {
+	messageHistory := make([]map[string]string, len(entries))
+	for i, entry := range entries {
+		messageHistory[i] = map[string]string{
+			"role":    entry.Role,
+			"content": entry.Content,
+		}
+	}
+	return messageHistory
+}
+
+// dispatchChatMessage sends a saved user message and the history before it to
+// the hive chat workflow, returning the stakwork project ID
+func (ch *ChatHandler) dispatchChatMessage(request SendMessageRequest, createdMessage *db.ChatMessage, history []db.ChatMessage, context interface{}, user *db.Person, pubkey string) (int64, error) {
+	chatContext := ch.buildChatContext(request.ChatID, request.WorkspaceUUID, createdMessage.BranchID, history)
+	messageHistory := chatContextEntries(chatContext.History)
+
 	var codeGraph *db.WorkspaceCodeGraph
 	if workspaceID := request.WorkspaceUUID; workspaceID != "" {
 		codeGraphResult, err := ch.db.GetCodeGraphByWorkspaceUuid(workspaceID)
@@ -593,13 +681,33 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 
 	var codeSpace db.CodeSpaceMap
 	if workspaceID := request.WorkspaceUUID; workspaceID != "" {
-		codeSpaceResult, err := ch.db.GetCodeSpaceMapByWorkspaceAndUser(workspaceID, pubKeyFromAuth)
+		codeSpaceResult, err := ch.db.GetCodeSpaceMapByWorkspaceAndUser(workspaceID, pubkey)
 		if err == nil {
 			codeSpace = codeSpaceResult
 		}
 	}
 
-	vars := buildVarsPayload(request, &createdMessage, messageHistory, context, &user, codeGraph, codeSpace, mode)
+	vars := buildVarsPayload(request, createdMessage, messageHistory, context, user, codeGraph, codeSpace, mode)
+	if chatContext.Summary != "" {
+		vars["historySummary"] = chatContext.Summary
+	}
+	if chatContext.Digest != "" {
+		vars["historyDigest"] = chatContext.Digest
+	}
+	if chatContext.SummaryRequest != nil {
+		vars["summarizeHistory"] = chatContext.SummaryRequest
+	}
+	if len(chatContext.Pinned) > 0 {
+		vars["pinnedContext"] = chatContextEntries(chatContext.Pinned)
+	}
+	if len(createdMessage.ContextTags) > 0 {
+		// tags are only resolved within the chat's own workspace and for its members
+		if chat, err := ch.db.GetChatByChatID(request.ChatID); err == nil && ch.db.IsWorkspaceMember(pubkey, chat.WorkspaceID) {
+			if tags := ch.resolveContextTags(chat.WorkspaceID, createdMessage.ContextTags); len(tags) > 0 {
+				vars["contextTagContent"] = tags
+			}
+		}
+	}
 
 	stakworkPayload := StakworkChatPayload{
 		Name:       "Hive Chat Processor",
@@ -627,30 +735,16 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 
 	apiKey := os.Getenv(apiKeyEnv)
 	if apiKey == "" {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ChatResponse{
-			Success: false,
-			Message: "environment variable is not set",
-		})
-		return
+		return 0, errStakworkKeyMissing
 	}
 
-	projectID, err := ch.sendToStakwork(stakworkPayload, apiKey)
-	if err != nil {
-		createdMessage.Status = "error"
-		ch.db.UpdateChatMessage(&createdMessage)
-
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ChatResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to process message: %v", err),
-		})
-		return
-	}
+	return ch.sendToStakwork(stakworkPayload, apiKey)
+}
 
+func notifyChatMessageSent(sourceWebsocketID string, projectID int64, createdMessage db.ChatMessage) {
 	projectMsg := websocket.TicketMessage{
 		BroadcastType:   "direct",
-		SourceSessionID: request.SourceWebsocketID,
+		SourceSessionID: sourceWebsocketID,
 		Message:         fmt.Sprintf("https://jobs.stakwork.com/admin/projects/%d", projectID),
 		Action:          "swrun",
 	}
@@ -661,7 +755,7 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 
 	wsMessage := websocket.TicketMessage{
 		BroadcastType:   "direct",
-		SourceSessionID: request.SourceWebsocketID,
+		SourceSessionID: sourceWebsocketID,
 		Message:         "Message sent",
 		Action:          "process",
 		ChatMessage:     createdMessage,
@@ -670,13 +764,6 @@ func (ch *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	if err := websocket.WebsocketPool.SendTicketMessage(wsMessage); err != nil {
 		log.Printf("Failed to send websocket message: %v", err)
 	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(ChatResponse{
-		Success: true,
-		Message: "Message sent successfully",
-		Data:    createdMessage,
-	})
 }
 
 func (ch *ChatHandler) sendToStakwork(payload StakworkChatPayload, apiKey string) (int64, error) {
@@ -782,14 +869,16 @@ func (ch *ChatHandler) GetChat(w http.ResponseWriter, r *http.Request) {
 // GetChatHistory retrieves the history of a chat
 //
 //	@Summary		Retrieve chat history
-//	@Description	Retrieve the history of a chat with the given ID
+//	@Description	Retrieve the history of a chat with the given ID, following one branch of edited messages
 //	@Tags			Hive Chat
 //	@Produce		json
 //	@Security		PubKeyContextAuth
-//	@Param			uuid	path		string	true	"Chat ID"
-//	@Success		200		{object}	HistoryChatResponse
-//	@Failure		400		{object}	ChatResponse
-//	@Failure		500		{object}	ChatResponse
+//	@Param			uuid		path		string	true	"Chat ID"
+//	@Param			branch_id	query		string	false	"Branch ID, main for the original messages, defaults to the active branch"
+//	@Success		200			{object}	HistoryChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
 //	@Router			/hivechat/history/{uuid} [get]
 func (ch *ChatHandler) GetChatHistory(w http.ResponseWriter, r *http.Request) {
 	chatID := chi.URLParam(r, "uuid")
@@ -802,7 +891,22 @@ func (ch *ChatHandler) GetChatHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := ch.db.GetChatMessagesForChatID(chatID)
+	branchID := chatBranchParam(r.URL.Query().Get("branch_id"))
+	if r.URL.Query().Get("branch_id") == "" {
+		if chat, err := ch.db.GetChatByChatID(chatID); err == nil {
+			branchID = chat.ActiveBranchID
+		}
+	}
+
+	messages, err := ch.db.GetChatBranchHistory(chatID, branchID)
+	if errors.Is(err, db.ErrChatBranchNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat branch not found",
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
@@ -885,6 +989,13 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 		Source:    "agent",
 	}
 
+	// answers continue the branch of the message they reply to
+	if request.Value.MessageID != "" {
+		if userMessage, err := ch.db.GetChatMessageByID(request.Value.MessageID); err == nil && userMessage.ChatID == request.Value.ChatID {
+			message.BranchID = userMessage.BranchID
+		}
+	}
+
 	createdMessage, err := ch.db.AddChatMessage(message)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -937,6 +1048,10 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 	if err := websocket.WebsocketPool.SendTicketMessage(wsMessage); err != nil {
 		log.Printf("Failed to send websocket message: %v", err)
 	}
+	publishChatEvent(createdMessage.ChatID, "chat_message", map[string]interface{}{
+		"message":   createdMessage,
+		"artifacts": artifacts,
+	})
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(ChatResponse{
@@ -955,12 +1070,41 @@ func (ch *ChatHandler) ProcessChatResponse(w http.ResponseWriter, r *http.Reques
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Security		PubKeyContextAuth
-//	@Param			file	formData	file	true	"File to upload"
-//	@Success		200		{object}	FileResponse
-//	@Failure		400		{object}	ChatResponse
+//	@Param			file		formData	file	true	"File to upload"
+//	@Param			workspaceId	query		string	true	"Workspace the file is stored under"
+//	@Success		200			{object}	FileResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		413		{object}	ChatResponse
 //	@Failure		500		{object}	ChatResponse
 //	@Router			/hivechat/upload [post]
 func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceID := r.URL.Query().Get("workspaceId")
+	if workspaceID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "workspaceId is required",
+		})
+		return
+	}
+
+	if !ch.db.IsWorkspaceMember(pubKeyFromAuth, workspaceID) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Don't have access to this workspace",
+		})
+		return
+	}
+
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -1016,23 +1160,34 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 
 	file.Seek(0, 0)
 
-	uploadFilename := uuid.New().String() + filepath.Ext(header.Filename)
-
-	challenge := GetMemeChallenge()
-	signer := SignChallenge(challenge.Challenge)
-	mErr, mToken := GetMemeToken(challenge.Id, signer.Response.Sig)
-
-	if mErr != "" {
+	used, err := ch.db.GetWorkspaceFileUsage(workspaceID)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
-			Message: "Failed to get meme token",
+			Message: "Failed to check storage quota",
 		})
 		return
 	}
+	if err := db.CheckFileQuota(used, header.Size, config.WorkspaceStorageQuota); err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Workspace storage quota exceeded",
+			Data: map[string]int64{
+				"used":  used,
+				"quota": config.WorkspaceStorageQuota,
+				"size":  header.Size,
+			},
+		})
+		return
+	}
+
+	uploadFilename := uuid.New().String() + filepath.Ext(header.Filename)
 
-	err, uploadURL := UploadMemeImage(file, mToken.Token, header.Filename)
+	blob, err := ch.uploads.Put(r.Context(), "chat/"+uploadFilename, file, header.Size, mimeType)
 	if err != nil {
+		logger.Log.Error("Failed to upload file to %s store: %v", ch.uploads.Name(), err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
@@ -1040,6 +1195,7 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	uploadURL := blob.URL
 
 	asset := &db.FileAsset{
 		OriginFilename: header.Filename,
@@ -1048,9 +1204,11 @@ func (ch *ChatHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		FileSize:       header.Size,
 		MimeType:       mimeType,
 		Status:         db.ActiveFileStatus,
-		UploadedBy:     r.Context().Value("pubkey").(string),
+		UploadedBy:     pubKeyFromAuth,
 		StoragePath:    uploadURL,
-		WorkspaceID:    r.URL.Query().Get("workspaceId"),
+		StorageBackend: ch.uploads.Name(),
+		StorageKey:     blob.Key,
+		WorkspaceID:    workspaceID,
 	}
 
 	asset, err = ch.db.CreateFileAsset(asset)
@@ -1504,49 +1662,26 @@ func (ch *ChatHandler) SendActionMessage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	history, err := ch.db.GetChatMessagesForChatID(request.ChatID)
+	chat, err := ch.db.GetChatByChatID(request.ChatID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to get chat history: %v", err),
+			Message: fmt.Sprintf("Failed to get chat: %v", err),
 		})
 		return
 	}
 
-	start := 0
-	if len(history) > 20 {
-		start = len(history) - 20
-	}
-	recentHistory := history[start:]
-
-	messageHistory := make([]map[string]string, len(recentHistory))
-	for i, msg := range recentHistory {
-		artefacts, err := ch.db.GetArtifactsByMessageID(msg.ID)
-		if err != nil {
-			artefacts = []db.Artifact{}
-		}
-
-		artifactJSON, err := json.Marshal(map[string]interface{}{"artifacts": buildArtifactList(artefacts)})
-		if err != nil {
-			artifactJSON = []byte(`{"artifacts": []}`)
-		}
-
-		messageHistory[i] = map[string]string{
-			"role":    string(msg.Role),
-			"content": msg.Message + "\nArtifacts: " + string(artifactJSON),
-		}
-	}
-
-	chat, err := ch.db.GetChatByChatID(request.ChatID)
+	history, err := ch.db.GetChatBranchHistory(request.ChatID, chat.ActiveBranchID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ChatResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to get chat: %v", err),
+			Message: fmt.Sprintf("Failed to get chat history: %v", err),
 		})
 		return
 	}
+	chatContext := ch.buildChatContext(request.ChatID, chat.WorkspaceID, chat.ActiveBranchID, history)
 
 	codeGraph, err := ch.db.GetCodeGraphByWorkspaceUuid(chat.WorkspaceID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -1566,6 +1701,7 @@ func (ch *ChatHandler) SendActionMessage(w http.ResponseWriter, r *http.Request)
 		Timestamp: time.Now(),
 		Status:    "sending",
 		Source:    "user",
+		BranchID:  chat.ActiveBranchID,
 	}
 
 	createdMessage, err := ch.db.AddChatMessage(message)
@@ -1590,7 +1726,11 @@ func (ch *ChatHandler) SendActionMessage(w http.ResponseWriter, r *http.Request)
 		ChatID:            request.ChatID,
 		MessageID:         request.MessageID,
 		Message:           request.Message,
-		History:           messageHistory,
+		History:           chatContextEntries(chatContext.History),
+		HistorySummary:    chatContext.Summary,
+		HistoryDigest:     chatContext.Digest,
+		SummarizeHistory:  chatContext.SummaryRequest,
+		PinnedContext:     chatContextEntries(chatContext.Pinned),
 		SourceWebsocketID: request.SourceWebsocketID,
 		WebhookURL:        fmt.Sprintf("%s/hivechat/response", os.Getenv("HOST")),
 	}
@@ -1601,7 +1741,7 @@ func (ch *ChatHandler) SendActionMessage(w http.ResponseWriter, r *http.Request)
 			url = "https://" + url
 		}
 		payload.CodeGraph = url
-		payload.CodeGraphAlias = codeGraph.SecretAlias
+		payload.CodeGraphAlias = codeGraph.SecretAlias.String()
 	}
 
 	if codeSpace.CodeSpaceURL != "" {
@@ -1697,6 +1837,15 @@ func (ch *ChatHandler) CreateOrEditChatWorkflow(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	if err := db.ValidateChatContextSettings(db.ChatContextSettings{HistorySize: request.HistorySize, TokenBudget: request.TokenBudget}); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatWorkflowResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
 	workspace := ch.db.GetWorkspaceByUuid(request.WorkspaceID)
 	if workspace.Uuid == "" {
 		w.WriteHeader(http.StatusNotFound)
@@ -1711,6 +1860,8 @@ func (ch *ChatHandler) CreateOrEditChatWorkflow(w http.ResponseWriter, r *http.R
 		WorkspaceID: request.WorkspaceID,
 		URL:         request.URL,
 		StackworkID: request.StackworkID,
+		HistorySize: request.HistorySize,
+		TokenBudget: request.TokenBudget,
 	}
 
 	result, err := ch.db.CreateOrEditChatWorkflow(workflow)
@@ -1932,9 +2083,14 @@ func (ch *ChatHandler) StartSSEClient(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := sse.NewClient(request.SSEURL, request.ChatID, webhookURL, ch.db)
-	sse.ClientRegistry.Register(client)
-
-	go client.Start()
+	if err := client.Start(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to start SSE client: %v", err),
+		})
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(ChatResponse{
@@ -1979,9 +2135,10 @@ func HandleSSEConnectionArtifact(database db.Database, artifact ChatMessageArtif
 	}
 
 	client := sse.NewClient(sseURL, chatID, webhookURL, database)
-	sse.ClientRegistry.Register(client)
-
-	go client.Start()
+	if err := client.Start(); err != nil {
+		log.Printf("Failed to start SSE client for chatID %s: %v", chatID, err)
+		return
+	}
 
 	log.Printf("Started SSE client for chatID %s connecting to %s", chatID, sseURL)
 
@@ -1994,6 +2151,9 @@ func HandleSSEConnectionArtifact(database db.Database, artifact ChatMessageArtif
 	}
 }
 
+// SSEEventsWebhook is the event of the webhooks forwarding SSE events
+const SSEEventsWebhook = "sse.events"
+
 func SendEventPayloadToWebhook(database db.Database, chatID string, webhookURL string, delayMs int64) {
 	if delayMs > 0 {
 		time.Sleep(time.Duration(delayMs) * time.Millisecond)
@@ -2023,22 +2183,10 @@ func SendEventPayloadToWebhook(database db.Database, chatID string, webhookURL s
 		"sse_url": sseURL,
 	}
 
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("Error marshaling webhook payload: %v", err)
-		return
-	}
-
-	resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(payloadJSON))
-	if err != nil {
-		log.Printf("Error sending events to webhook %s: %v", webhookURL, err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("Webhook returned non-success status %d: %s", resp.StatusCode, string(body))
+	// the dispatcher retries the batch until it is delivered or dead, so the
+	// events are handed over to it rather than sent again with the next batch
+	if _, err := webhook.NewDispatcher(database).Enqueue(webhookURL, SSEEventsWebhook, payload); err != nil {
+		log.Printf("Error queueing events for webhook %s: %v", webhookURL, err)
 		return
 	}
 
@@ -2053,7 +2201,7 @@ func SendEventPayloadToWebhook(database db.Database, chatID string, webhookURL s
 		return
 	}
 
-	log.Printf("Successfully sent %d events for chatID %s to webhook %s", len(unsentEvents), chatID, webhookURL)
+	log.Printf("Queued %d events for chatID %s to webhook %s", len(unsentEvents), chatID, webhookURL)
 }
 
 func (ch *ChatHandler) GetAllSSEMessagesByChatID(w http.ResponseWriter, r *http.Request) {
@@ -2510,6 +2658,7 @@ func (ch *ChatHandler) HandleChatWebhook(w http.ResponseWriter, r *http.Request)
 
 	logger.Log.Info("Created chat status for chat %s: %s - %s",
 		chatID, createdStatus.Status, createdStatus.Message)
+	publishChatEvent(chatID, "chat_status", createdStatus)
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(ChatStatusWebhookResponse{
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type EditMessageRequest struct {
+	Message           string `json:"message"`
+	SourceWebsocketID string `json:"sourceWebsocketId"`
+	ModelSelection    string `json:"modelSelection,omitempty"`
+	Mode              string `json:"mode,omitempty"`
+}
+
+type ReplayBranchRequest struct {
+	SourceWebsocketID string `json:"sourceWebsocketId"`
+	ModelSelection    string `json:"modelSelection,omitempty"`
+	Mode              string `json:"mode,omitempty"`
+}
+
+type ChatBranchesResponse struct {
+	Success        bool            `json:"success"`
+	ActiveBranchID string          `json:"activeBranchId"`
+	Data           []db.ChatBranch `json:"data"`
+}
+
+// chatBranchParam maps the main branch name used in paths and queries to its ID
+func chatBranchParam(branchID string) string {
+	if branchID == "main" {
+		return db.MainChatBranch
+	}
+	return branchID
+}
+
+// EditChatMessage edits a user message on a new branch
+//
+//	@Summary		Edit a chat message
+//	@Description	Forks the chat before a user message and sends the edited text on the new branch with only the history of that branch. The original branch is kept and the new branch becomes the active one.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string				true	"Chat ID"
+//	@Param			message_id	path		string				true	"Message ID"
+//	@Param			request		body		EditMessageRequest	true	"Edited message"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/messages/{message_id}/edit [post]
+func (ch *ChatHandler) EditChatMessage(w http.ResponseWriter, r *http.Request) {
+	user, pubKeyFromAuth, ok := ch.chatBranchUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Message == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	chat, ok := ch.chatFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	branch, createdMessage, err := ch.db.ForkChatMessage(chat.ID, chi.URLParam(r, "message_id"), request.Message, pubKeyFromAuth)
+	if errors.Is(err, db.ErrChatMessageNotEditable) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to edit message: %v", chat.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to edit message: %v", err),
+		})
+		return
+	}
+
+	history, err := ch.db.GetChatBranchHistory(chat.ID, branch.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat history: %v", err),
+		})
+		return
+	}
+
+	ch.sendBranchMessage(w, chat, &createdMessage, history[:len(history)-1], &user, pubKeyFromAuth, ReplayBranchRequest{
+		SourceWebsocketID: request.SourceWebsocketID,
+		ModelSelection:    request.ModelSelection,
+		Mode:              request.Mode,
+	})
+}
+
+// GetChatBranches lists the branches of a chat
+//
+//	@Summary		List chat branches
+//	@Description	Lists the branches created by editing messages of a chat, oldest first, and the branch the chat continues on. The main branch has an empty ID.
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Success		200		{object}	ChatBranchesResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/branches [get]
+func (ch *ChatHandler) GetChatBranches(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	branches, err := ch.db.GetChatBranches(chat.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat branches: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatBranchesResponse{
+		Success:        true,
+		ActiveBranchID: chat.ActiveBranchID,
+		Data:           branches,
+	})
+}
+
+// ActivateChatBranch switches the branch a chat continues on
+//
+//	@Summary		Activate a chat branch
+//	@Description	Makes a branch the one new messages and the history continue on, use main for the original messages
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string	true	"Chat ID"
+//	@Param			branch_id	path		string	true	"Branch ID"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/branches/{branch_id}/activate [put]
+func (ch *ChatHandler) ActivateChatBranch(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	updatedChat, err := ch.db.SetActiveChatBranch(chat.ID, chatBranchParam(chi.URLParam(r, "branch_id")))
+	if errors.Is(err, db.ErrChatBranchNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat branch not found",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to activate chat branch: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Chat branch activated",
+		Data:    updatedChat,
+	})
+}
+
+// ReplayChatBranch sends the last user message of a branch to the workflow again
+//
+//	@Summary		Replay a chat branch
+//	@Description	Regenerates the response to the last user message of a branch, sending it to the workflow with only the history of that branch before it
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string				true	"Chat ID"
+//	@Param			branch_id	path		string				true	"Branch ID"
+//	@Param			request		body		ReplayBranchRequest	false	"Replay options"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/branches/{branch_id}/replay [post]
+func (ch *ChatHandler) ReplayChatBranch(w http.ResponseWriter, r *http.Request) {
+	user, pubKeyFromAuth, ok := ch.chatBranchUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request ReplayBranchRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Invalid request body",
+			})
+			return
+		}
+	}
+
+	chat, ok := ch.chatFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := ch.db.GetChatBranchHistory(chat.ID, chatBranchParam(chi.URLParam(r, "branch_id")))
+	if errors.Is(err, db.ErrChatBranchNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat branch not found",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch chat history: %v", err),
+		})
+		return
+	}
+
+	last := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Branch has no user message to replay",
+		})
+		return
+	}
+
+	message := history[last]
+	message.Status = "sending"
+	if _, err := ch.db.UpdateChatMessage(&message); err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to update replayed message: %v", chat.ID, err)
+	}
+
+	ch.sendBranchMessage(w, chat, &message, history[:last], &user, pubKeyFromAuth, request)
+}
+
+func (ch *ChatHandler) chatBranchUser(w http.ResponseWriter, r *http.Request) (db.Person, string, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return db.Person{}, "", false
+	}
+
+	user := ch.db.GetPersonByPubkey(pubKeyFromAuth)
+	if user.OwnerPubKey != pubKeyFromAuth {
+		logger.Log.Info("Person not exists")
+		w.WriteHeader(http.StatusBadRequest)
+		return db.Person{}, "", false
+	}
+	return user, pubKeyFromAuth, true
+}
+
+func (ch *ChatHandler) chatFromPath(w http.ResponseWriter, r *http.Request) (db.Chat, bool) {
+	chat, err := ch.db.GetChatByChatID(chi.URLParam(r, "chat_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat not found",
+		})
+		return db.Chat{}, false
+	}
+	return chat, true
+}
+
+// sendBranchMessage sends a saved user message with the history of its branch
+// before it to the workflow and writes the response
+func (ch *ChatHandler) sendBranchMessage(w http.ResponseWriter, chat db.Chat, message *db.ChatMessage, history []db.ChatMessage, user *db.Person, pubkey string, options ReplayBranchRequest) {
+	context, err := ch.db.GetProductBrief(chat.WorkspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Error retrieving product brief",
+		})
+		return
+	}
+
+	request := SendMessageRequest{
+		ChatID:            chat.ID,
+		Message:           message.Message,
+		PDFURL:            message.PDFURL,
+		ModelSelection:    options.ModelSelection,
+		SourceWebsocketID: options.SourceWebsocketID,
+		WorkspaceUUID:     chat.WorkspaceID,
+		Mode:              options.Mode,
+		BranchID:          message.BranchID,
+	}
+
+	projectID, err := ch.dispatchChatMessage(request, message, history, context, user, pubkey)
+	if errors.Is(err, errStakworkKeyMissing) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		message.Status = "error"
+		ch.db.UpdateChatMessage(message)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to process message: %v", err),
+		})
+		return
+	}
+
+	notifyChatMessageSent(options.SourceWebsocketID, projectID, *message)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Message sent successfully",
+		Data:    *message,
+	})
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+)
+
+type PinRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+// PinChatMessage pins or unpins a chat message
+//
+//	@Summary		Pin a chat message
+//	@Description	Pinned messages are sent as context with every new message of the chat, even after they leave the history window
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string		true	"Chat ID"
+//	@Param			message_id	path		string		true	"Message ID"
+//	@Param			request		body		PinRequest	true	"Pin state"
+//	@Success		200			{object}	ChatResponse
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/messages/{message_id}/pin [put]
+func (ch *ChatHandler) PinChatMessage(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatForMember(w, r)
+	if !ok {
+		return
+	}
+
+	var request PinRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	message, err := ch.db.SetChatMessagePinned(chat.ID, chi.URLParam(r, "message_id"), request.Pinned)
+	if errors.Is(err, db.ErrChatMessageNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Message not found",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to pin message: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data:    message,
+	})
+}
+
+// PinArtefact pins or unpins an artifact
+//
+//	@Summary		Pin an artifact
+//	@Description	Pinned artifacts are sent as context with every new message of their chat and are never left out to fit the token budget
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			artifactId	path		string		true	"Artifact ID"
+//	@Param			request		body		PinRequest	true	"Pin state"
+//	@Success		200			{object}	db.Artifact
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Router			/hivechat/artefacts/{artifactId}/pin [put]
+func (ch *ChatHandler) PinArtefact(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "artifactId"))
+	if err != nil {
+		jsonErrorResponse(w, "Invalid artifact ID", http.StatusBadRequest)
+		return
+	}
+
+	var request PinRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		jsonErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// an artifact belongs to the chat of its message, only users of that
+	// chat's workspace may pin it
+	artifact, err := ch.db.GetArtifactByID(id)
+	if err != nil {
+		jsonErrorResponse(w, fmt.Sprintf("Failed to fetch artifact: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if artifact == nil {
+		jsonErrorResponse(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	message, err := ch.db.GetChatMessageByID(artifact.MessageID)
+	if err != nil {
+		jsonErrorResponse(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	chat, err := ch.db.GetChatByChatID(message.ChatID)
+	if err != nil {
+		jsonErrorResponse(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" || !ch.db.IsWorkspaceMember(pubKeyFromAuth, chat.WorkspaceID) {
+		jsonErrorResponse(w, "Don't have access to this artifact", http.StatusUnauthorized)
+		return
+	}
+
+	artifact, err = ch.db.SetArtifactPinned(id, request.Pinned)
+	if errors.Is(err, db.ErrArtifactNotFound) {
+		jsonErrorResponse(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonErrorResponse(w, fmt.Sprintf("Failed to pin artifact: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(artifact)
+}
+
+type ChatSummaryPayload struct {
+	BranchID  string `json:"branchId"`
+	ThroughID string `json:"throughId"`
+	Summary   string `json:"summary"`
+}
+
+// HandleChatSummaryWebhook stores the summary the workflow was asked for
+//
+//	@Summary		Process chat summary webhook
+//	@Description	Receives the summary of the messages that left the context window of a chat branch, up to and including throughId, from the chat workflow
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Param			chat_id	path		string				true	"Chat ID"
+//	@Param			payload	body		ChatSummaryPayload	true	"Summary"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		409		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/summary [post]
+func (ch *ChatHandler) HandleChatSummaryWebhook(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var payload ChatSummaryPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.ThroughID == "" || strings.TrimSpace(payload.Summary) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "A summary and the ID of the last summarized message are required",
+		})
+		return
+	}
+
+	updated, err := ch.db.UpdateChatSummary(chat.ID, payload.BranchID, payload.ThroughID, payload.Summary)
+	if errors.Is(err, db.ErrChatMessageNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Message not found",
+		})
+		return
+	}
+	if errors.Is(err, db.ErrChatSummaryStale) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save summary: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data:    updated,
+	})
+}
+
+func requestContextTags(request SendMessageRequest) []db.ContextTag {
+	tags := []db.ContextTag{}
+	for _, tag := range request.ContextTags {
+		tags = append(tags, db.ContextTag{Type: db.ContextTagType(tag.Type), ID: tag.ID})
+	}
+	return tags
+}
+
+// resolveContextTags turns the tags of a message into the text sent with it,
+// within the configured size limits
+func (ch *ChatHandler) resolveContextTags(workspaceID string, tags []db.ContextTag) []db.ResolvedContextTag {
+	if len(tags) == 0 {
+		return []db.ResolvedContextTag{}
+	}
+
+	resolved := ch.db.ResolveContextTags(workspaceID, tags)
+	for i, tag := range resolved {
+		if tag.Type != db.FileContext || tag.Error != "" {
+			continue
+		}
+		if text := ch.fileContextText(tag.ID); text != "" {
+			resolved[i].Content += "\n\nContent:\n" + text
+		}
+	}
+	return db.LimitContextTags(resolved, config.ContextTagMaxChars, config.ContextTagsMaxChars)
+}
+
+// fileContextText reads the start of an uploaded text file, other files are
+// only described by their metadata
+func (ch *ChatHandler) fileContextText(fileID string) string {
+	id, err := strconv.ParseUint(fileID, 10, 64)
+	if err != nil {
+		return ""
+	}
+	asset, err := ch.db.GetFileAssetByID(uint(id))
+	if err != nil || asset.StorageBackend == "" || asset.StorageKey == "" || !isTextMimeType(asset.MimeType) {
+		return ""
+	}
+
+	store, err := NewBlobStore(asset.StorageBackend, "uploads")
+	if err != nil {
+		return ""
+	}
+	body, err := store.Get(context.Background(), asset.StorageKey)
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	// a rune is at most four bytes
+	data, err := io.ReadAll(io.LimitReader(body, int64(config.ContextTagMaxChars)*4))
+	if err != nil {
+		return ""
+	}
+	data = trimPartialRune(data)
+	if !utf8.Valid(data) {
+		return ""
+	}
+	return string(data)
+}
+
+// trimPartialRune drops the bytes of a rune cut in half at the end of data
+func trimPartialRune(data []byte) []byte {
+	for i := 0; i < utf8.UTFMax-1 && len(data) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(data); r != utf8.RuneError || size > 1 {
+			return data
+		}
+		data = data[:len(data)-1]
+	}
+	return data
+}
+
+func isTextMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	switch mimeType {
+	case "application/json", "application/xml", "application/x-yaml", "application/yaml":
+		return true
+	}
+	return strings.HasPrefix(mimeType, "text/")
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stakwork/sphinx-tribes/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveContextTags(t *testing.T) {
+	dir, maxChars, totalChars := config.BlobLocalDir, config.ContextTagMaxChars, config.ContextTagsMaxChars
+	defer func() {
+		config.BlobLocalDir, config.ContextTagMaxChars, config.ContextTagsMaxChars = dir, maxChars, totalChars
+	}()
+	config.BlobLocalDir = t.TempDir()
+	config.ContextTagMaxChars = 40
+	config.ContextTagsMaxChars = 60
+
+	local := storage.NewLocalStore(config.BlobLocalDir, "http://localhost/blobs")
+	blob, err := local.Put(context.Background(), "chat/notes.md", strings.NewReader("# Notes"), 7, "text/markdown")
+	require.NoError(t, err)
+
+	tags := []db.ContextTag{
+		{Type: db.FileContext, ID: "1"},
+		{Type: db.FileContext, ID: "2"},
+		{Type: db.TicketContext, ID: "t1"},
+	}
+	mockDb := mocks.NewDatabase(t)
+	mockDb.On("ResolveContextTags", "ws1", tags).Return([]db.ResolvedContextTag{
+		{Type: db.FileContext, ID: "1", Content: "File: notes.md"},
+		{Type: db.FileContext, ID: "2", Content: "File: logo.png"},
+		{Type: db.TicketContext, ID: "t1", Content: strings.Repeat("t", 50)},
+	})
+	mockDb.On("GetFileAssetByID", uint(1)).Return(&db.FileAsset{ID: 1, MimeType: "text/markdown; charset=utf-8", StorageBackend: storage.LocalKind, StorageKey: blob.Key}, nil)
+	mockDb.On("GetFileAssetByID", uint(2)).Return(&db.FileAsset{ID: 2, MimeType: "image/png", StorageBackend: storage.LocalKind, StorageKey: "chat/logo.png"}, nil)
+
+	ch := &ChatHandler{db: mockDb}
+	resolved := ch.resolveContextTags("ws1", tags)
+
+	require.Len(t, resolved, 3)
+	assert.Equal(t, "File: notes.md\n\nContent:\n# Notes", resolved[0].Content)
+	assert.Equal(t, "File: logo.png", resolved[1].Content)
+	assert.Len(t, resolved[2].Content, 60-len(resolved[0].Content)-len(resolved[1].Content))
+	assert.True(t, resolved[2].Truncated)
+
+	assert.Empty(t, ch.resolveContextTags("ws1", nil))
+}
+
+func TestIsTextMimeType(t *testing.T) {
+	assert.True(t, isTextMimeType("text/plain"))
+	assert.True(t, isTextMimeType("Application/JSON; charset=utf-8"))
+	assert.False(t, isTextMimeType("application/pdf"))
+}
+
+func TestTrimPartialRune(t *testing.T) {
+	text := []byte("héllo wörld")
+	assert.Equal(t, "héllo w", string(trimPartialRune(text[:len("héllo wö")-1])))
+	assert.Equal(t, "héllo wö", string(trimPartialRune(text[:len("héllo wö")])))
+	assert.Equal(t, "🙂", string(trimPartialRune(append([]byte("🙂"), []byte("🙂")[:3]...))))
+	assert.Empty(t, trimPartialRune(nil))
+}
+
+func TestSendMessageContextTagsAccess(t *testing.T) {
+	chat := db.Chat{ID: "chat1", WorkspaceID: "ws1"}
+	body := `{"chat_id":"chat1","message":"hi","workspaceUUID":"ws2","contextTags":[{"type":"ticket","id":"t1"}]}`
+
+	mockDb := mocks.NewDatabase(t)
+	mockDb.On("GetPersonByPubkey", "stranger").Return(db.Person{OwnerPubKey: "stranger"}).Once()
+	mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+	mockDb.On("IsWorkspaceMember", "stranger", chat.WorkspaceID).Return(false).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/hivechat/send", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.ContextKey, "stranger"))
+	rr := httptest.NewRecorder()
+
+	ch := &ChatHandler{db: mockDb}
+	ch.SendMessage(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	mockDb.AssertNotCalled(t, "ResolveContextTags", mock.Anything, mock.Anything)
+}
+
+func TestPinAccess(t *testing.T) {
+	chat := db.Chat{ID: "chat1", WorkspaceID: "ws1"}
+	message := db.ChatMessage{ID: "message1", ChatID: chat.ID}
+	artifact := &db.Artifact{ID: uuid.New(), MessageID: message.ID}
+
+	request := func(params map[string]string, pubkey string) *http.Request {
+		rctx := chi.NewRouteContext()
+		for key, value := range params {
+			rctx.URLParams.Add(key, value)
+		}
+		ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, auth.ContextKey, pubkey)
+		return httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"pinned":true}`)).WithContext(ctx)
+	}
+
+	t.Run("rejects pinning a message by a non member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+		mockDb.On("IsWorkspaceMember", "stranger", chat.WorkspaceID).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		ch.PinChatMessage(rr, request(map[string]string{"chat_id": chat.ID, "message_id": message.ID}, "stranger"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockDb.AssertNotCalled(t, "SetChatMessagePinned", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("pins a message for a member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+		mockDb.On("IsWorkspaceMember", "member", chat.WorkspaceID).Return(true).Once()
+		mockDb.On("SetChatMessagePinned", chat.ID, message.ID, true).Return(db.ChatMessage{ID: message.ID, Pinned: true}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.PinChatMessage(rr, request(map[string]string{"chat_id": chat.ID, "message_id": message.ID}, "member"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("rejects pinning an artifact by a non member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil).Once()
+		mockDb.On("GetChatMessageByID", message.ID).Return(message, nil).Once()
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+		mockDb.On("IsWorkspaceMember", "stranger", chat.WorkspaceID).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		ch.PinArtefact(rr, request(map[string]string{"artifactId": artifact.ID.String()}, "stranger"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockDb.AssertNotCalled(t, "SetArtifactPinned", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns not found for an artifact without a message", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil).Once()
+		mockDb.On("GetChatMessageByID", message.ID).Return(db.ChatMessage{}, errors.New("message not found")).Once()
+
+		rr := httptest.NewRecorder()
+		ch.PinArtefact(rr, request(map[string]string{"artifactId": artifact.ID.String()}, "member"))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("pins an artifact for a member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetArtifactByID", artifact.ID).Return(artifact, nil).Once()
+		mockDb.On("GetChatMessageByID", message.ID).Return(message, nil).Once()
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+		mockDb.On("IsWorkspaceMember", "member", chat.WorkspaceID).Return(true).Once()
+		mockDb.On("SetArtifactPinned", artifact.ID, true).Return(&db.Artifact{ID: artifact.ID, Pinned: true}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.PinArtefact(rr, request(map[string]string{"artifactId": artifact.ID.String()}, "member"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestChatSummaryWebhook(t *testing.T) {
+	chat := db.Chat{ID: "chat1", WorkspaceID: "ws1"}
+
+	request := func(body string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("chat_id", chat.ID)
+		ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+		return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(ctx)
+	}
+
+	t.Run("rejects a payload without a summary", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.HandleChatSummaryWebhook(rr, request(`{"throughId":"message1","summary":" "}`))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("stores the summary", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+		mockDb.On("UpdateChatSummary", chat.ID, db.MainChatBranch, "message1", "what was said").Return(db.Chat{ID: chat.ID, Summary: "what was said"}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.HandleChatSummaryWebhook(rr, request(`{"throughId":"message1","summary":"what was said"}`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("refuses a summary older than the stored one", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		ch := &ChatHandler{db: mockDb}
+		mockDb.On("GetChatByChatID", chat.ID).Return(chat, nil).Once()
+		mockDb.On("UpdateChatSummary", chat.ID, db.MainChatBranch, "message1", "what was said").Return(db.Chat{}, db.ErrChatSummaryStale).Once()
+
+		rr := httptest.NewRecorder()
+		ch.HandleChatSummaryWebhook(rr, request(`{"throughId":"message1","summary":"what was said"}`))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/utils"
+)
+
+type ChatShareRequest struct {
+	ExpiresInHours int    `json:"expiresInHours"`
+	WorkspaceOnly  bool   `json:"workspaceOnly"`
+	BranchID       string `json:"branchId,omitempty"`
+}
+
+type ChatShareLinkView struct {
+	db.ChatShareLink
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+}
+
+func chatShareLinkView(link db.ChatShareLink) ChatShareLinkView {
+	return ChatShareLinkView{
+		ChatShareLink: link,
+		URL:           fmt.Sprintf("%s/hivechat/shared/%s", config.Host, link.Token),
+		Active:        link.Active(time.Now()),
+	}
+}
+
+// ExportChat downloads a chat transcript
+//
+//	@Summary		Export a chat
+//	@Description	Exports the messages of a chat branch with their artifacts and context tags as markdown, JSON or PDF
+//	@Tags			Hive Chat
+//	@Produce		json,text/markdown,application/pdf
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id		path		string	true	"Chat ID"
+//	@Param			format		query		string	false	"markdown, json or pdf, defaults to markdown"
+//	@Param			branch_id	query		string	false	"Branch ID, main for the original messages, defaults to the active branch"
+//	@Success		200			{object}	db.ChatExport
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		500			{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/export [get]
+func (ch *ChatHandler) ExportChat(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatForMember(w, r)
+	if !ok {
+		return
+	}
+
+	branchID := chat.ActiveBranchID
+	if r.URL.Query().Get("branch_id") != "" {
+		branchID = chatBranchParam(r.URL.Query().Get("branch_id"))
+	}
+
+	ch.writeChatExport(w, chat.ID, branchID, r.URL.Query().Get("format"))
+}
+
+// CreateChatShareLink creates a read only link to a chat
+//
+//	@Summary		Share a chat
+//	@Description	Creates a read only link to a chat branch that expires after the given hours, a week by default and 90 days at most. Workspace only links can only be opened by users of the workspace.
+//	@Tags			Hive Chat
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string				true	"Chat ID"
+//	@Param			request	body		ChatShareRequest	true	"Share options"
+//	@Success		200		{object}	ChatResponse{data=ChatShareLinkView}
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		401		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/share [post]
+func (ch *ChatHandler) CreateChatShareLink(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatForMember(w, r)
+	if !ok {
+		return
+	}
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+
+	var request ChatShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	branchID := chat.ActiveBranchID
+	if request.BranchID != "" {
+		branchID = chatBranchParam(request.BranchID)
+	}
+	if _, err := ch.db.GetChatBranchHistory(chat.ID, branchID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat branch not found",
+		})
+		return
+	}
+
+	link, err := ch.db.CreateChatShareLink(db.ChatShareLink{
+		ChatID:        chat.ID,
+		BranchID:      branchID,
+		WorkspaceOnly: request.WorkspaceOnly,
+		CreatedBy:     pubKeyFromAuth,
+		ExpiresAt:     time.Now().Add(time.Duration(db.ClampChatShareHours(request.ExpiresInHours)) * time.Hour),
+	})
+	if err != nil {
+		logger.Log.Error("[ChatID: %s] %v", chat.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to create share link",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Share link created",
+		Data:    chatShareLinkView(link),
+	})
+}
+
+// GetChatShareLinks lists the share links of a chat
+//
+//	@Summary		List chat share links
+//	@Description	Lists the share links of a chat, newest first, including expired and revoked ones
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Success		200		{object}	ChatResponse{data=[]ChatShareLinkView}
+//	@Failure		401		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/share [get]
+func (ch *ChatHandler) GetChatShareLinks(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatForMember(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := ch.db.GetChatShareLinks(chat.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch share links: %v", err),
+		})
+		return
+	}
+
+	views := []ChatShareLinkView{}
+	for _, link := range links {
+		views = append(views, chatShareLinkView(link))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Data:    views,
+	})
+}
+
+// RevokeChatShareLink revokes a share link of a chat
+//
+//	@Summary		Revoke a chat share link
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id	path		string	true	"Chat ID"
+//	@Param			link_id	path		string	true	"Share link ID"
+//	@Success		200		{object}	ChatResponse
+//	@Failure		401		{object}	ChatResponse
+//	@Failure		404		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/share/{link_id} [delete]
+func (ch *ChatHandler) RevokeChatShareLink(w http.ResponseWriter, r *http.Request) {
+	chat, ok := ch.chatForMember(w, r)
+	if !ok {
+		return
+	}
+
+	err := ch.db.RevokeChatShareLink(chat.ID, chi.URLParam(r, "link_id"))
+	if errors.Is(err, db.ErrChatShareLinkNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Share link not found",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to revoke share link: %v", err),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ChatResponse{
+		Success: true,
+		Message: "Share link revoked",
+	})
+}
+
+// GetSharedChat reads a chat through a share link
+//
+//	@Summary		Read a shared chat
+//	@Description	Reads the chat branch of a share link as markdown, JSON or PDF without signing in. Workspace only links need the token of a workspace user.
+//	@Tags			Hive Chat
+//	@Produce		json,text/markdown,application/pdf
+//	@Param			share_token	path		string	true	"Share token"
+//	@Param			format		query		string	false	"markdown, json or pdf, defaults to json"
+//	@Success		200			{object}	db.ChatExport
+//	@Failure		400			{object}	ChatResponse
+//	@Failure		401			{object}	ChatResponse
+//	@Failure		404			{object}	ChatResponse
+//	@Failure		410			{object}	ChatResponse
+//	@Router			/hivechat/shared/{share_token} [get]
+func (ch *ChatHandler) GetSharedChat(w http.ResponseWriter, r *http.Request) {
+	link, err := ch.db.GetChatShareLinkByToken(chi.URLParam(r, "share_token"))
+	if errors.Is(err, db.ErrChatShareLinkExpired) {
+		w.WriteHeader(http.StatusGone)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Share link has expired",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Share link not found",
+		})
+		return
+	}
+
+	if link.WorkspaceOnly {
+		chat, err := ch.db.GetChatByChatID(link.ChatID)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "Chat not found",
+			})
+			return
+		}
+		pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+		if pubKeyFromAuth == "" || !ch.db.IsWorkspaceMember(pubKeyFromAuth, chat.WorkspaceID) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(ChatResponse{
+				Success: false,
+				Message: "This chat is only shared with workspace users",
+			})
+			return
+		}
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	ch.writeChatExport(w, link.ChatID, link.BranchID, format)
+}
+
+// chatForMember loads the chat of the request for a user of its workspace
+func (ch *ChatHandler) chatForMember(w http.ResponseWriter, r *http.Request) (db.Chat, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return db.Chat{}, false
+	}
+
+	chat, ok := ch.chatFromPath(w, r)
+	if !ok {
+		return db.Chat{}, false
+	}
+
+	if !ch.db.IsWorkspaceMember(pubKeyFromAuth, chat.WorkspaceID) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Don't have access to this chat",
+		})
+		return db.Chat{}, false
+	}
+	return chat, true
+}
+
+func (ch *ChatHandler) writeChatExport(w http.ResponseWriter, chatID string, branchID string, format string) {
+	if format == "" {
+		format = "markdown"
+	}
+	if format != "markdown" && format != "json" && format != "pdf" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "format must be markdown, json or pdf",
+		})
+		return
+	}
+
+	export, err := ch.db.GetChatExport(chatID, branchID)
+	if errors.Is(err, db.ErrChatBranchNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat branch not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to export chat: %v", chatID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Failed to export chat",
+		})
+		return
+	}
+
+	fileName := "chat-" + markdownFileName.ReplaceAllString(chatID, "_")
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(export)
+	case "pdf":
+		title := export.Chat.Title
+		if title == "" {
+			title = "Chat " + export.Chat.ID
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".pdf"))
+		w.WriteHeader(http.StatusOK)
+		w.Write(utils.TextPDF(title, db.ChatExportMarkdown(export)))
+	default:
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".md"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(db.ChatExportMarkdown(export)))
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/sse"
+)
+
+const (
+	chatStreamBatch     = 100
+	chatStreamPoll      = 5 * time.Second
+	chatStreamHeartbeat = 15 * time.Second
+	chatStreamRetryMs   = 3000
+)
+
+// StreamChat streams the updates of a chat as server sent events
+//
+//	@Summary		Stream chat updates
+//	@Description	Streams the agent events stored for a chat followed by live updates as text/event-stream. Stored events carry their log ID, send it back as the Last-Event-ID header or last_event_id query parameter to resume after it. An unknown ID replays the chat from the start. The server ends streams on its request timeout and browsers reconnect on their own.
+//	@Tags			Hive Chat
+//	@Produce		text/event-stream
+//	@Security		PubKeyContextAuth
+//	@Param			chat_id			path		string	true	"Chat ID"
+//	@Param			last_event_id	query		string	false	"Resume after this event ID"
+//	@Success		200				{string}	string	"event stream"
+//	@Failure		400				{object}	ChatResponse
+//	@Failure		404				{object}	ChatResponse
+//	@Failure		500				{object}	ChatResponse
+//	@Router			/hivechat/{chat_id}/stream [get]
+func (ch *ChatHandler) StreamChat(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chat_id")
+	if chatID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat ID is required",
+		})
+		return
+	}
+
+	if _, err := ch.db.GetChatByChatID(chatID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Chat not found",
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Streaming is not supported",
+		})
+		return
+	}
+
+	cursor := ch.streamCursor(chatID, r)
+
+	// subscribe before replaying so nothing stored in between is missed
+	events, unsubscribe := sse.ChatBroker.Subscribe(chatID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", chatStreamRetryMs)
+
+	catchUp := func() error {
+		for {
+			logs, err := ch.db.GetSSEMessageLogsAfter(chatID, cursor, chatStreamBatch)
+			if err != nil {
+				return err
+			}
+			for i := range logs {
+				if err := sse.WriteEvent(w, sse.EventFromLog(logs[i])); err != nil {
+					return err
+				}
+				cursor = &logs[i]
+			}
+			if len(logs) < chatStreamBatch {
+				flusher.Flush()
+				return nil
+			}
+		}
+	}
+
+	if err := catchUp(); err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to replay chat stream: %v", chatID, err)
+		return
+	}
+
+	poll := time.NewTicker(chatStreamPoll)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(chatStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if event.ID != "" {
+				// stored events are read back in order from the log
+				err = catchUp()
+			} else if err = sse.WriteEvent(w, event); err == nil {
+				flusher.Flush()
+			}
+		case <-poll.C:
+			// picks up events stored by other instances
+			err = catchUp()
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": ping\n\n"); err == nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			logger.Log.Info("[ChatID: %s] Chat stream closed: %v", chatID, err)
+			return
+		}
+	}
+}
+
+// streamCursor resolves the event a client resumes after
+func (ch *ChatHandler) streamCursor(chatID string, r *http.Request) *db.SSEMessageLog {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID == "" {
+		return nil
+	}
+
+	id, err := uuid.Parse(lastEventID)
+	if err != nil {
+		return nil
+	}
+	messageLog, err := ch.db.GetSSEMessageLogByID(id)
+	if err != nil || messageLog.ChatID != chatID {
+		return nil
+	}
+	return messageLog
+}
+
+// publishChatEvent sends a live only event to the browsers streaming a chat
+func publishChatEvent(chatID string, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logger.Log.Error("Failed to encode %s event: %v", event, err)
+		return
+	}
+	sse.ChatBroker.Publish(chatID, sse.Event{Event: event, Data: string(data)})
+}
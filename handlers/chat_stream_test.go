@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStreamChat(t *testing.T) {
+	resumeAfter := db.SSEMessageLog{ID: uuid.New(), ChatID: "chat", CreatedAt: time.Now()}
+	next := db.SSEMessageLog{ID: uuid.New(), ChatID: "chat", CreatedAt: time.Now(), Event: db.PropertyMap{"event_type": "token", "raw": "hi"}}
+
+	mockDb := mocks.NewDatabase(t)
+	mockDb.On("GetChatByChatID", "chat").Return(db.Chat{ID: "chat"}, nil)
+	mockDb.On("GetSSEMessageLogByID", resumeAfter.ID).Return(&resumeAfter, nil)
+	mockDb.On("GetSSEMessageLogsAfter", "chat", &resumeAfter, chatStreamBatch).Return([]db.SSEMessageLog{next}, nil).Once()
+	mockDb.On("GetSSEMessageLogsAfter", "chat", mock.Anything, chatStreamBatch).Return([]db.SSEMessageLog{}, nil).Maybe()
+
+	ch := NewChatHandler(&http.Client{}, mockDb)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("chat_id", "chat")
+	req := httptest.NewRequest(http.MethodGet, "/hivechat/chat/stream", nil).
+		WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	req.Header.Set("Last-Event-ID", resumeAfter.ID.String())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		publishChatEvent("chat", "chat_status", map[string]string{"status": "success"})
+	}()
+
+	rr := httptest.NewRecorder()
+	ch.StreamChat(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "id: "+next.ID.String()+"\nevent: token\n")
+	assert.Contains(t, rr.Body.String(), "event: chat_status\ndata: {\"status\":\"success\"}\n\n")
+	assert.NotContains(t, rr.Body.String(), "id: "+resumeAfter.ID.String())
+}
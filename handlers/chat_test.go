@@ -1805,6 +1805,16 @@ func TestUploadFile(t *testing.T) {
 
 	chatHandler := NewChatHandler(&http.Client{}, db.TestDB)
 
+	workspace := db.Workspace{
+		Uuid:        "test-workspace-123",
+		Name:        "test-workspace" + uuid.New().String(),
+		OwnerPubKey: "test-pubkey-123",
+		Github:      "https://github.com/test",
+		Website:     "https://www.testwebsite.com",
+		Description: "test-description",
+	}
+	db.TestDB.CreateOrEditWorkspace(workspace)
+
 	createUploadRequest := func(filename, contentType string, content []byte, workspaceID string) (*http.Request, *httptest.ResponseRecorder) {
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -1829,7 +1839,7 @@ func TestUploadFile(t *testing.T) {
 
 		req := httptest.NewRequest(http.MethodPost, url, body)
 		req.Header.Set("Content-Type", writer.FormDataContentType())
-		ctx := context.WithValue(req.Context(), "pubkey", "test-pubkey-123")
+		ctx := context.WithValue(req.Context(), auth.ContextKey, "test-pubkey-123")
 		req = req.WithContext(ctx)
 
 		return req, httptest.NewRecorder()
@@ -1893,10 +1903,35 @@ func TestUploadFile(t *testing.T) {
 		assert.Equal(t, "File type not allowed", response.Message)
 	})
 
+	t.Run("should require a workspace", func(t *testing.T) {
+		req, rr := createUploadRequest("test.txt", "text/plain", []byte("test content"), "")
+
+		chatHandler.UploadFile(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		var response ChatResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, "workspaceId is required", response.Message)
+	})
+
+	t.Run("should reject uploads to a workspace the caller is not a member of", func(t *testing.T) {
+		req, rr := createUploadRequest("test.txt", "text/plain", []byte("test content"), "test-workspace-123")
+		req = req.WithContext(context.WithValue(req.Context(), auth.ContextKey, "other-pubkey"))
+
+		chatHandler.UploadFile(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		var response ChatResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err)
+		assert.Equal(t, "Don't have access to this workspace", response.Message)
+	})
+
 	t.Run("should handle missing file in request", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodPost, "/chat/upload", nil)
+		req := httptest.NewRequest(http.MethodPost, "/chat/upload?workspaceId=test-workspace-123", nil)
 		req.Header.Set("Content-Type", "multipart/form-data")
-		ctx := context.WithValue(req.Context(), "pubkey", "test-pubkey-123")
+		ctx := context.WithValue(req.Context(), auth.ContextKey, "test-pubkey-123")
 		req = req.WithContext(ctx)
 
 		rr := httptest.NewRecorder()
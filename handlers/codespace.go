@@ -33,6 +33,22 @@ type DeleteResponse struct {
 	Message string `json:"message"`
 }
 
+// UpdateCodeSpaceRequest carries explicit flags to clear the write only secrets,
+// since an empty secret in the body means "keep the stored value"
+type UpdateCodeSpaceRequest struct {
+	db.CodeSpaceMap
+	ClearGithubPat  bool `json:"clearGithubPat"`
+	ClearPoolAPIKey bool `json:"clearPoolAPIKey"`
+}
+
+func redactCodeSpaces(codespaces []db.CodeSpaceMap) []db.CodeSpaceMap {
+	redacted := make([]db.CodeSpaceMap, len(codespaces))
+	for i, codespace := range codespaces {
+		redacted[i] = codespace.Redacted()
+	}
+	return redacted
+}
+
 func (ch *codeSpaceHandler) GetAllCodeSpaceMaps(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
@@ -53,7 +69,7 @@ func (ch *codeSpaceHandler) GetAllCodeSpaceMaps(w http.ResponseWriter, r *http.R
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codespaces)
+	json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 }
 
 func (ch *codeSpaceHandler) GetCodeSpaceMapsByWorkspace(w http.ResponseWriter, r *http.Request) {
@@ -83,7 +99,7 @@ func (ch *codeSpaceHandler) GetCodeSpaceMapsByWorkspace(w http.ResponseWriter, r
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codespaces)
+	json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 }
 
 func (ch *codeSpaceHandler) GetCodeSpaceMapByWorkspaceAndUser(w http.ResponseWriter, r *http.Request) {
@@ -120,7 +136,7 @@ func (ch *codeSpaceHandler) GetCodeSpaceMapByWorkspaceAndUser(w http.ResponseWri
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codespace)
+	json.NewEncoder(w).Encode(codespace.Redacted())
 }
 
 func (ch *codeSpaceHandler) GetCodeSpaceMapsByUser(w http.ResponseWriter, r *http.Request) {
@@ -150,7 +166,7 @@ func (ch *codeSpaceHandler) GetCodeSpaceMapsByUser(w http.ResponseWriter, r *htt
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codespaces)
+	json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 }
 
 func (ch *codeSpaceHandler) GetCodeSpaceMapsByURL(w http.ResponseWriter, r *http.Request) {
@@ -180,7 +196,7 @@ func (ch *codeSpaceHandler) GetCodeSpaceMapsByURL(w http.ResponseWriter, r *http
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codespaces)
+	json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 }
 
 func (ch *codeSpaceHandler) QueryCodeSpaceMaps(w http.ResponseWriter, r *http.Request) {
@@ -217,7 +233,7 @@ func (ch *codeSpaceHandler) QueryCodeSpaceMaps(w http.ResponseWriter, r *http.Re
 			return
 		}
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode([]db.CodeSpaceMap{codeSpace})
+		json.NewEncoder(w).Encode([]db.CodeSpaceMap{codeSpace.Redacted()})
 		return
 	}
 
@@ -232,7 +248,7 @@ func (ch *codeSpaceHandler) QueryCodeSpaceMaps(w http.ResponseWriter, r *http.Re
 		}
 		logger.Log.Info("[codespace] Found %d mappings for workspace %s", len(codespaces), workspaceID)
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(codespaces)
+		json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 		return
 	}
 
@@ -247,7 +263,7 @@ func (ch *codeSpaceHandler) QueryCodeSpaceMaps(w http.ResponseWriter, r *http.Re
 		}
 		logger.Log.Info("[codespace] Found %d mappings for user %s", len(codespaces), userPubkey)
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(codespaces)
+		json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 		return
 	}
 
@@ -261,7 +277,7 @@ func (ch *codeSpaceHandler) QueryCodeSpaceMaps(w http.ResponseWriter, r *http.Re
 	}
 	logger.Log.Info("[codespace] Found %d total mappings", len(codespaces))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codespaces)
+	json.NewEncoder(w).Encode(redactCodeSpaces(codespaces))
 }
 
 func (ch *codeSpaceHandler) CreateCodeSpaceMap(w http.ResponseWriter, r *http.Request) {
@@ -308,7 +324,7 @@ func (ch *codeSpaceHandler) CreateCodeSpaceMap(w http.ResponseWriter, r *http.Re
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(createdCodeSpace)
+	json.NewEncoder(w).Encode(createdCodeSpace.Redacted())
 }
 
 func (ch *codeSpaceHandler) UpdateCodeSpaceMap(w http.ResponseWriter, r *http.Request) {
@@ -330,7 +346,7 @@ func (ch *codeSpaceHandler) UpdateCodeSpaceMap(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	var codeSpace db.CodeSpaceMap
+	var codeSpace UpdateCodeSpaceRequest
 	body, err := io.ReadAll(r.Body)
 	r.Body.Close()
 	if err != nil {
@@ -358,11 +374,21 @@ func (ch *codeSpaceHandler) UpdateCodeSpaceMap(w http.ResponseWriter, r *http.Re
 	if codeSpace.UserPubkey != "" {
 		updates["user_pubkey"] = codeSpace.UserPubkey
 	}
-	// Also allow updating Username, GithubPat and BaseBranch, even if empty to clear them
+	// Also allow updating Username and BaseBranch, even if empty to clear them
 	updates["username"] = codeSpace.Username
-	updates["github_pat"] = codeSpace.GithubPat
 	updates["base_branch"] = codeSpace.BaseBranch
-	updates["pool_api_key"] = codeSpace.PoolAPIKey
+
+	// Secrets are write only, they are only replaced when a new value is sent
+	if codeSpace.GithubPat != "" {
+		updates["github_pat"] = codeSpace.GithubPat
+	} else if codeSpace.ClearGithubPat {
+		updates["github_pat"] = ""
+	}
+	if codeSpace.PoolAPIKey != "" {
+		updates["pool_api_key"] = codeSpace.PoolAPIKey
+	} else if codeSpace.ClearPoolAPIKey {
+		updates["pool_api_key"] = ""
+	}
 
 	updatedCodeSpace, err := ch.db.UpdateCodeSpaceMap(id, updates)
 	if err != nil {
@@ -378,7 +404,7 @@ func (ch *codeSpaceHandler) UpdateCodeSpaceMap(w http.ResponseWriter, r *http.Re
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updatedCodeSpace)
+	json.NewEncoder(w).Encode(updatedCodeSpace.Redacted())
 }
 
 func (ch *codeSpaceHandler) DeleteCodeSpaceMap(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type FeatureMarkdownImportRequest struct {
+	Markdown string `json:"markdown"`
+	// FeatureUuid imports into an existing feature instead of creating one
+	FeatureUuid string `json:"feature_uuid"`
+	// Preview returns what would be created without writing it
+	Preview bool `json:"preview"`
+}
+
+var markdownFileName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ImportFeatureMarkdown godoc
+//
+//	@Summary		Import feature markdown
+//	@Description	Create a feature with its phases, stories and draft tickets from a markdown spec, or add them to an existing feature. '## Brief', '## Requirements' and '## Architecture' are feature text, bullets under '## Stories' are stories with nested acceptance criteria and task list items under '## Phase: Name' are tickets. Phases, stories and tickets that already exist are skipped.
+//	@Tags			Feature - Workspaces
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string							true	"Workspace UUID"
+//	@Param			request			body		FeatureMarkdownImportRequest	true	"Markdown spec"
+//	@Success		200				{object}	db.FeatureImportPlan
+//	@Router			/features/workspace/{workspace_uuid}/markdown [post]
+func (oh *featureHandler) ImportFeatureMarkdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" || workspace.Uuid != workspaceUuid {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace not found"})
+		return
+	}
+
+	if !oh.db.UserHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to import features"})
+		return
+	}
+
+	var request FeatureMarkdownImportRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[features] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	plan, err := oh.db.ImportFeatureMarkdown(workspaceUuid, request.FeatureUuid, pubKeyFromAuth, request.Markdown, request.Preview)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrFeatureSpecNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, db.ErrFeatureMarkdownEmpty), errors.Is(err, db.ErrFeatureMarkdownName):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			logger.Log.Error("[features] markdown import failed: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to import feature markdown"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "warnings": plan.Warnings})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// ExportFeatureMarkdown godoc
+//
+//	@Summary		Export feature markdown
+//	@Description	Download a feature with its phases, stories and latest tickets as a markdown spec that can be imported again
+//	@Tags			Feature - Workspaces
+//	@Produce		text/markdown
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string	true	"Feature UUID"
+//	@Success		200				{string}	string
+//	@Router			/features/{feature_uuid}/markdown [get]
+func (oh *featureHandler) ExportFeatureMarkdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	workspaceUuid, ok := featureWorkspace(w, oh.db, featureUuid)
+	if !ok {
+		return
+	}
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to this feature"})
+		return
+	}
+
+	markdown, err := oh.db.ExportFeatureMarkdown(featureUuid)
+	if err != nil {
+		if errors.Is(err, db.ErrFeatureSpecNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Feature not found"})
+			return
+		}
+		logger.Log.Error("[features] markdown export failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to export feature markdown"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", markdownFileName.ReplaceAllString(featureUuid, "_")+".md"))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(markdown))
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureMarkdownAccess(t *testing.T) {
+	workspace := db.Workspace{ID: 1, Uuid: "workspace", OwnerPubKey: "owner"}
+	feature := db.WorkspaceFeatures{Uuid: "feature", WorkspaceUuid: workspace.Uuid}
+
+	request := func(method string, param string, value string, pubkey string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add(param, value)
+		ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, auth.ContextKey, pubkey)
+		return httptest.NewRequest(method, "/", bytes.NewBufferString(`{"markdown":"# Feature"}`)).WithContext(ctx)
+	}
+
+	t.Run("rejects an import without the edit role", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetWorkspaceByUuid", workspace.Uuid).Return(workspace).Once()
+		mockDb.On("UserHasAccess", "stranger", workspace.Uuid, db.EditOrg).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		oh.ImportFeatureMarkdown(rr, request(http.MethodPost, "workspace_uuid", workspace.Uuid, "stranger"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("rejects an export by a non member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetFeatureByUuid", feature.Uuid).Return(feature).Once()
+		mockDb.On("IsWorkspaceMember", "stranger", workspace.Uuid).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		oh.ExportFeatureMarkdown(rr, request(http.MethodGet, "feature_uuid", feature.Uuid, "stranger"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("exports for a member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetFeatureByUuid", feature.Uuid).Return(feature).Once()
+		mockDb.On("IsWorkspaceMember", "member", workspace.Uuid).Return(true).Once()
+		mockDb.On("ExportFeatureMarkdown", feature.Uuid).Return("# Feature\n", nil).Once()
+
+		rr := httptest.NewRecorder()
+		oh.ExportFeatureMarkdown(rr, request(http.MethodGet, "feature_uuid", feature.Uuid, "member"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "# Feature\n", rr.Body.String())
+	})
+}
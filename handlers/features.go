@@ -113,6 +113,12 @@ func (oh *featureHandler) CreateOrEditFeatures(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if err := db.ValidateRoadmapDates(features.StartDate, features.TargetDate); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	// Check if workspace exists
 	workpace := oh.db.GetWorkspaceByUuid(features.WorkspaceUuid)
 	if workpace.Uuid != features.WorkspaceUuid {
@@ -151,7 +157,7 @@ func (oh *featureHandler) DeleteFeature(w http.ResponseWriter, r *http.Request)
 	}
 
 	uuid := chi.URLParam(r, "uuid")
-	err := oh.db.DeleteFeatureByUuid(uuid)
+	err := oh.db.TrashFeature(uuid, pubKeyFromAuth)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -326,6 +332,7 @@ func (oh *featureHandler) UpdateFeatureBrief(w http.ResponseWriter, r *http.Requ
 		BountiesCountCompleted: prevFeatureBrief.BountiesCountCompleted,
 		BountiesCountAssigned:  prevFeatureBrief.BountiesCountAssigned,
 		BountiesCountOpen:      prevFeatureBrief.BountiesCountOpen,
+		UpdatedBy:              pubKeyFromAuth,
 	}
 
 	p, err := oh.db.CreateOrEditFeature(featureToUpdate)
@@ -378,6 +385,20 @@ func (oh *featureHandler) CreateOrEditFeaturePhase(w http.ResponseWriter, r *htt
 
 	newPhase.UpdatedBy = pubKeyFromAuth
 
+	// dates left out of an edit keep their stored value
+	startDate, targetDate := newPhase.StartDate, newPhase.TargetDate
+	if startDate == nil {
+		startDate = existingPhase.StartDate
+	}
+	if targetDate == nil {
+		targetDate = existingPhase.TargetDate
+	}
+	if err := db.ValidateRoadmapDates(startDate, targetDate); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	// Check if feature exists
 	feature := oh.db.GetFeatureByUuid(newPhase.FeatureUuid)
 	if feature.Uuid != newPhase.FeatureUuid {
@@ -491,7 +512,7 @@ func (oh *featureHandler) DeleteFeaturePhase(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err := oh.db.DeleteFeaturePhase(featureUuid, phaseUuid)
+	err := oh.db.TrashFeaturePhase(featureUuid, phaseUuid, pubKeyFromAuth)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/storage"
+)
+
+// fileLifecycleBatch caps how many expired files one run removes from storage
+const fileLifecycleBatch = 200
+
+// RunFileAssetLifecycle archives unreferenced chat files and removes the
+// expired ones from their blob store
+func RunFileAssetLifecycle() {
+	result, err := runFileAssetLifecycle(db.DB, func(kind string) (storage.BlobStore, error) {
+		return NewBlobStore(kind, "uploads")
+	}, time.Now())
+	if err != nil {
+		logger.Log.Error("[files] %v", err)
+	}
+	if result.Archived > 0 || result.Reactivated > 0 || result.Deleted > 0 {
+		logger.Log.Info("[files] archived %d, reactivated %d and deleted %d files", result.Archived, result.Reactivated, result.Deleted)
+	}
+}
+
+func runFileAssetLifecycle(database db.Database, storeFor func(kind string) (storage.BlobStore, error), now time.Time) (db.FileLifecycleResult, error) {
+	result := db.FileLifecycleResult{}
+
+	archiveBefore := now.Add(-time.Duration(config.FileArchiveDays) * 24 * time.Hour)
+	archived, reactivated, err := database.ArchiveFileAssets(archiveBefore)
+	if err != nil {
+		return result, err
+	}
+	result.Archived = archived
+	result.Reactivated = reactivated
+
+	deleteBefore := now.Add(-time.Duration(config.FileRetentionDays) * 24 * time.Hour)
+	expired, err := database.GetExpiredFileAssets(deleteBefore, fileLifecycleBatch)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	for _, asset := range expired {
+		// files uploaded before the backend was recorded went to the meme server
+		if asset.StorageBackend != "" && asset.StorageKey != "" {
+			store, err := storeFor(asset.StorageBackend)
+			if err != nil {
+				logger.Log.Error("[files] no %s store for file %d: %v", asset.StorageBackend, asset.ID, err)
+				continue
+			}
+			err = store.Delete(ctx, asset.StorageKey)
+			if err != nil && !errors.Is(err, storage.ErrBlobNotFound) && !errors.Is(err, storage.ErrBlobUnsupported) {
+				logger.Log.Error("[files] failed to delete file %d from %s store: %v", asset.ID, store.Name(), err)
+				continue
+			}
+		}
+
+		if err := database.DeleteFileAsset(asset.ID); err != nil {
+			logger.Log.Error("[files] failed to mark file %d deleted: %v", asset.ID, err)
+			continue
+		}
+		result.Deleted++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stakwork/sphinx-tribes/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFileAssetLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	local := storage.NewLocalStore(t.TempDir(), "http://localhost/blobs")
+
+	blob, err := local.Put(ctx, "chat/old.pdf", strings.NewReader("pdf"), 3, "application/pdf")
+	require.NoError(t, err)
+
+	mockDb := mocks.NewDatabase(t)
+	mockDb.On("ArchiveFileAssets", now.Add(-time.Duration(config.FileArchiveDays)*24*time.Hour)).Return(int64(2), int64(1), nil)
+	mockDb.On("GetExpiredFileAssets", now.Add(-time.Duration(config.FileRetentionDays)*24*time.Hour), fileLifecycleBatch).Return([]db.FileAsset{
+		{ID: 1, StorageBackend: storage.LocalKind, StorageKey: blob.Key},
+		{ID: 2},
+		{ID: 3, StorageBackend: "ftp", StorageKey: "old.pdf"},
+	}, nil)
+	mockDb.On("DeleteFileAsset", mock.AnythingOfType("uint")).Return(nil).Twice()
+
+	result, err := runFileAssetLifecycle(mockDb, func(kind string) (storage.BlobStore, error) {
+		if kind == storage.LocalKind {
+			return local, nil
+		}
+		return nil, errors.New("unknown store")
+	}, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, db.FileLifecycleResult{Archived: 2, Reactivated: 1, Deleted: 2}, result)
+	mockDb.AssertCalled(t, "DeleteFileAsset", uint(1))
+	mockDb.AssertCalled(t, "DeleteFileAsset", uint(2))
+	mockDb.AssertNotCalled(t, "DeleteFileAsset", uint(3))
+
+	_, err = local.Get(ctx, blob.Key)
+	assert.True(t, errors.Is(err, storage.ErrBlobNotFound))
+}
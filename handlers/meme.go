@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,13 +11,14 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 
+	"github.com/google/uuid"
 	"github.com/stakwork/sphinx-tribes/auth"
 	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/storage"
 )
 
 // MemeImageUpload godoc
@@ -40,7 +42,6 @@ func MemeImageUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dirName := "uploads"
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		http.Error(w, "Unable to parse file", http.StatusBadRequest)
@@ -48,47 +49,19 @@ func MemeImageUpload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Check if uploads directory exists or create it
-	CreateUploadsDirectory(dirName)
-
-	// Saving the file
-	dst, err := os.Create(dirName + "/" + header.Filename)
-
-	if err != nil {
-		panic("Unable to create file")
-		return
-	}
-
-	defer dst.Close()
-
-	_, err = io.Copy(dst, file)
+	store := UploadStore()
+	key := "memes/" + uuid.New().String() + filepath.Ext(header.Filename)
+	blob, err := store.Put(r.Context(), key, file, header.Size, header.Header.Get("Content-Type"))
 	if err != nil {
-		panic("Unable to copy saved file")
-		return
-	}
-
-	challenge := GetMemeChallenge()
-	signer := SignChallenge(challenge.Challenge)
-	mErr, mToken := GetMemeToken(challenge.Id, signer.Response.Sig)
-
-	if mErr != "" {
-		msg := "Could not get meme token"
-		logger.Log.Error("%s: %s", msg, mErr)
-		w.WriteHeader(http.StatusNoContent)
-		json.NewEncoder(w).Encode(msg)
-	} else {
-		err, memeImgUrl := UploadMemeImage(file, mToken.Token, header.Filename)
-		if err == nil {
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(memeImgUrl)
-			return
-		}
-
 		msg := "Could not get meme image"
-		logger.Log.Error("%s", msg)
+		logger.Log.Error("%s: %v", msg, err)
 		w.WriteHeader(http.StatusNoContent)
 		json.NewEncoder(w).Encode(msg)
+		return
 	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(blob.URL)
 }
 
 func GetMemeChallenge() db.MemeChallenge {
@@ -268,89 +241,22 @@ func GetMemeToken(id string, sig string) (string, db.MemeTokenSuccess) {
 	}
 }
 
+// UploadMemeImage uploads a file to the meme server with an existing token
 func UploadMemeImage(file multipart.File, token string, fileName string) (error, string) {
+	store := storage.NewMemeStore(config.MemeUrl, nil, func() (string, error) {
+		return token, nil
+	})
 
-	if err := os.MkdirAll("./uploads", 0755); err != nil {
-		logger.Log.Error("Failed to create uploads directory: %v", err)
-		return err, ""
+	name := filepath.Base(fileName)
+	if name == "." || name == "/" {
+		name = "upload"
 	}
-	
-	filePath := path.Join("./uploads", fileName)
-	tempFile, err := os.Create(filePath)
-	if err != nil {
-		logger.Log.Error("Failed to create temporary file: %v", err)
-		return err, ""
-	}
-	
-	if _, err := io.Copy(tempFile, file); err != nil {
-		tempFile.Close()
-		os.Remove(filePath)
-		logger.Log.Error("Failed to write to temporary file: %v", err)
-		return err, ""
-	}
-	tempFile.Close()
-	
-	url := fmt.Sprintf("%s/public", config.MemeUrl)
-	
-	fileW, err := os.Open(filePath)
-	if err != nil {
-		os.Remove(filePath)
-		logger.Log.Error("Failed to open temporary file: %v", err)
-		return err, ""
-	}
-	defer fileW.Close()
-
-	fileBody := &bytes.Buffer{}
-	writer := multipart.NewWriter(fileBody)
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
-	if err != nil {
-		os.Remove(filePath)
-		logger.Log.Error("Failed to create form file: %v", err)
-		return err, ""
-	}
-	
-	if _, err := io.Copy(part, fileW); err != nil {
-		os.Remove(filePath)
-		logger.Log.Error("Failed to copy file to form: %v", err)
-		return err, ""
-	}
-	writer.Close()
-
-	client := &http.Client{}
-	req, err := http.NewRequest(http.MethodPost, url, fileBody)
-	if err != nil {
-		os.Remove(filePath)
-		logger.Log.Error("Failed to create request: %v", err)
-		return err, ""
-	}
-	
-	req.Header.Set("Authorization", "BEARER "+token)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	res, err := client.Do(req)
-
-	// Delete image from uploads folder
-	os.Remove(filePath)
-
+	blob, err := store.Put(context.Background(), name, file, -1, "")
 	if err != nil {
 		logger.Log.Error("Meme request Error: %v", err)
 		return err, ""
 	}
-
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-
-	if err == nil {
-		memeSuccess := db.Meme{}
-		// Unmarshal result
-		err = json.Unmarshal(body, &memeSuccess)
-		if err != nil {
-			log.Printf("Reading meme error body failed: %s", err)
-		} else {
-			return nil, config.MemeUrl + "/public/" + memeSuccess.Muid
-		}
-	}
-
-	return err, ""
+	return nil, blob.URL
 }
 
 func DeleteFileFromUploadsFolder(filePath string) {
@@ -7,17 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
-	"path"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/fatih/structs"
+	"github.com/google/uuid"
 	"github.com/stakwork/sphinx-tribes/auth"
-	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/logger"
 	"github.com/tuan78/jsonconv"
@@ -527,89 +522,23 @@ func ConvertMetricsToCSV(metricBountiesData []db.MetricsBountyCsv) [][]string {
 }
 
 func UploadMetricsCsv(data [][]string, request db.PaymentDateRange) (error, string) {
-	dirName := "uploads"
-	CreateUploadsDirectory(dirName)
-
-	filePath := path.Join("./uploads", "metrics.csv")
-	csvFile, err := os.Create(filePath)
-	if err != nil {
-		log.Fatalf("failed creating file: %s", err)
-	}
-
-	w := csv.NewWriter(csvFile)
-	err = w.WriteAll(data)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	csvFile.Close()
-
-	upFile, _ := os.Open(filePath)
-	defer upFile.Close()
-
-	upFileInfo, _ := upFile.Stat()
-	var fileSize int64 = upFileInfo.Size()
-	fileBuffer := make([]byte, fileSize)
-	upFile.Read(fileBuffer)
-
-	key := fmt.Sprintf("metrics%s-%s.csv", request.StartDate, request.EndDate)
-	path := fmt.Sprintf("%s/%s", config.S3FolderName, key)
-
-	err, postPresignedUrl := createPresignedUrl(path)
-
-	if err != nil {
-		logger.Log.Error("Presigned Error: %v", err)
-	}
-
-	r, err := http.NewRequest(http.MethodPut, postPresignedUrl, bytes.NewReader(fileBuffer))
-	if err != nil {
-		logger.Log.Error("Posting presign s3 error: %v", err)
-	}
-	r.Header.Set("Content-Type", "multipart/form-data")
-	client := &http.Client{}
-	_, err = client.Do(r)
-
-	if err != nil {
-		logger.Log.Error("Error occurred while posting presigned URL: %v", err)
-	}
-
-	// Delete image from uploads folder
-	DeleteFileFromUploadsFolder(filePath)
-
-	err, presignedUrlGet := getPresignedUrl(path)
-
-	return err, presignedUrlGet
-}
-
-func createPresignedUrl(path string) (error, string) {
-	presignedUrl, err := config.PresignClient.PresignPutObject(context.Background(),
-		&s3.PutObjectInput{
-			Bucket: aws.String(config.S3BucketName),
-			Key:    aws.String(path),
-		},
-		s3.WithPresignExpires(time.Minute*15),
-	)
-
-	if err != nil {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(data); err != nil {
 		return err, ""
 	}
 
-	return nil, presignedUrl.URL
-}
-
-func getPresignedUrl(path string) (error, string) {
-	presignedUrl, err := config.PresignClient.PresignGetObject(context.Background(),
-		&s3.GetObjectInput{
-			Bucket:                     aws.String(config.S3BucketName),
-			Key:                        aws.String(path),
-			ResponseContentDisposition: aws.String("attachment"),
-		},
-		s3.WithPresignExpires(time.Minute*15),
-	)
+	// the random folder keeps exports unguessable on stores without signing
+	key := fmt.Sprintf("%s/metrics%s-%s.csv", uuid.New().String(), request.StartDate, request.EndDate)
 
+	store := ExportStore()
+	ctx := context.Background()
+	blob, err := store.Put(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "text/csv")
 	if err != nil {
+		logger.Log.Error("Error uploading metrics csv to %s store: %v", store.Name(), err)
 		return err, ""
 	}
 
-	return nil, presignedUrl.URL
+	url, err := store.SignedURL(ctx, blob.Key, time.Minute*15)
+	return err, url
 }
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// GetWorkspaceRoadmap godoc
+//
+//	@Summary		Get workspace roadmap
+//	@Description	Features of a workspace with their phases laid out on a timeline, progress rolled up from ticket statuses and bounty completion, and overdue flags
+//	@Tags			Feature - Workspaces
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Success		200				{object}	db.Roadmap
+//	@Router			/features/workspace/{workspace_uuid}/roadmap [get]
+func (oh *featureHandler) GetWorkspaceRoadmap(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.Uuid == "" || workspace.Uuid != workspaceUuid {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace not found"})
+		return
+	}
+
+	roadmap, err := oh.db.GetWorkspaceRoadmap(workspaceUuid)
+	if err != nil {
+		logger.Log.Error("[roadmap] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get roadmap"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roadmap)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/sse"
+)
+
+type SSEClientView struct {
+	db.SSEClient
+	Running bool `json:"running"`
+}
+
+type SSEClientsResponse struct {
+	Success bool            `json:"success"`
+	Running int             `json:"running"`
+	Limit   int             `json:"limit"`
+	Data    []SSEClientView `json:"data"`
+}
+
+// GetSSEClients lists the stored SSE subscriptions
+//
+//	@Summary		List SSE clients
+//	@Description	Lists the remote SSE subscriptions with their last event ID and status, and whether this server is following them right now
+//	@Tags			Hive Chat
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Param			status	query		string	false	"active, stopped, failed or finished"
+//	@Success		200		{object}	SSEClientsResponse
+//	@Failure		400		{object}	ChatResponse
+//	@Failure		500		{object}	ChatResponse
+//	@Router			/hivechat/sse/clients [get]
+func (ch *ChatHandler) GetSSEClients(w http.ResponseWriter, r *http.Request) {
+	status := db.SSEClientStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", db.SSEClientActive, db.SSEClientStopped, db.SSEClientFailed, db.SSEClientFinished:
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: "Invalid status",
+		})
+		return
+	}
+
+	clients, err := ch.db.GetSSEClients(status)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ChatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to retrieve SSE clients: %v", err),
+		})
+		return
+	}
+
+	running := sse.ClientRegistry.Keys()
+	views := []SSEClientView{}
+	for _, client := range clients {
+		views = append(views, SSEClientView{
+			SSEClient: client,
+			Running:   running[sse.GenerateClientKey(client.ChatID, client.URL)],
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SSEClientsResponse{
+		Success: true,
+		Running: len(running),
+		Limit:   config.MaxSSEClients,
+		Data:    views,
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSSEClients(t *testing.T) {
+	mockDb := mocks.NewDatabase(t)
+	ch := &ChatHandler{db: mockDb}
+
+	t.Run("lists the stored clients", func(t *testing.T) {
+		mockDb.On("GetSSEClients", db.SSEClientFailed).Return([]db.SSEClient{
+			{ChatID: "chat", URL: "http://sse", Status: db.SSEClientFailed, LastEventID: "12"},
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		ch.GetSSEClients(rr, httptest.NewRequest(http.MethodGet, "/hivechat/sse/clients?status=failed", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response SSEClientsResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.True(t, response.Success)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "12", response.Data[0].LastEventID)
+		assert.False(t, response.Data[0].Running)
+	})
+
+	t.Run("rejects an unknown status", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ch.GetSSEClients(rr, httptest.NewRequest(http.MethodGet, "/hivechat/sse/clients?status=paused", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// writeTextRevisionError maps revision errors to a status
+func writeTextRevisionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, db.ErrTextRevisionField):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, db.ErrTextRevisionNotFound), errors.Is(err, db.ErrTextRevisionEntity):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		logger.Log.Error("[revisions] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to read revisions"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// textRevisionDiffRange reads the from and to versions of a diff, to is zero
+// when the diff runs up to the latest revision
+func textRevisionDiffRange(r *http.Request) (int, int, bool) {
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil || from < 1 {
+		return 0, 0, false
+	}
+	to := 0
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = strconv.Atoi(value); err != nil || to < 1 {
+			return 0, 0, false
+		}
+	}
+	return from, to, true
+}
+
+// featureWorkspace returns the workspace of a feature, answering 404 when the
+// feature does not exist
+func featureWorkspace(w http.ResponseWriter, database db.Database, featureUuid string) (string, bool) {
+	feature := database.GetFeatureByUuid(featureUuid)
+	if feature.Uuid == "" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Feature not found"})
+		return "", false
+	}
+	return feature.WorkspaceUuid, true
+}
+
+func listTextRevisions(w http.ResponseWriter, r *http.Request, database db.Database, entityType db.TextRevisionEntity, entityUuid string) {
+	revisions, err := database.GetTextRevisions(entityType, entityUuid, r.URL.Query().Get("field"))
+	if err != nil {
+		writeTextRevisionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func diffTextRevisions(w http.ResponseWriter, r *http.Request, database db.Database, entityType db.TextRevisionEntity, entityUuid string) {
+	from, to, ok := textRevisionDiffRange(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "from and to must be revision versions"})
+		return
+	}
+
+	diff, err := database.CompareTextRevisions(entityType, entityUuid, chi.URLParam(r, "field"), from, to)
+	if err != nil {
+		writeTextRevisionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(diff)
+}
+
+func restoreTextRevision(w http.ResponseWriter, r *http.Request, database db.Database, entityType db.TextRevisionEntity, entityUuid string, pubkey string) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid revision version"})
+		return
+	}
+
+	revision, err := database.RestoreTextRevision(entityType, entityUuid, chi.URLParam(r, "field"), version, pubkey)
+	if err != nil {
+		writeTextRevisionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revision)
+}
+
+// GetFeatureRevisions godoc
+//
+//	@Summary		Get feature text revisions
+//	@Description	Saved versions of the brief, requirements and architecture of a feature with who changed them, newest first
+//	@Tags			Features
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path	string	true	"Feature UUID"
+//	@Param			field			query	string	false	"brief, requirements or architecture"
+//	@Success		200				{array}	db.TextRevision
+//	@Router			/features/{feature_uuid}/revisions [get]
+func (oh *featureHandler) GetFeatureRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	workspaceUuid, ok := featureWorkspace(w, oh.db, featureUuid)
+	if !ok {
+		return
+	}
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to this feature"})
+		return
+	}
+
+	listTextRevisions(w, r, oh.db, db.TextRevisionFeature, featureUuid)
+}
+
+// DiffFeatureRevisions godoc
+//
+//	@Summary		Diff feature text revisions
+//	@Description	Line diff between two revisions of a feature text field, without to the diff runs up to the latest revision
+//	@Tags			Features
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string	true	"Feature UUID"
+//	@Param			field			path		string	true	"brief, requirements or architecture"
+//	@Param			from			query		int		true	"From version"
+//	@Param			to				query		int		false	"To version"
+//	@Success		200				{object}	db.TextRevisionDiff
+//	@Router			/features/{feature_uuid}/revisions/{field}/diff [get]
+func (oh *featureHandler) DiffFeatureRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	workspaceUuid, ok := featureWorkspace(w, oh.db, featureUuid)
+	if !ok {
+		return
+	}
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to this feature"})
+		return
+	}
+
+	diffTextRevisions(w, r, oh.db, db.TextRevisionFeature, featureUuid)
+}
+
+// RestoreFeatureRevision godoc
+//
+//	@Summary		Restore feature text revision
+//	@Description	Write an old revision back to the feature field, the restore is kept as a new revision
+//	@Tags			Features
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string	true	"Feature UUID"
+//	@Param			field			path		string	true	"brief, requirements or architecture"
+//	@Param			version			path		int		true	"Revision version"
+//	@Success		200				{object}	db.TextRevision
+//	@Router			/features/{feature_uuid}/revisions/{field}/{version}/restore [post]
+func (oh *featureHandler) RestoreFeatureRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	featureUuid := chi.URLParam(r, "feature_uuid")
+	workspaceUuid, ok := featureWorkspace(w, oh.db, featureUuid)
+	if !ok {
+		return
+	}
+	if !oh.db.UserHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to edit this feature"})
+		return
+	}
+
+	restoreTextRevision(w, r, oh.db, db.TextRevisionFeature, featureUuid, pubKeyFromAuth)
+}
+
+// GetWorkspaceRevisions godoc
+//
+//	@Summary		Get workspace text revisions
+//	@Description	Saved versions of the mission and tactics of a workspace with who changed them, newest first
+//	@Tags			Workspaces
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Param			field			query	string	false	"mission or tactics"
+//	@Success		200				{array}	db.TextRevision
+//	@Router			/workspaces/{workspace_uuid}/revisions [get]
+func (oh *workspaceHandler) GetWorkspaceRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to the workspace"})
+		return
+	}
+
+	listTextRevisions(w, r, oh.db, db.TextRevisionWorkspace, workspaceUuid)
+}
+
+// DiffWorkspaceRevisions godoc
+//
+//	@Summary		Diff workspace text revisions
+//	@Description	Line diff between two revisions of the workspace mission or tactics, without to the diff runs up to the latest revision
+//	@Tags			Workspaces
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			field			path		string	true	"mission or tactics"
+//	@Param			from			query		int		true	"From version"
+//	@Param			to				query		int		false	"To version"
+//	@Success		200				{object}	db.TextRevisionDiff
+//	@Router			/workspaces/{workspace_uuid}/revisions/{field}/diff [get]
+func (oh *workspaceHandler) DiffWorkspaceRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to the workspace"})
+		return
+	}
+
+	diffTextRevisions(w, r, oh.db, db.TextRevisionWorkspace, workspaceUuid)
+}
+
+// RestoreWorkspaceRevision godoc
+//
+//	@Summary		Restore workspace text revision
+//	@Description	Write an old revision back to the workspace mission or tactics, the restore is kept as a new revision
+//	@Tags			Workspaces
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			field			path		string	true	"mission or tactics"
+//	@Param			version			path		int		true	"Revision version"
+//	@Success		200				{object}	db.TextRevision
+//	@Router			/workspaces/{workspace_uuid}/revisions/{field}/{version}/restore [post]
+func (oh *workspaceHandler) RestoreWorkspaceRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if !workspace.IsOwner(pubKeyFromAuth) && !oh.db.UserHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to Edit workspace"})
+		return
+	}
+
+	restoreTextRevision(w, r, oh.db, db.TextRevisionWorkspace, workspaceUuid, pubKeyFromAuth)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureRevisionsAccess(t *testing.T) {
+	workspace := db.Workspace{ID: 1, Uuid: "workspace", OwnerPubKey: "owner"}
+	feature := db.WorkspaceFeatures{Uuid: "feature", WorkspaceUuid: workspace.Uuid}
+
+	request := func(method string, pubkey string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("feature_uuid", feature.Uuid)
+		rctx.URLParams.Add("field", "brief")
+		rctx.URLParams.Add("version", "1")
+		ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, auth.ContextKey, pubkey)
+		return httptest.NewRequest(method, "/?from=1", nil).WithContext(ctx)
+	}
+
+	t.Run("rejects reading revisions by a non member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetFeatureByUuid", feature.Uuid).Return(feature).Twice()
+		mockDb.On("IsWorkspaceMember", "stranger", workspace.Uuid).Return(false).Twice()
+
+		rr := httptest.NewRecorder()
+		oh.GetFeatureRevisions(rr, request(http.MethodGet, "stranger"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		rr = httptest.NewRecorder()
+		oh.DiffFeatureRevisions(rr, request(http.MethodGet, "stranger"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("lists revisions for a member", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetFeatureByUuid", feature.Uuid).Return(feature).Once()
+		mockDb.On("IsWorkspaceMember", workspace.OwnerPubKey, workspace.Uuid).Return(true).Once()
+		mockDb.On("GetTextRevisions", db.TextRevisionFeature, feature.Uuid, "").Return([]db.TextRevision{}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		oh.GetFeatureRevisions(rr, request(http.MethodGet, workspace.OwnerPubKey))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("rejects a restore without the edit role", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetFeatureByUuid", feature.Uuid).Return(feature).Once()
+		mockDb.On("UserHasAccess", "member", workspace.Uuid, db.EditOrg).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		oh.RestoreFeatureRevision(rr, request(http.MethodPost, "member"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("answers 404 for an unknown feature", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		oh := &featureHandler{db: mockDb}
+		mockDb.On("GetFeatureByUuid", feature.Uuid).Return(db.WorkspaceFeatures{}).Once()
+
+		rr := httptest.NewRecorder()
+		oh.RestoreFeatureRevision(rr, request(http.MethodPost, "member"))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -181,6 +182,12 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if updateRequest.Ticket.Author == nil {
+		author := db.HumanAuthor
+		updateRequest.Ticket.Author = &author
+		updateRequest.Ticket.AuthorID = &pubKeyFromAuth
+	}
+
 	existingTicket, err := th.db.GetTicket(ticketUUID.String())
 	var newTicket db.Tickets
 
@@ -193,11 +200,14 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 			Name:        updateRequest.Ticket.Name,
 			Sequence:    updateRequest.Ticket.Sequence,
 			Dependency:  updateRequest.Ticket.Dependency,
+			DependsOn:   updateRequest.Ticket.DependsOn,
 			Description: updateRequest.Ticket.Description,
 			Status:      updateRequest.Ticket.Status,
 			Amount:      updateRequest.Ticket.Amount,
 			Category:    updateRequest.Ticket.Category,
 			Version:     1,
+			Author:      updateRequest.Ticket.Author,
+			AuthorID:    updateRequest.Ticket.AuthorID,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
@@ -211,6 +221,7 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 			Name:        updateRequest.Ticket.Name,
 			Sequence:    updateRequest.Ticket.Sequence,
 			Dependency:  updateRequest.Ticket.Dependency,
+			DependsOn:   updateRequest.Ticket.DependsOn,
 			Description: updateRequest.Ticket.Description,
 			Status:      updateRequest.Ticket.Status,
 			Amount:      updateRequest.Ticket.Amount,
@@ -223,6 +234,19 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	previousStatus := db.DraftTicket
+	if existingTicket.Status != "" {
+		previousStatus = existingTicket.Status
+	}
+	transitionRule, err := th.db.CheckTicketTransition(pubKeyFromAuth, previousStatus, newTicket)
+	if err != nil {
+		if !writeTicketTransitionError(w, err) {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to check ticket workflow: %v", err)})
+		}
+		return
+	}
+
 	createdTicket, err := th.db.CreateOrEditTicket(&newTicket)
 	if err != nil {
 		if err.Error() == "feature_uuid, phase_uuid, and name are required" {
@@ -230,11 +254,20 @@ func (th *ticketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 			return
 		}
+		if status, ok := ticketDependencyErrorStatus(err); ok {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to update ticket: %v", err)})
 		return
 	}
 
+	// the action result is only reported by the board and bulk endpoints, the
+	// body here is always the ticket
+	runTicketTransitionAction(th.db, transitionRule, createdTicket, pubKeyFromAuth)
+
 	if updateRequest.Metadata.Source == "websocket" && updateRequest.Metadata.ID != "" {
 		ticketMsg := websocket.TicketMessage{
 			BroadcastType:   "direct",
@@ -324,6 +357,25 @@ func (th *ticketHandler) UpdateTicketSequence(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if len(groupTickets) > 0 && updateRequest.Ticket != nil {
+		latest := groupTickets[0]
+		for _, ticket := range groupTickets {
+			if ticket.Version > latest.Version {
+				latest = ticket
+			}
+		}
+		if err := th.db.CheckTicketSequence(latest.FeatureUUID, latest.PhaseUUID, ticketGroupUUID, updateRequest.Ticket.Sequence); err != nil {
+			if status, ok := ticketDependencyErrorStatus(err); ok {
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to check ticket sequence: %v", err)})
+			return
+		}
+	}
+
 	for _, ticket := range groupTickets {
 		ticket.Sequence = updateRequest.Ticket.Sequence
 		ticket.UpdatedAt = time.Now()
@@ -382,7 +434,12 @@ func (th *ticketHandler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := th.db.DeleteTicketGroup(*ticket.TicketGroup); err != nil {
+	if err := th.db.TrashTicketGroup(*ticket.TicketGroup, pubKeyFromAuth); err != nil {
+		if errors.Is(err, db.ErrTicketGroupLocked) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
 		logger.Log.Error("failed to delete ticket group",
 			"error", err,
 			"ticket_group", ticket.TicketGroup)
@@ -562,7 +619,7 @@ func (th *ticketHandler) PostTicketDataToStakwork(w http.ResponseWriter, r *http
 		codeGraph, err := th.db.GetCodeGraphByWorkspaceUuid(feature.WorkspaceUuid)
 		if err == nil {
 			codeGraphURL = codeGraph.Url
-			codeGraphAlias = codeGraph.SecretAlias
+			codeGraphAlias = codeGraph.SecretAlias.String()
 		} else {
 			codeGraphURL = ""
 			codeGraphAlias = ""
@@ -793,6 +850,7 @@ func (th *ticketHandler) ProcessTicketReview(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	agentAuthor := db.AgentAuthor
 	newTicket := db.Tickets{
 		UUID:        uuid.New(),
 		TicketGroup: existingTicket.TicketGroup,
@@ -801,11 +859,13 @@ func (th *ticketHandler) ProcessTicketReview(w http.ResponseWriter, r *http.Requ
 		Name:        reviewReq.Value.TicketName,
 		Sequence:    existingTicket.Sequence,
 		Dependency:  existingTicket.Dependency,
+		DependsOn:   existingTicket.DependsOn,
 		Description: reviewReq.Value.TicketDescription,
 		Status:      existingTicket.Status,
 		Version:     existingTicket.Version + 1,
 		Amount:      existingTicket.Amount,
 		Category:    existingTicket.Category,
+		Author:      &agentAuthor,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -1224,9 +1284,22 @@ func (th *ticketHandler) UpdateWorkspaceDraftTicket(w http.ResponseWriter, r *ht
 			json.NewEncoder(w).Encode(map[string]string{"error": "invalid ticket status"})
 			return
 		}
+		moved := existingTicket
+		moved.Status = ticketRequest.Status
+		if _, err := th.db.CheckTicketTransition(pubKeyFromAuth, existingTicket.Status, moved); err != nil {
+			if !writeTicketTransitionError(w, err) {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			}
+			return
+		}
 		existingTicket.Status = ticketRequest.Status
 	}
 
+	author := db.HumanAuthor
+	existingTicket.Author = &author
+	existingTicket.AuthorID = &pubKeyFromAuth
+
 	updatedTicket, err := th.db.UpdateWorkspaceDraftTicket(&existingTicket)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/websocket"
+)
+
+type BulkTicketsResponse struct {
+	db.BulkTicketResponse
+	TransitionActions map[string]map[string]interface{} `json:"transition_actions,omitempty"`
+}
+
+// BulkUpdateTickets godoc
+//
+//	@Summary		Bulk update tickets
+//	@Description	Apply a status, category, amount or feature and phase change, or a deletion, to a set of tickets. The latest version of every ticket group is changed in one transaction, if one ticket fails nothing is changed and the result of every ticket is returned with a 409.
+//	@Tags			Bounty Tickets
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			request	body		db.BulkTicketRequest	true	"Tickets and the change to apply"
+//	@Success		200		{object}	BulkTicketsResponse
+//	@Failure		409		{object}	BulkTicketsResponse
+//	@Router			/bounties/ticket/bulk [post]
+func (th *ticketHandler) BulkUpdateTickets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	var request db.BulkTicketRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := th.db.BulkUpdateTickets(pubKeyFromAuth, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrBulkTicketRequest):
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		case errors.Is(err, db.ErrBulkTicketFailed):
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(BulkTicketsResponse{BulkTicketResponse: result})
+		default:
+			logger.Log.Error("[ticket] bulk %s failed: %v", request.Action, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to apply bulk ticket change"})
+		}
+		return
+	}
+
+	response := BulkTicketsResponse{BulkTicketResponse: result}
+	action := "bulk_" + string(request.Action)
+	for _, ticketResult := range result.Results {
+		if ticketResult.Ticket == nil {
+			continue
+		}
+
+		if request.Action == db.BulkTicketUpdate {
+			if transition := runTicketTransitionAction(th.db, ticketResult.Rule, *ticketResult.Ticket, pubKeyFromAuth); transition != nil {
+				if response.TransitionActions == nil {
+					response.TransitionActions = map[string]map[string]interface{}{}
+				}
+				response.TransitionActions[ticketResult.TicketUUID] = transition
+			}
+		}
+
+		if ticketResult.Card != nil && ticketResult.WorkspaceUuid != "" {
+			if err := websocket.WebsocketPool.SendBoardMessage(websocket.BoardMessage{
+				BroadcastType: "workspace",
+				WorkspaceUuid: ticketResult.WorkspaceUuid,
+				Action:        action,
+				FromStatus:    ticketResult.FromStatus,
+				Card:          *ticketResult.Card,
+			}); err != nil {
+				logger.Log.Error("[ticket] failed to push board update: %v", err)
+			}
+		}
+	}
+
+	if request.SourceWebsocket != "" {
+		if err := websocket.WebsocketPool.SendTicketMessage(websocket.TicketMessage{
+			BroadcastType:   "direct",
+			SourceSessionID: request.SourceWebsocket,
+			Message:         "Bulk ticket change applied",
+			Action:          action,
+		}); err != nil {
+			logger.Log.Error("[ticket] failed to send websocket message: %v", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
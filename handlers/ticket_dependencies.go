@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// ticketDependencyErrorStatus maps dependency validation errors to the status
+// code they are reported with
+func ticketDependencyErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, db.ErrTicketDependencyCycle), errors.Is(err, db.ErrTicketDependencyUnknown):
+		return http.StatusBadRequest, true
+	case errors.Is(err, db.ErrTicketDependenciesIncomplete), errors.Is(err, db.ErrTicketSequenceOrder):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
+
+// GetPhaseTicketGraph godoc
+//
+//	@Summary		Get phase ticket graph
+//	@Description	Tickets of a phase in dependency order with their level, blocked state and the critical path of open tickets
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			feature_uuid	path		string	true	"Feature UUID"
+//	@Param			phase_uuid		path		string	true	"Phase UUID"
+//	@Success		200				{object}	db.PhaseTicketGraph
+//	@Router			/bounties/ticket/feature/{feature_uuid}/phase/{phase_uuid}/graph [get]
+func (th *ticketHandler) GetPhaseTicketGraph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	featureUUID := chi.URLParam(r, "feature_uuid")
+	phaseUUID := chi.URLParam(r, "phase_uuid")
+	if featureUUID == "" || phaseUUID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "feature_uuid and phase_uuid are required"})
+		return
+	}
+
+	graph, err := th.db.GetPhaseTicketGraph(featureUUID, phaseUUID)
+	if err != nil {
+		if status, ok := ticketDependencyErrorStatus(err); ok {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		logger.Log.Error("[ticket] failed to build graph for phase %s: %v", phaseUUID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to build ticket graph"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(graph)
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type UpdateTicketPlanRequest struct {
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	TicketGroupIDs    []string `json:"ticket_group_ids"`
+	Reviewers         []string `json:"reviewers"`
+	RequiredApprovals int      `json:"required_approvals"`
+}
+
+type TicketPlanDecisionRequest struct {
+	Decision db.PlanDecision `json:"decision"`
+	Comment  string          `json:"comment"`
+}
+
+// writeTicketPlanError maps the approval errors of a ticket plan to a status
+func writeTicketPlanError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, db.ErrTicketPlanNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, db.ErrTicketPlanNotReviewer):
+		status = http.StatusUnauthorized
+	case errors.Is(err, db.ErrTicketPlanLocked), errors.Is(err, db.ErrTicketPlanNotInReview),
+		errors.Is(err, db.ErrTicketPlanStatus), errors.Is(err, db.ErrTicketPlanNotApproved):
+		status = http.StatusConflict
+	case errors.Is(err, db.ErrTicketPlanReviewers), errors.Is(err, db.ErrTicketPlanDecision),
+		errors.Is(err, db.ErrTicketPlanSelfReview), errors.Is(err, db.ErrTicketPlanNonMember):
+		status = http.StatusBadRequest
+	default:
+		logger.Log.Error("[ticket plan] %v", err)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// checkTicketPlanReviewers refuses reviewers who created the plan or aren't
+// members of its workspace
+func checkTicketPlanReviewers(database db.Database, plan db.TicketPlan) error {
+	if err := db.CheckTicketPlanReviewers(plan, plan.CreatedBy); err != nil {
+		return err
+	}
+	for _, reviewer := range plan.Reviewers {
+		if !database.IsWorkspaceMember(reviewer, plan.WorkspaceUuid) {
+			return fmt.Errorf("%w: %s", db.ErrTicketPlanNonMember, reviewer)
+		}
+	}
+	return nil
+}
+
+func sameTicketPlanReviewers(reviewers []string, current []string) bool {
+	if len(reviewers) != len(current) {
+		return false
+	}
+	for i := range reviewers {
+		if reviewers[i] != current[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ticketPlanFromRequest reads the plan of the route, only its creator and its
+// reviewers get past it when manage is set
+func (th *ticketHandler) ticketPlanFromRequest(w http.ResponseWriter, r *http.Request, manage bool) (*db.TicketPlan, string, bool) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket plan] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return nil, "", false
+	}
+
+	planUUID, err := uuid.Parse(chi.URLParam(r, "uuid"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid plan UUID"})
+		return nil, "", false
+	}
+
+	plan, err := th.db.GetTicketPlan(planUUID.String())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "ticket plan not found" {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return nil, "", false
+	}
+
+	if manage && plan.CreatedBy != pubKeyFromAuth && !db.IsTicketPlanReviewer(*plan, pubKeyFromAuth) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the plan creator or its reviewers can do this"})
+		return nil, "", false
+	}
+
+	return plan, pubKeyFromAuth, true
+}
+
+// UpdateTicketPlan godoc
+//
+//	@Summary		Update Ticket Plan
+//	@Description	Edit a draft or rejected ticket plan. Every edit is kept as a new revision and needs a new review. Only the plan creator or a workspace admin can change its reviewers, who must be workspace members other than the creator.
+//	@Tags			Ticket Plans
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string					true	"Ticket Plan UUID"
+//	@Param			request	body		UpdateTicketPlanRequest	true	"Plan changes"
+//	@Success		200		{object}	db.TicketPlan
+//	@Router			/bounties/ticket/plan/{uuid} [put]
+func (th *ticketHandler) UpdateTicketPlan(w http.ResponseWriter, r *http.Request) {
+	plan, pubKeyFromAuth, ok := th.ticketPlanFromRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	var request UpdateTicketPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	reviewersChanged := request.Reviewers != nil && !sameTicketPlanReviewers(request.Reviewers, plan.Reviewers)
+	approvalsChanged := request.RequiredApprovals > 0 && request.RequiredApprovals != plan.RequiredApprovals
+	if (reviewersChanged || approvalsChanged) && plan.CreatedBy != pubKeyFromAuth && !th.db.UserHasAccess(pubKeyFromAuth, plan.WorkspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the plan creator or a workspace admin can change its reviewers"})
+		return
+	}
+
+	if request.Name != "" {
+		plan.Name = request.Name
+	}
+	plan.Description = request.Description
+	if request.TicketGroupIDs != nil {
+		plan.TicketGroups = request.TicketGroupIDs
+	}
+	if request.Reviewers != nil {
+		plan.Reviewers = request.Reviewers
+	}
+	if request.RequiredApprovals > 0 {
+		plan.RequiredApprovals = request.RequiredApprovals
+	}
+	plan.UpdatedBy = pubKeyFromAuth
+
+	if reviewersChanged {
+		if err := checkTicketPlanReviewers(th.db, *plan); err != nil {
+			writeTicketPlanError(w, err)
+			return
+		}
+	}
+
+	updated, err := th.db.CreateOrEditTicketPlan(plan)
+	if err != nil {
+		if errors.Is(err, db.ErrTicketPlanLocked) {
+			writeTicketPlanError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// SubmitTicketPlan godoc
+//
+//	@Summary		Submit Ticket Plan
+//	@Description	Ask the reviewers of a draft or rejected plan for approval. Reviewers must be workspace members other than the creator and the submitter.
+//	@Tags			Ticket Plans
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string	true	"Ticket Plan UUID"
+//	@Success		200		{object}	db.TicketPlan
+//	@Router			/bounties/ticket/plan/{uuid}/submit [post]
+func (th *ticketHandler) SubmitTicketPlan(w http.ResponseWriter, r *http.Request) {
+	plan, pubKeyFromAuth, ok := th.ticketPlanFromRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := checkTicketPlanReviewers(th.db, *plan); err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	submitted, err := th.db.SubmitTicketPlan(plan.UUID, pubKeyFromAuth)
+	if err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(submitted)
+}
+
+// ReviewTicketPlan godoc
+//
+//	@Summary		Review Ticket Plan
+//	@Description	Approve or reject the current version of a plan in review. One rejection sends it back, it is approved once enough reviewers approved it and its ticket groups are locked.
+//	@Tags			Ticket Plans
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string						true	"Ticket Plan UUID"
+//	@Param			request	body		TicketPlanDecisionRequest	true	"Decision"
+//	@Success		200		{object}	map[string]interface{}
+//	@Router			/bounties/ticket/plan/{uuid}/review [post]
+func (th *ticketHandler) ReviewTicketPlan(w http.ResponseWriter, r *http.Request) {
+	plan, pubKeyFromAuth, ok := th.ticketPlanFromRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	var request TicketPlanDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	reviewed, approval, err := th.db.ReviewTicketPlan(plan.UUID, pubKeyFromAuth, request.Decision, request.Comment)
+	if err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"plan":     reviewed,
+		"decision": approval,
+	})
+}
+
+// ReopenTicketPlan godoc
+//
+//	@Summary		Reopen Ticket Plan
+//	@Description	Put a plan in review or approved back into draft so it can be edited, which unlocks its ticket groups
+//	@Tags			Ticket Plans
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string	true	"Ticket Plan UUID"
+//	@Success		200		{object}	db.TicketPlan
+//	@Router			/bounties/ticket/plan/{uuid}/reopen [post]
+func (th *ticketHandler) ReopenTicketPlan(w http.ResponseWriter, r *http.Request) {
+	plan, pubKeyFromAuth, ok := th.ticketPlanFromRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	reopened, err := th.db.ReopenTicketPlan(plan.UUID, pubKeyFromAuth)
+	if err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reopened)
+}
+
+// GetTicketPlanRevisions godoc
+//
+//	@Summary		Get Ticket Plan Revisions
+//	@Description	Every saved version of a plan with the ticket versions it pointed at
+//	@Tags			Ticket Plans
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path	string	true	"Ticket Plan UUID"
+//	@Success		200		{array}	db.TicketPlanRevision
+//	@Router			/bounties/ticket/plan/{uuid}/revisions [get]
+func (th *ticketHandler) GetTicketPlanRevisions(w http.ResponseWriter, r *http.Request) {
+	plan, _, ok := th.ticketPlanFromRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	revisions, err := th.db.GetTicketPlanRevisions(plan.UUID)
+	if err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetTicketPlanReviews godoc
+//
+//	@Summary		Get Ticket Plan Reviews
+//	@Description	The approve and reject decisions of the reviewers with their comments
+//	@Tags			Ticket Plans
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path	string	true	"Ticket Plan UUID"
+//	@Success		200		{array}	db.TicketPlanApproval
+//	@Router			/bounties/ticket/plan/{uuid}/reviews [get]
+func (th *ticketHandler) GetTicketPlanReviews(w http.ResponseWriter, r *http.Request) {
+	plan, _, ok := th.ticketPlanFromRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	approvals, err := th.db.GetTicketPlanApprovals(plan.UUID)
+	if err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(approvals)
+}
+
+// CompareTicketPlan godoc
+//
+//	@Summary		Compare Ticket Plan with its approval
+//	@Description	Compare the last approved revision of a plan with the tickets that are executing now
+//	@Tags			Ticket Plans
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			uuid	path		string	true	"Ticket Plan UUID"
+//	@Success		200		{object}	db.TicketPlanExecution
+//	@Router			/bounties/ticket/plan/{uuid}/compare [get]
+func (th *ticketHandler) CompareTicketPlan(w http.ResponseWriter, r *http.Request) {
+	plan, _, ok := th.ticketPlanFromRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	execution, err := th.db.CompareApprovedTicketPlan(plan.UUID)
+	if err != nil {
+		writeTicketPlanError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(execution)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketPlanReviewers(t *testing.T) {
+	workspace := db.Workspace{ID: 1, Uuid: "workspace", OwnerPubKey: "owner"}
+	newPlan := func() *db.TicketPlan {
+		return &db.TicketPlan{
+			UUID:              uuid.New(),
+			WorkspaceUuid:     workspace.Uuid,
+			Name:              "plan",
+			Status:            db.DraftPlan,
+			CreatedBy:         "creator",
+			Reviewers:         pq.StringArray{"reviewer"},
+			RequiredApprovals: 1,
+		}
+	}
+	request := func(plan *db.TicketPlan, pubkey string, body string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("uuid", plan.UUID.String())
+		ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, auth.ContextKey, pubkey)
+		return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(ctx)
+	}
+
+	t.Run("rejects a reviewer replacing the reviewers", func(t *testing.T) {
+		plan := newPlan()
+		mockDb := mocks.NewDatabase(t)
+		th := &ticketHandler{db: mockDb}
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil).Once()
+		mockDb.On("UserHasAccess", "reviewer", workspace.Uuid, db.EditOrg).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		th.UpdateTicketPlan(rr, request(plan, "reviewer", `{"reviewers":["someone-else"]}`))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("rejects the creator assigning themselves as reviewer", func(t *testing.T) {
+		plan := newPlan()
+		mockDb := mocks.NewDatabase(t)
+		th := &ticketHandler{db: mockDb}
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil).Once()
+
+		rr := httptest.NewRecorder()
+		th.UpdateTicketPlan(rr, request(plan, "creator", `{"reviewers":["creator"],"required_approvals":1}`))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("rejects reviewers outside the workspace", func(t *testing.T) {
+		plan := newPlan()
+		mockDb := mocks.NewDatabase(t)
+		th := &ticketHandler{db: mockDb}
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil).Once()
+		mockDb.On("IsWorkspaceMember", "reviewer", workspace.Uuid).Return(false).Once()
+
+		rr := httptest.NewRecorder()
+		th.SubmitTicketPlan(rr, request(plan, "creator", ""))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("refuses the creator approving their own plan", func(t *testing.T) {
+		plan := newPlan()
+		plan.Status = db.PendingReviewPlan
+		plan.Reviewers = pq.StringArray{"creator"}
+		mockDb := mocks.NewDatabase(t)
+		th := &ticketHandler{db: mockDb}
+		mockDb.On("GetTicketPlan", plan.UUID.String()).Return(plan, nil).Once()
+		mockDb.On("ReviewTicketPlan", plan.UUID, "creator", db.PlanApprove, "").
+			Return(db.TicketPlan{}, db.TicketPlanApproval{}, db.ErrTicketPlanSelfReview).Once()
+
+		rr := httptest.NewRecorder()
+		th.ReviewTicketPlan(rr, request(plan, "creator", `{"decision":"APPROVE"}`))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -25,6 +25,9 @@ type CreateTicketPlanRequest struct {
 	Description     string   `json:"description"`
 	TicketGroupIDs  []string `json:"ticket_group_ids"`
 	SourceWebsocket string   `json:"source_websocket,omitempty"`
+	// Reviewers are the pubkeys that can approve the plan
+	Reviewers         []string `json:"reviewers,omitempty"`
+	RequiredApprovals int      `json:"required_approvals,omitempty"`
 }
 
 type TicketPlanResponse struct {
@@ -129,6 +132,19 @@ func (th *ticketHandler) CreateTicketPlan(w http.ResponseWriter, r *http.Request
 		Version:       1,
 		CreatedBy:     pubKeyFromAuth,
 		UpdatedBy:     pubKeyFromAuth,
+
+		Reviewers:         planRequest.Reviewers,
+		RequiredApprovals: planRequest.RequiredApprovals,
+	}
+
+	if err := checkTicketPlanReviewers(th.db, *newPlan); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(TicketPlanResponse{
+			Success: false,
+			Message: "Invalid reviewers",
+			Errors:  []string{err.Error()},
+		})
+		return
 	}
 
 	createdPlan, err := th.db.CreateOrEditTicketPlan(newPlan)
@@ -511,7 +527,7 @@ func (th *ticketHandler) SendTicketPlanToStakwork(w http.ResponseWriter, r *http
 		codeGraph, err := th.db.GetCodeGraphByWorkspaceUuid(feature.WorkspaceUuid)
 		if err == nil {
 			codeGraphURL = codeGraph.Url
-			codeGraphAlias = codeGraph.SecretAlias
+			codeGraphAlias = codeGraph.SecretAlias.String()
 		} else {
 			codeGraphURL = ""
 			codeGraphAlias = ""
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// GetTicketVersions godoc
+//
+//	@Summary		Get ticket versions
+//	@Description	List every version of a ticket group with its author and timestamp, oldest first
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path	string	true	"Ticket group UUID"
+//	@Success		200			{array}	db.TicketVersion
+//	@Router			/bounties/ticket/group/{group_uuid}/versions [get]
+func (th *ticketHandler) GetTicketVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	groupUUID, err := uuid.Parse(chi.URLParam(r, "group_uuid"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid group UUID format"})
+		return
+	}
+
+	versions, err := th.db.GetTicketVersions(groupUUID)
+	if err != nil {
+		logger.Log.Error("[ticket] failed to fetch versions of %s: %v", groupUUID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch ticket versions"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
+}
+
+// DiffTicketVersions godoc
+//
+//	@Summary		Diff ticket versions
+//	@Description	Line by line difference between the descriptions of two versions of a ticket group
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path		string	true	"Ticket group UUID"
+//	@Param			from		query		int		true	"Version to diff from"
+//	@Param			to			query		int		true	"Version to diff to"
+//	@Success		200			{object}	db.TicketVersionDiff
+//	@Router			/bounties/ticket/group/{group_uuid}/versions/diff [get]
+func (th *ticketHandler) DiffTicketVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	groupUUID, err := uuid.Parse(chi.URLParam(r, "group_uuid"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid group UUID format"})
+		return
+	}
+
+	fromVersion, fromErr := strconv.Atoi(r.URL.Query().Get("from"))
+	toVersion, toErr := strconv.Atoi(r.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "from and to versions are required"})
+		return
+	}
+
+	from, err := th.db.GetTicketVersion(groupUUID, fromVersion)
+	if err != nil {
+		writeTicketVersionError(w, err)
+		return
+	}
+	to, err := th.db.GetTicketVersion(groupUUID, toVersion)
+	if err != nil {
+		writeTicketVersionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(db.DiffTicketVersions(from, to))
+}
+
+// RevertTicketVersion godoc
+//
+//	@Summary		Revert ticket to a version
+//	@Description	Create a new version of a ticket group with the name and description of an older version
+//	@Tags			Bounty Tickets
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			group_uuid	path		string	true	"Ticket group UUID"
+//	@Param			version		path		int		true	"Version to revert to"
+//	@Success		200			{object}	db.Tickets
+//	@Router			/bounties/ticket/group/{group_uuid}/versions/{version}/revert [post]
+func (th *ticketHandler) RevertTicketVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[ticket] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	groupUUID, err := uuid.Parse(chi.URLParam(r, "group_uuid"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid group UUID format"})
+		return
+	}
+
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid version"})
+		return
+	}
+
+	ticket, err := th.db.RevertTicketVersion(groupUUID, version, db.HumanAuthor, pubKeyFromAuth)
+	if err != nil {
+		writeTicketVersionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+func writeTicketVersionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrTicketVersionNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	logger.Log.Error("[ticket] %v", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]string{"error": "Failed to process ticket versions"})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type TicketWorkflowResponse struct {
+	Configured bool                    `json:"configured"`
+	Rules      []db.TicketWorkflowRule `json:"rules"`
+}
+
+type TicketWorkflowRequest struct {
+	Rules []db.TicketWorkflowRule `json:"rules"`
+}
+
+// GetTicketWorkflow godoc
+//
+//	@Summary		Get ticket workflow
+//	@Description	Get the ticket status transitions of a workspace. When none are configured the default workflow is returned as a template and any transition is accepted.
+//	@Tags			Workspaces - Ticket Workflow
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Success		200				{object}	TicketWorkflowResponse
+//	@Router			/workspaces/{workspace_uuid}/ticket-workflow [get]
+func (oh *workspaceHandler) GetTicketWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	rules, err := oh.db.GetTicketWorkflowRules(workspaceUuid)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get ticket workflow"})
+		return
+	}
+
+	response := TicketWorkflowResponse{Configured: len(rules) > 0, Rules: rules}
+	if !response.Configured {
+		response.Rules = db.DefaultTicketWorkflow()
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetTicketWorkflow godoc
+//
+//	@Summary		Set ticket workflow
+//	@Description	Replace the ticket status transitions of a workspace, an empty list accepts any transition again
+//	@Tags			Workspaces - Ticket Workflow
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string					true	"Workspace UUID"
+//	@Param			request			body		TicketWorkflowRequest	true	"Workflow rules"
+//	@Success		200				{object}	TicketWorkflowResponse
+//	@Router			/workspaces/{workspace_uuid}/ticket-workflow [put]
+func (oh *workspaceHandler) SetTicketWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.userHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to edit the ticket workflow"})
+		return
+	}
+
+	var request TicketWorkflowRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	if err := db.ValidateTicketWorkflowRules(request.Rules); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	rules, err := oh.db.SetTicketWorkflowRules(workspaceUuid, request.Rules)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to save ticket workflow"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TicketWorkflowResponse{Configured: len(rules) > 0, Rules: rules})
+}
+
+// writeTicketTransitionError reports a rejected status change with the reason,
+// or returns false when err is not a transition error
+func writeTicketTransitionError(w http.ResponseWriter, err error) bool {
+	transitionErr, ok := db.IsTicketTransitionError(err)
+	if !ok {
+		return false
+	}
+
+	status := http.StatusBadRequest
+	if transitionErr.RequiredRole != "" {
+		status = http.StatusUnauthorized
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":      transitionErr.Error(),
+		"transition": transitionErr,
+	})
+	return true
+}
+
+// runTicketTransitionAction applies the side effect of the workflow rule a
+// ticket moved through. A ticket gets at most one bounty however often it
+// enters the status that creates it.
+func runTicketTransitionAction(database db.Database, rule *db.TicketWorkflowRule, ticket db.Tickets, pubkey string) map[string]interface{} {
+	if rule == nil || rule.Action == "" {
+		return nil
+	}
+
+	switch rule.Action {
+	case db.TicketActionCreateBounty:
+		ticketGroup := ticket.UUID
+		if ticket.TicketGroup != nil {
+			ticketGroup = *ticket.TicketGroup
+		}
+		existing, err := database.GetBountyByTicketGroup(ticketGroup)
+		if err != nil {
+			logger.Log.Error("[ticket] failed to check the bounty of ticket %s: %v", ticket.UUID, err)
+			return map[string]interface{}{"action": rule.Action, "error": err.Error()}
+		}
+		if existing.ID != 0 {
+			return nil
+		}
+
+		bounty, err := database.CreateBountyFromTicket(ticket, pubkey)
+		if err != nil {
+			logger.Log.Error("[ticket] failed to create bounty for ticket %s: %v", ticket.UUID, err)
+			return map[string]interface{}{"action": rule.Action, "error": err.Error()}
+		}
+		return map[string]interface{}{"action": rule.Action, "bounty_id": bounty.ID}
+	}
+	return nil
+}
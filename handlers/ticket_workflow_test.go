@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTicketTransitionAction(t *testing.T) {
+	group := uuid.New()
+	ticket := db.Tickets{UUID: uuid.New(), TicketGroup: &group, Name: "ticket"}
+	rule := &db.TicketWorkflowRule{FromStatus: db.InProgressTicket, ToStatus: db.PayTicket, Action: db.TicketActionCreateBounty}
+
+	t.Run("creates a bounty the first time the ticket enters the status", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		mockDb.On("GetBountyByTicketGroup", group).Return(db.NewBounty{}, nil).Once()
+		mockDb.On("CreateBountyFromTicket", ticket, "pubkey").Return(&db.NewBounty{ID: 1, TicketGroup: &group}, nil).Once()
+
+		action := runTicketTransitionAction(mockDb, rule, ticket, "pubkey")
+		assert.Equal(t, uint(1), action["bounty_id"])
+	})
+
+	t.Run("does not create a second bounty for the same ticket", func(t *testing.T) {
+		mockDb := mocks.NewDatabase(t)
+		mockDb.On("GetBountyByTicketGroup", group).Return(db.NewBounty{ID: 1, TicketGroup: &group}, nil).Once()
+
+		assert.Nil(t, runTicketTransitionAction(mockDb, rule, ticket, "pubkey"))
+	})
+
+	t.Run("does nothing without an action", func(t *testing.T) {
+		assert.Nil(t, runTicketTransitionAction(mocks.NewDatabase(t), &db.TicketWorkflowRule{FromStatus: db.DraftTicket, ToStatus: db.ReadyTicket}, ticket, "pubkey"))
+		assert.Nil(t, runTicketTransitionAction(mocks.NewDatabase(t), nil, ticket, "pubkey"))
+	})
+}
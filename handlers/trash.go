@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// GetWorkspaceTrash godoc
+//
+//	@Summary		Get workspace trash
+//	@Description	List the deleted workspace, features, phases, tickets and bounties that can still be restored
+//	@Tags			Workspaces - Trash
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Success		200				{array}	db.TrashItem
+//	@Router			/workspaces/{workspace_uuid}/trash [get]
+func (oh *workspaceHandler) GetWorkspaceTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[trash] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.userHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to the workspace trash"})
+		return
+	}
+
+	items, err := oh.db.GetTrashByWorkspace(workspaceUuid)
+	if err != nil {
+		logger.Log.Error("[trash] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get trash"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+// RestoreTrashItem godoc
+//
+//	@Summary		Restore a trash item
+//	@Description	Restore a deleted record with its relations, only the workspace owner can restore the workspace itself
+//	@Tags			Workspaces - Trash
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			id				path		string	true	"Trash item ID"
+//	@Success		200				{object}	db.TrashItem
+//	@Router			/workspaces/{workspace_uuid}/trash/{id}/restore [post]
+func (oh *workspaceHandler) RestoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[trash] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid trash item ID"})
+		return
+	}
+
+	item, err := oh.db.GetTrashItemByID(id)
+	if err != nil || item.WorkspaceUuid != workspaceUuid {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Trash item not found"})
+		return
+	}
+
+	if item.EntityType == db.TrashWorkspace {
+		workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+		if !workspace.IsOwner(pubKeyFromAuth) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Only the workspace owner can restore the workspace"})
+			return
+		}
+	} else if !oh.userHasAccess(pubKeyFromAuth, workspaceUuid, db.EditOrg) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to restore this item"})
+		return
+	}
+
+	restored, err := oh.db.RestoreTrashItem(id)
+	if err != nil {
+		if errors.Is(err, db.ErrTrashParentMissing) || errors.Is(err, db.ErrTrashConflict) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		logger.Log.Error("[trash] failed to restore %s: %v", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to restore item"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(restored)
+}
+
+// PurgeExpiredTrash drops trash items past the TRASH_RETENTION_DAYS window
+func PurgeExpiredTrash() {
+	purged, err := db.DB.PurgeExpiredTrash()
+	if err != nil {
+		logger.Log.Error("[trash] %v", err)
+		return
+	}
+	if purged > 0 {
+		logger.Log.Info("[trash] purged %d expired items", purged)
+	}
+}
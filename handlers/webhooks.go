@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+	"github.com/stakwork/sphinx-tribes/webhook"
+)
+
+type webhookHandler struct {
+	db         db.Database
+	dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookHandler(database db.Database) *webhookHandler {
+	return &webhookHandler{
+		db:         database,
+		dispatcher: webhook.NewDispatcher(database),
+	}
+}
+
+type WebhookDeliveriesResponse struct {
+	Total int64                `json:"total"`
+	Data  []db.WebhookDelivery `json:"data"`
+}
+
+type WebhookDeliveryResponse struct {
+	Delivery db.WebhookDelivery  `json:"delivery"`
+	Attempts []db.WebhookAttempt `json:"attempts"`
+}
+
+// RetryWebhookDeliveries sends the outbound webhooks whose retry is due
+func RetryWebhookDeliveries() {
+	if sent := webhook.NewDispatcher(db.DB).RetryDue(); sent > 0 {
+		logger.Log.Info("Retried %d webhook deliveries", sent)
+	}
+}
+
+// GetWebhookDeliveries lists outbound webhook deliveries
+//
+//	@Summary		List webhook deliveries
+//	@Description	Lists outbound webhook deliveries newest first, optionally of one endpoint and status. Dead deliveries ran out of attempts and can be replayed.
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Param			endpoint	query		string	false	"Endpoint URL"
+//	@Param			status		query		string	false	"pending, delivered or dead"
+//	@Param			limit		query		int		false	"Page size, 50 by default"
+//	@Param			offset		query		int		false	"Offset"
+//	@Success		200			{object}	WebhookDeliveriesResponse
+//	@Failure		400			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Router			/webhooks/deliveries [get]
+func (wh *webhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := db.WebhookDeliveryStatus(query.Get("status"))
+	switch status {
+	case "", db.WebhookPending, db.WebhookDelivered, db.WebhookDead:
+	default:
+		jsonErrorResponse(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	deliveries, total, err := wh.db.GetWebhookDeliveries(query.Get("endpoint"), status, limit, offset)
+	if err != nil {
+		logger.Log.Error("[webhooks] error getting deliveries: %v", err)
+		jsonErrorResponse(w, "Failed to retrieve webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WebhookDeliveriesResponse{
+		Total: total,
+		Data:  deliveries,
+	})
+}
+
+// GetWebhookDelivery returns a delivery with the log of its attempts
+//
+//	@Summary		Get a webhook delivery
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Param			id	path		string	true	"Delivery ID"
+//	@Success		200	{object}	WebhookDeliveryResponse
+//	@Failure		400	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Router			/webhooks/deliveries/{id} [get]
+func (wh *webhookHandler) GetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := deliveryIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := wh.db.GetWebhookDeliveryByID(id)
+	if err != nil {
+		deliveryError(w, id, err)
+		return
+	}
+	attempts, err := wh.db.GetWebhookAttempts(delivery.ID)
+	if err != nil {
+		logger.Log.Error("[webhooks] error getting attempts of %s: %v", delivery.ID, err)
+		jsonErrorResponse(w, "Failed to retrieve webhook attempts", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WebhookDeliveryResponse{
+		Delivery: *delivery,
+		Attempts: attempts,
+	})
+}
+
+// ReplayWebhookDelivery sends a delivery again
+//
+//	@Summary		Replay a webhook delivery
+//	@Description	Sends a delivery again right away, delivered and dead ones get a fresh set of retries. Pending deliveries that are being sent or wait for their retry are refused.
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Security		SuperAdminAuth
+//	@Param			id	path		string	true	"Delivery ID"
+//	@Success		200	{object}	db.WebhookDelivery
+//	@Failure		400	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Failure		409	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Router			/webhooks/deliveries/{id}/replay [post]
+func (wh *webhookHandler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := deliveryIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := wh.dispatcher.Replay(id)
+	if err != nil {
+		deliveryError(w, id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func deliveryIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		jsonErrorResponse(w, "Invalid delivery ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func deliveryError(w http.ResponseWriter, id uuid.UUID, err error) {
+	if errors.Is(err, db.ErrWebhookDeliveryNotFound) {
+		jsonErrorResponse(w, "Webhook delivery not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, db.ErrWebhookDeliveryClaimed) {
+		jsonErrorResponse(w, "Webhook delivery is already being sent or waiting for its retry", http.StatusConflict)
+		return
+	}
+	logger.Log.Error("[webhooks] error getting delivery %s: %v", id, err)
+	jsonErrorResponse(w, "Failed to retrieve webhook delivery", http.StatusInternalServerError)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func webhookRequest(method string, target string, id string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	mockDb := mocks.NewDatabase(t)
+	wh := NewWebhookHandler(mockDb)
+
+	t.Run("lists the dead deliveries of an endpoint", func(t *testing.T) {
+		mockDb.On("GetWebhookDeliveries", "http://hook", db.WebhookDead, 50, 0).
+			Return([]db.WebhookDelivery{{ID: uuid.New(), Endpoint: "http://hook", Status: db.WebhookDead}}, int64(1), nil).Once()
+
+		rr := httptest.NewRecorder()
+		wh.GetWebhookDeliveries(rr, httptest.NewRequest(http.MethodGet, "/webhooks/deliveries?endpoint=http://hook&status=dead&limit=500", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response WebhookDeliveriesResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, int64(1), response.Total)
+		assert.Len(t, response.Data, 1)
+	})
+
+	t.Run("rejects an unknown status", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		wh.GetWebhookDeliveries(rr, httptest.NewRequest(http.MethodGet, "/webhooks/deliveries?status=lost", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetWebhookDelivery(t *testing.T) {
+	mockDb := mocks.NewDatabase(t)
+	wh := NewWebhookHandler(mockDb)
+
+	t.Run("returns the delivery with its attempts", func(t *testing.T) {
+		delivery := db.WebhookDelivery{ID: uuid.New(), Status: db.WebhookPending, Attempts: 1}
+		mockDb.On("GetWebhookDeliveryByID", delivery.ID).Return(&delivery, nil).Once()
+		mockDb.On("GetWebhookAttempts", delivery.ID).Return([]db.WebhookAttempt{{DeliveryID: delivery.ID, Attempt: 1, StatusCode: 500}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		wh.GetWebhookDelivery(rr, webhookRequest(http.MethodGet, "/webhooks/deliveries/"+delivery.ID.String(), delivery.ID.String()))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response WebhookDeliveryResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, delivery.ID, response.Delivery.ID)
+		assert.Len(t, response.Attempts, 1)
+	})
+
+	t.Run("rejects an invalid ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		wh.GetWebhookDelivery(rr, webhookRequest(http.MethodGet, "/webhooks/deliveries/abc", "abc"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	mockDb := mocks.NewDatabase(t)
+	wh := NewWebhookHandler(mockDb)
+
+	id := uuid.New()
+	mockDb.On("ClaimWebhookDelivery", id, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Duration")).Return(nil, db.ErrWebhookDeliveryNotFound).Once()
+
+	rr := httptest.NewRecorder()
+	wh.ReplayWebhookDelivery(rr, webhookRequest(http.MethodPost, "/webhooks/deliveries/"+id.String()+"/replay", id.String()))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockDb.On("ClaimWebhookDelivery", id, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Duration")).Return(nil, db.ErrWebhookDeliveryClaimed).Once()
+
+	rr = httptest.NewRecorder()
+	wh.ReplayWebhookDelivery(rr, webhookRequest(http.MethodPost, "/webhooks/deliveries/"+id.String()+"/replay", id.String()))
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
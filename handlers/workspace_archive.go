@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type WorkspaceImportRequest struct {
+	Archive   db.WorkspaceArchive `json:"archive"`
+	Name      string              `json:"name"`
+	PubkeyMap map[string]string   `json:"pubkey_map"`
+	DryRun    bool                `json:"dry_run"`
+}
+
+type WorkspaceImportResponse struct {
+	Workspace db.Workspace        `json:"workspace"`
+	Conflicts []db.ImportConflict `json:"conflicts"`
+	DryRun    bool                `json:"dry_run"`
+}
+
+// ExportWorkspace godoc
+//
+//	@Summary		Export workspace
+//	@Description	Download the workspace with its members, repositories, features, tickets, bounties, snippets and activities as a versioned JSON archive
+//	@Tags			Workspaces - Archive
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Success		200				{object}	db.WorkspaceArchive
+//	@Router			/workspaces/{workspace_uuid}/export [get]
+func (oh *workspaceHandler) ExportWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.ID == 0 || workspace.Deleted {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace not found"})
+		return
+	}
+
+	if !workspace.IsOwner(pubKeyFromAuth) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only workspace owners can export the workspace"})
+		return
+	}
+
+	archive, err := oh.db.ExportWorkspace(workspaceUuid)
+	if err != nil {
+		logger.Log.Error("[workspaces] failed to export %s: %v", workspaceUuid, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to export workspace"})
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=workspace-%s.json", workspaceUuid))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(archive)
+}
+
+// ImportWorkspace godoc
+//
+//	@Summary		Import workspace
+//	@Description	Create a new workspace owned by the caller from an exported archive. Every record gets a new UUID and pubkeys are rewritten through pubkey_map. Pubkeys that are neither mapped nor the caller's are dropped and reported as conflicts, and bounties come in unassigned and unpaid. A dry run only reports conflicts.
+//	@Tags			Workspaces - Archive
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			request	body		WorkspaceImportRequest	true	"Import request"
+//	@Success		201		{object}	WorkspaceImportResponse
+//	@Failure		409		{object}	WorkspaceImportResponse
+//	@Router			/workspaces/import [post]
+func (oh *workspaceHandler) ImportWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request WorkspaceImportRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	if request.Archive.Version != db.WorkspaceArchiveVersion {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": fmt.Sprintf("Unsupported archive version %d, expected %d", request.Archive.Version, db.WorkspaceArchiveVersion),
+		})
+		return
+	}
+
+	options := db.WorkspaceImportOptions{
+		Name:        request.Name,
+		PubkeyMap:   request.PubkeyMap,
+		DryRun:      request.DryRun,
+		OwnerPubkey: pubKeyFromAuth,
+	}
+	if len(options.Name) > 20 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace name should not exceed 20 characters"})
+		return
+	}
+
+	imported, conflicts, err := oh.db.ImportWorkspace(request.Archive, options)
+	response := WorkspaceImportResponse{
+		Workspace: imported.Workspace,
+		Conflicts: conflicts,
+		DryRun:    request.DryRun,
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrWorkspaceImportConflict) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		logger.Log.Error("[workspaces] failed to import workspace: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to import workspace"})
+		return
+	}
+
+	if request.DryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(response)
+}
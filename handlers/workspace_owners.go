@@ -0,0 +1,461 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type OwnershipTransferRequest struct {
+	ToPubkey      string `json:"to_pubkey"`
+	KeepAsCoOwner bool   `json:"keep_as_co_owner"`
+}
+
+type CoOwnerRequest struct {
+	Pubkey string `json:"pubkey"`
+}
+
+// InitiateOwnershipTransfer godoc
+//
+//	@Summary		Initiate workspace ownership transfer
+//	@Description	Offer ownership of a workspace to another user, the transfer completes once the recipient accepts it
+//	@Tags			Workspaces - Ownership
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string						true	"Workspace UUID"
+//	@Param			request			body		OwnershipTransferRequest	true	"Transfer request"
+//	@Success		201				{object}	db.WorkspaceOwnershipTransfer
+//	@Router			/workspaces/{workspace_uuid}/ownership/transfer [post]
+func (oh *workspaceHandler) InitiateOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	workspace := oh.db.GetWorkspaceByUuid(workspaceUuid)
+	if workspace.ID == 0 || workspace.Deleted {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace not found"})
+		return
+	}
+
+	if pubKeyFromAuth != workspace.OwnerPubKey {
+		logger.Log.Info("[workspaces] only the workspace owner can transfer ownership")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the workspace owner can transfer ownership"})
+		return
+	}
+
+	var request OwnershipTransferRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	request.ToPubkey = strings.TrimSpace(request.ToPubkey)
+	if request.ToPubkey == "" || request.ToPubkey == workspace.OwnerPubKey {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "A recipient other than the current owner is required"})
+		return
+	}
+
+	recipient := oh.db.GetPersonByPubkey(request.ToPubkey)
+	if recipient.OwnerPubKey != request.ToPubkey {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Recipient doesn't exist in people"})
+		return
+	}
+
+	transfer, err := oh.db.CreateOwnershipTransfer(&db.WorkspaceOwnershipTransfer{
+		WorkspaceUuid: workspace.Uuid,
+		FromPubkey:    pubKeyFromAuth,
+		ToPubkey:      request.ToPubkey,
+		KeepAsCoOwner: request.KeepAsCoOwner,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrOwnershipTransferExists) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create ownership transfer"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// GetOwnershipTransfers godoc
+//
+//	@Summary		Get workspace ownership transfers
+//	@Description	List every ownership transfer of a workspace, newest first
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Success		200				{array}	db.WorkspaceOwnershipTransfer
+//	@Router			/workspaces/{workspace_uuid}/ownership/transfers [get]
+func (oh *workspaceHandler) GetOwnershipTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(chi.URLParam(r, "workspace_uuid"))
+	if !workspace.IsOwner(pubKeyFromAuth) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only workspace owners can view ownership transfers"})
+		return
+	}
+
+	transfers, err := oh.db.GetOwnershipTransfersByWorkspace(workspace.Uuid)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get ownership transfers"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// GetIncomingOwnershipTransfers godoc
+//
+//	@Summary		Get incoming ownership transfers
+//	@Description	List the pending ownership transfers offered to the authenticated user
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Success		200	{array}	db.WorkspaceOwnershipTransfer
+//	@Router			/workspaces/ownership/transfers/incoming [get]
+func (oh *workspaceHandler) GetIncomingOwnershipTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	transfers, err := oh.db.GetPendingOwnershipTransfersForPubkey(pubKeyFromAuth)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get ownership transfers"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// AcceptOwnershipTransfer godoc
+//
+//	@Summary		Accept an ownership transfer
+//	@Description	The recipient accepts a pending transfer and becomes the workspace owner
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id	path		string	true	"Transfer ID"
+//	@Success		200	{object}	db.Workspace
+//	@Router			/workspaces/ownership/transfers/{id}/accept [post]
+func (oh *workspaceHandler) AcceptOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, transfer, ok := oh.ownershipTransferFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if pubKeyFromAuth != transfer.ToPubkey {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the recipient can accept this transfer"})
+		return
+	}
+
+	workspace, err := oh.db.AcceptOwnershipTransfer(transfer.ID)
+	if err != nil {
+		writeOwnershipTransferError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workspace)
+}
+
+// RejectOwnershipTransfer godoc
+//
+//	@Summary		Reject an ownership transfer
+//	@Description	The recipient declines a pending transfer
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id	path		string	true	"Transfer ID"
+//	@Success		200	{object}	db.WorkspaceOwnershipTransfer
+//	@Router			/workspaces/ownership/transfers/{id}/reject [post]
+func (oh *workspaceHandler) RejectOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, transfer, ok := oh.ownershipTransferFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if pubKeyFromAuth != transfer.ToPubkey {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the recipient can reject this transfer"})
+		return
+	}
+
+	closed, err := oh.db.CloseOwnershipTransfer(transfer.ID, pubKeyFromAuth, db.OwnershipTransferRejected)
+	if err != nil {
+		writeOwnershipTransferError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(closed)
+}
+
+// CancelOwnershipTransfer godoc
+//
+//	@Summary		Cancel an ownership transfer
+//	@Description	The owner withdraws a pending transfer
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			id	path		string	true	"Transfer ID"
+//	@Success		200	{object}	db.WorkspaceOwnershipTransfer
+//	@Router			/workspaces/ownership/transfers/{id}/cancel [post]
+func (oh *workspaceHandler) CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	pubKeyFromAuth, transfer, ok := oh.ownershipTransferFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(transfer.WorkspaceUuid)
+	if pubKeyFromAuth != transfer.FromPubkey && pubKeyFromAuth != workspace.OwnerPubKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the workspace owner can cancel this transfer"})
+		return
+	}
+
+	closed, err := oh.db.CloseOwnershipTransfer(transfer.ID, pubKeyFromAuth, db.OwnershipTransferCancelled)
+	if err != nil {
+		writeOwnershipTransferError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(closed)
+}
+
+// AddWorkspaceCoOwner godoc
+//
+//	@Summary		Add a workspace co-owner
+//	@Description	Co-owners bypass role checks, only the workspace owner can add them
+//	@Tags			Workspaces - Ownership
+//	@Accept			json
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string			true	"Workspace UUID"
+//	@Param			request			body		CoOwnerRequest	true	"Co-owner"
+//	@Success		200				{object}	db.Workspace
+//	@Router			/workspaces/{workspace_uuid}/coowners [post]
+func (oh *workspaceHandler) AddWorkspaceCoOwner(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(chi.URLParam(r, "workspace_uuid"))
+	if workspace.ID == 0 || workspace.Deleted {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace not found"})
+		return
+	}
+
+	if pubKeyFromAuth != workspace.OwnerPubKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the workspace owner can add co-owners"})
+		return
+	}
+
+	var request CoOwnerRequest
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err := json.Unmarshal(body, &request); err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+
+	request.Pubkey = strings.TrimSpace(request.Pubkey)
+	if request.Pubkey == "" || request.Pubkey == workspace.OwnerPubKey {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "A co-owner other than the current owner is required"})
+		return
+	}
+
+	person := oh.db.GetPersonByPubkey(request.Pubkey)
+	if person.OwnerPubKey != request.Pubkey {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "User doesn't exist in people"})
+		return
+	}
+
+	updated, err := oh.db.AddWorkspaceCoOwner(workspace.Uuid, request.Pubkey, pubKeyFromAuth)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to add co-owner"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// RemoveWorkspaceCoOwner godoc
+//
+//	@Summary		Remove a workspace co-owner
+//	@Description	The workspace owner can remove any co-owner, and co-owners can remove themselves
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			pubkey			path		string	true	"Co-owner pubkey"
+//	@Success		200				{object}	db.Workspace
+//	@Router			/workspaces/{workspace_uuid}/coowners/{pubkey} [delete]
+func (oh *workspaceHandler) RemoveWorkspaceCoOwner(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(chi.URLParam(r, "workspace_uuid"))
+	if workspace.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Workspace not found"})
+		return
+	}
+
+	pubkey := chi.URLParam(r, "pubkey")
+	if pubKeyFromAuth != workspace.OwnerPubKey && pubKeyFromAuth != pubkey {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only the workspace owner can remove co-owners"})
+		return
+	}
+
+	updated, err := oh.db.RemoveWorkspaceCoOwner(workspace.Uuid, pubkey, pubKeyFromAuth)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove co-owner"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// GetWorkspaceOwnershipAudit godoc
+//
+//	@Summary		Get workspace ownership audit trail
+//	@Description	List transfers and co-owner changes of a workspace, newest first
+//	@Tags			Workspaces - Ownership
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path	string	true	"Workspace UUID"
+//	@Success		200				{array}	db.WorkspaceOwnershipAudit
+//	@Router			/workspaces/{workspace_uuid}/ownership/audit [get]
+func (oh *workspaceHandler) GetWorkspaceOwnershipAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspace := oh.db.GetWorkspaceByUuid(chi.URLParam(r, "workspace_uuid"))
+	if !workspace.IsOwner(pubKeyFromAuth) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Only workspace owners can view the ownership audit"})
+		return
+	}
+
+	audit, err := oh.db.GetWorkspaceOwnershipAudit(workspace.Uuid)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to get ownership audit"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(audit)
+}
+
+func (oh *workspaceHandler) ownershipTransferFromRequest(w http.ResponseWriter, r *http.Request) (string, *db.WorkspaceOwnershipTransfer, bool) {
+	pubKeyFromAuth, _ := r.Context().Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[workspaces] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return "", nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid transfer ID"})
+		return "", nil, false
+	}
+
+	transfer, err := oh.db.GetOwnershipTransferByID(id)
+	if err != nil {
+		logger.Log.Error("[workspaces] %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Ownership transfer not found"})
+		return "", nil, false
+	}
+
+	return pubKeyFromAuth, transfer, true
+}
+
+func writeOwnershipTransferError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrOwnershipTransferNotPending) || errors.Is(err, db.ErrOwnershipTransferStale) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Error("[workspaces] %v", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update ownership transfer"})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+// SearchWorkspace godoc
+//
+//	@Summary		Search workspace
+//	@Description	Full text search over the chat messages, artifacts and snippets of a workspace, ranked by relevance. The query accepts web search syntax like "quoted phrases", or and -excluded words. Excerpt parts mark the matched words.
+//	@Tags			Workspaces
+//	@Produce		json
+//	@Security		PubKeyContextAuth
+//	@Param			workspace_uuid	path		string	true	"Workspace UUID"
+//	@Param			q				query		string	true	"Search query"
+//	@Param			kinds			query		string	false	"Comma separated kinds: message, artifact, snippet"
+//	@Param			chat_id			query		string	false	"Only search this chat"
+//	@Param			limit			query		int		false	"Page size, at most 100"
+//	@Param			offset			query		int		false	"Page offset"
+//	@Success		200				{object}	db.WorkspaceSearchResult
+//	@Router			/workspaces/{workspace_uuid}/search [get]
+func (oh *workspaceHandler) SearchWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pubKeyFromAuth, _ := ctx.Value(auth.ContextKey).(string)
+	if pubKeyFromAuth == "" {
+		logger.Log.Info("[search] no pubkey from auth")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	workspaceUuid := chi.URLParam(r, "workspace_uuid")
+	if !oh.db.IsWorkspaceMember(pubKeyFromAuth, workspaceUuid) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Don't have access to search the workspace"})
+		return
+	}
+
+	query := r.URL.Query()
+	params := db.WorkspaceSearchParams{
+		Query:  query.Get("q"),
+		ChatID: query.Get("chat_id"),
+	}
+	for _, kind := range strings.Split(query.Get("kinds"), ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			params.Kinds = append(params.Kinds, db.SearchKind(kind))
+		}
+	}
+	params.Limit, _ = strconv.Atoi(query.Get("limit"))
+	params.Offset, _ = strconv.Atoi(query.Get("offset"))
+
+	result, err := oh.db.SearchWorkspace(workspaceUuid, params)
+	if errors.Is(err, db.ErrSearchQuery) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Log.Error("[search] %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to search the workspace"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
@@ -95,7 +95,16 @@ func (oh *workspaceHandler) CreateOrEditWorkspace(w http.ResponseWriter, r *http
 		return
 	}
 
-	if pubKeyFromAuth != workspace.OwnerPubKey {
+	existing := oh.db.GetWorkspaceByUuid(workspace.Uuid)
+	if existing.ID != 0 {
+		// ownership only changes through an ownership transfer
+		workspace.OwnerPubKey = existing.OwnerPubKey
+		workspace.CoOwners = existing.CoOwners
+	} else {
+		workspace.CoOwners = nil
+	}
+
+	if !workspace.IsOwner(pubKeyFromAuth) {
 		hasRole := db.UserHasAccess(pubKeyFromAuth, workspace.Uuid, db.EditOrg)
 		if !hasRole {
 			logger.Log.Info("[workspaces] mismatched pubkey")
@@ -123,7 +132,6 @@ func (oh *workspaceHandler) CreateOrEditWorkspace(w http.ResponseWriter, r *http
 		return
 	}
 
-	existing := oh.db.GetWorkspaceByUuid(workspace.Uuid)
 	if existing.ID == 0 { // new!
 		if workspace.ID != 0 { // can't try to "edit" if it does not exist already
 			logger.Log.Info("[workspaces] cant edit non existing")
@@ -150,6 +158,7 @@ func (oh *workspaceHandler) CreateOrEditWorkspace(w http.ResponseWriter, r *http
 		workspace.Updated = &now
 		workspace.Created = existing.Created
 	}
+	workspace.UpdatedBy = pubKeyFromAuth
 
 	p, err := oh.db.CreateOrEditWorkspace(workspace)
 	if err != nil {
@@ -259,7 +268,7 @@ func (oh *workspaceHandler) CreateWorkspaceUser(w http.ResponseWriter, r *http.R
 	}
 
 	// check if the user is the workspace admin
-	if workspaceUser.OwnerPubKey == workspace.OwnerPubKey {
+	if workspace.IsOwner(workspaceUser.OwnerPubKey) {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode("Cannot add workspace admin as a user")
 		return
@@ -416,7 +425,7 @@ func DeleteWorkspaceUser(w http.ResponseWriter, r *http.Request) {
 
 	workspace := db.DB.GetWorkspaceByUuid(workspaceUser.WorkspaceUuid)
 
-	if workspaceUser.OwnerPubKey == workspace.OwnerPubKey {
+	if workspace.IsOwner(workspaceUser.OwnerPubKey) {
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode("Cannot delete workspace admin")
 		return
@@ -656,7 +665,7 @@ func (oh *workspaceHandler) GetUserDropdownWorkspaces(w http.ResponseWriter, r *
 
 		alreadyAdded := false
 
-		if workspace.OwnerPubKey == user.OwnerPubKey {
+		if workspace.IsOwner(user.OwnerPubKey) {
 			alreadyAdded = true
 		}
 
@@ -1138,7 +1147,7 @@ func (oh *workspaceHandler) DeleteWorkspace(w http.ResponseWriter, r *http.Reque
 	}
 
 	workspace := oh.db.GetWorkspaceByUuid(uuid)
-	if pubKeyFromAuth != workspace.OwnerPubKey {
+	if !workspace.IsOwner(pubKeyFromAuth) {
 		msg := "only workspace admin can delete an workspace"
 		logger.Log.Info("[workspaces] %s", msg)
 		w.WriteHeader(http.StatusUnauthorized)
@@ -1146,8 +1155,8 @@ func (oh *workspaceHandler) DeleteWorkspace(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Soft delete Workspace and delete user data
-	if err := oh.db.ProcessDeleteWorkspace(uuid); err != nil {
+	// Soft delete Workspace and move its user data to the trash
+	if err := oh.db.TrashWorkspace(uuid, pubKeyFromAuth); err != nil {
 		msg := "Error removing users from workspace"
 		logger.Log.Error("%s: %v", msg, err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -1192,7 +1201,15 @@ func (oh *workspaceHandler) UpdateWorkspace(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if pubKeyFromAuth != workspace.OwnerPubKey {
+	existing := oh.db.GetWorkspaceByUuid(workspace.Uuid)
+	if existing.ID != 0 {
+		// ownership only changes through an ownership transfer
+		workspace.OwnerPubKey = existing.OwnerPubKey
+		workspace.CoOwners = existing.CoOwners
+	}
+	workspace.UpdatedBy = pubKeyFromAuth
+
+	if !workspace.IsOwner(pubKeyFromAuth) {
 		hasRole := db.UserHasAccess(pubKeyFromAuth, workspace.Uuid, db.EditOrg)
 		if !hasRole {
 			logger.Log.Info("[workspaces] mismatched pubkey")
@@ -1505,7 +1522,7 @@ func GetAllUserWorkspaces(pubkey string) []db.Workspace {
 
 		// don't add workspace to the list if user is the owner of the workspace
 		alreadyAdded := false
-		if workspace.OwnerPubKey == pubkey {
+		if workspace.IsOwner(pubkey) {
 			alreadyAdded = true
 		}
 
@@ -1594,7 +1611,7 @@ func (oh *workspaceHandler) CreateOrEditWorkspaceCodeGraph(w http.ResponseWriter
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(p)
+	json.NewEncoder(w).Encode(p.Redacted())
 }
 
 // GetWorkspaceCodeGraphByUUID godoc
@@ -1628,7 +1645,7 @@ func (oh *workspaceHandler) GetWorkspaceCodeGraphByUUID(w http.ResponseWriter, r
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codeGraph)
+	json.NewEncoder(w).Encode(codeGraph.Redacted())
 }
 
 // GetCodeGraphByWorkspaceUuid godoc
@@ -1660,7 +1677,7 @@ func (oh *workspaceHandler) GetCodeGraphByWorkspaceUuid(w http.ResponseWriter, r
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(codeGraph)
+	json.NewEncoder(w).Encode(codeGraph.Redacted())
 }
 
 // DeleteWorkspaceCodeGraph godoc
@@ -1770,7 +1787,7 @@ func (oh *workspaceHandler) GetWorkspaceEnvVars(w http.ResponseWriter, r *http.R
 		json.NewEncoder(w).Encode(map[string]string{"error": "failed to create request"})
 		return
 	}
-	req.Header.Set("Authorization", "Bearer "+codespaces[0].PoolAPIKey)
+	req.Header.Set("Authorization", "Bearer "+codespaces[0].PoolAPIKey.String())
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		w.WriteHeader(http.StatusBadGateway)
@@ -1824,7 +1841,7 @@ func (oh *workspaceHandler) UpdateWorkspaceEnvVars(w http.ResponseWriter, r *htt
 		json.NewEncoder(w).Encode(map[string]string{"error": "failed to create request"})
 		return
 	}
-	req.Header.Set("Authorization", "Bearer "+codespaces[0].PoolAPIKey)
+	req.Header.Set("Authorization", "Bearer "+codespaces[0].PoolAPIKey.String())
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -2027,7 +2027,8 @@ func TestCreateOrEditWorkspaceCodeGraph(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 		assert.Equal(t, codeGraph.Name, returnedCodeGraph.Name)
 		assert.Equal(t, codeGraph.Url, returnedCodeGraph.Url)
-		assert.Equal(t, codeGraph.SecretAlias, returnedCodeGraph.SecretAlias)
+		assert.Empty(t, returnedCodeGraph.SecretAlias)
+		assert.True(t, returnedCodeGraph.HasSecretAlias)
 	})
 
 	t.Run("should be able to update secret alias of existing code graph", func(t *testing.T) {
@@ -2071,7 +2072,11 @@ func TestCreateOrEditWorkspaceCodeGraph(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, http.StatusOK, rr.Code)
-		assert.Equal(t, updatedCodeGraph.SecretAlias, returnedCodeGraph.SecretAlias)
+		assert.Empty(t, returnedCodeGraph.SecretAlias)
+
+		storedCodeGraph, err := db.TestDB.GetCodeGraphByUUID(initialCodeGraph.Uuid)
+		assert.NoError(t, err)
+		assert.Equal(t, updatedCodeGraph.SecretAlias, storedCodeGraph.SecretAlias)
 	})
 }
 
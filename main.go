@@ -16,6 +16,7 @@ import (
 	_ "github.com/stakwork/sphinx-tribes/docs"
 	"github.com/stakwork/sphinx-tribes/handlers"
 	"github.com/stakwork/sphinx-tribes/routes"
+	"github.com/stakwork/sphinx-tribes/sse"
 	"github.com/stakwork/sphinx-tribes/websocket"
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -45,6 +46,7 @@ func main() {
 		fmt.Println("no .env file")
 	}
 
+	db.InitSecrets()
 	db.InitDB()
 	db.InitRedis()
 	db.InitCache()
@@ -59,6 +61,8 @@ func main() {
 	db.Validate = validator.New()
 	// Start websocket pool
 	go websocket.WebsocketPool.Start()
+	// follow the SSE streams that were running before the restart
+	sse.ResumeClients(db.DB)
 
 	skipLoops := os.Getenv("SKIP_LOOPS")
 	if skipLoops != "true" {
@@ -74,6 +78,9 @@ func runCron() {
 	c := cron.New()
 	c.AddFunc("@every 0h30m0s", handlers.InitV2PaymentsCron)
 	c.AddFunc("@every 0h0m30s", handlers.ProcessWaitingNotifications)
+	c.AddFunc("@every 1h0m0s", handlers.PurgeExpiredTrash)
+	c.AddFunc("@every 1h0m0s", handlers.RunFileAssetLifecycle)
+	c.AddFunc("@every 0h0m30s", handlers.RetryWebhookDeliveries)
 	c.Start()
 }
 
@@ -0,0 +1,346 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+type RateLimitResponse struct {
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+// RateLimitPolicy limits how many requests a single caller can make to the
+// matching route within the window. Path uses the same :param syntax as
+// feature flag endpoints, an empty Method matches every method.
+type RateLimitPolicy struct {
+	Name          string `json:"name"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	Limit         int64  `json:"limit"`
+	WindowSeconds int    `json:"window_seconds"`
+}
+
+func (p RateLimitPolicy) Window() time.Duration {
+	return time.Duration(p.WindowSeconds) * time.Second
+}
+
+func (p RateLimitPolicy) matches(r *http.Request) bool {
+	if p.Method != "" && !strings.EqualFold(p.Method, r.Method) {
+		return false
+	}
+	return matchPath(p.Path, r.URL.Path)
+}
+
+var DefaultRateLimitPolicies = []RateLimitPolicy{
+	{Name: "hivechat-send", Method: http.MethodPost, Path: "/hivechat/send", Limit: 30, WindowSeconds: 60},
+	{Name: "feed-download", Method: http.MethodPost, Path: "/feed/download", Limit: 10, WindowSeconds: 60},
+	{Name: "bounty-pay", Method: http.MethodPost, Path: "/gobounties/pay/:id", Limit: 10, WindowSeconds: 60},
+	{Name: "search-bots", Method: http.MethodGet, Path: "/search/bots/:query", Limit: 60, WindowSeconds: 60},
+	{Name: "search-podcasts", Method: http.MethodGet, Path: "/search_podcasts", Limit: 60, WindowSeconds: 60},
+	{Name: "search-podcast-episodes", Method: http.MethodGet, Path: "/search_podcast_episodes", Limit: 60, WindowSeconds: 60},
+	{Name: "search-youtube", Method: http.MethodGet, Path: "/search_youtube", Limit: 60, WindowSeconds: 60},
+	{Name: "search-youtube-videos", Method: http.MethodGet, Path: "/search_youtube_videos", Limit: 60, WindowSeconds: 60},
+	{Name: "people-search", Method: http.MethodGet, Path: "/people/search", Limit: 60, WindowSeconds: 60},
+}
+
+// LoadRateLimitPolicies merges a JSON list of policies over the defaults,
+// policies with the same name replace the default one and a limit of 0 disables it
+func LoadRateLimitPolicies(raw string) ([]RateLimitPolicy, error) {
+	policies := make([]RateLimitPolicy, len(DefaultRateLimitPolicies))
+	copy(policies, DefaultRateLimitPolicies)
+
+	if strings.TrimSpace(raw) == "" {
+		return policies, nil
+	}
+
+	var overrides []RateLimitPolicy
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid rate limit policies: %w", err)
+	}
+
+	for _, override := range overrides {
+		if override.Path == "" {
+			return nil, fmt.Errorf("rate limit policy %s has no path", override.Name)
+		}
+		if override.WindowSeconds <= 0 {
+			override.WindowSeconds = 60
+		}
+		if override.Name == "" {
+			override.Name = strings.ToUpper(override.Method) + " " + override.Path
+		}
+
+		replaced := false
+		for i, p := range policies {
+			if p.Name == override.Name {
+				policies[i] = override
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			policies = append(policies, override)
+		}
+	}
+
+	active := policies[:0]
+	for _, p := range policies {
+		if p.Limit > 0 {
+			active = append(active, p)
+		}
+	}
+	return active, nil
+}
+
+// RateLimitStore counts hits in fixed windows
+type RateLimitStore interface {
+	Increment(key string, window time.Duration) (count int64, resetIn time.Duration, err error)
+}
+
+type memoryWindow struct {
+	count   int64
+	resetAt time.Time
+}
+
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	windows   map[string]*memoryWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		windows: map[string]*memoryWindow{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Increment(key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &memoryWindow{resetAt: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.count++
+
+	return w.count, w.resetAt.Sub(now), nil
+}
+
+// sweep drops expired windows at most once a minute so the map does not grow unbounded
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, w := range s.windows {
+		if !now.Before(w.resetAt) {
+			delete(s.windows, key)
+		}
+	}
+}
+
+// RedisRateLimitStore shares counters between instances, and falls back to
+// process memory whenever Redis can not be reached
+type RedisRateLimitStore struct {
+	client   *redis.Client
+	fallback RateLimitStore
+}
+
+func NewRedisRateLimitStore(client *redis.Client, fallback RateLimitStore) *RedisRateLimitStore {
+	return &RedisRateLimitStore{
+		client:   client,
+		fallback: fallback,
+	}
+}
+
+// incrementScript only sets the expiry on the first hit, so the window does not slide
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+func (s *RedisRateLimitStore) Increment(key string, window time.Duration) (int64, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	result, err := incrementScript.Run(ctx, s.client, []string{"ratelimit:" + key}, window.Milliseconds()).Int64Slice()
+	if err != nil || len(result) != 2 {
+		logger.Log.Error("[rate limit] redis error, using memory store: %v", err)
+		return s.fallback.Increment(key, window)
+	}
+
+	resetIn := time.Duration(result[1]) * time.Millisecond
+	if resetIn <= 0 {
+		resetIn = window
+	}
+	return result[0], resetIn, nil
+}
+
+// NewRateLimitStore uses Redis when it is connected and process memory otherwise
+func NewRateLimitStore() RateLimitStore {
+	memory := NewMemoryRateLimitStore()
+	if db.RedisClient == nil || db.RedisError != nil {
+		logger.Log.Info("[rate limit] redis not available, using memory store")
+		return memory
+	}
+	return NewRedisRateLimitStore(db.RedisClient, memory)
+}
+
+// RateLimit rejects requests over the matching policy limit with 429 and a Retry-After header.
+// Callers are identified by their authenticated pubkey, or by client IP when anonymous.
+func RateLimit(policies []RateLimitPolicy, store RateLimitStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var policy *RateLimitPolicy
+			for i := range policies {
+				if policies[i].matches(r) {
+					policy = &policies[i]
+					break
+				}
+			}
+
+			if policy == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := policy.Name + ":" + rateLimitCaller(r)
+			count, resetIn, err := store.Increment(key, policy.Window())
+			if err != nil {
+				logger.Log.Error("[rate limit] failed to count request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			remaining := policy.Limit - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			resetSeconds := int(math.Ceil(resetIn.Seconds()))
+
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(policy.Limit, 10))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+			if count > policy.Limit {
+				w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(RateLimitResponse{
+					Success:    false,
+					Message:    "Too many requests, please try again later.",
+					RetryAfter: resetSeconds,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitFromConfig builds the middleware from the RATE_LIMIT_* settings
+func RateLimitFromConfig() func(http.Handler) http.Handler {
+	if !config.RateLimitEnabled {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	policies, err := LoadRateLimitPolicies(config.RateLimitPolicies)
+	if err != nil {
+		logger.Log.Error("[rate limit] %v, using default policies", err)
+		policies = DefaultRateLimitPolicies
+	}
+
+	return RateLimit(policies, NewRateLimitStore())
+}
+
+func rateLimitCaller(r *http.Request) string {
+	if pubkey, ok := r.Context().Value(auth.ContextKey).(string); ok && pubkey != "" {
+		return "pubkey:" + pubkey
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.Header.Get("x-jwt")
+	}
+	if token != "" && strings.Contains(token, ".") && !strings.HasPrefix(token, ".") {
+		if claims, err := auth.DecodeJwt(token); err == nil {
+			if pubkey, ok := claims["pubkey"].(string); ok && pubkey != "" {
+				return "pubkey:" + pubkey
+			}
+		}
+	}
+
+	return "ip:" + clientIP(r)
+}
+
+// clientIP is the address the request came from. The forwarding headers are
+// only believed when that address is one of config.TrustedProxies, the client
+// is then the last address in X-Forwarded-For that isn't a trusted proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && (i == 0 || !isTrustedProxy(hop)) {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return host
+}
+
+func isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range config.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
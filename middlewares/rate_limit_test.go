@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	policies := []RateLimitPolicy{
+		{Name: "bounty-pay", Method: http.MethodPost, Path: "/gobounties/pay/:id", Limit: 2, WindowSeconds: 60},
+	}
+
+	newHandler := func() http.Handler {
+		return RateLimit(policies, NewMemoryRateLimitStore())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+
+	t.Run("should allow requests under the limit and set headers", func(t *testing.T) {
+		handler := newHandler()
+
+		req := httptest.NewRequest(http.MethodPost, "/gobounties/pay/1", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "1", rr.Header().Get("X-RateLimit-Remaining"))
+		assert.Empty(t, rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("should reject requests over the limit with Retry-After", func(t *testing.T) {
+		handler := newHandler()
+
+		var rr *httptest.ResponseRecorder
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/gobounties/pay/1", nil)
+			rr = httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+		}
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	})
+
+	t.Run("should not limit routes without a policy", func(t *testing.T) {
+		handler := newHandler()
+
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/gobounties/pay/1", nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+
+	t.Run("should count callers separately by pubkey and ip", func(t *testing.T) {
+		handler := newHandler()
+
+		for i := 0; i < 2; i++ {
+			ctx := context.WithValue(context.Background(), auth.ContextKey, "pubkey-1")
+			req := httptest.NewRequest(http.MethodPost, "/gobounties/pay/1", nil).WithContext(ctx)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+		}
+
+		ctx := context.WithValue(context.Background(), auth.ContextKey, "pubkey-2")
+		req := httptest.NewRequest(http.MethodPost, "/gobounties/pay/1", nil).WithContext(ctx)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/gobounties/pay/1", nil)
+		req.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	count, resetIn, err := store.Increment("key", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, time.Minute, resetIn)
+
+	now = now.Add(30 * time.Second)
+	count, resetIn, _ = store.Increment("key", time.Minute)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, 30*time.Second, resetIn)
+
+	now = now.Add(31 * time.Second)
+	count, _, _ = store.Increment("key", time.Minute)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestLoadRateLimitPolicies(t *testing.T) {
+	t.Run("should return defaults when empty", func(t *testing.T) {
+		policies, err := LoadRateLimitPolicies("")
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultRateLimitPolicies, policies)
+	})
+
+	t.Run("should override, add and disable policies", func(t *testing.T) {
+		raw := `[
+			{"name": "hivechat-send", "method": "POST", "path": "/hivechat/send", "limit": 5, "window_seconds": 10},
+			{"name": "feed-download", "path": "/feed/download", "limit": 0},
+			{"method": "get", "path": "/tribes", "limit": 100}
+		]`
+
+		policies, err := LoadRateLimitPolicies(raw)
+		assert.NoError(t, err)
+		assert.Len(t, policies, len(DefaultRateLimitPolicies))
+
+		byName := map[string]RateLimitPolicy{}
+		for _, p := range policies {
+			byName[p.Name] = p
+		}
+
+		assert.Equal(t, int64(5), byName["hivechat-send"].Limit)
+		assert.Equal(t, 10*time.Second, byName["hivechat-send"].Window())
+		assert.NotContains(t, byName, "feed-download")
+		assert.Equal(t, 60, byName["GET /tribes"].WindowSeconds)
+	})
+
+	t.Run("should reject invalid policies", func(t *testing.T) {
+		_, err := LoadRateLimitPolicies(`{`)
+		assert.Error(t, err)
+
+		_, err = LoadRateLimitPolicies(`[{"name": "no-path", "limit": 1}]`)
+		assert.Error(t, err)
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := config.TrustedProxies
+	defer func() { config.TrustedProxies = proxies }()
+	config.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+
+	request := func(remoteAddr string, forwarded string, realIP string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/search", nil)
+		req.RemoteAddr = remoteAddr
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		if realIP != "" {
+			req.Header.Set("X-Real-IP", realIP)
+		}
+		return req
+	}
+
+	t.Run("ignores forwarding headers from untrusted callers", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.9", clientIP(request("203.0.113.9:4000", "198.51.100.1", "198.51.100.2")))
+	})
+
+	t.Run("takes the last untrusted hop behind a trusted proxy", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.7", clientIP(request("10.1.2.3:4000", "1.2.3.4, 198.51.100.7, 10.0.0.5", "")))
+		assert.Equal(t, "198.51.100.2", clientIP(request("192.168.1.1:4000", "", "198.51.100.2")))
+		assert.Equal(t, "10.1.2.3", clientIP(request("10.1.2.3:4000", "", "")))
+	})
+}
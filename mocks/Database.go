@@ -18423,4 +18423,4158 @@ func (_c *Database_DeleteBountyStakeProcess_Call) Return(_a0 error) *Database_De
 func (_c *Database_DeleteBountyStakeProcess_Call) RunAndReturn(run func(uuid.UUID) error) *Database_DeleteBountyStakeProcess_Call {
 	_c.Call.Return(run)
 	return _c
-}
\ No newline at end of file
+}
+func (_m *Database) CreateOwnershipTransfer(transfer *db.WorkspaceOwnershipTransfer) (*db.WorkspaceOwnershipTransfer, error) {
+	ret := _m.Called(transfer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOwnershipTransfer")
+	}
+
+	var r0 *db.WorkspaceOwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*db.WorkspaceOwnershipTransfer) (*db.WorkspaceOwnershipTransfer, error)); ok {
+		return rf(transfer)
+	}
+	if rf, ok := ret.Get(0).(func(*db.WorkspaceOwnershipTransfer) *db.WorkspaceOwnershipTransfer); ok {
+		r0 = rf(transfer)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WorkspaceOwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*db.WorkspaceOwnershipTransfer) error); ok {
+		r1 = rf(transfer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CreateOwnershipTransfer_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CreateOwnershipTransfer(transfer interface{}) *Database_CreateOwnershipTransfer_Call {
+	return &Database_CreateOwnershipTransfer_Call{Call: _e.mock.On("CreateOwnershipTransfer", transfer)}
+}
+
+func (_c *Database_CreateOwnershipTransfer_Call) Run(run func(transfer *db.WorkspaceOwnershipTransfer)) *Database_CreateOwnershipTransfer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*db.WorkspaceOwnershipTransfer))
+	})
+	return _c
+}
+
+func (_c *Database_CreateOwnershipTransfer_Call) Return(_a0 *db.WorkspaceOwnershipTransfer, _a1 error) *Database_CreateOwnershipTransfer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateOwnershipTransfer_Call) RunAndReturn(run func(*db.WorkspaceOwnershipTransfer) (*db.WorkspaceOwnershipTransfer, error)) *Database_CreateOwnershipTransfer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetOwnershipTransferByID(id uuid.UUID) (*db.WorkspaceOwnershipTransfer, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOwnershipTransferByID")
+	}
+
+	var r0 *db.WorkspaceOwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*db.WorkspaceOwnershipTransfer, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *db.WorkspaceOwnershipTransfer); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WorkspaceOwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetOwnershipTransferByID_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetOwnershipTransferByID(id interface{}) *Database_GetOwnershipTransferByID_Call {
+	return &Database_GetOwnershipTransferByID_Call{Call: _e.mock.On("GetOwnershipTransferByID", id)}
+}
+
+func (_c *Database_GetOwnershipTransferByID_Call) Run(run func(id uuid.UUID)) *Database_GetOwnershipTransferByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetOwnershipTransferByID_Call) Return(_a0 *db.WorkspaceOwnershipTransfer, _a1 error) *Database_GetOwnershipTransferByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetOwnershipTransferByID_Call) RunAndReturn(run func(uuid.UUID) (*db.WorkspaceOwnershipTransfer, error)) *Database_GetOwnershipTransferByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetOwnershipTransfersByWorkspace(workspaceUuid string) ([]db.WorkspaceOwnershipTransfer, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOwnershipTransfersByWorkspace")
+	}
+
+	var r0 []db.WorkspaceOwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.WorkspaceOwnershipTransfer, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.WorkspaceOwnershipTransfer); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WorkspaceOwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetOwnershipTransfersByWorkspace_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetOwnershipTransfersByWorkspace(workspaceUuid interface{}) *Database_GetOwnershipTransfersByWorkspace_Call {
+	return &Database_GetOwnershipTransfersByWorkspace_Call{Call: _e.mock.On("GetOwnershipTransfersByWorkspace", workspaceUuid)}
+}
+
+func (_c *Database_GetOwnershipTransfersByWorkspace_Call) Run(run func(workspaceUuid string)) *Database_GetOwnershipTransfersByWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetOwnershipTransfersByWorkspace_Call) Return(_a0 []db.WorkspaceOwnershipTransfer, _a1 error) *Database_GetOwnershipTransfersByWorkspace_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetOwnershipTransfersByWorkspace_Call) RunAndReturn(run func(string) ([]db.WorkspaceOwnershipTransfer, error)) *Database_GetOwnershipTransfersByWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetPendingOwnershipTransfersForPubkey(pubkey string) ([]db.WorkspaceOwnershipTransfer, error) {
+	ret := _m.Called(pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingOwnershipTransfersForPubkey")
+	}
+
+	var r0 []db.WorkspaceOwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.WorkspaceOwnershipTransfer, error)); ok {
+		return rf(pubkey)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.WorkspaceOwnershipTransfer); ok {
+		r0 = rf(pubkey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WorkspaceOwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(pubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetPendingOwnershipTransfersForPubkey_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetPendingOwnershipTransfersForPubkey(pubkey interface{}) *Database_GetPendingOwnershipTransfersForPubkey_Call {
+	return &Database_GetPendingOwnershipTransfersForPubkey_Call{Call: _e.mock.On("GetPendingOwnershipTransfersForPubkey", pubkey)}
+}
+
+func (_c *Database_GetPendingOwnershipTransfersForPubkey_Call) Run(run func(pubkey string)) *Database_GetPendingOwnershipTransfersForPubkey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetPendingOwnershipTransfersForPubkey_Call) Return(_a0 []db.WorkspaceOwnershipTransfer, _a1 error) *Database_GetPendingOwnershipTransfersForPubkey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetPendingOwnershipTransfersForPubkey_Call) RunAndReturn(run func(string) ([]db.WorkspaceOwnershipTransfer, error)) *Database_GetPendingOwnershipTransfersForPubkey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) AcceptOwnershipTransfer(id uuid.UUID) (db.Workspace, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptOwnershipTransfer")
+	}
+
+	var r0 db.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.Workspace, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.Workspace); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.Workspace)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_AcceptOwnershipTransfer_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) AcceptOwnershipTransfer(id interface{}) *Database_AcceptOwnershipTransfer_Call {
+	return &Database_AcceptOwnershipTransfer_Call{Call: _e.mock.On("AcceptOwnershipTransfer", id)}
+}
+
+func (_c *Database_AcceptOwnershipTransfer_Call) Run(run func(id uuid.UUID)) *Database_AcceptOwnershipTransfer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_AcceptOwnershipTransfer_Call) Return(_a0 db.Workspace, _a1 error) *Database_AcceptOwnershipTransfer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_AcceptOwnershipTransfer_Call) RunAndReturn(run func(uuid.UUID) (db.Workspace, error)) *Database_AcceptOwnershipTransfer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CloseOwnershipTransfer(id uuid.UUID, actorPubkey string, status db.OwnershipTransferStatus) (*db.WorkspaceOwnershipTransfer, error) {
+	ret := _m.Called(id, actorPubkey, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloseOwnershipTransfer")
+	}
+
+	var r0 *db.WorkspaceOwnershipTransfer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, db.OwnershipTransferStatus) (*db.WorkspaceOwnershipTransfer, error)); ok {
+		return rf(id, actorPubkey, status)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, db.OwnershipTransferStatus) *db.WorkspaceOwnershipTransfer); ok {
+		r0 = rf(id, actorPubkey, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WorkspaceOwnershipTransfer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string, db.OwnershipTransferStatus) error); ok {
+		r1 = rf(id, actorPubkey, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CloseOwnershipTransfer_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CloseOwnershipTransfer(id interface{}, actorPubkey interface{}, status interface{}) *Database_CloseOwnershipTransfer_Call {
+	return &Database_CloseOwnershipTransfer_Call{Call: _e.mock.On("CloseOwnershipTransfer", id, actorPubkey, status)}
+}
+
+func (_c *Database_CloseOwnershipTransfer_Call) Run(run func(id uuid.UUID, actorPubkey string, status db.OwnershipTransferStatus)) *Database_CloseOwnershipTransfer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string), args[2].(db.OwnershipTransferStatus))
+	})
+	return _c
+}
+
+func (_c *Database_CloseOwnershipTransfer_Call) Return(_a0 *db.WorkspaceOwnershipTransfer, _a1 error) *Database_CloseOwnershipTransfer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CloseOwnershipTransfer_Call) RunAndReturn(run func(uuid.UUID, string, db.OwnershipTransferStatus) (*db.WorkspaceOwnershipTransfer, error)) *Database_CloseOwnershipTransfer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) AddWorkspaceCoOwner(workspaceUuid string, pubkey string, actorPubkey string) (db.Workspace, error) {
+	ret := _m.Called(workspaceUuid, pubkey, actorPubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddWorkspaceCoOwner")
+	}
+
+	var r0 db.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (db.Workspace, error)); ok {
+		return rf(workspaceUuid, pubkey, actorPubkey)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) db.Workspace); ok {
+		r0 = rf(workspaceUuid, pubkey, actorPubkey)
+	} else {
+		r0 = ret.Get(0).(db.Workspace)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(workspaceUuid, pubkey, actorPubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_AddWorkspaceCoOwner_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) AddWorkspaceCoOwner(workspaceUuid interface{}, pubkey interface{}, actorPubkey interface{}) *Database_AddWorkspaceCoOwner_Call {
+	return &Database_AddWorkspaceCoOwner_Call{Call: _e.mock.On("AddWorkspaceCoOwner", workspaceUuid, pubkey, actorPubkey)}
+}
+
+func (_c *Database_AddWorkspaceCoOwner_Call) Run(run func(workspaceUuid string, pubkey string, actorPubkey string)) *Database_AddWorkspaceCoOwner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_AddWorkspaceCoOwner_Call) Return(_a0 db.Workspace, _a1 error) *Database_AddWorkspaceCoOwner_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_AddWorkspaceCoOwner_Call) RunAndReturn(run func(string, string, string) (db.Workspace, error)) *Database_AddWorkspaceCoOwner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) RemoveWorkspaceCoOwner(workspaceUuid string, pubkey string, actorPubkey string) (db.Workspace, error) {
+	ret := _m.Called(workspaceUuid, pubkey, actorPubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveWorkspaceCoOwner")
+	}
+
+	var r0 db.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (db.Workspace, error)); ok {
+		return rf(workspaceUuid, pubkey, actorPubkey)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) db.Workspace); ok {
+		r0 = rf(workspaceUuid, pubkey, actorPubkey)
+	} else {
+		r0 = ret.Get(0).(db.Workspace)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(workspaceUuid, pubkey, actorPubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_RemoveWorkspaceCoOwner_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) RemoveWorkspaceCoOwner(workspaceUuid interface{}, pubkey interface{}, actorPubkey interface{}) *Database_RemoveWorkspaceCoOwner_Call {
+	return &Database_RemoveWorkspaceCoOwner_Call{Call: _e.mock.On("RemoveWorkspaceCoOwner", workspaceUuid, pubkey, actorPubkey)}
+}
+
+func (_c *Database_RemoveWorkspaceCoOwner_Call) Run(run func(workspaceUuid string, pubkey string, actorPubkey string)) *Database_RemoveWorkspaceCoOwner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_RemoveWorkspaceCoOwner_Call) Return(_a0 db.Workspace, _a1 error) *Database_RemoveWorkspaceCoOwner_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_RemoveWorkspaceCoOwner_Call) RunAndReturn(run func(string, string, string) (db.Workspace, error)) *Database_RemoveWorkspaceCoOwner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWorkspaceOwnershipAudit(workspaceUuid string) ([]db.WorkspaceOwnershipAudit, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceOwnershipAudit")
+	}
+
+	var r0 []db.WorkspaceOwnershipAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.WorkspaceOwnershipAudit, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.WorkspaceOwnershipAudit); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WorkspaceOwnershipAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetWorkspaceOwnershipAudit_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWorkspaceOwnershipAudit(workspaceUuid interface{}) *Database_GetWorkspaceOwnershipAudit_Call {
+	return &Database_GetWorkspaceOwnershipAudit_Call{Call: _e.mock.On("GetWorkspaceOwnershipAudit", workspaceUuid)}
+}
+
+func (_c *Database_GetWorkspaceOwnershipAudit_Call) Run(run func(workspaceUuid string)) *Database_GetWorkspaceOwnershipAudit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceOwnershipAudit_Call) Return(_a0 []db.WorkspaceOwnershipAudit, _a1 error) *Database_GetWorkspaceOwnershipAudit_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceOwnershipAudit_Call) RunAndReturn(run func(string) ([]db.WorkspaceOwnershipAudit, error)) *Database_GetWorkspaceOwnershipAudit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) TrashWorkspace(workspaceUuid string, deletedBy string) error {
+	ret := _m.Called(workspaceUuid, deletedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrashWorkspace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(workspaceUuid, deletedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_TrashWorkspace_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) TrashWorkspace(workspaceUuid interface{}, deletedBy interface{}) *Database_TrashWorkspace_Call {
+	return &Database_TrashWorkspace_Call{Call: _e.mock.On("TrashWorkspace", workspaceUuid, deletedBy)}
+}
+
+func (_c *Database_TrashWorkspace_Call) Run(run func(workspaceUuid string, deletedBy string)) *Database_TrashWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_TrashWorkspace_Call) Return(_a0 error) *Database_TrashWorkspace_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_TrashWorkspace_Call) RunAndReturn(run func(string, string) error) *Database_TrashWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) TrashFeature(featureUuid string, deletedBy string) error {
+	ret := _m.Called(featureUuid, deletedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrashFeature")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(featureUuid, deletedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_TrashFeature_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) TrashFeature(featureUuid interface{}, deletedBy interface{}) *Database_TrashFeature_Call {
+	return &Database_TrashFeature_Call{Call: _e.mock.On("TrashFeature", featureUuid, deletedBy)}
+}
+
+func (_c *Database_TrashFeature_Call) Run(run func(featureUuid string, deletedBy string)) *Database_TrashFeature_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_TrashFeature_Call) Return(_a0 error) *Database_TrashFeature_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_TrashFeature_Call) RunAndReturn(run func(string, string) error) *Database_TrashFeature_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) TrashFeaturePhase(featureUuid string, phaseUuid string, deletedBy string) error {
+	ret := _m.Called(featureUuid, phaseUuid, deletedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrashFeaturePhase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(featureUuid, phaseUuid, deletedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_TrashFeaturePhase_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) TrashFeaturePhase(featureUuid interface{}, phaseUuid interface{}, deletedBy interface{}) *Database_TrashFeaturePhase_Call {
+	return &Database_TrashFeaturePhase_Call{Call: _e.mock.On("TrashFeaturePhase", featureUuid, phaseUuid, deletedBy)}
+}
+
+func (_c *Database_TrashFeaturePhase_Call) Run(run func(featureUuid string, phaseUuid string, deletedBy string)) *Database_TrashFeaturePhase_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_TrashFeaturePhase_Call) Return(_a0 error) *Database_TrashFeaturePhase_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_TrashFeaturePhase_Call) RunAndReturn(run func(string, string, string) error) *Database_TrashFeaturePhase_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) TrashTicketGroup(ticketGroupUUID uuid.UUID, deletedBy string) error {
+	ret := _m.Called(ticketGroupUUID, deletedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrashTicketGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(ticketGroupUUID, deletedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_TrashTicketGroup_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) TrashTicketGroup(ticketGroupUUID interface{}, deletedBy interface{}) *Database_TrashTicketGroup_Call {
+	return &Database_TrashTicketGroup_Call{Call: _e.mock.On("TrashTicketGroup", ticketGroupUUID, deletedBy)}
+}
+
+func (_c *Database_TrashTicketGroup_Call) Run(run func(ticketGroupUUID uuid.UUID, deletedBy string)) *Database_TrashTicketGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_TrashTicketGroup_Call) Return(_a0 error) *Database_TrashTicketGroup_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_TrashTicketGroup_Call) RunAndReturn(run func(uuid.UUID, string) error) *Database_TrashTicketGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) TrashBounty(pubkey string, created string, deletedBy string) (db.NewBounty, error) {
+	ret := _m.Called(pubkey, created, deletedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrashBounty")
+	}
+
+	var r0 db.NewBounty
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (db.NewBounty, error)); ok {
+		return rf(pubkey, created, deletedBy)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) db.NewBounty); ok {
+		r0 = rf(pubkey, created, deletedBy)
+	} else {
+		r0 = ret.Get(0).(db.NewBounty)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(pubkey, created, deletedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_TrashBounty_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) TrashBounty(pubkey interface{}, created interface{}, deletedBy interface{}) *Database_TrashBounty_Call {
+	return &Database_TrashBounty_Call{Call: _e.mock.On("TrashBounty", pubkey, created, deletedBy)}
+}
+
+func (_c *Database_TrashBounty_Call) Run(run func(pubkey string, created string, deletedBy string)) *Database_TrashBounty_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_TrashBounty_Call) Return(_a0 db.NewBounty, _a1 error) *Database_TrashBounty_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_TrashBounty_Call) RunAndReturn(run func(string, string, string) (db.NewBounty, error)) *Database_TrashBounty_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTrashByWorkspace(workspaceUuid string) ([]db.TrashItem, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrashByWorkspace")
+	}
+
+	var r0 []db.TrashItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.TrashItem, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.TrashItem); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TrashItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTrashByWorkspace_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTrashByWorkspace(workspaceUuid interface{}) *Database_GetTrashByWorkspace_Call {
+	return &Database_GetTrashByWorkspace_Call{Call: _e.mock.On("GetTrashByWorkspace", workspaceUuid)}
+}
+
+func (_c *Database_GetTrashByWorkspace_Call) Run(run func(workspaceUuid string)) *Database_GetTrashByWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTrashByWorkspace_Call) Return(_a0 []db.TrashItem, _a1 error) *Database_GetTrashByWorkspace_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTrashByWorkspace_Call) RunAndReturn(run func(string) ([]db.TrashItem, error)) *Database_GetTrashByWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTrashItemByID(id uuid.UUID) (*db.TrashItem, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrashItemByID")
+	}
+
+	var r0 *db.TrashItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*db.TrashItem, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *db.TrashItem); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.TrashItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTrashItemByID_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTrashItemByID(id interface{}) *Database_GetTrashItemByID_Call {
+	return &Database_GetTrashItemByID_Call{Call: _e.mock.On("GetTrashItemByID", id)}
+}
+
+func (_c *Database_GetTrashItemByID_Call) Run(run func(id uuid.UUID)) *Database_GetTrashItemByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetTrashItemByID_Call) Return(_a0 *db.TrashItem, _a1 error) *Database_GetTrashItemByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTrashItemByID_Call) RunAndReturn(run func(uuid.UUID) (*db.TrashItem, error)) *Database_GetTrashItemByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) RestoreTrashItem(id uuid.UUID) (*db.TrashItem, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreTrashItem")
+	}
+
+	var r0 *db.TrashItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*db.TrashItem, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *db.TrashItem); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.TrashItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_RestoreTrashItem_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) RestoreTrashItem(id interface{}) *Database_RestoreTrashItem_Call {
+	return &Database_RestoreTrashItem_Call{Call: _e.mock.On("RestoreTrashItem", id)}
+}
+
+func (_c *Database_RestoreTrashItem_Call) Run(run func(id uuid.UUID)) *Database_RestoreTrashItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_RestoreTrashItem_Call) Return(_a0 *db.TrashItem, _a1 error) *Database_RestoreTrashItem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_RestoreTrashItem_Call) RunAndReturn(run func(uuid.UUID) (*db.TrashItem, error)) *Database_RestoreTrashItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) PurgeExpiredTrash() (int64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeExpiredTrash")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_PurgeExpiredTrash_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) PurgeExpiredTrash() *Database_PurgeExpiredTrash_Call {
+	return &Database_PurgeExpiredTrash_Call{Call: _e.mock.On("PurgeExpiredTrash")}
+}
+
+func (_c *Database_PurgeExpiredTrash_Call) Run(run func()) *Database_PurgeExpiredTrash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Database_PurgeExpiredTrash_Call) Return(_a0 int64, _a1 error) *Database_PurgeExpiredTrash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_PurgeExpiredTrash_Call) RunAndReturn(run func() (int64, error)) *Database_PurgeExpiredTrash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ExportWorkspace(workspaceUuid string) (db.WorkspaceArchive, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportWorkspace")
+	}
+
+	var r0 db.WorkspaceArchive
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.WorkspaceArchive, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.WorkspaceArchive); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceArchive)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_ExportWorkspace_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ExportWorkspace(workspaceUuid interface{}) *Database_ExportWorkspace_Call {
+	return &Database_ExportWorkspace_Call{Call: _e.mock.On("ExportWorkspace", workspaceUuid)}
+}
+
+func (_c *Database_ExportWorkspace_Call) Run(run func(workspaceUuid string)) *Database_ExportWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_ExportWorkspace_Call) Return(_a0 db.WorkspaceArchive, _a1 error) *Database_ExportWorkspace_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ExportWorkspace_Call) RunAndReturn(run func(string) (db.WorkspaceArchive, error)) *Database_ExportWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ImportWorkspace(archive db.WorkspaceArchive, options db.WorkspaceImportOptions) (db.WorkspaceArchive, []db.ImportConflict, error) {
+	ret := _m.Called(archive, options)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportWorkspace")
+	}
+
+	var r0 db.WorkspaceArchive
+	var r1 []db.ImportConflict
+	var r2 error
+	if rf, ok := ret.Get(0).(func(db.WorkspaceArchive, db.WorkspaceImportOptions) (db.WorkspaceArchive, []db.ImportConflict, error)); ok {
+		return rf(archive, options)
+	}
+	if rf, ok := ret.Get(0).(func(db.WorkspaceArchive, db.WorkspaceImportOptions) db.WorkspaceArchive); ok {
+		r0 = rf(archive, options)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceArchive)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.WorkspaceArchive, db.WorkspaceImportOptions) []db.ImportConflict); ok {
+		r1 = rf(archive, options)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]db.ImportConflict)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(db.WorkspaceArchive, db.WorkspaceImportOptions) error); ok {
+		r2 = rf(archive, options)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type Database_ImportWorkspace_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ImportWorkspace(archive interface{}, options interface{}) *Database_ImportWorkspace_Call {
+	return &Database_ImportWorkspace_Call{Call: _e.mock.On("ImportWorkspace", archive, options)}
+}
+
+func (_c *Database_ImportWorkspace_Call) Run(run func(archive db.WorkspaceArchive, options db.WorkspaceImportOptions)) *Database_ImportWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.WorkspaceArchive), args[1].(db.WorkspaceImportOptions))
+	})
+	return _c
+}
+
+func (_c *Database_ImportWorkspace_Call) Return(_a0 db.WorkspaceArchive, _a1 []db.ImportConflict, _a2 error) *Database_ImportWorkspace_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_ImportWorkspace_Call) RunAndReturn(run func(db.WorkspaceArchive, db.WorkspaceImportOptions) (db.WorkspaceArchive, []db.ImportConflict, error)) *Database_ImportWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTicketVersions(ticketGroup uuid.UUID) ([]db.TicketVersion, error) {
+	ret := _m.Called(ticketGroup)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketVersions")
+	}
+
+	var r0 []db.TicketVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]db.TicketVersion, error)); ok {
+		return rf(ticketGroup)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []db.TicketVersion); ok {
+		r0 = rf(ticketGroup)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(ticketGroup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTicketVersions_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTicketVersions(ticketGroup interface{}) *Database_GetTicketVersions_Call {
+	return &Database_GetTicketVersions_Call{Call: _e.mock.On("GetTicketVersions", ticketGroup)}
+}
+
+func (_c *Database_GetTicketVersions_Call) Run(run func(ticketGroup uuid.UUID)) *Database_GetTicketVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketVersions_Call) Return(_a0 []db.TicketVersion, _a1 error) *Database_GetTicketVersions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketVersions_Call) RunAndReturn(run func(uuid.UUID) ([]db.TicketVersion, error)) *Database_GetTicketVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTicketVersion(ticketGroup uuid.UUID, version int) (db.TicketVersion, error) {
+	ret := _m.Called(ticketGroup, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketVersion")
+	}
+
+	var r0 db.TicketVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) (db.TicketVersion, error)); ok {
+		return rf(ticketGroup, version)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) db.TicketVersion); ok {
+		r0 = rf(ticketGroup, version)
+	} else {
+		r0 = ret.Get(0).(db.TicketVersion)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(ticketGroup, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTicketVersion_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTicketVersion(ticketGroup interface{}, version interface{}) *Database_GetTicketVersion_Call {
+	return &Database_GetTicketVersion_Call{Call: _e.mock.On("GetTicketVersion", ticketGroup, version)}
+}
+
+func (_c *Database_GetTicketVersion_Call) Run(run func(ticketGroup uuid.UUID, version int)) *Database_GetTicketVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketVersion_Call) Return(_a0 db.TicketVersion, _a1 error) *Database_GetTicketVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketVersion_Call) RunAndReturn(run func(uuid.UUID, int) (db.TicketVersion, error)) *Database_GetTicketVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) RevertTicketVersion(ticketGroup uuid.UUID, version int, author db.Author, authorID string) (db.Tickets, error) {
+	ret := _m.Called(ticketGroup, version, author, authorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertTicketVersion")
+	}
+
+	var r0 db.Tickets
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, db.Author, string) (db.Tickets, error)); ok {
+		return rf(ticketGroup, version, author, authorID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, db.Author, string) db.Tickets); ok {
+		r0 = rf(ticketGroup, version, author, authorID)
+	} else {
+		r0 = ret.Get(0).(db.Tickets)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int, db.Author, string) error); ok {
+		r1 = rf(ticketGroup, version, author, authorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_RevertTicketVersion_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) RevertTicketVersion(ticketGroup interface{}, version interface{}, author interface{}, authorID interface{}) *Database_RevertTicketVersion_Call {
+	return &Database_RevertTicketVersion_Call{Call: _e.mock.On("RevertTicketVersion", ticketGroup, version, author, authorID)}
+}
+
+func (_c *Database_RevertTicketVersion_Call) Run(run func(ticketGroup uuid.UUID, version int, author db.Author, authorID string)) *Database_RevertTicketVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int), args[2].(db.Author), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_RevertTicketVersion_Call) Return(_a0 db.Tickets, _a1 error) *Database_RevertTicketVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_RevertTicketVersion_Call) RunAndReturn(run func(uuid.UUID, int, db.Author, string) (db.Tickets, error)) *Database_RevertTicketVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetPhaseTicketGraph(featureUUID string, phaseUUID string) (db.PhaseTicketGraph, error) {
+	ret := _m.Called(featureUUID, phaseUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPhaseTicketGraph")
+	}
+
+	var r0 db.PhaseTicketGraph
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (db.PhaseTicketGraph, error)); ok {
+		return rf(featureUUID, phaseUUID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) db.PhaseTicketGraph); ok {
+		r0 = rf(featureUUID, phaseUUID)
+	} else {
+		r0 = ret.Get(0).(db.PhaseTicketGraph)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(featureUUID, phaseUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetPhaseTicketGraph_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetPhaseTicketGraph(featureUUID interface{}, phaseUUID interface{}) *Database_GetPhaseTicketGraph_Call {
+	return &Database_GetPhaseTicketGraph_Call{Call: _e.mock.On("GetPhaseTicketGraph", featureUUID, phaseUUID)}
+}
+
+func (_c *Database_GetPhaseTicketGraph_Call) Run(run func(featureUUID string, phaseUUID string)) *Database_GetPhaseTicketGraph_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetPhaseTicketGraph_Call) Return(_a0 db.PhaseTicketGraph, _a1 error) *Database_GetPhaseTicketGraph_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetPhaseTicketGraph_Call) RunAndReturn(run func(string, string) (db.PhaseTicketGraph, error)) *Database_GetPhaseTicketGraph_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CheckTicketSequence(featureUUID string, phaseUUID string, group uuid.UUID, sequence int) error {
+	ret := _m.Called(featureUUID, phaseUUID, group, sequence)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckTicketSequence")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, uuid.UUID, int) error); ok {
+		r0 = rf(featureUUID, phaseUUID, group, sequence)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_CheckTicketSequence_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CheckTicketSequence(featureUUID interface{}, phaseUUID interface{}, group interface{}, sequence interface{}) *Database_CheckTicketSequence_Call {
+	return &Database_CheckTicketSequence_Call{Call: _e.mock.On("CheckTicketSequence", featureUUID, phaseUUID, group, sequence)}
+}
+
+func (_c *Database_CheckTicketSequence_Call) Run(run func(featureUUID string, phaseUUID string, group uuid.UUID, sequence int)) *Database_CheckTicketSequence_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(uuid.UUID), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Database_CheckTicketSequence_Call) Return(_a0 error) *Database_CheckTicketSequence_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_CheckTicketSequence_Call) RunAndReturn(run func(string, string, uuid.UUID, int) error) *Database_CheckTicketSequence_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTicketWorkflowRules(workspaceUuid string) ([]db.TicketWorkflowRule, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketWorkflowRules")
+	}
+
+	var r0 []db.TicketWorkflowRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.TicketWorkflowRule, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.TicketWorkflowRule); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketWorkflowRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTicketWorkflowRules_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTicketWorkflowRules(workspaceUuid interface{}) *Database_GetTicketWorkflowRules_Call {
+	return &Database_GetTicketWorkflowRules_Call{Call: _e.mock.On("GetTicketWorkflowRules", workspaceUuid)}
+}
+
+func (_c *Database_GetTicketWorkflowRules_Call) Run(run func(workspaceUuid string)) *Database_GetTicketWorkflowRules_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketWorkflowRules_Call) Return(_a0 []db.TicketWorkflowRule, _a1 error) *Database_GetTicketWorkflowRules_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketWorkflowRules_Call) RunAndReturn(run func(string) ([]db.TicketWorkflowRule, error)) *Database_GetTicketWorkflowRules_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SetTicketWorkflowRules(workspaceUuid string, rules []db.TicketWorkflowRule) ([]db.TicketWorkflowRule, error) {
+	ret := _m.Called(workspaceUuid, rules)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTicketWorkflowRules")
+	}
+
+	var r0 []db.TicketWorkflowRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []db.TicketWorkflowRule) ([]db.TicketWorkflowRule, error)); ok {
+		return rf(workspaceUuid, rules)
+	}
+	if rf, ok := ret.Get(0).(func(string, []db.TicketWorkflowRule) []db.TicketWorkflowRule); ok {
+		r0 = rf(workspaceUuid, rules)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketWorkflowRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []db.TicketWorkflowRule) error); ok {
+		r1 = rf(workspaceUuid, rules)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SetTicketWorkflowRules_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SetTicketWorkflowRules(workspaceUuid interface{}, rules interface{}) *Database_SetTicketWorkflowRules_Call {
+	return &Database_SetTicketWorkflowRules_Call{Call: _e.mock.On("SetTicketWorkflowRules", workspaceUuid, rules)}
+}
+
+func (_c *Database_SetTicketWorkflowRules_Call) Run(run func(workspaceUuid string, rules []db.TicketWorkflowRule)) *Database_SetTicketWorkflowRules_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]db.TicketWorkflowRule))
+	})
+	return _c
+}
+
+func (_c *Database_SetTicketWorkflowRules_Call) Return(_a0 []db.TicketWorkflowRule, _a1 error) *Database_SetTicketWorkflowRules_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetTicketWorkflowRules_Call) RunAndReturn(run func(string, []db.TicketWorkflowRule) ([]db.TicketWorkflowRule, error)) *Database_SetTicketWorkflowRules_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CheckTicketTransition(pubkey string, from db.TicketStatus, ticket db.Tickets) (*db.TicketWorkflowRule, error) {
+	ret := _m.Called(pubkey, from, ticket)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckTicketTransition")
+	}
+
+	var r0 *db.TicketWorkflowRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.TicketStatus, db.Tickets) (*db.TicketWorkflowRule, error)); ok {
+		return rf(pubkey, from, ticket)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.TicketStatus, db.Tickets) *db.TicketWorkflowRule); ok {
+		r0 = rf(pubkey, from, ticket)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.TicketWorkflowRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.TicketStatus, db.Tickets) error); ok {
+		r1 = rf(pubkey, from, ticket)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CheckTicketTransition_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CheckTicketTransition(pubkey interface{}, from interface{}, ticket interface{}) *Database_CheckTicketTransition_Call {
+	return &Database_CheckTicketTransition_Call{Call: _e.mock.On("CheckTicketTransition", pubkey, from, ticket)}
+}
+
+func (_c *Database_CheckTicketTransition_Call) Run(run func(pubkey string, from db.TicketStatus, ticket db.Tickets)) *Database_CheckTicketTransition_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.TicketStatus), args[2].(db.Tickets))
+	})
+	return _c
+}
+
+func (_c *Database_CheckTicketTransition_Call) Return(_a0 *db.TicketWorkflowRule, _a1 error) *Database_CheckTicketTransition_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CheckTicketTransition_Call) RunAndReturn(run func(string, db.TicketStatus, db.Tickets) (*db.TicketWorkflowRule, error)) *Database_CheckTicketTransition_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWorkspaceBoard(workspaceUuid string, filter db.BoardFilter) (db.Board, error) {
+	ret := _m.Called(workspaceUuid, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceBoard")
+	}
+
+	var r0 db.Board
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.BoardFilter) (db.Board, error)); ok {
+		return rf(workspaceUuid, filter)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.BoardFilter) db.Board); ok {
+		r0 = rf(workspaceUuid, filter)
+	} else {
+		r0 = ret.Get(0).(db.Board)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.BoardFilter) error); ok {
+		r1 = rf(workspaceUuid, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetWorkspaceBoard_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWorkspaceBoard(workspaceUuid interface{}, filter interface{}) *Database_GetWorkspaceBoard_Call {
+	return &Database_GetWorkspaceBoard_Call{Call: _e.mock.On("GetWorkspaceBoard", workspaceUuid, filter)}
+}
+
+func (_c *Database_GetWorkspaceBoard_Call) Run(run func(workspaceUuid string, filter db.BoardFilter)) *Database_GetWorkspaceBoard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.BoardFilter))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceBoard_Call) Return(_a0 db.Board, _a1 error) *Database_GetWorkspaceBoard_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceBoard_Call) RunAndReturn(run func(string, db.BoardFilter) (db.Board, error)) *Database_GetWorkspaceBoard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SetBoardColumnLimits(workspaceUuid string, limits []db.BoardColumnConfig) ([]db.BoardColumnConfig, error) {
+	ret := _m.Called(workspaceUuid, limits)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBoardColumnLimits")
+	}
+
+	var r0 []db.BoardColumnConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []db.BoardColumnConfig) ([]db.BoardColumnConfig, error)); ok {
+		return rf(workspaceUuid, limits)
+	}
+	if rf, ok := ret.Get(0).(func(string, []db.BoardColumnConfig) []db.BoardColumnConfig); ok {
+		r0 = rf(workspaceUuid, limits)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.BoardColumnConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []db.BoardColumnConfig) error); ok {
+		r1 = rf(workspaceUuid, limits)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SetBoardColumnLimits_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SetBoardColumnLimits(workspaceUuid interface{}, limits interface{}) *Database_SetBoardColumnLimits_Call {
+	return &Database_SetBoardColumnLimits_Call{Call: _e.mock.On("SetBoardColumnLimits", workspaceUuid, limits)}
+}
+
+func (_c *Database_SetBoardColumnLimits_Call) Run(run func(workspaceUuid string, limits []db.BoardColumnConfig)) *Database_SetBoardColumnLimits_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]db.BoardColumnConfig))
+	})
+	return _c
+}
+
+func (_c *Database_SetBoardColumnLimits_Call) Return(_a0 []db.BoardColumnConfig, _a1 error) *Database_SetBoardColumnLimits_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetBoardColumnLimits_Call) RunAndReturn(run func(string, []db.BoardColumnConfig) ([]db.BoardColumnConfig, error)) *Database_SetBoardColumnLimits_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) MoveBoardCard(workspaceUuid string, pubkey string, move db.BoardMove) (db.BoardMoveResult, error) {
+	ret := _m.Called(workspaceUuid, pubkey, move)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MoveBoardCard")
+	}
+
+	var r0 db.BoardMoveResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, db.BoardMove) (db.BoardMoveResult, error)); ok {
+		return rf(workspaceUuid, pubkey, move)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, db.BoardMove) db.BoardMoveResult); ok {
+		r0 = rf(workspaceUuid, pubkey, move)
+	} else {
+		r0 = ret.Get(0).(db.BoardMoveResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, db.BoardMove) error); ok {
+		r1 = rf(workspaceUuid, pubkey, move)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_MoveBoardCard_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) MoveBoardCard(workspaceUuid interface{}, pubkey interface{}, move interface{}) *Database_MoveBoardCard_Call {
+	return &Database_MoveBoardCard_Call{Call: _e.mock.On("MoveBoardCard", workspaceUuid, pubkey, move)}
+}
+
+func (_c *Database_MoveBoardCard_Call) Run(run func(workspaceUuid string, pubkey string, move db.BoardMove)) *Database_MoveBoardCard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(db.BoardMove))
+	})
+	return _c
+}
+
+func (_c *Database_MoveBoardCard_Call) Return(_a0 db.BoardMoveResult, _a1 error) *Database_MoveBoardCard_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_MoveBoardCard_Call) RunAndReturn(run func(string, string, db.BoardMove) (db.BoardMoveResult, error)) *Database_MoveBoardCard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWorkspaceRoadmap(workspaceUuid string) (db.Roadmap, error) {
+	ret := _m.Called(workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceRoadmap")
+	}
+
+	var r0 db.Roadmap
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.Roadmap, error)); ok {
+		return rf(workspaceUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.Roadmap); ok {
+		r0 = rf(workspaceUuid)
+	} else {
+		r0 = ret.Get(0).(db.Roadmap)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetWorkspaceRoadmap_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWorkspaceRoadmap(workspaceUuid interface{}) *Database_GetWorkspaceRoadmap_Call {
+	return &Database_GetWorkspaceRoadmap_Call{Call: _e.mock.On("GetWorkspaceRoadmap", workspaceUuid)}
+}
+
+func (_c *Database_GetWorkspaceRoadmap_Call) Run(run func(workspaceUuid string)) *Database_GetWorkspaceRoadmap_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceRoadmap_Call) Return(_a0 db.Roadmap, _a1 error) *Database_GetWorkspaceRoadmap_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceRoadmap_Call) RunAndReturn(run func(string) (db.Roadmap, error)) *Database_GetWorkspaceRoadmap_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetAcceptanceCriterionLinks(featureUuid string, itemType db.BoardItemType, itemKey string) ([]db.AcceptanceCriterionLink, error) {
+	ret := _m.Called(featureUuid, itemType, itemKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAcceptanceCriterionLinks")
+	}
+
+	var r0 []db.AcceptanceCriterionLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.BoardItemType, string) ([]db.AcceptanceCriterionLink, error)); ok {
+		return rf(featureUuid, itemType, itemKey)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.BoardItemType, string) []db.AcceptanceCriterionLink); ok {
+		r0 = rf(featureUuid, itemType, itemKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AcceptanceCriterionLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.BoardItemType, string) error); ok {
+		r1 = rf(featureUuid, itemType, itemKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetAcceptanceCriterionLinks_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetAcceptanceCriterionLinks(featureUuid interface{}, itemType interface{}, itemKey interface{}) *Database_GetAcceptanceCriterionLinks_Call {
+	return &Database_GetAcceptanceCriterionLinks_Call{Call: _e.mock.On("GetAcceptanceCriterionLinks", featureUuid, itemType, itemKey)}
+}
+
+func (_c *Database_GetAcceptanceCriterionLinks_Call) Run(run func(featureUuid string, itemType db.BoardItemType, itemKey string)) *Database_GetAcceptanceCriterionLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.BoardItemType), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetAcceptanceCriterionLinks_Call) Return(_a0 []db.AcceptanceCriterionLink, _a1 error) *Database_GetAcceptanceCriterionLinks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetAcceptanceCriterionLinks_Call) RunAndReturn(run func(string, db.BoardItemType, string) ([]db.AcceptanceCriterionLink, error)) *Database_GetAcceptanceCriterionLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SetAcceptanceCriterionLinks(featureUuid string, itemType db.BoardItemType, itemKey string, criteria []string, pubkey string) ([]db.AcceptanceCriterionLink, error) {
+	ret := _m.Called(featureUuid, itemType, itemKey, criteria, pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAcceptanceCriterionLinks")
+	}
+
+	var r0 []db.AcceptanceCriterionLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.BoardItemType, string, []string, string) ([]db.AcceptanceCriterionLink, error)); ok {
+		return rf(featureUuid, itemType, itemKey, criteria, pubkey)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.BoardItemType, string, []string, string) []db.AcceptanceCriterionLink); ok {
+		r0 = rf(featureUuid, itemType, itemKey, criteria, pubkey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AcceptanceCriterionLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.BoardItemType, string, []string, string) error); ok {
+		r1 = rf(featureUuid, itemType, itemKey, criteria, pubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SetAcceptanceCriterionLinks_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SetAcceptanceCriterionLinks(featureUuid interface{}, itemType interface{}, itemKey interface{}, criteria interface{}, pubkey interface{}) *Database_SetAcceptanceCriterionLinks_Call {
+	return &Database_SetAcceptanceCriterionLinks_Call{Call: _e.mock.On("SetAcceptanceCriterionLinks", featureUuid, itemType, itemKey, criteria, pubkey)}
+}
+
+func (_c *Database_SetAcceptanceCriterionLinks_Call) Run(run func(featureUuid string, itemType db.BoardItemType, itemKey string, criteria []string, pubkey string)) *Database_SetAcceptanceCriterionLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.BoardItemType), args[2].(string), args[3].([]string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *Database_SetAcceptanceCriterionLinks_Call) Return(_a0 []db.AcceptanceCriterionLink, _a1 error) *Database_SetAcceptanceCriterionLinks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetAcceptanceCriterionLinks_Call) RunAndReturn(run func(string, db.BoardItemType, string, []string, string) ([]db.AcceptanceCriterionLink, error)) *Database_SetAcceptanceCriterionLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetFeatureTraceability(featureUuid string) (db.FeatureTraceability, error) {
+	ret := _m.Called(featureUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFeatureTraceability")
+	}
+
+	var r0 db.FeatureTraceability
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.FeatureTraceability, error)); ok {
+		return rf(featureUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.FeatureTraceability); ok {
+		r0 = rf(featureUuid)
+	} else {
+		r0 = ret.Get(0).(db.FeatureTraceability)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(featureUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetFeatureTraceability_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetFeatureTraceability(featureUuid interface{}) *Database_GetFeatureTraceability_Call {
+	return &Database_GetFeatureTraceability_Call{Call: _e.mock.On("GetFeatureTraceability", featureUuid)}
+}
+
+func (_c *Database_GetFeatureTraceability_Call) Run(run func(featureUuid string)) *Database_GetFeatureTraceability_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetFeatureTraceability_Call) Return(_a0 db.FeatureTraceability, _a1 error) *Database_GetFeatureTraceability_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetFeatureTraceability_Call) RunAndReturn(run func(string) (db.FeatureTraceability, error)) *Database_GetFeatureTraceability_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SubmitTicketPlan(planUUID uuid.UUID, pubkey string) (db.TicketPlan, error) {
+	ret := _m.Called(planUUID, pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubmitTicketPlan")
+	}
+
+	var r0 db.TicketPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (db.TicketPlan, error)); ok {
+		return rf(planUUID, pubkey)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) db.TicketPlan); ok {
+		r0 = rf(planUUID, pubkey)
+	} else {
+		r0 = ret.Get(0).(db.TicketPlan)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(planUUID, pubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SubmitTicketPlan_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SubmitTicketPlan(planUUID interface{}, pubkey interface{}) *Database_SubmitTicketPlan_Call {
+	return &Database_SubmitTicketPlan_Call{Call: _e.mock.On("SubmitTicketPlan", planUUID, pubkey)}
+}
+
+func (_c *Database_SubmitTicketPlan_Call) Run(run func(planUUID uuid.UUID, pubkey string)) *Database_SubmitTicketPlan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_SubmitTicketPlan_Call) Return(_a0 db.TicketPlan, _a1 error) *Database_SubmitTicketPlan_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SubmitTicketPlan_Call) RunAndReturn(run func(uuid.UUID, string) (db.TicketPlan, error)) *Database_SubmitTicketPlan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ReviewTicketPlan(planUUID uuid.UUID, reviewer string, decision db.PlanDecision, comment string) (db.TicketPlan, db.TicketPlanApproval, error) {
+	ret := _m.Called(planUUID, reviewer, decision, comment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewTicketPlan")
+	}
+
+	var r0 db.TicketPlan
+	var r1 db.TicketPlanApproval
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, db.PlanDecision, string) (db.TicketPlan, db.TicketPlanApproval, error)); ok {
+		return rf(planUUID, reviewer, decision, comment)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, db.PlanDecision, string) db.TicketPlan); ok {
+		r0 = rf(planUUID, reviewer, decision, comment)
+	} else {
+		r0 = ret.Get(0).(db.TicketPlan)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string, db.PlanDecision, string) db.TicketPlanApproval); ok {
+		r1 = rf(planUUID, reviewer, decision, comment)
+	} else {
+		r1 = ret.Get(1).(db.TicketPlanApproval)
+	}
+
+	if rf, ok := ret.Get(2).(func(uuid.UUID, string, db.PlanDecision, string) error); ok {
+		r2 = rf(planUUID, reviewer, decision, comment)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type Database_ReviewTicketPlan_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ReviewTicketPlan(planUUID interface{}, reviewer interface{}, decision interface{}, comment interface{}) *Database_ReviewTicketPlan_Call {
+	return &Database_ReviewTicketPlan_Call{Call: _e.mock.On("ReviewTicketPlan", planUUID, reviewer, decision, comment)}
+}
+
+func (_c *Database_ReviewTicketPlan_Call) Run(run func(planUUID uuid.UUID, reviewer string, decision db.PlanDecision, comment string)) *Database_ReviewTicketPlan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string), args[2].(db.PlanDecision), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_ReviewTicketPlan_Call) Return(_a0 db.TicketPlan, _a1 db.TicketPlanApproval, _a2 error) *Database_ReviewTicketPlan_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_ReviewTicketPlan_Call) RunAndReturn(run func(uuid.UUID, string, db.PlanDecision, string) (db.TicketPlan, db.TicketPlanApproval, error)) *Database_ReviewTicketPlan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ReopenTicketPlan(planUUID uuid.UUID, pubkey string) (db.TicketPlan, error) {
+	ret := _m.Called(planUUID, pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReopenTicketPlan")
+	}
+
+	var r0 db.TicketPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (db.TicketPlan, error)); ok {
+		return rf(planUUID, pubkey)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) db.TicketPlan); ok {
+		r0 = rf(planUUID, pubkey)
+	} else {
+		r0 = ret.Get(0).(db.TicketPlan)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(planUUID, pubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_ReopenTicketPlan_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ReopenTicketPlan(planUUID interface{}, pubkey interface{}) *Database_ReopenTicketPlan_Call {
+	return &Database_ReopenTicketPlan_Call{Call: _e.mock.On("ReopenTicketPlan", planUUID, pubkey)}
+}
+
+func (_c *Database_ReopenTicketPlan_Call) Run(run func(planUUID uuid.UUID, pubkey string)) *Database_ReopenTicketPlan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_ReopenTicketPlan_Call) Return(_a0 db.TicketPlan, _a1 error) *Database_ReopenTicketPlan_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ReopenTicketPlan_Call) RunAndReturn(run func(uuid.UUID, string) (db.TicketPlan, error)) *Database_ReopenTicketPlan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTicketPlanRevisions(planUUID uuid.UUID) ([]db.TicketPlanRevision, error) {
+	ret := _m.Called(planUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketPlanRevisions")
+	}
+
+	var r0 []db.TicketPlanRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]db.TicketPlanRevision, error)); ok {
+		return rf(planUUID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []db.TicketPlanRevision); ok {
+		r0 = rf(planUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketPlanRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(planUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTicketPlanRevisions_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTicketPlanRevisions(planUUID interface{}) *Database_GetTicketPlanRevisions_Call {
+	return &Database_GetTicketPlanRevisions_Call{Call: _e.mock.On("GetTicketPlanRevisions", planUUID)}
+}
+
+func (_c *Database_GetTicketPlanRevisions_Call) Run(run func(planUUID uuid.UUID)) *Database_GetTicketPlanRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketPlanRevisions_Call) Return(_a0 []db.TicketPlanRevision, _a1 error) *Database_GetTicketPlanRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketPlanRevisions_Call) RunAndReturn(run func(uuid.UUID) ([]db.TicketPlanRevision, error)) *Database_GetTicketPlanRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTicketPlanApprovals(planUUID uuid.UUID) ([]db.TicketPlanApproval, error) {
+	ret := _m.Called(planUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTicketPlanApprovals")
+	}
+
+	var r0 []db.TicketPlanApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]db.TicketPlanApproval, error)); ok {
+		return rf(planUUID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []db.TicketPlanApproval); ok {
+		r0 = rf(planUUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TicketPlanApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(planUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTicketPlanApprovals_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTicketPlanApprovals(planUUID interface{}) *Database_GetTicketPlanApprovals_Call {
+	return &Database_GetTicketPlanApprovals_Call{Call: _e.mock.On("GetTicketPlanApprovals", planUUID)}
+}
+
+func (_c *Database_GetTicketPlanApprovals_Call) Run(run func(planUUID uuid.UUID)) *Database_GetTicketPlanApprovals_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetTicketPlanApprovals_Call) Return(_a0 []db.TicketPlanApproval, _a1 error) *Database_GetTicketPlanApprovals_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTicketPlanApprovals_Call) RunAndReturn(run func(uuid.UUID) ([]db.TicketPlanApproval, error)) *Database_GetTicketPlanApprovals_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CompareApprovedTicketPlan(planUUID uuid.UUID) (db.TicketPlanExecution, error) {
+	ret := _m.Called(planUUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompareApprovedTicketPlan")
+	}
+
+	var r0 db.TicketPlanExecution
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.TicketPlanExecution, error)); ok {
+		return rf(planUUID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.TicketPlanExecution); ok {
+		r0 = rf(planUUID)
+	} else {
+		r0 = ret.Get(0).(db.TicketPlanExecution)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(planUUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CompareApprovedTicketPlan_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CompareApprovedTicketPlan(planUUID interface{}) *Database_CompareApprovedTicketPlan_Call {
+	return &Database_CompareApprovedTicketPlan_Call{Call: _e.mock.On("CompareApprovedTicketPlan", planUUID)}
+}
+
+func (_c *Database_CompareApprovedTicketPlan_Call) Run(run func(planUUID uuid.UUID)) *Database_CompareApprovedTicketPlan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_CompareApprovedTicketPlan_Call) Return(_a0 db.TicketPlanExecution, _a1 error) *Database_CompareApprovedTicketPlan_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CompareApprovedTicketPlan_Call) RunAndReturn(run func(uuid.UUID) (db.TicketPlanExecution, error)) *Database_CompareApprovedTicketPlan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) BulkUpdateTickets(pubkey string, request db.BulkTicketRequest) (db.BulkTicketResponse, error) {
+	ret := _m.Called(pubkey, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkUpdateTickets")
+	}
+
+	var r0 db.BulkTicketResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.BulkTicketRequest) (db.BulkTicketResponse, error)); ok {
+		return rf(pubkey, request)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.BulkTicketRequest) db.BulkTicketResponse); ok {
+		r0 = rf(pubkey, request)
+	} else {
+		r0 = ret.Get(0).(db.BulkTicketResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.BulkTicketRequest) error); ok {
+		r1 = rf(pubkey, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_BulkUpdateTickets_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) BulkUpdateTickets(pubkey interface{}, request interface{}) *Database_BulkUpdateTickets_Call {
+	return &Database_BulkUpdateTickets_Call{Call: _e.mock.On("BulkUpdateTickets", pubkey, request)}
+}
+
+func (_c *Database_BulkUpdateTickets_Call) Run(run func(pubkey string, request db.BulkTicketRequest)) *Database_BulkUpdateTickets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.BulkTicketRequest))
+	})
+	return _c
+}
+
+func (_c *Database_BulkUpdateTickets_Call) Return(_a0 db.BulkTicketResponse, _a1 error) *Database_BulkUpdateTickets_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_BulkUpdateTickets_Call) RunAndReturn(run func(string, db.BulkTicketRequest) (db.BulkTicketResponse, error)) *Database_BulkUpdateTickets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ImportFeatureMarkdown(workspaceUuid string, featureUuid string, pubkey string, markdown string, preview bool) (db.FeatureImportPlan, error) {
+	ret := _m.Called(workspaceUuid, featureUuid, pubkey, markdown, preview)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportFeatureMarkdown")
+	}
+
+	var r0 db.FeatureImportPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, bool) (db.FeatureImportPlan, error)); ok {
+		return rf(workspaceUuid, featureUuid, pubkey, markdown, preview)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, bool) db.FeatureImportPlan); ok {
+		r0 = rf(workspaceUuid, featureUuid, pubkey, markdown, preview)
+	} else {
+		r0 = ret.Get(0).(db.FeatureImportPlan)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, bool) error); ok {
+		r1 = rf(workspaceUuid, featureUuid, pubkey, markdown, preview)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_ImportFeatureMarkdown_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ImportFeatureMarkdown(workspaceUuid interface{}, featureUuid interface{}, pubkey interface{}, markdown interface{}, preview interface{}) *Database_ImportFeatureMarkdown_Call {
+	return &Database_ImportFeatureMarkdown_Call{Call: _e.mock.On("ImportFeatureMarkdown", workspaceUuid, featureUuid, pubkey, markdown, preview)}
+}
+
+func (_c *Database_ImportFeatureMarkdown_Call) Run(run func(workspaceUuid string, featureUuid string, pubkey string, markdown string, preview bool)) *Database_ImportFeatureMarkdown_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string), args[4].(bool))
+	})
+	return _c
+}
+
+func (_c *Database_ImportFeatureMarkdown_Call) Return(_a0 db.FeatureImportPlan, _a1 error) *Database_ImportFeatureMarkdown_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ImportFeatureMarkdown_Call) RunAndReturn(run func(string, string, string, string, bool) (db.FeatureImportPlan, error)) *Database_ImportFeatureMarkdown_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ExportFeatureMarkdown(featureUuid string) (string, error) {
+	ret := _m.Called(featureUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportFeatureMarkdown")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(featureUuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(featureUuid)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(featureUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_ExportFeatureMarkdown_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ExportFeatureMarkdown(featureUuid interface{}) *Database_ExportFeatureMarkdown_Call {
+	return &Database_ExportFeatureMarkdown_Call{Call: _e.mock.On("ExportFeatureMarkdown", featureUuid)}
+}
+
+func (_c *Database_ExportFeatureMarkdown_Call) Run(run func(featureUuid string)) *Database_ExportFeatureMarkdown_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_ExportFeatureMarkdown_Call) Return(_a0 string, _a1 error) *Database_ExportFeatureMarkdown_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ExportFeatureMarkdown_Call) RunAndReturn(run func(string) (string, error)) *Database_ExportFeatureMarkdown_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTextRevisions(entityType db.TextRevisionEntity, entityUuid string, field string) ([]db.TextRevision, error) {
+	ret := _m.Called(entityType, entityUuid, field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTextRevisions")
+	}
+
+	var r0 []db.TextRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string) ([]db.TextRevision, error)); ok {
+		return rf(entityType, entityUuid, field)
+	}
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string) []db.TextRevision); ok {
+		r0 = rf(entityType, entityUuid, field)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.TextRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(db.TextRevisionEntity, string, string) error); ok {
+		r1 = rf(entityType, entityUuid, field)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTextRevisions_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTextRevisions(entityType interface{}, entityUuid interface{}, field interface{}) *Database_GetTextRevisions_Call {
+	return &Database_GetTextRevisions_Call{Call: _e.mock.On("GetTextRevisions", entityType, entityUuid, field)}
+}
+
+func (_c *Database_GetTextRevisions_Call) Run(run func(entityType db.TextRevisionEntity, entityUuid string, field string)) *Database_GetTextRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.TextRevisionEntity), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetTextRevisions_Call) Return(_a0 []db.TextRevision, _a1 error) *Database_GetTextRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTextRevisions_Call) RunAndReturn(run func(db.TextRevisionEntity, string, string) ([]db.TextRevision, error)) *Database_GetTextRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetTextRevision(entityType db.TextRevisionEntity, entityUuid string, field string, version int) (db.TextRevision, error) {
+	ret := _m.Called(entityType, entityUuid, field, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTextRevision")
+	}
+
+	var r0 db.TextRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string, int) (db.TextRevision, error)); ok {
+		return rf(entityType, entityUuid, field, version)
+	}
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string, int) db.TextRevision); ok {
+		r0 = rf(entityType, entityUuid, field, version)
+	} else {
+		r0 = ret.Get(0).(db.TextRevision)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.TextRevisionEntity, string, string, int) error); ok {
+		r1 = rf(entityType, entityUuid, field, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetTextRevision_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetTextRevision(entityType interface{}, entityUuid interface{}, field interface{}, version interface{}) *Database_GetTextRevision_Call {
+	return &Database_GetTextRevision_Call{Call: _e.mock.On("GetTextRevision", entityType, entityUuid, field, version)}
+}
+
+func (_c *Database_GetTextRevision_Call) Run(run func(entityType db.TextRevisionEntity, entityUuid string, field string, version int)) *Database_GetTextRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.TextRevisionEntity), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetTextRevision_Call) Return(_a0 db.TextRevision, _a1 error) *Database_GetTextRevision_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetTextRevision_Call) RunAndReturn(run func(db.TextRevisionEntity, string, string, int) (db.TextRevision, error)) *Database_GetTextRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CompareTextRevisions(entityType db.TextRevisionEntity, entityUuid string, field string, from int, to int) (db.TextRevisionDiff, error) {
+	ret := _m.Called(entityType, entityUuid, field, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompareTextRevisions")
+	}
+
+	var r0 db.TextRevisionDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string, int, int) (db.TextRevisionDiff, error)); ok {
+		return rf(entityType, entityUuid, field, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string, int, int) db.TextRevisionDiff); ok {
+		r0 = rf(entityType, entityUuid, field, from, to)
+	} else {
+		r0 = ret.Get(0).(db.TextRevisionDiff)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.TextRevisionEntity, string, string, int, int) error); ok {
+		r1 = rf(entityType, entityUuid, field, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CompareTextRevisions_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CompareTextRevisions(entityType interface{}, entityUuid interface{}, field interface{}, from interface{}, to interface{}) *Database_CompareTextRevisions_Call {
+	return &Database_CompareTextRevisions_Call{Call: _e.mock.On("CompareTextRevisions", entityType, entityUuid, field, from, to)}
+}
+
+func (_c *Database_CompareTextRevisions_Call) Run(run func(entityType db.TextRevisionEntity, entityUuid string, field string, from int, to int)) *Database_CompareTextRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.TextRevisionEntity), args[1].(string), args[2].(string), args[3].(int), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *Database_CompareTextRevisions_Call) Return(_a0 db.TextRevisionDiff, _a1 error) *Database_CompareTextRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CompareTextRevisions_Call) RunAndReturn(run func(db.TextRevisionEntity, string, string, int, int) (db.TextRevisionDiff, error)) *Database_CompareTextRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) RestoreTextRevision(entityType db.TextRevisionEntity, entityUuid string, field string, version int, pubkey string) (db.TextRevision, error) {
+	ret := _m.Called(entityType, entityUuid, field, version, pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreTextRevision")
+	}
+
+	var r0 db.TextRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string, int, string) (db.TextRevision, error)); ok {
+		return rf(entityType, entityUuid, field, version, pubkey)
+	}
+	if rf, ok := ret.Get(0).(func(db.TextRevisionEntity, string, string, int, string) db.TextRevision); ok {
+		r0 = rf(entityType, entityUuid, field, version, pubkey)
+	} else {
+		r0 = ret.Get(0).(db.TextRevision)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.TextRevisionEntity, string, string, int, string) error); ok {
+		r1 = rf(entityType, entityUuid, field, version, pubkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_RestoreTextRevision_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) RestoreTextRevision(entityType interface{}, entityUuid interface{}, field interface{}, version interface{}, pubkey interface{}) *Database_RestoreTextRevision_Call {
+	return &Database_RestoreTextRevision_Call{Call: _e.mock.On("RestoreTextRevision", entityType, entityUuid, field, version, pubkey)}
+}
+
+func (_c *Database_RestoreTextRevision_Call) Run(run func(entityType db.TextRevisionEntity, entityUuid string, field string, version int, pubkey string)) *Database_RestoreTextRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.TextRevisionEntity), args[1].(string), args[2].(string), args[3].(int), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *Database_RestoreTextRevision_Call) Return(_a0 db.TextRevision, _a1 error) *Database_RestoreTextRevision_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_RestoreTextRevision_Call) RunAndReturn(run func(db.TextRevisionEntity, string, string, int, string) (db.TextRevision, error)) *Database_RestoreTextRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWorkspaceFileUsage(workspaceID string) (int64, error) {
+	ret := _m.Called(workspaceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWorkspaceFileUsage")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(workspaceID)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(workspaceID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(workspaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetWorkspaceFileUsage_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWorkspaceFileUsage(workspaceID interface{}) *Database_GetWorkspaceFileUsage_Call {
+	return &Database_GetWorkspaceFileUsage_Call{Call: _e.mock.On("GetWorkspaceFileUsage", workspaceID)}
+}
+
+func (_c *Database_GetWorkspaceFileUsage_Call) Run(run func(workspaceID string)) *Database_GetWorkspaceFileUsage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetWorkspaceFileUsage_Call) Return(_a0 int64, _a1 error) *Database_GetWorkspaceFileUsage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWorkspaceFileUsage_Call) RunAndReturn(run func(string) (int64, error)) *Database_GetWorkspaceFileUsage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ArchiveFileAssets(before time.Time) (int64, int64, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveFileAssets")
+	}
+
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, int64, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) int64); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(time.Time) error); ok {
+		r2 = rf(before)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type Database_ArchiveFileAssets_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ArchiveFileAssets(before interface{}) *Database_ArchiveFileAssets_Call {
+	return &Database_ArchiveFileAssets_Call{Call: _e.mock.On("ArchiveFileAssets", before)}
+}
+
+func (_c *Database_ArchiveFileAssets_Call) Run(run func(before time.Time)) *Database_ArchiveFileAssets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Database_ArchiveFileAssets_Call) Return(_a0 int64, _a1 int64, _a2 error) *Database_ArchiveFileAssets_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_ArchiveFileAssets_Call) RunAndReturn(run func(time.Time) (int64, int64, error)) *Database_ArchiveFileAssets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetExpiredFileAssets(before time.Time, limit int) ([]db.FileAsset, error) {
+	ret := _m.Called(before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExpiredFileAssets")
+	}
+
+	var r0 []db.FileAsset
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]db.FileAsset, error)); ok {
+		return rf(before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []db.FileAsset); ok {
+		r0 = rf(before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.FileAsset)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetExpiredFileAssets_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetExpiredFileAssets(before interface{}, limit interface{}) *Database_GetExpiredFileAssets_Call {
+	return &Database_GetExpiredFileAssets_Call{Call: _e.mock.On("GetExpiredFileAssets", before, limit)}
+}
+
+func (_c *Database_GetExpiredFileAssets_Call) Run(run func(before time.Time, limit int)) *Database_GetExpiredFileAssets_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetExpiredFileAssets_Call) Return(_a0 []db.FileAsset, _a1 error) *Database_GetExpiredFileAssets_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetExpiredFileAssets_Call) RunAndReturn(run func(time.Time, int) ([]db.FileAsset, error)) *Database_GetExpiredFileAssets_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetSSEMessageLogsAfter(chatID string, after *db.SSEMessageLog, limit int) ([]db.SSEMessageLog, error) {
+	ret := _m.Called(chatID, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSSEMessageLogsAfter")
+	}
+
+	var r0 []db.SSEMessageLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *db.SSEMessageLog, int) ([]db.SSEMessageLog, error)); ok {
+		return rf(chatID, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, *db.SSEMessageLog, int) []db.SSEMessageLog); ok {
+		r0 = rf(chatID, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.SSEMessageLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *db.SSEMessageLog, int) error); ok {
+		r1 = rf(chatID, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetSSEMessageLogsAfter_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetSSEMessageLogsAfter(chatID interface{}, after interface{}, limit interface{}) *Database_GetSSEMessageLogsAfter_Call {
+	return &Database_GetSSEMessageLogsAfter_Call{Call: _e.mock.On("GetSSEMessageLogsAfter", chatID, after, limit)}
+}
+
+func (_c *Database_GetSSEMessageLogsAfter_Call) Run(run func(chatID string, after *db.SSEMessageLog, limit int)) *Database_GetSSEMessageLogsAfter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*db.SSEMessageLog), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetSSEMessageLogsAfter_Call) Return(_a0 []db.SSEMessageLog, _a1 error) *Database_GetSSEMessageLogsAfter_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetSSEMessageLogsAfter_Call) RunAndReturn(run func(string, *db.SSEMessageLog, int) ([]db.SSEMessageLog, error)) *Database_GetSSEMessageLogsAfter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SearchWorkspace(workspaceID string, params db.WorkspaceSearchParams) (db.WorkspaceSearchResult, error) {
+	ret := _m.Called(workspaceID, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchWorkspace")
+	}
+
+	var r0 db.WorkspaceSearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, db.WorkspaceSearchParams) (db.WorkspaceSearchResult, error)); ok {
+		return rf(workspaceID, params)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.WorkspaceSearchParams) db.WorkspaceSearchResult); ok {
+		r0 = rf(workspaceID, params)
+	} else {
+		r0 = ret.Get(0).(db.WorkspaceSearchResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.WorkspaceSearchParams) error); ok {
+		r1 = rf(workspaceID, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SearchWorkspace_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SearchWorkspace(workspaceID interface{}, params interface{}) *Database_SearchWorkspace_Call {
+	return &Database_SearchWorkspace_Call{Call: _e.mock.On("SearchWorkspace", workspaceID, params)}
+}
+
+func (_c *Database_SearchWorkspace_Call) Run(run func(workspaceID string, params db.WorkspaceSearchParams)) *Database_SearchWorkspace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.WorkspaceSearchParams))
+	})
+	return _c
+}
+
+func (_c *Database_SearchWorkspace_Call) Return(_a0 db.WorkspaceSearchResult, _a1 error) *Database_SearchWorkspace_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SearchWorkspace_Call) RunAndReturn(run func(string, db.WorkspaceSearchParams) (db.WorkspaceSearchResult, error)) *Database_SearchWorkspace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetChatBranches(chatID string) ([]db.ChatBranch, error) {
+	ret := _m.Called(chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatBranches")
+	}
+
+	var r0 []db.ChatBranch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.ChatBranch, error)); ok {
+		return rf(chatID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.ChatBranch); ok {
+		r0 = rf(chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatBranch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetChatBranches_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetChatBranches(chatID interface{}) *Database_GetChatBranches_Call {
+	return &Database_GetChatBranches_Call{Call: _e.mock.On("GetChatBranches", chatID)}
+}
+
+func (_c *Database_GetChatBranches_Call) Run(run func(chatID string)) *Database_GetChatBranches_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatBranches_Call) Return(_a0 []db.ChatBranch, _a1 error) *Database_GetChatBranches_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatBranches_Call) RunAndReturn(run func(string) ([]db.ChatBranch, error)) *Database_GetChatBranches_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetChatBranchHistory(chatID string, branchID string) ([]db.ChatMessage, error) {
+	ret := _m.Called(chatID, branchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatBranchHistory")
+	}
+
+	var r0 []db.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]db.ChatMessage, error)); ok {
+		return rf(chatID, branchID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []db.ChatMessage); ok {
+		r0 = rf(chatID, branchID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, branchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetChatBranchHistory_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetChatBranchHistory(chatID interface{}, branchID interface{}) *Database_GetChatBranchHistory_Call {
+	return &Database_GetChatBranchHistory_Call{Call: _e.mock.On("GetChatBranchHistory", chatID, branchID)}
+}
+
+func (_c *Database_GetChatBranchHistory_Call) Run(run func(chatID string, branchID string)) *Database_GetChatBranchHistory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatBranchHistory_Call) Return(_a0 []db.ChatMessage, _a1 error) *Database_GetChatBranchHistory_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatBranchHistory_Call) RunAndReturn(run func(string, string) ([]db.ChatMessage, error)) *Database_GetChatBranchHistory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetChatMessageByID(id string) (db.ChatMessage, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatMessageByID")
+	}
+
+	var r0 db.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.ChatMessage, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.ChatMessage); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.ChatMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetChatMessageByID_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetChatMessageByID(id interface{}) *Database_GetChatMessageByID_Call {
+	return &Database_GetChatMessageByID_Call{Call: _e.mock.On("GetChatMessageByID", id)}
+}
+
+func (_c *Database_GetChatMessageByID_Call) Run(run func(id string)) *Database_GetChatMessageByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatMessageByID_Call) Return(_a0 db.ChatMessage, _a1 error) *Database_GetChatMessageByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatMessageByID_Call) RunAndReturn(run func(string) (db.ChatMessage, error)) *Database_GetChatMessageByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ForkChatMessage(chatID string, messageID string, text string, pubkey string) (db.ChatBranch, db.ChatMessage, error) {
+	ret := _m.Called(chatID, messageID, text, pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForkChatMessage")
+	}
+
+	var r0 db.ChatBranch
+	var r1 db.ChatMessage
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (db.ChatBranch, db.ChatMessage, error)); ok {
+		return rf(chatID, messageID, text, pubkey)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) db.ChatBranch); ok {
+		r0 = rf(chatID, messageID, text, pubkey)
+	} else {
+		r0 = ret.Get(0).(db.ChatBranch)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) db.ChatMessage); ok {
+		r1 = rf(chatID, messageID, text, pubkey)
+	} else {
+		r1 = ret.Get(1).(db.ChatMessage)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, string, string) error); ok {
+		r2 = rf(chatID, messageID, text, pubkey)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type Database_ForkChatMessage_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ForkChatMessage(chatID interface{}, messageID interface{}, text interface{}, pubkey interface{}) *Database_ForkChatMessage_Call {
+	return &Database_ForkChatMessage_Call{Call: _e.mock.On("ForkChatMessage", chatID, messageID, text, pubkey)}
+}
+
+func (_c *Database_ForkChatMessage_Call) Run(run func(chatID string, messageID string, text string, pubkey string)) *Database_ForkChatMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_ForkChatMessage_Call) Return(_a0 db.ChatBranch, _a1 db.ChatMessage, _a2 error) *Database_ForkChatMessage_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_ForkChatMessage_Call) RunAndReturn(run func(string, string, string, string) (db.ChatBranch, db.ChatMessage, error)) *Database_ForkChatMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SetActiveChatBranch(chatID string, branchID string) (db.Chat, error) {
+	ret := _m.Called(chatID, branchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetActiveChatBranch")
+	}
+
+	var r0 db.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (db.Chat, error)); ok {
+		return rf(chatID, branchID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) db.Chat); ok {
+		r0 = rf(chatID, branchID)
+	} else {
+		r0 = ret.Get(0).(db.Chat)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, branchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SetActiveChatBranch_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SetActiveChatBranch(chatID interface{}, branchID interface{}) *Database_SetActiveChatBranch_Call {
+	return &Database_SetActiveChatBranch_Call{Call: _e.mock.On("SetActiveChatBranch", chatID, branchID)}
+}
+
+func (_c *Database_SetActiveChatBranch_Call) Run(run func(chatID string, branchID string)) *Database_SetActiveChatBranch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_SetActiveChatBranch_Call) Return(_a0 db.Chat, _a1 error) *Database_SetActiveChatBranch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetActiveChatBranch_Call) RunAndReturn(run func(string, string) (db.Chat, error)) *Database_SetActiveChatBranch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetChatExport(chatID string, branchID string) (db.ChatExport, error) {
+	ret := _m.Called(chatID, branchID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatExport")
+	}
+
+	var r0 db.ChatExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (db.ChatExport, error)); ok {
+		return rf(chatID, branchID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) db.ChatExport); ok {
+		r0 = rf(chatID, branchID)
+	} else {
+		r0 = ret.Get(0).(db.ChatExport)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(chatID, branchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetChatExport_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetChatExport(chatID interface{}, branchID interface{}) *Database_GetChatExport_Call {
+	return &Database_GetChatExport_Call{Call: _e.mock.On("GetChatExport", chatID, branchID)}
+}
+
+func (_c *Database_GetChatExport_Call) Run(run func(chatID string, branchID string)) *Database_GetChatExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatExport_Call) Return(_a0 db.ChatExport, _a1 error) *Database_GetChatExport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatExport_Call) RunAndReturn(run func(string, string) (db.ChatExport, error)) *Database_GetChatExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CreateChatShareLink(link db.ChatShareLink) (db.ChatShareLink, error) {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateChatShareLink")
+	}
+
+	var r0 db.ChatShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.ChatShareLink) (db.ChatShareLink, error)); ok {
+		return rf(link)
+	}
+	if rf, ok := ret.Get(0).(func(db.ChatShareLink) db.ChatShareLink); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Get(0).(db.ChatShareLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(db.ChatShareLink) error); ok {
+		r1 = rf(link)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CreateChatShareLink_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CreateChatShareLink(link interface{}) *Database_CreateChatShareLink_Call {
+	return &Database_CreateChatShareLink_Call{Call: _e.mock.On("CreateChatShareLink", link)}
+}
+
+func (_c *Database_CreateChatShareLink_Call) Run(run func(link db.ChatShareLink)) *Database_CreateChatShareLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.ChatShareLink))
+	})
+	return _c
+}
+
+func (_c *Database_CreateChatShareLink_Call) Return(_a0 db.ChatShareLink, _a1 error) *Database_CreateChatShareLink_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateChatShareLink_Call) RunAndReturn(run func(db.ChatShareLink) (db.ChatShareLink, error)) *Database_CreateChatShareLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetChatShareLinks(chatID string) ([]db.ChatShareLink, error) {
+	ret := _m.Called(chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatShareLinks")
+	}
+
+	var r0 []db.ChatShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]db.ChatShareLink, error)); ok {
+		return rf(chatID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []db.ChatShareLink); ok {
+		r0 = rf(chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ChatShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetChatShareLinks_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetChatShareLinks(chatID interface{}) *Database_GetChatShareLinks_Call {
+	return &Database_GetChatShareLinks_Call{Call: _e.mock.On("GetChatShareLinks", chatID)}
+}
+
+func (_c *Database_GetChatShareLinks_Call) Run(run func(chatID string)) *Database_GetChatShareLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatShareLinks_Call) Return(_a0 []db.ChatShareLink, _a1 error) *Database_GetChatShareLinks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatShareLinks_Call) RunAndReturn(run func(string) ([]db.ChatShareLink, error)) *Database_GetChatShareLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetChatShareLinkByToken(token string) (db.ChatShareLink, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatShareLinkByToken")
+	}
+
+	var r0 db.ChatShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (db.ChatShareLink, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) db.ChatShareLink); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(db.ChatShareLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetChatShareLinkByToken_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetChatShareLinkByToken(token interface{}) *Database_GetChatShareLinkByToken_Call {
+	return &Database_GetChatShareLinkByToken_Call{Call: _e.mock.On("GetChatShareLinkByToken", token)}
+}
+
+func (_c *Database_GetChatShareLinkByToken_Call) Run(run func(token string)) *Database_GetChatShareLinkByToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *Database_GetChatShareLinkByToken_Call) Return(_a0 db.ChatShareLink, _a1 error) *Database_GetChatShareLinkByToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetChatShareLinkByToken_Call) RunAndReturn(run func(string) (db.ChatShareLink, error)) *Database_GetChatShareLinkByToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) RevokeChatShareLink(chatID string, id string) error {
+	ret := _m.Called(chatID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeChatShareLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(chatID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_RevokeChatShareLink_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) RevokeChatShareLink(chatID interface{}, id interface{}) *Database_RevokeChatShareLink_Call {
+	return &Database_RevokeChatShareLink_Call{Call: _e.mock.On("RevokeChatShareLink", chatID, id)}
+}
+
+func (_c *Database_RevokeChatShareLink_Call) Run(run func(chatID string, id string)) *Database_RevokeChatShareLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_RevokeChatShareLink_Call) Return(_a0 error) *Database_RevokeChatShareLink_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_RevokeChatShareLink_Call) RunAndReturn(run func(string, string) error) *Database_RevokeChatShareLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SetChatMessagePinned(chatID string, messageID string, pinned bool) (db.ChatMessage, error) {
+	ret := _m.Called(chatID, messageID, pinned)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetChatMessagePinned")
+	}
+
+	var r0 db.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, bool) (db.ChatMessage, error)); ok {
+		return rf(chatID, messageID, pinned)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, bool) db.ChatMessage); ok {
+		r0 = rf(chatID, messageID, pinned)
+	} else {
+		r0 = ret.Get(0).(db.ChatMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, bool) error); ok {
+		r1 = rf(chatID, messageID, pinned)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SetChatMessagePinned_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SetChatMessagePinned(chatID interface{}, messageID interface{}, pinned interface{}) *Database_SetChatMessagePinned_Call {
+	return &Database_SetChatMessagePinned_Call{Call: _e.mock.On("SetChatMessagePinned", chatID, messageID, pinned)}
+}
+
+func (_c *Database_SetChatMessagePinned_Call) Run(run func(chatID string, messageID string, pinned bool)) *Database_SetChatMessagePinned_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *Database_SetChatMessagePinned_Call) Return(_a0 db.ChatMessage, _a1 error) *Database_SetChatMessagePinned_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetChatMessagePinned_Call) RunAndReturn(run func(string, string, bool) (db.ChatMessage, error)) *Database_SetChatMessagePinned_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SetArtifactPinned(id uuid.UUID, pinned bool) (*db.Artifact, error) {
+	ret := _m.Called(id, pinned)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetArtifactPinned")
+	}
+
+	var r0 *db.Artifact
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool) (*db.Artifact, error)); ok {
+		return rf(id, pinned)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, bool) *db.Artifact); ok {
+		r0 = rf(id, pinned)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.Artifact)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, bool) error); ok {
+		r1 = rf(id, pinned)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SetArtifactPinned_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SetArtifactPinned(id interface{}, pinned interface{}) *Database_SetArtifactPinned_Call {
+	return &Database_SetArtifactPinned_Call{Call: _e.mock.On("SetArtifactPinned", id, pinned)}
+}
+
+func (_c *Database_SetArtifactPinned_Call) Run(run func(id uuid.UUID, pinned bool)) *Database_SetArtifactPinned_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *Database_SetArtifactPinned_Call) Return(_a0 *db.Artifact, _a1 error) *Database_SetArtifactPinned_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SetArtifactPinned_Call) RunAndReturn(run func(uuid.UUID, bool) (*db.Artifact, error)) *Database_SetArtifactPinned_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ResolveContextTags(workspaceID string, tags []db.ContextTag) []db.ResolvedContextTag {
+	ret := _m.Called(workspaceID, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveContextTags")
+	}
+
+	var r0 []db.ResolvedContextTag
+	if rf, ok := ret.Get(0).(func(string, []db.ContextTag) []db.ResolvedContextTag); ok {
+		r0 = rf(workspaceID, tags)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ResolvedContextTag)
+		}
+	}
+
+	return r0
+}
+
+type Database_ResolveContextTags_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ResolveContextTags(workspaceID interface{}, tags interface{}) *Database_ResolveContextTags_Call {
+	return &Database_ResolveContextTags_Call{Call: _e.mock.On("ResolveContextTags", workspaceID, tags)}
+}
+
+func (_c *Database_ResolveContextTags_Call) Run(run func(workspaceID string, tags []db.ContextTag)) *Database_ResolveContextTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]db.ContextTag))
+	})
+	return _c
+}
+
+func (_c *Database_ResolveContextTags_Call) Return(_a0 []db.ResolvedContextTag) *Database_ResolveContextTags_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_ResolveContextTags_Call) RunAndReturn(run func(string, []db.ContextTag) []db.ResolvedContextTag) *Database_ResolveContextTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SaveSSEClient(chatID string, url string, webhookURL string, lastEventID string) (*db.SSEClient, error) {
+	ret := _m.Called(chatID, url, webhookURL, lastEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSSEClient")
+	}
+
+	var r0 *db.SSEClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*db.SSEClient, error)); ok {
+		return rf(chatID, url, webhookURL, lastEventID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *db.SSEClient); ok {
+		r0 = rf(chatID, url, webhookURL, lastEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.SSEClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(chatID, url, webhookURL, lastEventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_SaveSSEClient_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SaveSSEClient(chatID interface{}, url interface{}, webhookURL interface{}, lastEventID interface{}) *Database_SaveSSEClient_Call {
+	return &Database_SaveSSEClient_Call{Call: _e.mock.On("SaveSSEClient", chatID, url, webhookURL, lastEventID)}
+}
+
+func (_c *Database_SaveSSEClient_Call) Run(run func(chatID string, url string, webhookURL string, lastEventID string)) *Database_SaveSSEClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_SaveSSEClient_Call) Return(_a0 *db.SSEClient, _a1 error) *Database_SaveSSEClient_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_SaveSSEClient_Call) RunAndReturn(run func(string, string, string, string) (*db.SSEClient, error)) *Database_SaveSSEClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) UpdateSSEClientLastEventID(chatID string, url string, lastEventID string) error {
+	ret := _m.Called(chatID, url, lastEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSSEClientLastEventID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(chatID, url, lastEventID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_UpdateSSEClientLastEventID_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) UpdateSSEClientLastEventID(chatID interface{}, url interface{}, lastEventID interface{}) *Database_UpdateSSEClientLastEventID_Call {
+	return &Database_UpdateSSEClientLastEventID_Call{Call: _e.mock.On("UpdateSSEClientLastEventID", chatID, url, lastEventID)}
+}
+
+func (_c *Database_UpdateSSEClientLastEventID_Call) Run(run func(chatID string, url string, lastEventID string)) *Database_UpdateSSEClientLastEventID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateSSEClientLastEventID_Call) Return(_a0 error) *Database_UpdateSSEClientLastEventID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_UpdateSSEClientLastEventID_Call) RunAndReturn(run func(string, string, string) error) *Database_UpdateSSEClientLastEventID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) UpdateSSEClientStatus(chatID string, url string, status db.SSEClientStatus, lastError string) error {
+	ret := _m.Called(chatID, url, status, lastError)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSSEClientStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, db.SSEClientStatus, string) error); ok {
+		r0 = rf(chatID, url, status, lastError)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_UpdateSSEClientStatus_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) UpdateSSEClientStatus(chatID interface{}, url interface{}, status interface{}, lastError interface{}) *Database_UpdateSSEClientStatus_Call {
+	return &Database_UpdateSSEClientStatus_Call{Call: _e.mock.On("UpdateSSEClientStatus", chatID, url, status, lastError)}
+}
+
+func (_c *Database_UpdateSSEClientStatus_Call) Run(run func(chatID string, url string, status db.SSEClientStatus, lastError string)) *Database_UpdateSSEClientStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(db.SSEClientStatus), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateSSEClientStatus_Call) Return(_a0 error) *Database_UpdateSSEClientStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_UpdateSSEClientStatus_Call) RunAndReturn(run func(string, string, db.SSEClientStatus, string) error) *Database_UpdateSSEClientStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetSSEClients(status db.SSEClientStatus) ([]db.SSEClient, error) {
+	ret := _m.Called(status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSSEClients")
+	}
+
+	var r0 []db.SSEClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(db.SSEClientStatus) ([]db.SSEClient, error)); ok {
+		return rf(status)
+	}
+	if rf, ok := ret.Get(0).(func(db.SSEClientStatus) []db.SSEClient); ok {
+		r0 = rf(status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.SSEClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(db.SSEClientStatus) error); ok {
+		r1 = rf(status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetSSEClients_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetSSEClients(status interface{}) *Database_GetSSEClients_Call {
+	return &Database_GetSSEClients_Call{Call: _e.mock.On("GetSSEClients", status)}
+}
+
+func (_c *Database_GetSSEClients_Call) Run(run func(status db.SSEClientStatus)) *Database_GetSSEClients_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.SSEClientStatus))
+	})
+	return _c
+}
+
+func (_c *Database_GetSSEClients_Call) Return(_a0 []db.SSEClient, _a1 error) *Database_GetSSEClients_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetSSEClients_Call) RunAndReturn(run func(db.SSEClientStatus) ([]db.SSEClient, error)) *Database_GetSSEClients_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) CreateWebhookDelivery(endpoint string, event string, payload string, nextAttemptAt time.Time) (*db.WebhookDelivery, error) {
+	ret := _m.Called(endpoint, event, payload, nextAttemptAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhookDelivery")
+	}
+
+	var r0 *db.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time) (*db.WebhookDelivery, error)); ok {
+		return rf(endpoint, event, payload, nextAttemptAt)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time) *db.WebhookDelivery); ok {
+		r0 = rf(endpoint, event, payload, nextAttemptAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, time.Time) error); ok {
+		r1 = rf(endpoint, event, payload, nextAttemptAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_CreateWebhookDelivery_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) CreateWebhookDelivery(endpoint interface{}, event interface{}, payload interface{}, nextAttemptAt interface{}) *Database_CreateWebhookDelivery_Call {
+	return &Database_CreateWebhookDelivery_Call{Call: _e.mock.On("CreateWebhookDelivery", endpoint, event, payload, nextAttemptAt)}
+}
+
+func (_c *Database_CreateWebhookDelivery_Call) Run(run func(endpoint string, event string, payload string, nextAttemptAt time.Time)) *Database_CreateWebhookDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Database_CreateWebhookDelivery_Call) Return(_a0 *db.WebhookDelivery, _a1 error) *Database_CreateWebhookDelivery_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_CreateWebhookDelivery_Call) RunAndReturn(run func(string, string, string, time.Time) (*db.WebhookDelivery, error)) *Database_CreateWebhookDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) SaveWebhookAttempt(delivery db.WebhookDelivery, attempt db.WebhookAttempt) error {
+	ret := _m.Called(delivery, attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWebhookAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(db.WebhookDelivery, db.WebhookAttempt) error); ok {
+		r0 = rf(delivery, attempt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type Database_SaveWebhookAttempt_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) SaveWebhookAttempt(delivery interface{}, attempt interface{}) *Database_SaveWebhookAttempt_Call {
+	return &Database_SaveWebhookAttempt_Call{Call: _e.mock.On("SaveWebhookAttempt", delivery, attempt)}
+}
+
+func (_c *Database_SaveWebhookAttempt_Call) Run(run func(delivery db.WebhookDelivery, attempt db.WebhookAttempt)) *Database_SaveWebhookAttempt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(db.WebhookDelivery), args[1].(db.WebhookAttempt))
+	})
+	return _c
+}
+
+func (_c *Database_SaveWebhookAttempt_Call) Return(_a0 error) *Database_SaveWebhookAttempt_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_SaveWebhookAttempt_Call) RunAndReturn(run func(db.WebhookDelivery, db.WebhookAttempt) error) *Database_SaveWebhookAttempt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ClaimDueWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]db.WebhookDelivery, error) {
+	ret := _m.Called(now, lease, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDueWebhookDeliveries")
+	}
+
+	var r0 []db.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, time.Duration, int) ([]db.WebhookDelivery, error)); ok {
+		return rf(now, lease, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, time.Duration, int) []db.WebhookDelivery); ok {
+		r0 = rf(now, lease, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, time.Duration, int) error); ok {
+		r1 = rf(now, lease, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_ClaimDueWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ClaimDueWebhookDeliveries(now interface{}, lease interface{}, limit interface{}) *Database_ClaimDueWebhookDeliveries_Call {
+	return &Database_ClaimDueWebhookDeliveries_Call{Call: _e.mock.On("ClaimDueWebhookDeliveries", now, lease, limit)}
+}
+
+func (_c *Database_ClaimDueWebhookDeliveries_Call) Run(run func(now time.Time, lease time.Duration, limit int)) *Database_ClaimDueWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(time.Duration), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Database_ClaimDueWebhookDeliveries_Call) Return(_a0 []db.WebhookDelivery, _a1 error) *Database_ClaimDueWebhookDeliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ClaimDueWebhookDeliveries_Call) RunAndReturn(run func(time.Time, time.Duration, int) ([]db.WebhookDelivery, error)) *Database_ClaimDueWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWebhookDeliveryByID(id uuid.UUID) (*db.WebhookDelivery, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhookDeliveryByID")
+	}
+
+	var r0 *db.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*db.WebhookDelivery, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *db.WebhookDelivery); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetWebhookDeliveryByID_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWebhookDeliveryByID(id interface{}) *Database_GetWebhookDeliveryByID_Call {
+	return &Database_GetWebhookDeliveryByID_Call{Call: _e.mock.On("GetWebhookDeliveryByID", id)}
+}
+
+func (_c *Database_GetWebhookDeliveryByID_Call) Run(run func(id uuid.UUID)) *Database_GetWebhookDeliveryByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetWebhookDeliveryByID_Call) Return(_a0 *db.WebhookDelivery, _a1 error) *Database_GetWebhookDeliveryByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWebhookDeliveryByID_Call) RunAndReturn(run func(uuid.UUID) (*db.WebhookDelivery, error)) *Database_GetWebhookDeliveryByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWebhookDeliveries(endpoint string, status db.WebhookDeliveryStatus, limit int, offset int) ([]db.WebhookDelivery, int64, error) {
+	ret := _m.Called(endpoint, status, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhookDeliveries")
+	}
+
+	var r0 []db.WebhookDelivery
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, db.WebhookDeliveryStatus, int, int) ([]db.WebhookDelivery, int64, error)); ok {
+		return rf(endpoint, status, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(string, db.WebhookDeliveryStatus, int, int) []db.WebhookDelivery); ok {
+		r0 = rf(endpoint, status, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, db.WebhookDeliveryStatus, int, int) int64); ok {
+		r1 = rf(endpoint, status, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, db.WebhookDeliveryStatus, int, int) error); ok {
+		r2 = rf(endpoint, status, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type Database_GetWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWebhookDeliveries(endpoint interface{}, status interface{}, limit interface{}, offset interface{}) *Database_GetWebhookDeliveries_Call {
+	return &Database_GetWebhookDeliveries_Call{Call: _e.mock.On("GetWebhookDeliveries", endpoint, status, limit, offset)}
+}
+
+func (_c *Database_GetWebhookDeliveries_Call) Run(run func(endpoint string, status db.WebhookDeliveryStatus, limit int, offset int)) *Database_GetWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(db.WebhookDeliveryStatus), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Database_GetWebhookDeliveries_Call) Return(_a0 []db.WebhookDelivery, _a1 int64, _a2 error) *Database_GetWebhookDeliveries_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Database_GetWebhookDeliveries_Call) RunAndReturn(run func(string, db.WebhookDeliveryStatus, int, int) ([]db.WebhookDelivery, int64, error)) *Database_GetWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetWebhookAttempts(deliveryID uuid.UUID) ([]db.WebhookAttempt, error) {
+	ret := _m.Called(deliveryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhookAttempts")
+	}
+
+	var r0 []db.WebhookAttempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]db.WebhookAttempt, error)); ok {
+		return rf(deliveryID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []db.WebhookAttempt); ok {
+		r0 = rf(deliveryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookAttempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(deliveryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetWebhookAttempts_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetWebhookAttempts(deliveryID interface{}) *Database_GetWebhookAttempts_Call {
+	return &Database_GetWebhookAttempts_Call{Call: _e.mock.On("GetWebhookAttempts", deliveryID)}
+}
+
+func (_c *Database_GetWebhookAttempts_Call) Run(run func(deliveryID uuid.UUID)) *Database_GetWebhookAttempts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetWebhookAttempts_Call) Return(_a0 []db.WebhookAttempt, _a1 error) *Database_GetWebhookAttempts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetWebhookAttempts_Call) RunAndReturn(run func(uuid.UUID) ([]db.WebhookAttempt, error)) *Database_GetWebhookAttempts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) GetBountyByTicketGroup(ticketGroup uuid.UUID) (db.NewBounty, error) {
+	ret := _m.Called(ticketGroup)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBountyByTicketGroup")
+	}
+
+	var r0 db.NewBounty
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (db.NewBounty, error)); ok {
+		return rf(ticketGroup)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) db.NewBounty); ok {
+		r0 = rf(ticketGroup)
+	} else {
+		r0 = ret.Get(0).(db.NewBounty)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(ticketGroup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_GetBountyByTicketGroup_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) GetBountyByTicketGroup(ticketGroup interface{}) *Database_GetBountyByTicketGroup_Call {
+	return &Database_GetBountyByTicketGroup_Call{Call: _e.mock.On("GetBountyByTicketGroup", ticketGroup)}
+}
+
+func (_c *Database_GetBountyByTicketGroup_Call) Run(run func(ticketGroup uuid.UUID)) *Database_GetBountyByTicketGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Database_GetBountyByTicketGroup_Call) Return(_a0 db.NewBounty, _a1 error) *Database_GetBountyByTicketGroup_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_GetBountyByTicketGroup_Call) RunAndReturn(run func(uuid.UUID) (db.NewBounty, error)) *Database_GetBountyByTicketGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) ClaimWebhookDelivery(id uuid.UUID, now time.Time, lease time.Duration) (*db.WebhookDelivery, error) {
+	ret := _m.Called(id, now, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimWebhookDelivery")
+	}
+
+	var r0 *db.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, time.Duration) (*db.WebhookDelivery, error)); ok {
+		return rf(id, now, lease)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, time.Duration) *db.WebhookDelivery); ok {
+		r0 = rf(id, now, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*db.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time, time.Duration) error); ok {
+		r1 = rf(id, now, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_ClaimWebhookDelivery_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) ClaimWebhookDelivery(id interface{}, now interface{}, lease interface{}) *Database_ClaimWebhookDelivery_Call {
+	return &Database_ClaimWebhookDelivery_Call{Call: _e.mock.On("ClaimWebhookDelivery", id, now, lease)}
+}
+
+func (_c *Database_ClaimWebhookDelivery_Call) Run(run func(id uuid.UUID, now time.Time, lease time.Duration)) *Database_ClaimWebhookDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(time.Time), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *Database_ClaimWebhookDelivery_Call) Return(_a0 *db.WebhookDelivery, _a1 error) *Database_ClaimWebhookDelivery_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_ClaimWebhookDelivery_Call) RunAndReturn(run func(uuid.UUID, time.Time, time.Duration) (*db.WebhookDelivery, error)) *Database_ClaimWebhookDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) IsWorkspaceMember(pubkey string, workspaceUuid string) bool {
+	ret := _m.Called(pubkey, workspaceUuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsWorkspaceMember")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(pubkey, workspaceUuid)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+type Database_IsWorkspaceMember_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) IsWorkspaceMember(pubkey interface{}, workspaceUuid interface{}) *Database_IsWorkspaceMember_Call {
+	return &Database_IsWorkspaceMember_Call{Call: _e.mock.On("IsWorkspaceMember", pubkey, workspaceUuid)}
+}
+
+func (_c *Database_IsWorkspaceMember_Call) Run(run func(pubkey string, workspaceUuid string)) *Database_IsWorkspaceMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Database_IsWorkspaceMember_Call) Return(_a0 bool) *Database_IsWorkspaceMember_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Database_IsWorkspaceMember_Call) RunAndReturn(run func(string, string) bool) *Database_IsWorkspaceMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+func (_m *Database) UpdateChatSummary(chatID string, branchID string, throughID string, summary string) (db.Chat, error) {
+	ret := _m.Called(chatID, branchID, throughID, summary)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateChatSummary")
+	}
+
+	var r0 db.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (db.Chat, error)); ok {
+		return rf(chatID, branchID, throughID, summary)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) db.Chat); ok {
+		r0 = rf(chatID, branchID, throughID, summary)
+	} else {
+		r0 = ret.Get(0).(db.Chat)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(chatID, branchID, throughID, summary)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type Database_UpdateChatSummary_Call struct {
+	*mock.Call
+}
+
+func (_e *Database_Expecter) UpdateChatSummary(chatID interface{}, branchID interface{}, throughID interface{}, summary interface{}) *Database_UpdateChatSummary_Call {
+	return &Database_UpdateChatSummary_Call{Call: _e.mock.On("UpdateChatSummary", chatID, branchID, throughID, summary)}
+}
+
+func (_c *Database_UpdateChatSummary_Call) Run(run func(chatID string, branchID string, throughID string, summary string)) *Database_UpdateChatSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Database_UpdateChatSummary_Call) Return(_a0 db.Chat, _a1 error) *Database_UpdateChatSummary_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Database_UpdateChatSummary_Call) RunAndReturn(run func(string, string, string, string) (db.Chat, error)) *Database_UpdateChatSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -16,6 +16,19 @@ func ChatRoutes() chi.Router {
 
 	r.Post("/response", chatHandler.ProcessChatResponse)
 	r.Post("/{chat_id}/update", chatHandler.HandleChatWebhook)
+	r.Post("/{chat_id}/summary", chatHandler.HandleChatSummaryWebhook)
+
+	r.Group(func(r chi.Router) {
+		r.Use(auth.OptionalPubKeyContext)
+
+		r.Get("/shared/{share_token}", chatHandler.GetSharedChat)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(auth.PubKeyContextSuperAdmin)
+
+		r.Get("/sse/clients", chatHandler.GetSSEClients)
+	})
 
 	r.Group(func(r chi.Router) {
 		r.Use(auth.CombinedAuthContext)
@@ -28,6 +41,15 @@ func ChatRoutes() chi.Router {
 		r.Get("/history/{uuid}", chatHandler.GetChatHistory)
 		r.Post("/send/build", chatHandler.SendBuildMessage)
 		r.Post("/send/action", chatHandler.SendActionMessage)
+		r.Post("/{chat_id}/messages/{message_id}/edit", chatHandler.EditChatMessage)
+		r.Put("/{chat_id}/messages/{message_id}/pin", chatHandler.PinChatMessage)
+		r.Get("/{chat_id}/branches", chatHandler.GetChatBranches)
+		r.Put("/{chat_id}/branches/{branch_id}/activate", chatHandler.ActivateChatBranch)
+		r.Post("/{chat_id}/branches/{branch_id}/replay", chatHandler.ReplayChatBranch)
+		r.Get("/{chat_id}/export", chatHandler.ExportChat)
+		r.Post("/{chat_id}/share", chatHandler.CreateChatShareLink)
+		r.Get("/{chat_id}/share", chatHandler.GetChatShareLinks)
+		r.Delete("/{chat_id}/share/{link_id}", chatHandler.RevokeChatShareLink)
 
 		r.Post("/upload", chatHandler.UploadFile)
 		r.Get("/file/{id}", chatHandler.GetFile)
@@ -39,6 +61,7 @@ func ChatRoutes() chi.Router {
 		r.Get("/artefacts/{artifactId}", chatHandler.GetArtefactByID)
 		r.Get("/artefacts/message/{messageId}", chatHandler.GetArtefactsByMessageID)
 		r.Put("/artefacts/{artifactId}", chatHandler.UpdateArtefact)
+		r.Put("/artefacts/{artifactId}/pin", chatHandler.PinArtefact)
 		r.Delete("/artefacts/{artifactId}", chatHandler.DeleteArtefactByID)
 		r.Delete("/artefacts/chat/{chatId}", chatHandler.DeleteAllArtefactsByChatID)
 
@@ -51,6 +74,7 @@ func ChatRoutes() chi.Router {
 		r.Post("/sse", chatHandler.StartSSEClient)
 		r.Get("/sse/all/{chat_id}", chatHandler.GetAllSSEMessagesByChatID)
 		r.Post("/sse/maintenance", chatHandler.SSEMaintenance)
+		r.Get("/{chat_id}/stream", chatHandler.StreamChat)
 
 		r.Get("/status/{chat_id}", chatHandler.GetAllChatStatus)
 		r.Get("/status/{chat_id}/latest", chatHandler.GetLatestChatStatus)
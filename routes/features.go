@@ -27,6 +27,8 @@ func FeatureRoutes() chi.Router {
 		// Old route for to getting features for workspace uuid
 		r.Get("/forworkspace/{workspace_uuid}", featureHandlers.GetFeaturesByWorkspaceUuid)
 		r.Get("/workspace/count/{uuid}", featureHandlers.GetWorkspaceFeaturesCount)
+		r.Get("/workspace/{workspace_uuid}/roadmap", featureHandlers.GetWorkspaceRoadmap)
+		r.Post("/workspace/{workspace_uuid}/markdown", featureHandlers.ImportFeatureMarkdown)
 		r.Delete("/{uuid}", featureHandlers.DeleteFeature)
 
 		r.Post("/phase", featureHandlers.CreateOrEditFeaturePhase)
@@ -39,6 +41,13 @@ func FeatureRoutes() chi.Router {
 		r.Get("/{feature_uuid}/story", featureHandlers.GetStoriesByFeatureUuid)
 		r.Get("/{feature_uuid}/story/{story_uuid}", featureHandlers.GetStoryByUuid)
 		r.Delete("/{feature_uuid}/story/{story_uuid}", featureHandlers.DeleteStory)
+		r.Get("/{feature_uuid}/criteria/{item_type}/{item_key}", featureHandlers.GetCriterionLinks)
+		r.Put("/{feature_uuid}/criteria/{item_type}/{item_key}", featureHandlers.SetCriterionLinks)
+		r.Get("/{feature_uuid}/traceability", featureHandlers.GetFeatureTraceability)
+		r.Get("/{feature_uuid}/markdown", featureHandlers.ExportFeatureMarkdown)
+		r.Get("/{feature_uuid}/revisions", featureHandlers.GetFeatureRevisions)
+		r.Get("/{feature_uuid}/revisions/{field}/diff", featureHandlers.DiffFeatureRevisions)
+		r.Post("/{feature_uuid}/revisions/{field}/{version}/restore", featureHandlers.RestoreFeatureRevision)
 		r.Get("/{feature_uuid}/phase/{phase_uuid}/bounty", featureHandlers.GetBountiesByFeatureAndPhaseUuid)
 		r.Get("/{feature_uuid}/phase/{phase_uuid}/bounty/count", featureHandlers.GetBountiesCountByFeatureAndPhaseUuid)
 		r.Get("/{feature_uuid}/quick-bounties", featureHandlers.GetQuickBounties)
@@ -62,6 +62,7 @@ func NewRouter() *http.Server {
 	r.Mount("/activities", ActivityRoutes())
 	r.Mount("/skill", SkillRoutes())
 	r.Mount("/codespace", CodeSpaceRoutes())
+	r.Mount("/webhooks", WebhookRoutes())
 	r.Get("/docs/*", httpSwagger.WrapHandler)
 
 	r.Group(func(r chi.Router) {
@@ -87,6 +88,7 @@ func NewRouter() *http.Server {
 		r.Get("/save/{key}", db.PollSave)
 		r.Get("/migrate_bounties", handlers.MigrateBounties)
 		r.Get("/websocket", handlers.HandleWebSocket)
+		r.Get("/blobs/*", handlers.ServeBlob)
 	})
 
 	r.Group(func(r chi.Router) {
@@ -338,10 +340,11 @@ func initChi() *chi.Mux {
 	// Disabled for now because crashing
 	//r.Use(internalServerErrorHandler)
 	r.Use(customMiddleware.FeatureFlag(db.DB))
+	r.Use(customMiddleware.RateLimitFromConfig())
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-User", "authorization", "x-jwt", "Referer", "User-Agent", "x-session-id"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-User", "authorization", "x-jwt", "Referer", "User-Agent", "x-session-id", "Last-Event-ID"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
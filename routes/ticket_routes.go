@@ -23,13 +23,18 @@ func TicketRoutes() chi.Router {
 		r.Use(auth.CombinedAuthContext)
 
 		r.Get("/feature/{feature_uuid}/phase/{phase_uuid}", ticketHandler.GetTicketsByPhaseUUID)
+		r.Get("/feature/{feature_uuid}/phase/{phase_uuid}/graph", ticketHandler.GetPhaseTicketGraph)
 		r.Post("/review/send", ticketHandler.PostTicketDataToStakwork)
 		r.Post("/{uuid}", ticketHandler.UpdateTicket)
 		r.Post("/{ticket_group}/sequence", ticketHandler.UpdateTicketSequence)
 		r.Post("/{ticket_uuid}/bounty", ticketHandler.TicketToBounty)
 		r.Post("/bounty/bulk", ticketHandler.TicketsToBounties)
+		r.Post("/bulk", ticketHandler.BulkUpdateTickets)
 		r.Delete("/{uuid}", ticketHandler.DeleteTicket)
 		r.Get("/group/{group_uuid}", ticketHandler.GetTicketsByGroup)
+		r.Get("/group/{group_uuid}/versions", ticketHandler.GetTicketVersions)
+		r.Get("/group/{group_uuid}/versions/diff", ticketHandler.DiffTicketVersions)
+		r.Post("/group/{group_uuid}/versions/{version}/revert", ticketHandler.RevertTicketVersion)
 
 		r.Post("/workspace/{workspace_uuid}/draft", ticketHandler.CreateWorkspaceDraftTicket)
 		r.Get("/workspace/{workspace_uuid}/draft/{uuid}", ticketHandler.GetWorkspaceDraftTicket)
@@ -40,6 +45,13 @@ func TicketRoutes() chi.Router {
 		r.Post("/plan/send", ticketHandler.SendTicketPlanToStakwork)
 		r.Get("/plan/{uuid}", ticketHandler.GetTicketPlan)
 		r.Delete("/plan/{uuid}", ticketHandler.DeleteTicketPlan)
+		r.Put("/plan/{uuid}", ticketHandler.UpdateTicketPlan)
+		r.Post("/plan/{uuid}/submit", ticketHandler.SubmitTicketPlan)
+		r.Post("/plan/{uuid}/review", ticketHandler.ReviewTicketPlan)
+		r.Post("/plan/{uuid}/reopen", ticketHandler.ReopenTicketPlan)
+		r.Get("/plan/{uuid}/revisions", ticketHandler.GetTicketPlanRevisions)
+		r.Get("/plan/{uuid}/reviews", ticketHandler.GetTicketPlanReviews)
+		r.Get("/plan/{uuid}/compare", ticketHandler.CompareTicketPlan)
 		r.Get("/plan/feature/{feature_uuid}", ticketHandler.GetTicketPlansByFeature)
 		r.Get("/plan/phase/{phase_uuid}", ticketHandler.GetTicketPlansByPhase)
 		r.Get("/plan/workspace/{workspace_uuid}", ticketHandler.GetTicketPlansByWorkspace)
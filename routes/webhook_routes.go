@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/stakwork/sphinx-tribes/auth"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/handlers"
+)
+
+func WebhookRoutes() chi.Router {
+	r := chi.NewRouter()
+	webhookHandler := handlers.NewWebhookHandler(db.DB)
+
+	r.Group(func(r chi.Router) {
+		r.Use(auth.PubKeyContextSuperAdmin)
+
+		r.Get("/deliveries", webhookHandler.GetWebhookDeliveries)
+		r.Get("/deliveries/{id}", webhookHandler.GetWebhookDelivery)
+		r.Post("/deliveries/{id}/replay", webhookHandler.ReplayWebhookDelivery)
+	})
+
+	return r
+}
@@ -44,6 +44,9 @@ func WorkspaceRoutes() chi.Router {
 		r.Post("/mission", workspaceHandlers.UpdateWorkspace)
 		r.Post("/tactics", workspaceHandlers.UpdateWorkspace)
 		r.Post("/schematicurl", workspaceHandlers.UpdateWorkspace)
+		r.Get("/{workspace_uuid}/revisions", workspaceHandlers.GetWorkspaceRevisions)
+		r.Get("/{workspace_uuid}/revisions/{field}/diff", workspaceHandlers.DiffWorkspaceRevisions)
+		r.Post("/{workspace_uuid}/revisions/{field}/{version}/restore", workspaceHandlers.RestoreWorkspaceRevision)
 		r.Put("/{workspace_uuid}/payments", handlers.UpdateWorkspacePendingPayments)
 
 		r.Post("/repositories", workspaceHandlers.CreateOrEditWorkspaceRepository)
@@ -63,6 +66,32 @@ func WorkspaceRoutes() chi.Router {
 		r.Get("/codegraph/{uuid}", workspaceHandlers.GetWorkspaceCodeGraphByUUID)
 		r.Get("/{workspace_uuid}/codegraph", workspaceHandlers.GetCodeGraphByWorkspaceUuid)
 		r.Delete("/{workspace_uuid}/codegraph/{uuid}", workspaceHandlers.DeleteWorkspaceCodeGraph)
+
+		r.Post("/{workspace_uuid}/ownership/transfer", workspaceHandlers.InitiateOwnershipTransfer)
+		r.Get("/{workspace_uuid}/ownership/transfers", workspaceHandlers.GetOwnershipTransfers)
+		r.Get("/{workspace_uuid}/ownership/audit", workspaceHandlers.GetWorkspaceOwnershipAudit)
+		r.Get("/ownership/transfers/incoming", workspaceHandlers.GetIncomingOwnershipTransfers)
+		r.Post("/ownership/transfers/{id}/accept", workspaceHandlers.AcceptOwnershipTransfer)
+		r.Post("/ownership/transfers/{id}/reject", workspaceHandlers.RejectOwnershipTransfer)
+		r.Post("/ownership/transfers/{id}/cancel", workspaceHandlers.CancelOwnershipTransfer)
+		r.Post("/{workspace_uuid}/coowners", workspaceHandlers.AddWorkspaceCoOwner)
+		r.Delete("/{workspace_uuid}/coowners/{pubkey}", workspaceHandlers.RemoveWorkspaceCoOwner)
+
+		r.Get("/{workspace_uuid}/trash", workspaceHandlers.GetWorkspaceTrash)
+		r.Post("/{workspace_uuid}/trash/{id}/restore", workspaceHandlers.RestoreTrashItem)
+
+		r.Get("/{workspace_uuid}/export", workspaceHandlers.ExportWorkspace)
+		r.Post("/import", workspaceHandlers.ImportWorkspace)
+
+		r.Get("/{workspace_uuid}/ticket-workflow", workspaceHandlers.GetTicketWorkflow)
+		r.Put("/{workspace_uuid}/ticket-workflow", workspaceHandlers.SetTicketWorkflow)
+
+		r.Get("/{workspace_uuid}/board", workspaceHandlers.GetWorkspaceBoard)
+		r.Put("/{workspace_uuid}/board/limits", workspaceHandlers.SetBoardLimits)
+		r.Post("/{workspace_uuid}/board/move", workspaceHandlers.MoveBoardCard)
+		r.Post("/{workspace_uuid}/board/subscribe", workspaceHandlers.SubscribeBoard)
+
+		r.Get("/{workspace_uuid}/search", workspaceHandlers.SearchWorkspace)
 	})
 	return r
 }
@@ -0,0 +1,112 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/stakwork/sphinx-tribes/db"
+)
+
+// Event is one server sent event. Events with an ID come from SSEMessageLog
+// and can be resumed with Last-Event-ID, events without one are live only.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// ChatBroker fans out chat events to the browsers streaming a chat
+var ChatBroker = NewBroker()
+
+type Broker struct {
+	subscribers map[string]map[chan Event]struct{}
+	mutex       *sync.RWMutex
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan Event]struct{}),
+		mutex:       &sync.RWMutex{},
+	}
+}
+
+// Subscribe returns a channel of the events published for a chat and a
+// function that ends the subscription
+func (b *Broker) Subscribe(chatID string) (<-chan Event, func()) {
+	ch := make(chan Event, 64)
+
+	b.mutex.Lock()
+	if b.subscribers[chatID] == nil {
+		b.subscribers[chatID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[chatID][ch] = struct{}{}
+	b.mutex.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers[chatID], ch)
+			if len(b.subscribers[chatID]) == 0 {
+				delete(b.subscribers, chatID)
+			}
+			b.mutex.Unlock()
+		})
+	}
+}
+
+// Publish sends an event to every subscriber of a chat. Slow subscribers miss
+// live events rather than block the publisher, logged events are picked up
+// again from the database.
+func (b *Broker) Publish(chatID string, event Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for ch := range b.subscribers[chatID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *Broker) SubscriberCount(chatID string) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subscribers[chatID])
+}
+
+// WriteEvent writes an event in the text/event-stream format
+func WriteEvent(w io.Writer, event Event) error {
+	var sb strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&sb, "id: %s\n", event.ID)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&sb, "event: %s\n", event.Event)
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// EventFromLog turns a stored remote event into an event for the browser, the
+// log ID is the event ID so a reconnecting browser resumes after it
+func EventFromLog(log db.SSEMessageLog) Event {
+	name := "event"
+	if eventType, ok := log.Event["event_type"].(string); ok && eventType != "" {
+		name = eventType
+	}
+	data, err := json.Marshal(log.Event)
+	if err != nil {
+		data = []byte("{}")
+	}
+	return Event{ID: log.ID.String(), Event: name, Data: string(data)}
+}
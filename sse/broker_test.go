@@ -0,0 +1,38 @@
+package sse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+	events, unsubscribe := broker.Subscribe("chat")
+
+	broker.Publish("chat", Event{Event: "chat_status", Data: "{}"})
+	broker.Publish("other", Event{Event: "chat_status", Data: "{}"})
+	assert.Equal(t, Event{Event: "chat_status", Data: "{}"}, <-events)
+	assert.Len(t, events, 0)
+
+	unsubscribe()
+	unsubscribe()
+	assert.Equal(t, 0, broker.SubscriberCount("chat"))
+	broker.Publish("chat", Event{Data: "dropped"})
+}
+
+func TestWriteEvent(t *testing.T) {
+	var sb strings.Builder
+	err := WriteEvent(&sb, Event{ID: "1", Event: "token", Data: "first\r\nsecond"})
+	assert.NoError(t, err)
+	assert.Equal(t, "id: 1\nevent: token\ndata: first\ndata: second\n\n", sb.String())
+
+	id := uuid.New()
+	event := EventFromLog(db.SSEMessageLog{ID: id, Event: db.PropertyMap{"event_type": "tool", "raw": "x"}})
+	assert.Equal(t, id.String(), event.ID)
+	assert.Equal(t, "tool", event.Event)
+	assert.JSONEq(t, `{"event_type":"tool","raw":"x"}`, event.Data)
+}
@@ -2,17 +2,24 @@ package sse
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/stakwork/sphinx-tribes/config"
 	"github.com/stakwork/sphinx-tribes/db"
 	"github.com/stakwork/sphinx-tribes/logger"
 )
 
+var ErrTooManyClients = errors.New("too many SSE clients are running")
+
+var errClientIdle = errors.New("no events received within the idle timeout")
+
 var ClientRegistry = &Registry{
 	clients: make(map[string]*Client),
 	mutex:   &sync.RWMutex{},
@@ -27,13 +34,25 @@ func GenerateClientKey(chatID, sseURL string) string {
 	return fmt.Sprintf("%s:%s", chatID, sseURL)
 }
 
-func (r *Registry) Register(client *Client) {
+// Register adds a client, replacing and stopping the one running for the same
+// chat and URL. New clients past config.MaxSSEClients running ones are refused.
+func (r *Registry) Register(client *Client) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	key := GenerateClientKey(client.ChatID, client.URL)
+
+	existing, exists := r.clients[key]
+	if !exists && r.running() >= config.MaxSSEClients {
+		return ErrTooManyClients
+	}
+	if exists && existing != client {
+		existing.Stop()
+	}
 	r.clients[key] = client
+	return nil
 }
 
+// Unregister stops a client for good, it won't be resumed after a restart
 func (r *Registry) Unregister(sseURL, chatID string) bool {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -44,6 +63,7 @@ func (r *Registry) Unregister(sseURL, chatID string) bool {
 	if client, exists := r.clients[key]; exists {
 		client.Stop()
 		delete(r.clients, key)
+		client.setStatus(db.SSEClientStopped, "")
 		return true
 	}
 
@@ -55,15 +75,29 @@ func (r *Registry) Unregister(sseURL, chatID string) bool {
 	return false
 }
 
+// running counts the clients that weren't stopped, clients whose stream has
+// finished leave the registry on their own
+func (r *Registry) running() int {
+	count := 0
+	for _, client := range r.clients {
+		if !client.stopped() {
+			count++
+		}
+	}
+	return count
+}
+
 type Client struct {
 	URL           string
 	ChatID        string
 	WebhookURL    string
 	LastEventID   string
 	RetryInterval time.Duration
+	IdleTimeout   time.Duration
 	Client        *http.Client
 	DB            db.Database
 	stopChan      chan struct{}
+	stopOnce      sync.Once
 	firstFailTime time.Time
 }
 
@@ -73,6 +107,7 @@ func NewClient(sseURL string, chatID string, webhookURL string, database db.Data
 		ChatID:        chatID,
 		WebhookURL:    webhookURL,
 		RetryInterval: 3 * time.Second,
+		IdleTimeout:   time.Duration(config.SSEClientIdleMinutes) * time.Minute,
 		Client: &http.Client{
 			Timeout: 0,
 		},
@@ -81,15 +116,19 @@ func NewClient(sseURL string, chatID string, webhookURL string, database db.Data
 	}
 }
 
-func (c *Client) Start() {
-
-	ClientRegistry.Register(c)
+// Start registers the client, stores it as active so it is resumed after a
+// restart and follows the stream in the background. The client is finished
+// once the stream ends or stays idle for IdleTimeout.
+func (c *Client) Start() error {
+	if err := ClientRegistry.Register(c); err != nil {
+		return err
+	}
+	if _, err := c.DB.SaveSSEClient(c.ChatID, c.URL, c.WebhookURL, c.LastEventID); err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to store SSE client: %v", c.ChatID, err)
+	}
 
 	go func() {
-		defer func() {
-
-			ClientRegistry.Unregister(c.URL, c.ChatID)
-		}()
+		defer ClientRegistry.remove(c)
 
 		for {
 			select {
@@ -98,11 +137,21 @@ func (c *Client) Start() {
 				return
 			default:
 				err := c.connect()
+				if c.stopped() {
+					logger.Log.Info("[ChatID: %s] SSE client stopped", c.ChatID)
+					return
+				}
+				if errors.Is(err, errClientIdle) {
+					logger.Log.Info("[ChatID: %s] No events for %v, finishing client", c.ChatID, c.IdleTimeout)
+					c.setStatus(db.SSEClientFinished, "")
+					return
+				}
 				if err != nil {
 					if c.firstFailTime.IsZero() {
 						c.firstFailTime = time.Now()
 					} else if time.Since(c.firstFailTime) > 60*time.Minute {
 						logger.Log.Error("[ChatID: %s] Server unreachable for 60 minutes, stopping client", c.ChatID)
+						c.setStatus(db.SSEClientFailed, err.Error())
 						return
 					}
 
@@ -111,20 +160,58 @@ func (c *Client) Start() {
 					continue
 				}
 
-				c.firstFailTime = time.Time{}
-
-				time.Sleep(c.RetryInterval)
+				logger.Log.Info("[ChatID: %s] SSE stream completed", c.ChatID)
+				c.setStatus(db.SSEClientFinished, "")
+				return
 			}
 		}
 	}()
+	return nil
 }
 
 func (c *Client) Stop() {
-	close(c.stopChan)
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
+
+func (c *Client) stopped() bool {
+	select {
+	case <-c.stopChan:
+		return true
+	default:
+		return false
+	}
 }
 
+func (c *Client) setStatus(status db.SSEClientStatus, lastError string) {
+	if err := c.DB.UpdateSSEClientStatus(c.ChatID, c.URL, status, lastError); err != nil {
+		logger.Log.Error("[ChatID: %s] Failed to update SSE client status: %v", c.ChatID, err)
+	}
+}
+
+// connect follows the stream until it ends, the client is stopped or no
+// event arrives within IdleTimeout
 func (c *Client) connect() error {
-	req, err := http.NewRequest("GET", c.URL, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	idled := make(chan struct{})
+	var idleOnce sync.Once
+	idle := time.AfterFunc(c.IdleTimeout, func() {
+		idleOnce.Do(func() { close(idled) })
+		cancel()
+	})
+	defer idle.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.URL, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -152,10 +239,16 @@ func (c *Client) connect() error {
 	}
 
 	logger.Log.Info("[ChatID: %s] Connected successfully, waiting for events...", c.ChatID, c.URL)
-	return c.processEvents(resp)
+	err = c.processEvents(resp, func() { idle.Reset(c.IdleTimeout) })
+	select {
+	case <-idled:
+		return errClientIdle
+	default:
+		return err
+	}
 }
 
-func (c *Client) processEvents(resp *http.Response) error {
+func (c *Client) processEvents(resp *http.Response, received func()) error {
 	scanner := bufio.NewScanner(resp.Body)
 	eventData := map[string]string{
 		"id":    "",
@@ -172,6 +265,7 @@ func (c *Client) processEvents(resp *http.Response) error {
 
 			if line == "" {
 				if eventData["data"] != "" {
+					received()
 
 					err := c.storeEvent(eventData)
 					if err != nil {
@@ -180,6 +274,9 @@ func (c *Client) processEvents(resp *http.Response) error {
 
 					if eventData["id"] != "" {
 						c.LastEventID = eventData["id"]
+						if err := c.DB.UpdateSSEClientLastEventID(c.ChatID, c.URL, c.LastEventID); err != nil {
+							logger.Log.Error("[ChatID: %s] Error storing last event ID: %v", c.ChatID, err)
+						}
 					}
 
 					eventData = map[string]string{
@@ -251,6 +348,7 @@ func (c *Client) storeEvent(eventData map[string]string) error {
 	}
 
 	logger.Log.Info("[ChatID: %s] Stored SSE event with ID: %s", c.ChatID, messageLog.ID)
+	ChatBroker.Publish(c.ChatID, EventFromLog(*messageLog))
 	return nil
 }
 
@@ -265,6 +363,8 @@ func (r *Registry) HasClient(sseURL, chatID string) bool {
 	return exists
 }
 
+// StopAllClients stops every client for good, none of them are resumed after
+// a restart
 func (r *Registry) StopAllClients() int {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -273,7 +373,56 @@ func (r *Registry) StopAllClients() int {
 	for key, client := range r.clients {
 		client.Stop()
 		delete(r.clients, key)
+		client.setStatus(db.SSEClientStopped, "")
 	}
 
 	return count
 }
+
+// remove drops a client whose stream ended unless another client has taken
+// its place
+func (r *Registry) remove(client *Client) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := GenerateClientKey(client.ChatID, client.URL)
+	if r.clients[key] == client {
+		delete(r.clients, key)
+	}
+}
+
+// Keys returns the keys of the running clients
+func (r *Registry) Keys() map[string]bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make(map[string]bool, len(r.clients))
+	for key := range r.clients {
+		keys[key] = true
+	}
+	return keys
+}
+
+// ResumeClients starts the clients that were active when the server stopped,
+// each from the last event it received
+func ResumeClients(database db.Database) int {
+	stored, err := database.GetSSEClients(db.SSEClientActive)
+	if err != nil {
+		logger.Log.Error("Failed to load SSE clients: %v", err)
+		return 0
+	}
+
+	resumed := 0
+	for _, s := range stored {
+		client := NewClient(s.URL, s.ChatID, s.WebhookURL, database)
+		client.LastEventID = s.LastEventID
+		if err := client.Start(); err != nil {
+			logger.Log.Error("[ChatID: %s] Failed to resume SSE client for %s: %v", s.ChatID, s.URL, err)
+			continue
+		}
+		resumed++
+	}
+
+	logger.Log.Info("Resumed %d of %d SSE clients", resumed, len(stored))
+	return resumed
+}
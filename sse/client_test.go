@@ -0,0 +1,153 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryLimit(t *testing.T) {
+	limit := config.MaxSSEClients
+	defer func() { config.MaxSSEClients = limit }()
+	config.MaxSSEClients = 1
+
+	mockDb := mocks.NewDatabase(t)
+	registry := &Registry{clients: make(map[string]*Client), mutex: &sync.RWMutex{}}
+
+	first := NewClient("http://sse/1", "chat", "http://hook", mockDb)
+	require.NoError(t, registry.Register(first))
+	assert.ErrorIs(t, registry.Register(NewClient("http://sse/2", "chat", "http://hook", mockDb)), ErrTooManyClients)
+
+	replacement := NewClient("http://sse/1", "chat", "http://hook", mockDb)
+	require.NoError(t, registry.Register(replacement))
+	assert.True(t, isStopped(first))
+	assert.Equal(t, map[string]bool{GenerateClientKey("chat", "http://sse/1"): true}, registry.Keys())
+
+	registry.remove(first)
+	assert.Len(t, registry.Keys(), 1)
+
+	mockDb.On("UpdateSSEClientStatus", "chat", "http://sse/1", db.SSEClientStopped, "").Return(nil).Once()
+	assert.True(t, registry.Unregister("http://sse/1", "chat"))
+	assert.True(t, isStopped(replacement))
+	replacement.Stop()
+	assert.Empty(t, registry.Keys())
+
+	stopped := NewClient("http://sse/3", "chat", "http://hook", mockDb)
+	require.NoError(t, registry.Register(stopped))
+	stopped.Stop()
+	assert.NoError(t, registry.Register(NewClient("http://sse/4", "chat", "http://hook", mockDb)))
+}
+
+func TestClientFinishes(t *testing.T) {
+	t.Run("when the stream completes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: done\n\n")
+		}))
+		defer server.Close()
+
+		mockDb := mocks.NewDatabase(t)
+		mockDb.On("SaveSSEClient", "completed-chat", server.URL, "http://hook", "").Return(&db.SSEClient{}, nil)
+		mockDb.On("CreateSSEMessageLog", mock.Anything, "completed-chat", server.URL, "http://hook").
+			Return(&db.SSEMessageLog{ID: uuid.New(), Event: db.PropertyMap{"raw": "done"}}, nil)
+		finished := make(chan struct{})
+		mockDb.On("UpdateSSEClientStatus", "completed-chat", server.URL, db.SSEClientFinished, "").Return(nil).Once().
+			Run(func(mock.Arguments) { close(finished) })
+
+		require.NoError(t, NewClient(server.URL, "completed-chat", "http://hook", mockDb).Start())
+		waitFinished(t, finished)
+		assert.Eventually(t, func() bool {
+			return !ClientRegistry.HasClient(server.URL, "completed-chat")
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("when no event arrives within the idle timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		mockDb := mocks.NewDatabase(t)
+		mockDb.On("SaveSSEClient", "idle-chat", server.URL, "http://hook", "").Return(&db.SSEClient{}, nil)
+		finished := make(chan struct{})
+		mockDb.On("UpdateSSEClientStatus", "idle-chat", server.URL, db.SSEClientFinished, "").Return(nil).Once().
+			Run(func(mock.Arguments) { close(finished) })
+
+		client := NewClient(server.URL, "idle-chat", "http://hook", mockDb)
+		client.IdleTimeout = 50 * time.Millisecond
+		require.NoError(t, client.Start())
+		waitFinished(t, finished)
+	})
+}
+
+func waitFinished(t *testing.T, finished chan struct{}) {
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("client was not finished")
+	}
+}
+
+func TestResumeClients(t *testing.T) {
+	done := make(chan struct{})
+	lastEventIDs := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case lastEventIDs <- r.Header.Get("Last-Event-ID"):
+		default:
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 7\nevent: tool\ndata: {\"step\":1}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	mockDb := mocks.NewDatabase(t)
+	mockDb.On("GetSSEClients", db.SSEClientActive).Return([]db.SSEClient{
+		{ChatID: "resumed-chat", URL: server.URL, WebhookURL: "http://hook", LastEventID: "6", Status: db.SSEClientActive},
+	}, nil)
+	mockDb.On("SaveSSEClient", "resumed-chat", server.URL, "http://hook", "6").Return(&db.SSEClient{}, nil)
+	mockDb.On("CreateSSEMessageLog", mock.Anything, "resumed-chat", server.URL, "http://hook").
+		Return(&db.SSEMessageLog{ID: uuid.New(), Event: db.PropertyMap{"event_type": "tool"}}, nil)
+	stored := make(chan struct{})
+	mockDb.On("UpdateSSEClientLastEventID", "resumed-chat", server.URL, "7").Return(nil).Once().
+		Run(func(mock.Arguments) { close(stored) })
+
+	assert.Equal(t, 1, ResumeClients(mockDb))
+	assert.Equal(t, "6", <-lastEventIDs)
+	select {
+	case <-stored:
+	case <-time.After(5 * time.Second):
+		t.Fatal("last event ID was not stored")
+	}
+
+	mockDb.On("UpdateSSEClientStatus", "resumed-chat", server.URL, db.SSEClientStopped, "").Return(nil).Once()
+	assert.True(t, ClientRegistry.Unregister(server.URL, "resumed-chat"))
+}
+
+func isStopped(client *Client) bool {
+	select {
+	case <-client.stopChan:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBlobNotFound    = errors.New("blob not found")
+	ErrBlobUnsupported = errors.New("blob store does not support this operation")
+	ErrBlobKey         = errors.New("invalid blob key")
+)
+
+// Store kinds accepted by the BLOB_STORE and EXPORT_BLOB_STORE settings
+const (
+	LocalKind = "local"
+	S3Kind    = "s3"
+	MemeKind  = "meme"
+)
+
+// Blob is a stored file. Key is where the store keeps it, which the meme
+// server picks itself, and URL is where it can be fetched from.
+type Blob struct {
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// BlobStore keeps uploaded and generated files
+type BlobStore interface {
+	Name() string
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (Blob, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that is readable for the given time, stores
+	// without access control return the plain URL
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// CleanKey checks that a key is a relative slash separated path that stays
+// inside the store
+func CleanKey(key string) (string, error) {
+	key = strings.TrimSpace(strings.ReplaceAll(key, "\\", "/"))
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrBlobKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %q", ErrBlobKey, key)
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." {
+		return "", fmt.Errorf("%w: %q", ErrBlobKey, key)
+	}
+	return cleaned, nil
+}
+
+// joinURL appends an escaped key to a base URL
+func joinURL(base string, key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.Join(parts, "/")
+}
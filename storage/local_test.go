@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir(), "https://people.sphinx.chat/blobs/")
+
+	t.Run("Should put, get and delete a blob", func(t *testing.T) {
+		blob, err := store.Put(ctx, "chat/a b.txt", strings.NewReader("hello"), 5, "text/plain")
+		require.NoError(t, err)
+		assert.Equal(t, Blob{Key: "chat/a b.txt", URL: "https://people.sphinx.chat/blobs/chat/a%20b.txt", Size: 5, ContentType: "text/plain"}, blob)
+
+		body, err := store.Get(ctx, blob.Key)
+		require.NoError(t, err)
+		content, _ := io.ReadAll(body)
+		body.Close()
+		assert.Equal(t, "hello", string(content))
+
+		signed, err := store.SignedURL(ctx, blob.Key, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, blob.URL, signed)
+
+		require.NoError(t, store.Delete(ctx, blob.Key))
+		_, err = store.Get(ctx, blob.Key)
+		assert.True(t, errors.Is(err, ErrBlobNotFound))
+	})
+
+	t.Run("Should reject keys outside the store", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../secret", "chat/../../secret", "."} {
+			_, err := store.Put(ctx, key, strings.NewReader("x"), 1, "")
+			assert.True(t, errors.Is(err, ErrBlobKey), key)
+		}
+	})
+}
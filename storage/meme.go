@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strings"
+	"time"
+)
+
+// MemeStore uploads blobs to a sphinx meme server. Token returns a bearer
+// token for the server, which is signed by the relay or the v2 bot.
+type MemeStore struct {
+	BaseURL string
+	Client  *http.Client
+	Token   func() (string, error)
+}
+
+func NewMemeStore(baseURL string, client *http.Client, token func() (string, error)) *MemeStore {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &MemeStore{BaseURL: strings.TrimRight(baseURL, "/"), Client: client, Token: token}
+}
+
+func (s *MemeStore) Name() string {
+	return MemeKind
+}
+
+func (s *MemeStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (Blob, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return Blob{}, err
+	}
+	token, err := s.Token()
+	if err != nil {
+		return Blob{}, fmt.Errorf("failed to get meme token: %w", err)
+	}
+
+	// stream the multipart body instead of buffering the whole file
+	reader, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.ReplaceAll(path.Base(key), `"`, "")))
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		} else {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		part, err := form.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(part, body)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+"/public", reader)
+	if err != nil {
+		reader.Close()
+		return Blob{}, err
+	}
+	req.Header.Set("Authorization", "BEARER "+token)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		reader.Close()
+		return Blob{}, fmt.Errorf("meme upload failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return Blob{}, fmt.Errorf("meme upload failed with status %d", res.StatusCode)
+	}
+	var uploaded struct {
+		Muid string `json:"muid"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&uploaded); err != nil || uploaded.Muid == "" {
+		return Blob{}, fmt.Errorf("meme upload returned no muid: %v", err)
+	}
+
+	return Blob{Key: uploaded.Muid, URL: s.BaseURL + "/public/" + uploaded.Muid, Size: size, ContentType: contentType}, nil
+}
+
+func (s *MemeStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, joinURL(s.BaseURL+"/public", key), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("meme download failed: %w", err)
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		res.Body.Close()
+		return nil, fmt.Errorf("meme download failed with status %d", res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+// Delete is not offered by the meme server
+func (s *MemeStore) Delete(ctx context.Context, key string) error {
+	return ErrBlobUnsupported
+}
+
+func (s *MemeStore) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return joinURL(s.BaseURL+"/public", key), nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Store keeps blobs in a bucket of S3 or an S3 compatible server. Keys are
+// stored under Prefix and BaseURL is the public address of the bucket.
+type S3Store struct {
+	Client  *s3.Client
+	Presign *s3.PresignClient
+	Bucket  string
+	Prefix  string
+	BaseURL string
+}
+
+func NewS3Store(client *s3.Client, bucket string, prefix string, baseURL string) *S3Store {
+	return &S3Store{
+		Client:  client,
+		Presign: s3.NewPresignClient(client),
+		Bucket:  bucket,
+		Prefix:  prefix,
+		BaseURL: baseURL,
+	}
+}
+
+func (s *S3Store) Name() string {
+	return S3Kind
+}
+
+func (s *S3Store) objectKey(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if s.Prefix != "" {
+		key = path.Join(s.Prefix, key)
+	}
+	return key, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (Blob, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return Blob{}, err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(objectKey),
+		Body:   body,
+	}
+	if size >= 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if _, err := s.Client.PutObject(ctx, input); err != nil {
+		return Blob{}, fmt.Errorf("failed to put object: %w", err)
+	}
+
+	return Blob{Key: objectKey, URL: joinURL(s.BaseURL, objectKey), Size: size, ContentType: contentType}, nil
+}
+
+// keyOf accepts both the key given to Put and the prefixed key it returned
+func (s *S3Store) keyOf(key string) (string, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if s.Prefix != "" && (cleaned == s.Prefix || len(cleaned) > len(s.Prefix) && cleaned[:len(s.Prefix)+1] == s.Prefix+"/") {
+		return cleaned, nil
+	}
+	return s.objectKey(cleaned)
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectKey, err := s.keyOf(key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		var missing *types.NoSuchKey
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	return out.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	objectKey, err := s.keyOf(key)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(objectKey),
+	}); err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Store) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	objectKey, err := s.keyOf(key)
+	if err != nil {
+		return "", err
+	}
+	presigned, err := s.Presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(s.Bucket),
+		Key:                        aws.String(objectKey),
+		ResponseContentDisposition: aws.String("attachment"),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object: %w", err)
+	}
+	return presigned.URL, nil
+}
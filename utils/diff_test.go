@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineDiff(t *testing.T) {
+	t.Run("Should mark inserted and deleted lines", func(t *testing.T) {
+		diff := LineDiff("one\ntwo\nthree", "one\nthree\nfour")
+		assert.Equal(t, []DiffLine{
+			{Op: DiffEqual, Text: "one"},
+			{Op: DiffDelete, Text: "two"},
+			{Op: DiffEqual, Text: "three"},
+			{Op: DiffInsert, Text: "four"},
+		}, diff)
+		assert.Equal(t, "  one\n- two\n  three\n+ four\n", UnifiedDiff(diff))
+	})
+
+	t.Run("Should handle empty texts", func(t *testing.T) {
+		assert.Equal(t, []DiffLine{}, LineDiff("", ""))
+		assert.Equal(t, []DiffLine{{Op: DiffInsert, Text: "new"}}, LineDiff("", "new"))
+		assert.Equal(t, []DiffLine{{Op: DiffDelete, Text: "old"}}, LineDiff("old", ""))
+	})
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	pdfPageWidth  = 612
+	pdfPageHeight = 792
+	pdfMargin     = 54
+	pdfFontSize   = 10
+	pdfLeading    = 13
+	// Courier is monospaced, each glyph is 0.6 of the font size wide
+	pdfLineChars = int((pdfPageWidth - 2*pdfMargin) / (pdfFontSize * 0.6))
+	pdfPageLines = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// TextPDF renders plain text as a PDF of wrapped Courier lines. Characters
+// outside of Latin-1 are replaced with a question mark.
+func TextPDF(title string, text string) []byte {
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		lines = append(lines, WrapPDFLine(strings.ReplaceAll(line, "\t", "    "), pdfLineChars)...)
+	}
+
+	pages := [][]string{}
+	for len(lines) > pdfPageLines {
+		pages = append(pages, lines[:pdfPageLines])
+		lines = lines[pdfPageLines:]
+	}
+	pages = append(pages, lines)
+
+	// objects 1 to 4 are the catalog, page tree, font and info, each page
+	// is followed by its content stream
+	objects := make([]string, 4+2*len(pages))
+	kids := []string{}
+	for i, page := range pages {
+		pageObj := 5 + 2*i
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", pdfString(line))
+		}
+		content.WriteString("ET")
+
+		objects[pageObj-1] = fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageObj+1)
+		objects[pageObj] = fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String())
+	}
+	objects[0] = "<< /Type /Catalog /Pages 2 0 R >>"
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	objects[2] = "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>"
+	objects[3] = fmt.Sprintf("<< /Title (%s) /Producer (sphinx-tribes) >>", pdfString(title))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// WrapPDFLine breaks a line into lines of at most width characters,
+// preferring to break at spaces
+func WrapPDFLine(line string, width int) []string {
+	wrapped := []string{}
+	for utf8.RuneCountInString(line) > width {
+		runes := []rune(line)
+		cut := width
+		for i := width; i > width/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		wrapped = append(wrapped, strings.TrimRight(string(runes[:cut]), " "))
+		line = strings.TrimLeft(string(runes[cut:]), " ")
+	}
+	return append(wrapped, line)
+}
+
+// pdfString escapes text for a PDF string literal in WinAnsi encoding
+func pdfString(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+			sb.WriteByte(' ')
+		case r < 0x7f:
+			sb.WriteRune(r)
+		case r <= 0xff:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapPDFLine(t *testing.T) {
+	assert.Equal(t, []string{"short"}, WrapPDFLine("short", 10))
+	assert.Equal(t, []string{"hello", "world"}, WrapPDFLine("hello world", 8))
+	assert.Equal(t, []string{"abcdefgh", "ij"}, WrapPDFLine("abcdefghij", 8))
+}
+
+func TestTextPDF(t *testing.T) {
+	text := strings.Repeat("line (with) \\ chars and ünïcode ✓\n", 120)
+	pdf := TextPDF("Chat", text)
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), `(line \(with\) \\ chars and \374n\357code ?) '`)
+	assert.Contains(t, string(pdf), "/Count 3")
+
+	// the xref offsets point at their objects
+	xref := bytes.Index(pdf, []byte("\nxref\n")) + 1
+	entries := strings.Split(string(pdf[xref:]), "\n")[3:]
+	for i, entry := range entries[:4] {
+		var offset int
+		fmt.Sscanf(entry, "%d", &offset)
+		assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+}
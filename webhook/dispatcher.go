@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/config"
+	"github.com/stakwork/sphinx-tribes/db"
+	"github.com/stakwork/sphinx-tribes/logger"
+)
+
+const (
+	SignatureHeader = "x-hub-signature-256"
+	EventHeader     = "x-webhook-event"
+	DeliveryHeader  = "x-webhook-delivery"
+
+	requestTimeout = 15 * time.Second
+	// a claimed delivery isn't due again before this, long enough for a
+	// whole retry batch to be sent
+	claimLease   = 5 * time.Minute
+	retryBatch   = 50
+	retryWorkers = 10
+	firstBackoff = 30 * time.Second
+	maxBackoff   = time.Hour
+)
+
+// Sign is the signature of a body in the x-hub-signature-256 format
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff is the wait after a failed attempt, doubling from thirty seconds up
+// to an hour
+func Backoff(attempt int) time.Duration {
+	wait := firstBackoff
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// ApplyAttempt is the state of a delivery after an attempt. A failed attempt
+// is retried after its backoff until maxAttempts since the last replay, then
+// the delivery is dead.
+func ApplyAttempt(delivery db.WebhookDelivery, statusCode int, err error, maxAttempts int, now time.Time) db.WebhookDelivery {
+	delivery.Attempts++
+	attempts := delivery.Attempts - delivery.AttemptBase
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.Status = db.WebhookDelivered
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	if attempts >= maxAttempts {
+		delivery.Status = db.WebhookDead
+		delivery.NextAttemptAt = nil
+		return delivery
+	}
+	next := now.Add(Backoff(attempts))
+	delivery.Status = db.WebhookPending
+	delivery.NextAttemptAt = &next
+	return delivery
+}
+
+// Dispatcher sends outbound webhooks. Every delivery is stored before it is
+// sent, failed ones are retried by RetryDue and end up dead after
+// MaxAttempts, where Replay can pick them up again.
+type Dispatcher struct {
+	DB          db.Database
+	Client      *http.Client
+	Secret      string
+	MaxAttempts int
+}
+
+func NewDispatcher(database db.Database) *Dispatcher {
+	return &Dispatcher{
+		DB:          database,
+		Client:      &http.Client{Timeout: requestTimeout},
+		Secret:      config.WebhookSecret,
+		MaxAttempts: config.WebhookMaxAttempts,
+	}
+}
+
+// Enqueue stores a delivery of payload as JSON and makes its first attempt in
+// the background
+func (d *Dispatcher) Enqueue(endpoint string, event string, payload interface{}) (*db.WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	// the first attempt holds the lease, RetryDue takes over if it never ends
+	delivery, err := d.DB.CreateWebhookDelivery(endpoint, event, string(body), time.Now().Add(claimLease))
+	if err != nil {
+		return nil, err
+	}
+
+	go d.Deliver(*delivery)
+	return delivery, nil
+}
+
+// Deliver makes one attempt of a delivery and logs it
+func (d *Dispatcher) Deliver(delivery db.WebhookDelivery) db.WebhookDelivery {
+	start := time.Now()
+	statusCode, err := d.send(delivery)
+	delivery = ApplyAttempt(delivery, statusCode, err, d.MaxAttempts, time.Now())
+
+	attempt := db.WebhookAttempt{
+		ID:         uuid.New(),
+		DeliveryID: delivery.ID,
+		Endpoint:   delivery.Endpoint,
+		Attempt:    delivery.Attempts,
+		StatusCode: statusCode,
+		DurationMs: time.Since(start).Milliseconds(),
+		CreatedAt:  start,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		logger.Log.Error("Webhook %s to %s failed on attempt %d: %v", delivery.ID, delivery.Endpoint, delivery.Attempts, err)
+	}
+	if err := d.DB.SaveWebhookAttempt(delivery, attempt); err != nil {
+		logger.Log.Error("Failed to save webhook attempt for %s: %v", delivery.ID, err)
+	}
+	return delivery
+}
+
+func (d *Dispatcher) send(delivery db.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.Endpoint, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	if d.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(d.Secret, []byte(delivery.Payload)))
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp.StatusCode, nil
+}
+
+// RetryDue sends the deliveries whose retry is due and returns how many
+func (d *Dispatcher) RetryDue() int {
+	deliveries, err := d.DB.ClaimDueWebhookDeliveries(time.Now(), claimLease, retryBatch)
+	if err != nil {
+		logger.Log.Error("Failed to claim webhook deliveries: %v", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, retryWorkers)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(delivery db.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			d.Deliver(delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+// Replay sends a delivery again right away. It claims the delivery first so
+// RetryDue can't send it at the same time, a delivered or dead one gets a
+// fresh set of attempts numbered on from the ones it made.
+func (d *Dispatcher) Replay(id uuid.UUID) (db.WebhookDelivery, error) {
+	delivery, err := d.DB.ClaimWebhookDelivery(id, time.Now(), claimLease)
+	if err != nil {
+		return db.WebhookDelivery{}, err
+	}
+	return d.Deliver(*delivery), nil
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stakwork/sphinx-tribes/db"
+	mocks "github.com/stakwork/sphinx-tribes/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(8))
+	assert.Equal(t, time.Hour, Backoff(100))
+}
+
+func TestApplyAttempt(t *testing.T) {
+	now := time.Now()
+	delivery := db.WebhookDelivery{ID: uuid.New(), Status: db.WebhookPending, Attempts: 1, LastError: "timeout"}
+
+	retried := ApplyAttempt(delivery, 502, errors.New("bad gateway"), 3, now)
+	assert.Equal(t, db.WebhookPending, retried.Status)
+	assert.Equal(t, 2, retried.Attempts)
+	assert.Equal(t, 502, retried.LastStatusCode)
+	require.NotNil(t, retried.NextAttemptAt)
+	assert.Equal(t, now.Add(time.Minute), *retried.NextAttemptAt)
+
+	dead := ApplyAttempt(retried, 0, errors.New("refused"), 3, now)
+	assert.Equal(t, db.WebhookDead, dead.Status)
+	assert.Nil(t, dead.NextAttemptAt)
+	assert.Equal(t, "refused", dead.LastError)
+
+	replayed := ApplyAttempt(db.WebhookDelivery{Status: db.WebhookPending, Attempts: 3, AttemptBase: 3}, 500, errors.New("down"), 3, now)
+	assert.Equal(t, db.WebhookPending, replayed.Status)
+	assert.Equal(t, 4, replayed.Attempts)
+	require.NotNil(t, replayed.NextAttemptAt)
+	assert.Equal(t, now.Add(30*time.Second), *replayed.NextAttemptAt)
+
+	delivered := ApplyAttempt(delivery, 200, nil, 3, now)
+	assert.Equal(t, db.WebhookDelivered, delivered.Status)
+	assert.Empty(t, delivered.LastError)
+	assert.Nil(t, delivered.NextAttemptAt)
+	assert.Equal(t, &now, delivered.DeliveredAt)
+}
+
+func TestDispatcherDeliver(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+		assert.Equal(t, "sse.events", r.Header.Get(EventHeader))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mockDb := mocks.NewDatabase(t)
+	dispatcher := &Dispatcher{DB: mockDb, Client: server.Client(), Secret: "secret", MaxAttempts: 3}
+	delivery := db.WebhookDelivery{ID: uuid.New(), Endpoint: server.URL, Event: "sse.events", Payload: `{"chatID":"chat"}`, Status: db.WebhookPending}
+
+	mockDb.On("SaveWebhookAttempt", mock.MatchedBy(func(d db.WebhookDelivery) bool {
+		return d.Status == db.WebhookPending && d.Attempts == 1
+	}), mock.MatchedBy(func(a db.WebhookAttempt) bool {
+		return a.DeliveryID == delivery.ID && a.StatusCode == http.StatusServiceUnavailable && a.Error == "endpoint returned status 503: try later"
+	})).Return(nil).Once()
+	failed := dispatcher.Deliver(delivery)
+	assert.Equal(t, db.WebhookPending, failed.Status)
+
+	mockDb.On("SaveWebhookAttempt", mock.MatchedBy(func(d db.WebhookDelivery) bool {
+		return d.Status == db.WebhookDelivered && d.Attempts == 2
+	}), mock.MatchedBy(func(a db.WebhookAttempt) bool {
+		return a.Attempt == 2 && a.StatusCode == http.StatusNoContent && a.Error == ""
+	})).Return(nil).Once()
+	delivered := dispatcher.Deliver(failed)
+	assert.Equal(t, db.WebhookDelivered, delivered.Status)
+}
+
+func TestDispatcherRetryAndReplay(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	mockDb := mocks.NewDatabase(t)
+	dispatcher := &Dispatcher{DB: mockDb, Client: server.Client(), MaxAttempts: 3}
+	due := []db.WebhookDelivery{
+		{ID: uuid.New(), Endpoint: server.URL, Payload: "{}", Status: db.WebhookPending, Attempts: 1},
+		{ID: uuid.New(), Endpoint: server.URL, Payload: "{}", Status: db.WebhookPending, Attempts: 2},
+	}
+	mockDb.On("ClaimDueWebhookDeliveries", mock.AnythingOfType("time.Time"), claimLease, retryBatch).Return(due, nil).Once()
+	mockDb.On("SaveWebhookAttempt", mock.MatchedBy(func(d db.WebhookDelivery) bool {
+		return d.Status == db.WebhookDelivered
+	}), mock.Anything).Return(nil).Twice()
+
+	assert.Equal(t, 2, dispatcher.RetryDue())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	claimed := db.WebhookDelivery{ID: uuid.New(), Endpoint: server.URL, Payload: "{}", Status: db.WebhookPending, Attempts: 3, AttemptBase: 3}
+	mockDb.On("ClaimWebhookDelivery", claimed.ID, mock.AnythingOfType("time.Time"), claimLease).Return(&claimed, nil).Once()
+	mockDb.On("SaveWebhookAttempt", mock.MatchedBy(func(d db.WebhookDelivery) bool {
+		return d.ID == claimed.ID && d.Status == db.WebhookDelivered && d.Attempts == 4
+	}), mock.MatchedBy(func(a db.WebhookAttempt) bool {
+		return a.Attempt == 4
+	})).Return(nil).Once()
+
+	replayed, err := dispatcher.Replay(claimed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.WebhookDelivered, replayed.Status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	busy := uuid.New()
+	mockDb.On("ClaimWebhookDelivery", busy, mock.AnythingOfType("time.Time"), claimLease).Return(nil, db.ErrWebhookDeliveryClaimed).Once()
+	_, err = dispatcher.Replay(busy)
+	assert.ErrorIs(t, err, db.ErrWebhookDeliveryClaimed)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	missing := uuid.New()
+	mockDb.On("ClaimWebhookDelivery", missing, mock.AnythingOfType("time.Time"), claimLease).Return(nil, db.ErrWebhookDeliveryNotFound).Once()
+	_, err = dispatcher.Replay(missing)
+	assert.ErrorIs(t, err, db.ErrWebhookDeliveryNotFound)
+}
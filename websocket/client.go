@@ -45,6 +45,14 @@ type TicketData struct {
 	TicketName        string `json:"ticketName,omitempty"`
 }
 
+type BoardMessage struct {
+	BroadcastType string          `json:"broadcast_type"`
+	WorkspaceUuid string          `json:"workspace_uuid"`
+	Action        string          `json:"action"`
+	FromStatus    db.TicketStatus `json:"from_status,omitempty"`
+	Card          db.BoardCard    `json:"card"`
+}
+
 type TicketPlanMessage struct {
     BroadcastType   string             `json:"broadcast_type"`
     SourceSessionID string             `json:"source_session_id"`
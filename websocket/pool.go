@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/stakwork/sphinx-tribes/db"
 )
@@ -11,6 +12,9 @@ type Pool struct {
 	Unregister chan *Client
 	Clients    map[string]*ClientData
 	Broadcast  chan Message
+
+	boardMu          sync.RWMutex
+	boardSubscribers map[string]map[string]bool
 }
 
 func NewPool() *Pool {
@@ -53,6 +57,7 @@ func (pool *Pool) Start() {
 			if pool.Clients[client.Host] != nil {
 				pool.Clients[client.Host].Client.Conn.WriteJSON(Message{Type: 1, Body: "User Disconnected..."})
 				delete(pool.Clients, client.Host)
+				pool.UnsubscribeBoards(client.Host)
 				fmt.Println("Size of Connection Pool: ", len(pool.Clients))
 			}
 
@@ -109,3 +114,62 @@ func (pool *Pool) SendTicketPlanMessage(message TicketPlanMessage) error {
 
 	return nil
 }
+
+// SubscribeBoard registers a connected client for board updates of a workspace
+func (pool *Pool) SubscribeBoard(workspaceUuid string, host string) error {
+	if pool == nil {
+		return fmt.Errorf("pool is nil")
+	}
+	if _, ok := pool.Clients[host]; !ok {
+		return fmt.Errorf("client not found: %s", host)
+	}
+
+	pool.boardMu.Lock()
+	defer pool.boardMu.Unlock()
+	if pool.boardSubscribers == nil {
+		pool.boardSubscribers = make(map[string]map[string]bool)
+	}
+	if pool.boardSubscribers[workspaceUuid] == nil {
+		pool.boardSubscribers[workspaceUuid] = make(map[string]bool)
+	}
+	pool.boardSubscribers[workspaceUuid][host] = true
+	return nil
+}
+
+func (pool *Pool) UnsubscribeBoards(host string) {
+	pool.boardMu.Lock()
+	defer pool.boardMu.Unlock()
+	for workspaceUuid, hosts := range pool.boardSubscribers {
+		delete(hosts, host)
+		if len(hosts) == 0 {
+			delete(pool.boardSubscribers, workspaceUuid)
+		}
+	}
+}
+
+// SendBoardMessage pushes a board change to every client subscribed to the
+// workspace board
+func (pool *Pool) SendBoardMessage(message BoardMessage) error {
+	if pool == nil {
+		return fmt.Errorf("pool is nil")
+	}
+
+	pool.boardMu.RLock()
+	hosts := make([]string, 0, len(pool.boardSubscribers[message.WorkspaceUuid]))
+	for host := range pool.boardSubscribers[message.WorkspaceUuid] {
+		hosts = append(hosts, host)
+	}
+	pool.boardMu.RUnlock()
+
+	var lastErr error
+	for _, host := range hosts {
+		client, ok := pool.Clients[host]
+		if !ok || client.Client.Conn == nil {
+			continue
+		}
+		if err := client.Client.Conn.WriteJSON(message); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
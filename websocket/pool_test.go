@@ -599,3 +599,27 @@ func setupTestWebsocket(t *testing.T) (*websocket.Conn, *httptest.Server) {
 
 	return ws, server
 }
+
+func TestSendBoardMessage(t *testing.T) {
+	ws, server := setupTestWebsocket(t)
+	defer server.Close()
+	defer ws.Close()
+
+	pool := NewPool()
+	pool.Clients["subscriber"] = &ClientData{Client: &Client{Host: "subscriber", Conn: ws, Pool: pool}, Status: true}
+
+	t.Run("Should only subscribe connected clients", func(t *testing.T) {
+		assert.Error(t, pool.SubscribeBoard("workspace", "unknown"))
+		assert.NoError(t, pool.SubscribeBoard("workspace", "subscriber"))
+	})
+
+	t.Run("Should push to subscribers of the workspace", func(t *testing.T) {
+		assert.NoError(t, pool.SendBoardMessage(BoardMessage{WorkspaceUuid: "workspace", Action: "move"}))
+		assert.NoError(t, pool.SendBoardMessage(BoardMessage{WorkspaceUuid: "other", Action: "move"}))
+	})
+
+	t.Run("Should drop subscriptions of disconnected clients", func(t *testing.T) {
+		pool.UnsubscribeBoards("subscriber")
+		assert.Empty(t, pool.boardSubscribers)
+	})
+}